@@ -1,17 +1,37 @@
 package gorm
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
 )
 
 // Association Mode contains some helper methods to handle relationship things easily.
+// `Append`, `Replace`, `Delete` and `Clear` run in a transaction, which is rolled back if any of their statements
+// fails, so relations aren't left half updated, the error is set to `Error`.
+// The methods keep returning `*Association` rather than an error, like `DB` methods, so they can still be chained,
+// e.g. `Unscoped().Where(...).Replace(...)`, and existing callers keep compiling; check `Error` after each operation
+//     if err := db.Model(&user).Association("Languages").Replace(languages).Error; err != nil {
+//       return err
+//     }
 type Association struct {
-	Error  error
-	scope  *Scope
-	column string
-	field  *Field
+	Error error
+	// RowsAffected is the number of associations appended and unlinked by the last `Append`, `Replace`, `Delete` or
+	// `Clear`, it is zero if the operation failed
+	RowsAffected int64
+	scope        *Scope
+	column       string
+	field        *Field
+}
+
+// Unscoped include soft deleted records when finding, counting, replacing or deleting associations
+func (association *Association) Unscoped() *Association {
+	if association.Error == nil {
+		association.scope.db = association.scope.db.Unscoped()
+		association.scope.Search.unscoped()
+	}
+	return association
 }
 
 // Find find out all related associations
@@ -29,25 +49,31 @@ func (association *Association) Append(values ...interface{}) *Association {
 	if relationship := association.field.Relationship; relationship.Kind == "has_one" {
 		return association.Replace(values...)
 	}
-	return association.saveAssociations(values...)
+	return association.transaction(func() {
+		association.saveAssociations(values...)
+	})
 }
 
 // Replace replace current associations with new one
 func (association *Association) Replace(values ...interface{}) *Association {
-	if association.Error != nil {
-		return association
-	}
+	return association.transaction(func() {
+		association.replace(values...)
+	})
+}
 
+func (association *Association) replace(values ...interface{}) {
 	var (
 		relationship = association.field.Relationship
 		scope        = association.scope
 		field        = association.field.Field
-		newDB        = scope.NewDB()
+		newDB        = association.newDB()
 	)
 
 	// Append new values
-	association.field.Set(reflect.Zero(association.field.Field.Type()))
-	association.saveAssociations(values...)
+	association.setErr(association.field.Set(reflect.Zero(association.field.Field.Type())))
+	if association.saveAssociations(values...); association.Error != nil {
+		return
+	}
 
 	// Belongs To
 	if relationship.Kind == "belongs_to" {
@@ -89,8 +115,8 @@ func (association *Association) Replace(values ...interface{}) *Association {
 			newPrimaryKeys := scope.getColumnAsArray(associationForeignFieldNames, field.Interface())
 
 			if len(newPrimaryKeys) > 0 {
-				sql := fmt.Sprintf("%v NOT IN (%v)", toQueryCondition(scope, associationForeignDBNames), toQueryMarks(newPrimaryKeys))
-				newDB = newDB.Where(sql, toQueryValues(newPrimaryKeys)...)
+				sql, vars := toNotInCondition(scope, associationForeignDBNames, newPrimaryKeys)
+				newDB = newDB.Where(sql, vars...)
 			}
 		}
 
@@ -105,7 +131,8 @@ func (association *Association) Replace(values ...interface{}) *Association {
 			}
 
 			if sourcePrimaryKeys := scope.getColumnAsArray(sourceForeignFieldNames, scope.Value); len(sourcePrimaryKeys) > 0 {
-				newDB = newDB.Where(fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(sourcePrimaryKeys)), toQueryValues(sourcePrimaryKeys)...)
+				condition, vars := toInCondition(scope, relationship.ForeignDBNames, sourcePrimaryKeys)
+				newDB = newDB.Where(condition, vars...)
 
 				association.setErr(relationship.JoinTableHandler.Delete(relationship.JoinTableHandler, newDB))
 			}
@@ -115,7 +142,7 @@ func (association *Association) Replace(values ...interface{}) *Association {
 			for idx, foreignKey := range relationship.ForeignDBNames {
 				foreignKeyMap[foreignKey] = nil
 				if field, ok := scope.FieldByName(relationship.AssociationForeignFieldNames[idx]); ok {
-					newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.Field.Interface())
+					newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.sqlValue())
 				}
 			}
 
@@ -123,26 +150,27 @@ func (association *Association) Replace(values ...interface{}) *Association {
 			association.setErr(newDB.Model(fieldValue).UpdateColumn(foreignKeyMap).Error)
 		}
 	}
-	return association
 }
 
 // Delete remove relationship between source & passed arguments, but won't delete those arguments
 func (association *Association) Delete(values ...interface{}) *Association {
-	if association.Error != nil {
+	if len(values) == 0 {
 		return association
 	}
 
+	return association.transaction(func() {
+		association.delete(values...)
+	})
+}
+
+func (association *Association) delete(values ...interface{}) {
 	var (
 		relationship = association.field.Relationship
 		scope        = association.scope
 		field        = association.field.Field
-		newDB        = scope.NewDB()
+		newDB        = association.newDB()
 	)
 
-	if len(values) == 0 {
-		return association
-	}
-
 	var deletingResourcePrimaryFieldNames, deletingResourcePrimaryDBNames []string
 	for _, field := range scope.New(reflect.New(field.Type()).Interface()).PrimaryFields() {
 		deletingResourcePrimaryFieldNames = append(deletingResourcePrimaryFieldNames, field.Name)
@@ -155,7 +183,7 @@ func (association *Association) Delete(values ...interface{}) *Association {
 		// source value's foreign keys
 		for idx, foreignKey := range relationship.ForeignDBNames {
 			if field, ok := scope.FieldByName(relationship.ForeignFieldNames[idx]); ok {
-				newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.Field.Interface())
+				newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.sqlValue())
 			}
 		}
 
@@ -170,8 +198,8 @@ func (association *Association) Delete(values ...interface{}) *Association {
 
 		// association value's foreign keys
 		deletingPrimaryKeys := scope.getColumnAsArray(associationForeignFieldNames, values...)
-		sql := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.AssociationForeignDBNames), toQueryMarks(deletingPrimaryKeys))
-		newDB = newDB.Where(sql, toQueryValues(deletingPrimaryKeys)...)
+		sql, vars := toInCondition(scope, relationship.AssociationForeignDBNames, deletingPrimaryKeys)
+		newDB = newDB.Where(sql, vars...)
 
 		association.setErr(relationship.JoinTableHandler.Delete(relationship.JoinTableHandler, newDB))
 	} else {
@@ -183,10 +211,8 @@ func (association *Association) Delete(values ...interface{}) *Association {
 		if relationship.Kind == "belongs_to" {
 			// find with deleting relation's foreign keys
 			primaryKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, values...)
-			newDB = newDB.Where(
-				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(primaryKeys)),
-				toQueryValues(primaryKeys)...,
-			)
+			condition, vars := toInCondition(scope, relationship.ForeignDBNames, primaryKeys)
+			newDB = newDB.Where(condition, vars...)
 
 			// set foreign key to be null if there are some records affected
 			modelValue := reflect.New(scope.GetModelStruct().ModelType).Interface()
@@ -200,16 +226,12 @@ func (association *Association) Delete(values ...interface{}) *Association {
 		} else if relationship.Kind == "has_one" || relationship.Kind == "has_many" {
 			// find all relations
 			primaryKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, scope.Value)
-			newDB = newDB.Where(
-				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(primaryKeys)),
-				toQueryValues(primaryKeys)...,
-			)
+			condition, vars := toInCondition(scope, relationship.ForeignDBNames, primaryKeys)
+			newDB = newDB.Where(condition, vars...)
 
 			// only include those deleting relations
-			newDB = newDB.Where(
-				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, deletingResourcePrimaryDBNames), toQueryMarks(deletingPrimaryKeys)),
-				toQueryValues(deletingPrimaryKeys)...,
-			)
+			condition, vars = toInCondition(scope, deletingResourcePrimaryDBNames, deletingPrimaryKeys)
+			newDB = newDB.Where(condition, vars...)
 
 			// set matched relation's foreign key to be null
 			fieldValue := reflect.New(association.field.Field.Type()).Interface()
@@ -237,19 +259,17 @@ func (association *Association) Delete(values ...interface{}) *Association {
 				}
 			}
 
-			association.field.Set(leftValues)
+			association.setErr(association.field.Set(leftValues))
 		} else if field.Kind() == reflect.Struct {
 			primaryKey := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, field.Interface())[0]
 			for _, pk := range deletingPrimaryKeys {
 				if equalAsString(primaryKey, pk) {
-					association.field.Set(reflect.Zero(field.Type()))
+					association.setErr(association.field.Set(reflect.Zero(field.Type())))
 					break
 				}
 			}
 		}
 	}
-
-	return association
 }
 
 // Clear remove relationship between source & current associations, won't delete those associations
@@ -257,10 +277,52 @@ func (association *Association) Clear() *Association {
 	return association.Replace()
 }
 
+// Where return a copy of the association filtering associations found, counted or checked with conditions, similar
+// to `DB.Where`, the association itself isn't changed
+//     db.Model(&user).Association("Orders").Where("state = ?", "paid").Exists()
+func (association *Association) Where(query interface{}, args ...interface{}) *Association {
+	if association.Error != nil {
+		return association
+	}
+
+	scope := *association.scope
+	scope.Search = association.scope.Search.clone()
+	scope.db = association.scope.db.Where(query, args...)
+	result := *association
+	result.scope = &scope
+	return &result
+}
+
 // Count return the count of current associations
 func (association *Association) Count() int {
+	var count = 0
+	if association.Error != nil {
+		return count
+	}
+
+	if err := association.relatedQuery().Count(&count).Error; err != nil {
+		association.Error = err
+	}
+	return count
+}
+
+// Exists report whether there are any current associations, without loading or counting them
+func (association *Association) Exists() bool {
+	if association.Error != nil {
+		return false
+	}
+
+	var exists int
+	err := association.relatedQuery().Select("1").Limit(1).Row().Scan(&exists)
+	if err != nil && err != sql.ErrNoRows {
+		association.Error = err
+	}
+	return err == nil
+}
+
+// relatedQuery return the query of current associations
+func (association *Association) relatedQuery() *DB {
 	var (
-		count        = 0
 		relationship = association.field.Relationship
 		scope        = association.scope
 		fieldValue   = association.field.Field.Interface()
@@ -272,16 +334,14 @@ func (association *Association) Count() int {
 		query = relationship.JoinTableHandler.JoinWith(relationship.JoinTableHandler, query, scope.Value)
 	case "has_many", "has_one":
 		primaryKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, scope.Value)
-		query = query.Where(
-			fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(primaryKeys)),
-			toQueryValues(primaryKeys)...,
-		)
+		condition, vars := toInCondition(scope, relationship.ForeignDBNames, primaryKeys)
+		query = query.Where(condition, vars...)
 	case "belongs_to":
 		primaryKeys := scope.getColumnAsArray(relationship.ForeignFieldNames, scope.Value)
-		query = query.Where(
-			fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.AssociationForeignDBNames), toQueryMarks(primaryKeys)),
-			toQueryValues(primaryKeys)...,
-		)
+		condition, vars := toInCondition(scope, relationship.AssociationForeignDBNames, primaryKeys)
+		query = query.Where(condition, vars...)
+	case "has_many_through":
+		query = scope.joinThrough(query, association.field.StructField)
 	}
 
 	if relationship.PolymorphicType != "" {
@@ -291,14 +351,11 @@ func (association *Association) Count() int {
 		)
 	}
 
-	if err := query.Model(fieldValue).Count(&count).Error; err != nil {
-		association.Error = err
-	}
-	return count
+	return query.Model(fieldValue)
 }
 
-// saveAssociations save passed values as associations
-func (association *Association) saveAssociations(values ...interface{}) *Association {
+// saveAssociations save passed values as associations, until one of them fails
+func (association *Association) saveAssociations(values ...interface{}) {
 	var (
 		scope        = association.scope
 		field        = association.field
@@ -316,7 +373,9 @@ func (association *Association) saveAssociations(values ...interface{}) *Associa
 		// value has to been saved for many2many
 		if relationship.Kind == "many_to_many" {
 			if scope.New(reflectValue.Interface()).PrimaryKeyZero() {
-				association.setErr(scope.NewDB().Save(reflectValue.Interface()).Error)
+				if association.setErr(scope.NewDB().Save(reflectValue.Interface()).Error).Error != nil {
+					return
+				}
 			}
 		}
 
@@ -324,21 +383,25 @@ func (association *Association) saveAssociations(values ...interface{}) *Associa
 		var fieldType = field.Field.Type()
 		var setFieldBackToValue, setSliceFieldBackToValue bool
 		if reflectValue.Type().AssignableTo(fieldType) {
-			field.Set(reflectValue)
+			association.setErr(field.Set(reflectValue))
 		} else if reflectValue.Type().Elem().AssignableTo(fieldType) {
 			// if field's type is struct, then need to set value back to argument after save
 			setFieldBackToValue = true
-			field.Set(reflectValue.Elem())
+			association.setErr(field.Set(reflectValue.Elem()))
 		} else if fieldType.Kind() == reflect.Slice {
 			if reflectValue.Type().AssignableTo(fieldType.Elem()) {
-				field.Set(reflect.Append(field.Field, reflectValue))
+				association.setErr(field.Set(reflect.Append(field.Field, reflectValue)))
 			} else if reflectValue.Type().Elem().AssignableTo(fieldType.Elem()) {
 				// if field's type is slice of struct, then need to set value back to argument after save
 				setSliceFieldBackToValue = true
-				field.Set(reflect.Append(field.Field, reflectValue.Elem()))
+				association.setErr(field.Set(reflect.Append(field.Field, reflectValue.Elem())))
 			}
 		}
 
+		if association.Error != nil {
+			return
+		}
+
 		if relationship.Kind == "many_to_many" {
 			association.setErr(relationship.JoinTableHandler.Add(relationship.JoinTableHandler, scope.NewDB(), scope.Value, reflectValue.Interface()))
 		} else {
@@ -350,21 +413,68 @@ func (association *Association) saveAssociations(values ...interface{}) *Associa
 				reflectValue.Elem().Set(field.Field.Index(field.Field.Len() - 1))
 			}
 		}
+
+		if association.Error == nil {
+			association.RowsAffected++
+		}
 	}
 
 	for _, value := range values {
+		if association.Error != nil {
+			return
+		}
+
 		reflectValue := reflect.ValueOf(value)
 		indirectReflectValue := reflect.Indirect(reflectValue)
 		if indirectReflectValue.Kind() == reflect.Struct {
 			saveAssociation(reflectValue)
 		} else if indirectReflectValue.Kind() == reflect.Slice {
-			for i := 0; i < indirectReflectValue.Len(); i++ {
+			for i := 0; i < indirectReflectValue.Len() && association.Error == nil; i++ {
 				saveAssociation(indirectReflectValue.Index(i))
 			}
 		} else {
 			association.setErr(errors.New("invalid value type"))
 		}
 	}
+}
+
+// newDB create a new DB without search information to unlink associations, respecting Unscoped, rows affected by its
+// statements are added to RowsAffected
+func (association *Association) newDB() *DB {
+	newDB := association.scope.NewDB()
+	if association.scope.db.search.Unscoped {
+		newDB = newDB.Unscoped()
+	}
+	return newDB.InstantSet("gorm:rows_affected_counter", &association.RowsAffected)
+}
+
+// transaction run the operation in a transaction of the source's DB, which is rolled back if the operation fails,
+// its error is set to `Error` rather than returned, refer `Association`
+func (association *Association) transaction(operation func()) *Association {
+	if association.Error != nil {
+		return association
+	}
+
+	if association.field.Relationship.Kind == "has_many_through" {
+		return association.setErr(errors.New("has many through associations can't be changed, change the associations they go through"))
+	}
+
+	var (
+		scope = association.scope
+		db    = scope.db
+	)
+	defer func() { scope.db = db }()
+
+	err := db.Transaction(func(tx *DB) error {
+		// transactions might be retried, refer `DB.SetRetryPolicy`
+		scope.db, association.Error, association.RowsAffected = tx, nil, 0
+		operation()
+		return association.Error
+	})
+
+	if association.setErr(err); association.Error != nil {
+		association.RowsAffected = 0
+	}
 	return association
 }
 
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/zanmato/gorm"
 )
@@ -1048,3 +1049,276 @@ func TestAutoSaveMany2ManyAssociation(t *testing.T) {
 		t.Errorf("Relationship should been updated")
 	}
 }
+
+func TestAssociationUnscoped(t *testing.T) {
+	user := User{Name: "association_unscoped", Emails: []Email{{Email: "unscoped1@example.com"}}, CreditCard: CreditCard{Number: "411111111111"}}
+	DB.Save(&user)
+	DB.Delete(&user.CreditCard)
+
+	if count := DB.Model(&user).Association("CreditCard").Count(); count != 0 {
+		t.Errorf("Soft deleted credit card should not be counted, but got %v", count)
+	}
+
+	if count := DB.Model(&user).Association("CreditCard").Unscoped().Count(); count != 1 {
+		t.Errorf("Unscoped association should count soft deleted credit card, but got %v", count)
+	}
+
+	var card CreditCard
+	DB.Model(&user).Association("CreditCard").Unscoped().Find(&card)
+	if card.Number != "411111111111" {
+		t.Errorf("Unscoped association should find soft deleted credit card, but got %v", card.Number)
+	}
+}
+
+func TestAssociationExists(t *testing.T) {
+	user := User{
+		Name:      "association_exists",
+		Emails:    []Email{{Email: "exists1@example.com"}, {Email: "exists2@example.org"}},
+		Languages: []Language{{Name: "ExistsEN"}, {Name: "ExistsDE"}},
+	}
+	DB.Save(&user)
+
+	if !DB.Model(&user).Association("Emails").Exists() {
+		t.Errorf("User should have emails")
+	}
+
+	if count := DB.Model(&user).Association("Emails").Where("email LIKE ?", "%.org").Count(); count != 1 {
+		t.Errorf("User should have 1 email matching conditions, got %v", count)
+	}
+
+	if DB.Model(&user).Association("Emails").Where("email LIKE ?", "%.net").Exists() {
+		t.Errorf("User shouldn't have emails matching conditions")
+	}
+
+	if !DB.Model(&user).Association("Languages").Where("name = ?", "ExistsDE").Exists() {
+		t.Errorf("User should have languages matching conditions")
+	}
+
+	DB.Where("name = ?", "ExistsDE").Delete(&Language{})
+	if DB.Model(&user).Association("Languages").Where("name = ?", "ExistsDE").Exists() {
+		t.Errorf("Soft deleted languages shouldn't exist")
+	}
+
+	if !DB.Model(&user).Association("Languages").Unscoped().Where("name = ?", "ExistsDE").Exists() {
+		t.Errorf("Soft deleted languages should exist with Unscoped")
+	}
+
+	var emails []Email
+	if DB.Model(&user).Association("Emails").Where("email LIKE ?", "%.com").Find(&emails); len(emails) != 1 {
+		t.Errorf("Should find emails matching conditions, got %v", emails)
+	}
+
+	association := DB.Model(&user).Association("Emails")
+	if association.Where("email LIKE ?", "%.org").Count() != 1 || association.Count() != 2 {
+		t.Errorf("Conditions shouldn't stick to the association, got %v emails", association.Count())
+	}
+
+	if association := DB.Model(&user).Association("Unknown"); association.Exists() || association.Count() != 0 || association.Error == nil {
+		t.Errorf("Invalid associations shouldn't exist")
+	}
+}
+
+func TestFullSaveAssociations(t *testing.T) {
+	type FullSaveEmail struct {
+		gorm.Model
+		FullSaveUserID uint
+		Email          string
+	}
+
+	type FullSaveUser struct {
+		gorm.Model
+		Name   string
+		Emails []FullSaveEmail `gorm:"association_autoupdate:false;association_autocreate:false;"`
+	}
+
+	DB.Set("gorm:table_options", "").DropTableIfExists(&FullSaveEmail{}, &FullSaveUser{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&FullSaveEmail{}, &FullSaveUser{})
+
+	user := FullSaveUser{Name: "full_save", Emails: []FullSaveEmail{{Email: "full_save@example.com"}}}
+	DB.Save(&user)
+
+	var count int
+	if DB.Model(&FullSaveEmail{}).Count(&count); count != 0 {
+		t.Errorf("Emails shouldn't be created when autocreate is false, got %v", count)
+	}
+
+	fullSave := DB.Session(&gorm.Session{FullSaveAssociations: true})
+	if err := fullSave.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to full save associations, got %v", err)
+	}
+
+	if DB.Model(&FullSaveEmail{}).Where("full_save_user_id = ?", user.ID).Count(&count); count != 1 {
+		t.Errorf("Emails should be created with full save associations, got %v", count)
+	}
+
+	user.Emails[0].Email = "full_save_changed@example.com"
+	DB.Save(&user)
+
+	var email FullSaveEmail
+	if DB.First(&email, user.Emails[0].ID); email.Email != "full_save@example.com" {
+		t.Errorf("Emails shouldn't be updated when autoupdate is false, got %v", email.Email)
+	}
+
+	fullSave.Save(&user)
+	if DB.First(&email, user.Emails[0].ID); email.Email != "full_save_changed@example.com" {
+		t.Errorf("Emails should be updated with full save associations, got %v", email.Email)
+	}
+
+	fullSave.Model(&user).Updates(FullSaveUser{Emails: []FullSaveEmail{
+		{Model: gorm.Model{ID: user.Emails[0].ID}, Email: "full_save_updated@example.com"},
+		{Email: "full_save_new@example.com"},
+	}})
+
+	if DB.First(&email, user.Emails[0].ID); email.Email != "full_save_updated@example.com" {
+		t.Errorf("Emails should be updated by Updates with full save associations, got %v", email.Email)
+	}
+
+	if DB.Model(&FullSaveEmail{}).Where("full_save_user_id = ?", user.ID).Count(&count); count != 2 {
+		t.Errorf("Emails should be created by Updates with full save associations, got %v", count)
+	}
+
+	fullSave.Model(&user).UpdateColumns(FullSaveUser{Name: "full_save_columns", Emails: []FullSaveEmail{{Email: "full_save_columns@example.com"}}})
+	if DB.Model(&FullSaveEmail{}).Where("full_save_user_id = ?", user.ID).Count(&count); count != 2 {
+		t.Errorf("Emails shouldn't be saved by UpdateColumns, got %v", count)
+	}
+}
+
+type AssociationOwner struct {
+	ID    uint
+	Items []AssociationItem
+}
+
+type AssociationItem struct {
+	ID                 uint
+	AssociationOwnerID uint
+	Name               string
+}
+
+func (item *AssociationItem) Validate() error {
+	if item.Name == "" {
+		return gorm.ValidationErrors{{Field: "Name", Message: "can't be blank"}}
+	}
+	return nil
+}
+
+func TestAssociationTransaction(t *testing.T) {
+	DB.Set("gorm:table_options", "").AutoMigrate(&AssociationOwner{}, &AssociationItem{})
+
+	owner := AssociationOwner{}
+	DB.Save(&owner)
+
+	association := DB.Model(&owner).Association("Items")
+	if association.Append(&AssociationItem{Name: "a"}, &AssociationItem{Name: "b"}); association.Error != nil || association.RowsAffected != 2 {
+		t.Fatalf("Associations should be appended, got %v, %v", association.RowsAffected, association.Error)
+	}
+
+	association = DB.Model(&owner).Association("Items").Replace(&AssociationItem{Name: "c"}, &AssociationItem{})
+	if _, ok := association.Error.(gorm.ValidationErrors); !ok || association.RowsAffected != 0 {
+		t.Errorf("Errors of replacing should be reported, got %v, %v", association.RowsAffected, association.Error)
+	}
+
+	var items []AssociationItem
+	DB.Where("association_owner_id = ?", owner.ID).Order("name").Find(&items)
+	if len(items) != 2 || items[0].Name != "a" || items[1].Name != "b" {
+		t.Errorf("Failed replacing should be rolled back, got %v", items)
+	}
+
+	owner = AssociationOwner{ID: owner.ID}
+	association = DB.Model(&owner).Association("Items").Replace(&AssociationItem{Name: "c"})
+	if association.Error != nil || association.RowsAffected != 3 {
+		t.Errorf("Appended and unlinked associations should be counted, got %v, %v", association.RowsAffected, association.Error)
+	}
+
+	association = DB.Model(&owner).Association("Items").Delete(owner.Items)
+	if association.Error != nil || association.RowsAffected != 1 || len(owner.Items) != 0 {
+		t.Errorf("Deleted associations should be counted, got %v, %v", association.RowsAffected, association.Error)
+	}
+
+	if count := DB.Model(&owner).Association("Items").Count(); count != 0 {
+		t.Errorf("Associations should be deleted, got %v", count)
+	}
+}
+
+type ThroughUser struct {
+	ID        uint
+	Name      string
+	Orders    []ThroughOrder
+	LineItems []ThroughLineItem `gorm:"through:Orders"`
+}
+
+type ThroughOrder struct {
+	ID            uint
+	ThroughUserID uint
+	DeletedAt     *time.Time
+	LineItems     []ThroughLineItem
+}
+
+type ThroughLineItem struct {
+	ID             uint
+	ThroughOrderID uint
+	Item           string
+}
+
+func TestHasManyThrough(t *testing.T) {
+	DB.DropTableIfExists(&ThroughUser{}, &ThroughOrder{}, &ThroughLineItem{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&ThroughUser{}, &ThroughOrder{}, &ThroughLineItem{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	users := []ThroughUser{
+		{Name: "through 1", Orders: []ThroughOrder{
+			{LineItems: []ThroughLineItem{{Item: "bolt"}, {Item: "nut"}}},
+			{LineItems: []ThroughLineItem{{Item: "washer"}}},
+		}},
+		{Name: "through 2", Orders: []ThroughOrder{{LineItems: []ThroughLineItem{{Item: "screw"}}}}},
+		{Name: "through 3"},
+	}
+	for i := range users {
+		if err := DB.Save(&users[i]).Error; err != nil {
+			t.Fatalf("Failed to save, got %v", err)
+		}
+	}
+
+	var found []ThroughUser
+	if err := DB.Preload("LineItems").Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("Failed to preload has many through associations, got %v", err)
+	}
+	if len(found) != 3 || len(found[0].LineItems) != 3 || len(found[1].LineItems) != 1 || found[1].LineItems[0].Item != "screw" || found[2].LineItems == nil || len(found[2].LineItems) != 0 {
+		t.Errorf("Has many through associations should be preloaded, got %+v", found)
+	}
+	if len(found[0].Orders) != 0 {
+		t.Errorf("Intermediate associations shouldn't be preloaded, got %+v", found[0].Orders)
+	}
+
+	var user ThroughUser
+	DB.Preload("LineItems", "item <> ?", "nut").First(&user, users[0].ID)
+	if len(user.LineItems) != 2 {
+		t.Errorf("Has many through associations should be preloaded with conditions, got %+v", user.LineItems)
+	}
+
+	if count := DB.Model(&users[0]).Association("LineItems").Count(); count != 3 {
+		t.Errorf("Has many through associations should be counted, got %v", count)
+	}
+	if count := DB.Model(&users[2]).Association("LineItems").Count(); count != 0 {
+		t.Errorf("Has many through associations of users without orders should be counted, got %v", count)
+	}
+
+	var lineItems []ThroughLineItem
+	DB.Model(&users[1]).Related(&lineItems, "LineItems")
+	if len(lineItems) != 1 || lineItems[0].ID != users[1].Orders[0].LineItems[0].ID {
+		t.Errorf("Has many through associations should be found with Related, got %+v", lineItems)
+	}
+
+	DB.Delete(&users[0].Orders[1])
+	if count := DB.Model(&users[0]).Association("LineItems").Count(); count != 2 {
+		t.Errorf("Associations of soft deleted intermediate records shouldn't be counted, got %v", count)
+	}
+	DB.Preload("LineItems").First(&user, users[0].ID)
+	if len(user.LineItems) != 2 {
+		t.Errorf("Associations of soft deleted intermediate records shouldn't be preloaded, got %+v", user.LineItems)
+	}
+
+	if err := DB.Model(&users[2]).Association("LineItems").Append(&ThroughLineItem{Item: "rivet"}).Error; err == nil {
+		t.Errorf("Has many through associations shouldn't be changed")
+	}
+}
@@ -0,0 +1,75 @@
+package gorm
+
+// Audit fields are filled with the value of setting `gorm:current_user` when it is set, fields are found by names
+// or tags
+//     type Document struct {
+//       ID        uint
+//       CreatedBy uint                      // set when creating if blank
+//       UpdatedBy uint                      // set when creating and updating
+//       DeletedBy *uint                     // set when soft deleting
+//       Reviewer  uint  `gorm:"updated_by"` // tagged fields are filled too
+//     }
+//
+//     db.Set("gorm:current_user", currentUser.ID).Save(&document)
+const (
+	auditCreatedBy = "CREATED_BY"
+	auditUpdatedBy = "UPDATED_BY"
+	auditDeletedBy = "DELETED_BY"
+)
+
+var auditFieldNames = map[string]string{
+	auditCreatedBy: "CreatedBy",
+	auditUpdatedBy: "UpdatedBy",
+	auditDeletedBy: "DeletedBy",
+}
+
+// auditFields return fields tagged with the audit tag or named with the audit name
+func (scope *Scope) auditFields(tag string) (fields []*Field) {
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+
+		if _, ok := field.TagSettingsGet(tag); ok || field.Name == auditFieldNames[tag] {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+func (scope *Scope) currentUser() (interface{}, bool) {
+	user, ok := scope.Get("gorm:current_user")
+	return user, ok && user != nil
+}
+
+// auditForCreateCallback set `CreatedBy` and `UpdatedBy` fields to the current user when creating
+func auditForCreateCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if user, ok := scope.currentUser(); ok {
+		for _, field := range scope.auditFields(auditCreatedBy) {
+			if field.IsBlank {
+				scope.Err(field.Set(user))
+			}
+		}
+
+		for _, field := range scope.auditFields(auditUpdatedBy) {
+			scope.Err(field.Set(user))
+		}
+	}
+}
+
+// auditForUpdateCallback set `UpdatedBy` fields to the current user when updating
+func auditForUpdateCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:update_column"); ok || scope.HasError() {
+		return
+	}
+
+	if user, ok := scope.currentUser(); ok {
+		for _, field := range scope.auditFields(auditUpdatedBy) {
+			scope.Err(scope.SetColumn(field, user))
+		}
+	}
+}
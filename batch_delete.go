@@ -0,0 +1,38 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DeleteInBatches delete records matching conditions with statements deleting up to batchSize records each, until
+// no record matches, so purging lots of records doesn't lock the table or hold a huge transaction for long.
+// Conditions are the same as `Delete`, records are soft deleted if the model supports it
+//     db.DeleteInBatches(&Event{}, 1000, "created_at < ?", time.Now().AddDate(0, -6, 0))
+// records are deleted with `Limit`, refer `WriteLimiter`
+func (s *DB) DeleteInBatches(value interface{}, batchSize int, where ...interface{}) *DB {
+	db := s.clone()
+	if batchSize <= 0 {
+		db.AddError(errors.New("batch size of DeleteInBatches should be positive"))
+		return db
+	}
+
+	scope := s.NewScope(value)
+	if len(scope.PrimaryFields()) == 0 {
+		db.AddError(fmt.Errorf("DeleteInBatches requires primary keys of %v", scope.TableName()))
+		return db
+	}
+
+	for {
+		result := s.Limit(batchSize).Delete(value, where...)
+		db.RowsAffected += result.RowsAffected
+		if result.Error != nil {
+			db.AddError(result.Error)
+			return db
+		}
+
+		if result.RowsAffected < int64(batchSize) || scope.isDryRun() {
+			return db
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OnConflict is the conflict handling of inserting, refer `DB.OnConflict`
+type OnConflict struct {
+	// Columns is the conflict target, defaults to primary keys, it is ignored by mysql which handles conflicts of
+	// all unique indexes
+	Columns []string
+	// DoNothing skip rows conflicting with existing records
+	DoNothing bool
+	// DoUpdates is columns updated with inserting values when rows conflict
+	DoUpdates []string
+	// UpdateAll update all inserted columns except primary keys and created_at when rows conflict
+	UpdateAll bool
+}
+
+// OnConflict set the conflict handling of `CreateInBatches`, which makes it an upsert
+//     db.OnConflict(gorm.OnConflict{Columns: []string{"code"}, DoUpdates: []string{"price", "stock"}}).CreateInBatches(&products, 1000)
+//     // INSERT INTO products (code,price,stock) VALUES (...),(...) ON CONFLICT (code) DO UPDATE SET price = excluded.price, stock = excluded.stock
+func (s *DB) OnConflict(onConflict OnConflict) *DB {
+	return s.Set("gorm:on_conflict", onConflict)
+}
+
+// CreateInBatches insert records of the slice with multi rows INSERT statements, each of them inserts up to
+// batchSize records, all records are inserted with one statement if batchSize isn't positive.
+// `BeforeSave`, `BeforeCreate`, `AfterCreate` and `AfterSave` hooks are called for each record, but associations
+// aren't saved. Primary keys are set with `RETURNING` for postgres, and with the last insert id for other dialects
+// unless conflicts are handled.
+// Columns that are blank in all records of a batch and have default values are not inserted, so databases
+// fill the default values, blank values of other records are inserted as they are
+func (s *DB) CreateInBatches(values interface{}, batchSize int) *DB {
+	var (
+		db           = s.clone()
+		reflectValue = indirect(reflect.ValueOf(values))
+	)
+
+	if reflectValue.Kind() != reflect.Slice {
+		db.AddError(errors.New("values of CreateInBatches should be a slice"))
+		return db
+	}
+
+	if batchSize <= 0 {
+		batchSize = reflectValue.Len()
+	}
+
+	for i := 0; i < reflectValue.Len(); i += batchSize {
+		end := i + batchSize
+		if end > reflectValue.Len() {
+			end = reflectValue.Len()
+		}
+
+		result := s.insertBatch(reflectValue.Slice(i, end))
+		db.RowsAffected += result.RowsAffected
+		if result.Error != nil {
+			db.AddError(result.Error)
+			return db
+		}
+	}
+	return db
+}
+
+// batchScopes return scopes of records with `BeforeSave`, `BeforeCreate` hooks called, fields transformed, records
+// validated and tenants, timestamps, audit fields, UUIDs, IDs set
+func (s *DB) batchScopes(records reflect.Value) ([]*Scope, *DB) {
+	var scopes []*Scope
+	for i := 0; i < records.Len(); i++ {
+		record := records.Index(i)
+		if record.Kind() != reflect.Ptr {
+			record = record.Addr()
+		}
+
+		scope := s.NewScope(record.Interface())
+		for _, callback := range []func(*Scope){tenancyForCreateCallback, beforeCreateCallback, transformFieldsCallback, validateCallback, updateTimeStampForCreateCallback, auditForCreateCallback, generateUUIDCallback, generateIDCallback, normalizeTimesCallback} {
+			if callback(scope); scope.HasError() {
+				return nil, scope.db
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// batchFieldIndexes return indexes of fields inserted for records of scopes, fields that are blank in all records
+// and have default values are skipped
+func (scope *Scope) batchFieldIndexes(scopes []*Scope) (fieldIndexes []int) {
+	for index, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored || !scope.changeableField(field) {
+			continue
+		}
+
+		allBlank := true
+		for _, recordScope := range scopes {
+			allBlank = allBlank && recordScope.Fields()[index].IsBlank
+		}
+
+		if allBlank && (field.HasDefaultValue || field.IsPrimaryKey) {
+			continue
+		}
+		fieldIndexes = append(fieldIndexes, index)
+	}
+	return
+}
+
+// afterBatchCallbacks call `AfterCreate`, `AfterSave` hooks of records of scopes, save their histories and invalidate
+// cached queries
+func (scope *Scope) afterBatchCallbacks(scopes []*Scope) {
+	for _, recordScope := range scopes {
+		if afterCreateCallback(recordScope); recordScope.HasError() {
+			scope.Err(recordScope.db.Error)
+			return
+		}
+
+		if saveHistoryForCreateCallback(recordScope); recordScope.HasError() {
+			scope.Err(recordScope.db.Error)
+			return
+		}
+	}
+
+	invalidateQueryCacheCallback(scope)
+}
+
+func (s *DB) insertBatch(records reflect.Value) *DB {
+	scopes, failed := s.batchScopes(records)
+	if failed != nil {
+		return failed
+	}
+
+	if len(scopes) == 0 {
+		return s.clone()
+	}
+
+	var (
+		scope        = s.NewScope(records.Index(0).Interface())
+		columns      []string
+		fieldIndexes = scope.batchFieldIndexes(scopes)
+		rows         []string
+		primaryField = scope.PrimaryField()
+	)
+
+	for _, index := range fieldIndexes {
+		columns = append(columns, scope.Quote(scope.Fields()[index].DBName))
+	}
+
+	for _, recordScope := range scopes {
+		var placeholders []string
+		for _, index := range fieldIndexes {
+			placeholders = append(placeholders, scope.AddToVars(recordScope.Fields()[index].sqlValue()))
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ",")+")")
+	}
+
+	onConflict, hasOnConflict := scope.Get("gorm:on_conflict")
+	conflictSQL, err := scope.onConflictSQL(onConflict, hasOnConflict, fieldIndexes)
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+
+	var (
+		quotedTableName = scope.QuotedTableName()
+		returningSQL    string
+	)
+	if primaryField != nil {
+		returningSQL = scope.Dialect().LastInsertIDReturningSuffix(quotedTableName, scope.Quote(primaryField.DBName))
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"INSERT INTO %v (%v) VALUES %v%v%v",
+		quotedTableName,
+		strings.Join(columns, ","),
+		strings.Join(rows, ","),
+		addExtraSpaceIfExist(conflictSQL),
+		addExtraSpaceIfExist(returningSQL),
+	))
+
+	if scope.dryRun() {
+		return scope.db
+	}
+	defer scope.trace(NowFunc())
+
+	if returningSQL == "" {
+		result, err := scope.sqlExec(scope.SQL, scope.SQLVars...)
+		if scope.Err(err) != nil {
+			return scope.db
+		}
+		scope.db.RowsAffected, _ = result.RowsAffected()
+
+		// last insert ids are sequential for multi rows inserts without conflicts
+		if lastInsertID, err := result.LastInsertId(); err == nil && lastInsertID > 0 && primaryField != nil && !hasOnConflict {
+			firstID := lastInsertID
+			if scope.Dialect().GetName() != "mysql" {
+				firstID = lastInsertID - int64(len(scopes)) + 1
+			}
+			for idx, recordScope := range scopes {
+				if field := recordScope.PrimaryField(); field != nil && field.IsBlank {
+					scope.Err(field.Set(firstID + int64(idx)))
+				}
+			}
+		}
+	} else {
+		rows, err := scope.sqlQuery(scope.SQL, scope.SQLVars...)
+		if scope.Err(err) != nil {
+			return scope.db
+		}
+		defer rows.Close()
+
+		var ids []interface{}
+		for rows.Next() {
+			id := reflect.New(primaryField.Struct.Type)
+			if scope.Err(rows.Scan(id.Interface())) != nil {
+				return scope.db
+			}
+			ids = append(ids, id.Elem().Interface())
+		}
+		scope.Err(rows.Err())
+
+		scope.db.RowsAffected = int64(len(ids))
+		if len(ids) == len(scopes) {
+			for idx, recordScope := range scopes {
+				scope.Err(recordScope.PrimaryField().Set(ids[idx]))
+			}
+		}
+	}
+
+	if !scope.HasError() {
+		scope.afterBatchCallbacks(scopes)
+	}
+	return scope.db
+}
+
+// onConflictSQL return the clause handling conflicts of inserting, fieldIndexes is indexes of inserted fields
+func (scope *Scope) onConflictSQL(value interface{}, ok bool, fieldIndexes []int) (string, error) {
+	if !ok {
+		return "", nil
+	}
+
+	onConflict, ok := value.(OnConflict)
+	if !ok {
+		return "", fmt.Errorf("invalid conflict handling %v", value)
+	}
+
+	updates := onConflict.DoUpdates
+	if onConflict.UpdateAll {
+		fields := scope.Fields()
+		for _, index := range fieldIndexes {
+			if field := fields[index]; !field.IsPrimaryKey && !isAutoTimeField(field, autoCreateTime) {
+				updates = append(updates, field.DBName)
+			}
+		}
+	}
+
+	targets := onConflict.Columns
+	if len(targets) == 0 {
+		for _, field := range scope.PrimaryFields() {
+			targets = append(targets, field.DBName)
+		}
+	}
+
+	var quotedTargets []string
+	for _, target := range targets {
+		quotedTargets = append(quotedTargets, scope.Quote(target))
+	}
+
+	switch scope.Dialect().GetName() {
+	case "mysql":
+		var assignments []string
+		for _, column := range updates {
+			assignments = append(assignments, fmt.Sprintf("%v = VALUES(%v)", scope.Quote(column), scope.Quote(column)))
+		}
+		if onConflict.DoNothing || len(assignments) == 0 {
+			if len(targets) == 0 {
+				return "", errors.New("on conflict do nothing requires conflict columns or a primary key")
+			}
+			// assign the column to itself, so conflicting rows are left untouched
+			column := scope.Quote(targets[0])
+			assignments = []string{fmt.Sprintf("%v = %v", column, column)}
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", "), nil
+	case "postgres", "cockroach", "sqlite3":
+		if len(quotedTargets) == 0 {
+			return "", errors.New("conflict columns are required without primary keys")
+		}
+
+		if onConflict.DoNothing || len(updates) == 0 {
+			return fmt.Sprintf("ON CONFLICT (%v) DO NOTHING", strings.Join(quotedTargets, ",")), nil
+		}
+
+		var assignments []string
+		for _, column := range updates {
+			assignments = append(assignments, fmt.Sprintf("%v = excluded.%v", scope.Quote(column), scope.Quote(column)))
+		}
+		return fmt.Sprintf("ON CONFLICT (%v) DO UPDATE SET %v", strings.Join(quotedTargets, ","), strings.Join(assignments, ", ")), nil
+	default:
+		return "", fmt.Errorf("dialect %v doesn't support handling conflicts of inserting", scope.Dialect().GetName())
+	}
+}
@@ -0,0 +1,208 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// BatchLoader combine queries of records by primary keys within a window into one `IN` query, like DataLoader,
+// it is usually created for each request, so records aren't shared between requests
+//     db := db.BatchLoad(gorm.NewBatchLoader(2 * time.Millisecond))
+//     // in resolvers running concurrently
+//     db.First(&user, post.UserID)
+//     // SELECT * FROM users WHERE users.id IN (1,2,3)
+// only queries of a single record with an inline primary key and no other conditions are batched, they wait for the
+// window and get ErrRecordNotFound like `First` if the record isn't found, queries in transactions aren't batched
+type BatchLoader struct {
+	wait     time.Duration
+	maxBatch int
+	mutex    sync.Mutex
+	batches  map[string]*loadBatch
+}
+
+// loadBatch is primary keys of queries waiting to be loaded together
+type loadBatch struct {
+	db        *DB
+	modelType reflect.Type
+	condition string
+	ids       []interface{}
+	keys      map[string]bool
+	records   map[string]reflect.Value
+	err       error
+	done      chan struct{}
+	timer     *time.Timer
+}
+
+// NewBatchLoader create a loader combining queries within wait
+func NewBatchLoader(wait time.Duration) *BatchLoader {
+	return &BatchLoader{wait: wait, batches: map[string]*loadBatch{}}
+}
+
+// MaxBatch load the batch without waiting once it has maxBatch primary keys, batches are unlimited by default
+func (loader *BatchLoader) MaxBatch(maxBatch int) *BatchLoader {
+	loader.maxBatch = maxBatch
+	return loader
+}
+
+// BatchLoad batch queries of records by primary keys with the loader, refer `BatchLoader`
+func (s *DB) BatchLoad(loader *BatchLoader) *DB {
+	return s.Set("gorm:batch_loader", loader)
+}
+
+// batchLoadCallback wait for the batch of the query's primary key and copy the loaded record to the destination
+func batchLoadCallback(scope *Scope) {
+	value, ok := scope.Get("gorm:batch_loader")
+	loader, _ := value.(*BatchLoader)
+	if !ok || loader == nil || scope.HasError() || scope.isDryRun() {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	if _, inTransaction := scope.db.db.(sqlTx); inTransaction {
+		return
+	}
+
+	id, ok := scope.batchLoadID()
+	if !ok {
+		return
+	}
+
+	batch := loader.add(scope, id)
+	<-batch.done
+
+	if batch.err != nil {
+		scope.Err(batch.err)
+	} else if record, ok := batch.records[fmt.Sprint(id)]; ok {
+		scope.IndirectValue().Set(record)
+		scope.db.RowsAffected = 1
+	} else {
+		scope.recordNotFound()
+	}
+	scope.InstanceSet("gorm:skip_query_callback", true)
+}
+
+// batchLoadID return the primary key of queries which could be batched
+func (scope *Scope) batchLoadID() (interface{}, bool) {
+	search := scope.Search
+	if search.raw || len(search.whereConditions) != 1 ||
+		len(search.orConditions) > 0 || len(search.notConditions) > 0 || len(search.havingConditions) > 0 ||
+		len(search.joinConditions) > 0 || len(search.preload) > 0 || len(search.selects) > 0 || len(search.omits) > 0 ||
+		len(search.orders) > 0 || search.group != "" {
+		return nil, false
+	}
+
+	if limit := fmt.Sprint(search.limit); limit != "1" && limit != "-1" {
+		return nil, false
+	}
+
+	if offset := fmt.Sprint(search.offset); offset != "-1" && offset != "<nil>" {
+		return nil, false
+	}
+
+	if scope.queryDestination() != scope.Value || scope.IndirectValue().Kind() != reflect.Struct ||
+		len(scope.PrimaryFields()) != 1 {
+		return nil, false
+	}
+
+	for _, setting := range []string{"gorm:query_option", "gorm:query_hint"} {
+		if _, ok := scope.Get(setting); ok {
+			return nil, false
+		}
+	}
+
+	condition := search.whereConditions[0]
+	if args, _ := condition["args"].([]interface{}); len(args) > 0 {
+		return nil, false
+	}
+
+	switch id := condition["query"].(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return id, true
+	case string:
+		return id, isNumberRegexp.MatchString(id)
+	}
+	return nil, false
+}
+
+// add add the primary key to the batch of the scope's table, the batch is loaded after the wait of the loader or
+// once it is full
+func (loader *BatchLoader) add(scope *Scope, id interface{}) *loadBatch {
+	key := fmt.Sprintf("%p|%v|%v|%v", scope.db.db, scope.IndirectValue().Type(), scope.TableName(), scope.Search.Unscoped)
+
+	loader.mutex.Lock()
+	batch, ok := loader.batches[key]
+	if !ok {
+		db := scope.NewDB().Set("gorm:batch_loader", nil).Set("gorm:skip_hooks", true)
+		if scope.Search.Unscoped {
+			db = db.Unscoped()
+		}
+		if scope.Search.tableName != "" {
+			db = db.Table(scope.Search.tableName)
+		}
+
+		batch = &loadBatch{
+			db:        db,
+			modelType: scope.IndirectValue().Type(),
+			condition: fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(scope.PrimaryField().DBName)),
+			keys:      map[string]bool{},
+			done:      make(chan struct{}),
+		}
+		loader.batches[key] = batch
+		batch.timer = time.AfterFunc(loader.wait, func() {
+			if loader.take(key, batch) {
+				batch.load()
+			}
+		})
+	}
+
+	if !batch.keys[fmt.Sprint(id)] {
+		batch.keys[fmt.Sprint(id)] = true
+		batch.ids = append(batch.ids, id)
+	}
+
+	full := loader.maxBatch > 0 && len(batch.ids) >= loader.maxBatch
+	if full {
+		delete(loader.batches, key)
+	}
+	loader.mutex.Unlock()
+
+	if full {
+		batch.timer.Stop()
+		batch.load()
+	}
+	return batch
+}
+
+// take remove the batch from the loader when its wait is over, return false if it was taken as it is full
+func (loader *BatchLoader) take(key string, batch *loadBatch) bool {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	if loader.batches[key] == batch {
+		delete(loader.batches, key)
+		return true
+	}
+	return false
+}
+
+// load query records of the batch with their primary keys and wake up waiting queries
+func (batch *loadBatch) load() {
+	defer close(batch.done)
+
+	results := reflect.New(reflect.SliceOf(batch.modelType))
+	batch.err = batch.db.Where(batch.condition, batch.ids).Find(results.Interface()).Error
+	if batch.err != nil {
+		return
+	}
+
+	batch.records = map[string]reflect.Value{}
+	for i := 0; i < results.Elem().Len(); i++ {
+		record := results.Elem().Index(i)
+		batch.records[fmt.Sprint(batch.db.NewScope(record.Addr().Interface()).PrimaryKeyValue())] = record
+	}
+}
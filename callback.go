@@ -12,6 +12,7 @@ var DefaultCallback = &Callback{logger: nopLogger{}}
 //   Field `queries` contains callbacks will be call when querying object with query methods like Find, First, Related, Association...
 //   Field `rowQueries` contains callbacks will be call when querying object with Row, Rows...
 //   Field `processors` contains all callback processors, will be used to generate above callbacks in order
+//   Field `hooks` contains global hooks registered with `DB.RegisterGlobalHook`, by hook points
 type Callback struct {
 	logger     logger
 	creates    []*func(scope *Scope)
@@ -20,6 +21,7 @@ type Callback struct {
 	queries    []*func(scope *Scope)
 	rowQueries []*func(scope *Scope)
 	processors []*CallbackProcessor
+	hooks      map[Hook][]func(scope *Scope)
 }
 
 // CallbackProcessor contains callback informations
@@ -44,6 +46,7 @@ func (c *Callback) clone(logger logger) *Callback {
 		queries:    c.queries,
 		rowQueries: c.rowQueries,
 		processors: c.processors,
+		hooks:      c.hooks,
 	}
 }
 
@@ -7,41 +7,65 @@ import (
 
 // Define callbacks for creating
 func init() {
+	DefaultCallback.Create().Register("gorm:tenancy", tenancyForCreateCallback)
+	DefaultCallback.Create().Register("gorm:sharding", shardingCallback)
 	DefaultCallback.Create().Register("gorm:begin_transaction", beginTransactionCallback)
 	DefaultCallback.Create().Register("gorm:before_create", beforeCreateCallback)
+	DefaultCallback.Create().Register("gorm:transform_fields", transformFieldsCallback)
+	DefaultCallback.Create().Register("gorm:validate", validateCallback)
 	DefaultCallback.Create().Register("gorm:save_before_associations", saveBeforeAssociationsCallback)
 	DefaultCallback.Create().Register("gorm:update_time_stamp", updateTimeStampForCreateCallback)
+	DefaultCallback.Create().Register("gorm:audit", auditForCreateCallback)
+	DefaultCallback.Create().Register("gorm:generate_uuid", generateUUIDCallback)
+	DefaultCallback.Create().Register("gorm:generate_id", generateIDCallback)
+	DefaultCallback.Create().Register("gorm:normalize_times", normalizeTimesCallback)
 	DefaultCallback.Create().Register("gorm:create", createCallback)
 	DefaultCallback.Create().Register("gorm:force_reload_after_create", forceReloadAfterCreateCallback)
 	DefaultCallback.Create().Register("gorm:save_after_associations", saveAfterAssociationsCallback)
 	DefaultCallback.Create().Register("gorm:after_create", afterCreateCallback)
+	DefaultCallback.Create().Register("gorm:save_history", saveHistoryForCreateCallback)
 	DefaultCallback.Create().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
+	DefaultCallback.Create().Register("gorm:track_changes", trackChangesCallback)
+	DefaultCallback.Create().Register("gorm:invalidate_query_cache", invalidateQueryCacheCallback)
 }
 
 // beforeCreateCallback will invoke `BeforeSave`, `BeforeCreate` method before creating
 func beforeCreateCallback(scope *Scope) {
 	if !scope.HasError() {
 		scope.CallMethod("BeforeSave")
+		scope.callGlobalHooks(BeforeSave)
 	}
 	if !scope.HasError() {
 		scope.CallMethod("BeforeCreate")
+		scope.callGlobalHooks(BeforeCreate)
 	}
 }
 
-// updateTimeStampForCreateCallback will set `CreatedAt`, `UpdatedAt` when creating
+// updateTimeStampForCreateCallback will set `CreatedAt`, `UpdatedAt` and fields tagged with `autoCreateTime`,
+// `autoUpdateTime` when creating
 func updateTimeStampForCreateCallback(scope *Scope) {
 	if !scope.HasError() {
 		now := scope.db.nowFunc()
 
-		if createdAtField, ok := scope.FieldByName("CreatedAt"); ok {
-			if createdAtField.IsBlank {
-				createdAtField.Set(now)
+		for _, tag := range []string{autoCreateTime, autoUpdateTime} {
+			for _, field := range scope.timestampFields(tag) {
+				if field.IsBlank {
+					scope.Err(field.Set(timestampValue(field, tag, now)))
+				}
 			}
 		}
+	}
+}
 
-		if updatedAtField, ok := scope.FieldByName("UpdatedAt"); ok {
-			if updatedAtField.IsBlank {
-				updatedAtField.Set(now)
+// generateUUIDCallback will generate UUID for blank fields tagged with `default:uuid`, `default:uuid_v7`
+func generateUUIDCallback(scope *Scope) {
+	if !scope.HasError() {
+		for _, field := range scope.Fields() {
+			if generator, ok := uuidGeneratorOf(field.StructField); ok && field.IsBlank {
+				uuid, err := generator()
+				if scope.Err(err) == nil {
+					scope.Err(setUUID(field, uuid))
+				}
 			}
 		}
 	}
@@ -55,6 +79,7 @@ func createCallback(scope *Scope) {
 		var (
 			columns, placeholders        []string
 			blankColumnsWithDefaultValue []string
+			blankFieldsWithDefaultValue  []*Field
 		)
 
 		for _, field := range scope.Fields() {
@@ -62,10 +87,11 @@ func createCallback(scope *Scope) {
 				if field.IsNormal && !field.IsIgnored {
 					if field.IsBlank && field.HasDefaultValue {
 						blankColumnsWithDefaultValue = append(blankColumnsWithDefaultValue, scope.Quote(field.DBName))
+						blankFieldsWithDefaultValue = append(blankFieldsWithDefaultValue, field)
 						scope.InstanceSet("gorm:blank_columns_with_default_value", blankColumnsWithDefaultValue)
 					} else if !field.IsPrimaryKey || !field.IsBlank {
 						columns = append(columns, scope.Quote(field.DBName))
-						placeholders = append(placeholders, scope.AddToVars(field.Field.Interface()))
+						placeholders = append(placeholders, scope.AddToVars(field.sqlValue()))
 					}
 				} else if field.Relationship != nil && field.Relationship.Kind == "belongs_to" {
 					for _, foreignKey := range field.Relationship.ForeignDBNames {
@@ -106,6 +132,17 @@ func createCallback(scope *Scope) {
 			lastInsertIDReturningSuffix = scope.Dialect().LastInsertIDReturningSuffix(quotedTableName, returningColumn)
 		}
 
+		// read values generated by default expressions with the primary key, so they don't need to be reloaded
+		returningFields := []*Field{primaryField}
+		if primaryField != nil && strings.HasPrefix(lastInsertIDReturningSuffix, "RETURNING ") {
+			for _, field := range blankFieldsWithDefaultValue {
+				if field.Field.CanAddr() {
+					lastInsertIDReturningSuffix += fmt.Sprintf(", %v.%v", quotedTableName, scope.Quote(field.DBName))
+					returningFields = append(returningFields, field)
+				}
+			}
+		}
+
 		if len(columns) == 0 {
 			scope.Raw(fmt.Sprintf(
 				"INSERT%v INTO %v %v%v%v",
@@ -128,9 +165,13 @@ func createCallback(scope *Scope) {
 			))
 		}
 
+		if scope.dryRun() {
+			return
+		}
+
 		// execute create sql: no primaryField
 		if primaryField == nil {
-			if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			if result, err := scope.sqlExec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 				// set rows affected count
 				scope.db.RowsAffected, _ = result.RowsAffected()
 
@@ -146,12 +187,13 @@ func createCallback(scope *Scope) {
 
 		// execute create sql: lastInsertID implemention for majority of dialects
 		if lastInsertIDReturningSuffix == "" && lastInsertIDOutputInterstitial == "" {
-			if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			if result, err := scope.sqlExec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 				// set rows affected count
 				scope.db.RowsAffected, _ = result.RowsAffected()
 
-				// set primary value to primary field
-				if primaryField != nil && primaryField.IsBlank {
+				// set primary value to primary field, primary keys which aren't integers are generated by default
+				// expressions and can't be got from the last insert id
+				if primaryField != nil && primaryField.IsBlank && isIntegerType(primaryField.Struct.Type) {
 					if primaryValue, err := result.LastInsertId(); scope.Err(err) == nil {
 						scope.Err(primaryField.Set(primaryValue))
 					}
@@ -162,9 +204,20 @@ func createCallback(scope *Scope) {
 
 		// execute create sql: dialects with additional lastInsertID requirements (currently postgres & mssql)
 		if primaryField.Field.CanAddr() {
-			if err := scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...).Scan(primaryField.Field.Addr().Interface()); scope.Err(err) == nil {
-				primaryField.IsBlank = false
+			var dests []interface{}
+			for _, field := range returningFields {
+				dests = append(dests, field.Field.Addr().Interface())
+			}
+
+			if err := scope.sqlQueryRow(scope.SQL, scope.SQLVars...).Scan(dests...); scope.Err(err) == nil {
+				for _, field := range returningFields {
+					field.IsBlank = false
+				}
 				scope.db.RowsAffected = 1
+
+				if len(returningFields) == len(blankFieldsWithDefaultValue)+1 {
+					scope.InstanceSet("gorm:blank_columns_with_default_value", []string{})
+				}
 			}
 		} else {
 			scope.Err(ErrUnaddressable)
@@ -175,7 +228,7 @@ func createCallback(scope *Scope) {
 
 // forceReloadAfterCreateCallback will reload columns that having default value, and set it back to current object
 func forceReloadAfterCreateCallback(scope *Scope) {
-	if blankColumnsWithDefaultValue, ok := scope.InstanceGet("gorm:blank_columns_with_default_value"); ok {
+	if blankColumnsWithDefaultValue, ok := scope.InstanceGet("gorm:blank_columns_with_default_value"); ok && len(blankColumnsWithDefaultValue.([]string)) > 0 {
 		var shouldScan bool
 		db := scope.DB().New().Table(scope.TableName()).Select(blankColumnsWithDefaultValue.([]string))
 		for _, field := range scope.Fields() {
@@ -197,8 +250,10 @@ func forceReloadAfterCreateCallback(scope *Scope) {
 func afterCreateCallback(scope *Scope) {
 	if !scope.HasError() {
 		scope.CallMethod("AfterCreate")
+		scope.callGlobalHooks(AfterCreate)
 	}
 	if !scope.HasError() {
 		scope.CallMethod("AfterSave")
+		scope.callGlobalHooks(AfterSave)
 	}
 }
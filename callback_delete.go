@@ -3,15 +3,22 @@ package gorm
 import (
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // Define callbacks for deleting
 func init() {
+	DefaultCallback.Delete().Register("gorm:sharding", shardingCallback)
+	DefaultCallback.Delete().Register("gorm:tenancy", tenancyCallback)
 	DefaultCallback.Delete().Register("gorm:begin_transaction", beginTransactionCallback)
 	DefaultCallback.Delete().Register("gorm:before_delete", beforeDeleteCallback)
+	DefaultCallback.Delete().Register("gorm:delete_associations", deleteAssociationsCallback)
+	DefaultCallback.Delete().Register("gorm:find_history_records", findHistoryRecordsCallback)
 	DefaultCallback.Delete().Register("gorm:delete", deleteCallback)
 	DefaultCallback.Delete().Register("gorm:after_delete", afterDeleteCallback)
+	DefaultCallback.Delete().Register("gorm:save_history", saveHistoryForDeleteCallback)
 	DefaultCallback.Delete().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
+	DefaultCallback.Delete().Register("gorm:invalidate_query_cache", invalidateQueryCacheCallback)
 }
 
 // beforeDeleteCallback will invoke `BeforeDelete` method before deleting
@@ -22,6 +29,61 @@ func beforeDeleteCallback(scope *Scope) {
 	}
 	if !scope.HasError() {
 		scope.CallMethod("BeforeDelete")
+		scope.callGlobalHooks(BeforeDelete)
+	}
+}
+
+// deleteAssociationsCallback delete associations selected with `Select` together with records, see `Associations`
+func deleteAssociationsCallback(scope *Scope) {
+	if scope.HasError() || len(scope.Search.selects) == 0 {
+		return
+	}
+
+	var (
+		selects        = scope.SelectAttrs()
+		allAssociation = strInSlice(Associations, selects)
+	)
+
+	for _, field := range scope.Fields() {
+		relationship := field.Relationship
+		if relationship == nil || (!allAssociation && !strInSlice(field.Name, selects)) {
+			continue
+		}
+
+		switch relationship.Kind {
+		case "has_one", "has_many":
+			primaryKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, scope.Value)
+			if len(primaryKeys) == 0 {
+				continue
+			}
+
+			condition, vars := toInCondition(scope, relationship.ForeignDBNames, primaryKeys)
+			db := scope.NewDB().Where(condition, vars...)
+			if relationship.PolymorphicType != "" {
+				db = db.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.PolymorphicValue)
+			}
+			if scope.Search.Unscoped {
+				db = db.Unscoped()
+			}
+
+			fieldType := field.Struct.Type
+			for fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			scope.Err(db.Delete(reflect.New(fieldType).Interface()).Error)
+		case "many_to_many":
+			// only relations in the join table are deleted, associated records might be shared
+			handler := relationship.JoinTableHandler
+			records := indirect(scope.IndirectValue())
+			if records.Kind() != reflect.Slice {
+				scope.Err(handler.Delete(handler, scope.NewDB(), scope.Value))
+				continue
+			}
+
+			for i := 0; i < records.Len() && !scope.HasError(); i++ {
+				scope.Err(handler.Delete(handler, scope.NewDB(), records.Index(i).Addr().Interface()))
+			}
+		}
 	}
 }
 
@@ -33,22 +95,21 @@ func deleteCallback(scope *Scope) {
 			extraOption = fmt.Sprint(str)
 		}
 
-		deletedAtField, hasDeletedAtField := scope.FieldByName("DeletedAt")
+		softDeleteField, strategy, hasSoftDeleteField := scope.softDeleteField()
 
-		if !scope.Search.Unscoped && hasDeletedAtField {
+		if !scope.Search.Unscoped && hasSoftDeleteField {
 			scope.Raw(fmt.Sprintf(
-				"UPDATE %v SET %v=%v%v%v",
+				"UPDATE %v SET %v%v%v",
 				scope.QuotedTableName(),
-				scope.Quote(deletedAtField.DBName),
-				scope.AddToVars(scope.db.nowFunc()),
-				addExtraSpaceIfExist(scope.CombinedConditionSql()),
+				scope.softDeleteAssignments(softDeleteField, strategy),
+				addExtraSpaceIfExist(scope.writeConditionSQL()),
 				addExtraSpaceIfExist(extraOption),
 			)).Exec()
 		} else {
 			scope.Raw(fmt.Sprintf(
 				"DELETE FROM %v%v%v",
 				scope.QuotedTableName(),
-				addExtraSpaceIfExist(scope.CombinedConditionSql()),
+				addExtraSpaceIfExist(scope.writeConditionSQL()),
 				addExtraSpaceIfExist(extraOption),
 			)).Exec()
 		}
@@ -59,5 +120,6 @@ func deleteCallback(scope *Scope) {
 func afterDeleteCallback(scope *Scope) {
 	if !scope.HasError() {
 		scope.CallMethod("AfterDelete")
+		scope.callGlobalHooks(AfterDelete)
 	}
 }
@@ -1,16 +1,30 @@
 package gorm
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
+var mapDestinationType = reflect.TypeOf(map[string]interface{}{})
+
 // Define callbacks for querying
 func init() {
+	DefaultCallback.Query().Register("gorm:before_query", beforeQueryCallback)
+	DefaultCallback.Query().Register("gorm:sharding", shardingCallback)
+	DefaultCallback.Query().Register("gorm:tenancy", tenancyCallback)
+	DefaultCallback.Query().Register("gorm:load_query_cache", loadQueryCacheCallback)
+	DefaultCallback.Query().Register("gorm:singleflight", singleflightCallback)
+	DefaultCallback.Query().Register("gorm:batch_load", batchLoadCallback)
 	DefaultCallback.Query().Register("gorm:query", queryCallback)
 	DefaultCallback.Query().Register("gorm:preload", preloadCallback)
+	DefaultCallback.Query().Register("gorm:finish_singleflight", finishSingleflightCallback)
+	DefaultCallback.Query().Register("gorm:save_query_cache", saveQueryCacheCallback)
 	DefaultCallback.Query().Register("gorm:after_query", afterQueryCallback)
+	DefaultCallback.Query().Register("gorm:track_changes", trackChangesCallback)
 }
 
 // queryCallback used to query data from database
@@ -33,8 +47,13 @@ func queryCallback(scope *Scope) {
 	)
 
 	if orderBy, ok := scope.Get("gorm:order_by_primary_key"); ok {
-		if primaryField := scope.PrimaryField(); primaryField != nil {
-			scope.Search.Order(fmt.Sprintf("%v.%v %v", scope.QuotedTableName(), scope.Quote(primaryField.DBName), orderBy))
+		columns, err := scope.sortKeyColumns()
+		if scope.Err(err) != nil {
+			return
+		}
+
+		for _, column := range columns {
+			scope.Search.Order(fmt.Sprintf("%v %v", column, orderBy))
 		}
 	}
 
@@ -51,8 +70,12 @@ func queryCallback(scope *Scope) {
 			isPtr = true
 			resultType = resultType.Elem()
 		}
+	} else if kind == reflect.Map && results.Type() == mapDestinationType {
+		if results.IsNil() {
+			results.Set(reflect.MakeMap(mapDestinationType))
+		}
 	} else if kind != reflect.Struct {
-		scope.Err(errors.New("unsupported destination, should be slice or struct"))
+		scope.Err(errors.New("unsupported destination, should be slice, struct or map[string]interface{}"))
 		return
 	}
 
@@ -65,10 +88,28 @@ func queryCallback(scope *Scope) {
 			scope.SQL = fmt.Sprint(str) + scope.SQL
 		}
 
-		if rows, err := scope.SQLDB().Query(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+		if scope.dryRun() {
+			return
+		}
+
+		if rows, err := scope.sqlQuery(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 			defer rows.Close()
 
 			columns, _ := rows.Columns()
+			columnTypes, _ := rows.ColumnTypes()
+			joinedAssociations := scope.joinedAssociations()
+
+			// scan rows with the cached plan unless fields are joined or checked by strict scan
+			var plan *scanPlan
+			if strict, _ := scope.Get("gorm:strict_scan"); len(joinedAssociations) == 0 && strict != true && resultType != mapDestinationType {
+				if resultType == nil {
+					resultType = results.Type()
+				}
+				if resultType.Kind() == reflect.Struct {
+					plan = scope.scanPlan(resultType, columns)
+				}
+			}
+
 			for rows.Next() {
 				scope.db.RowsAffected++
 
@@ -77,7 +118,18 @@ func queryCallback(scope *Scope) {
 					elem = reflect.New(resultType).Elem()
 				}
 
-				scope.scan(rows, columns, scope.New(elem.Addr().Interface()).Fields())
+				if elem.Type() == mapDestinationType {
+					if elem.IsNil() {
+						elem.Set(reflect.MakeMap(mapDestinationType))
+					}
+					scope.Err(scanIntoMap(rows, columns, columnTypes, elem.Interface().(map[string]interface{})))
+				} else if plan != nil {
+					scope.Err(plan.scan(rows, elem))
+				} else {
+					fields := scope.New(elem.Addr().Interface()).Fields()
+					scope.scan(rows, columns, append(fields, joinedFields(fields, joinedAssociations)...))
+					resetUnmatchedJoins(fields, joinedAssociations)
+				}
 
 				if isSlice {
 					if isPtr {
@@ -91,15 +143,93 @@ func queryCallback(scope *Scope) {
 			if err := rows.Err(); err != nil {
 				scope.Err(err)
 			} else if scope.db.RowsAffected == 0 && !isSlice {
-				scope.Err(ErrRecordNotFound)
+				scope.recordNotFound()
+			}
+		}
+	}
+}
+
+// recordNotFound report ErrRecordNotFound for queries of single records if it isn't disabled with `ErrorOnNotFound`
+func (scope *Scope) recordNotFound() {
+	if enable, ok := scope.Get("gorm:error_on_not_found"); !ok || enable == true {
+		scope.Err(ErrRecordNotFound)
+	}
+}
+
+// beforeQueryCallback will invoke `BeforeFind` method before querying, it is called once with the model, or a new
+// record for slices, so it could add conditions to queries of the model, e.g. `scope.Search.Where("tenant_id = ?", id)`
+func beforeQueryCallback(scope *Scope) {
+	if scope.HasError() || scope.Value == nil {
+		return
+	}
+
+	if skip, ok := scope.Get("gorm:skip_hooks"); !ok || skip != true {
+		if results := scope.IndirectValue(); results.Kind() == reflect.Slice {
+			elemType := results.Type().Elem()
+			for elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
 			}
+			scope.callMethod("BeforeFind", reflect.New(elemType))
+		} else {
+			scope.callMethod("BeforeFind", results)
 		}
 	}
+
+	if !scope.HasError() {
+		scope.callGlobalHooks(BeforeFind)
+	}
 }
 
 // afterQueryCallback will invoke `AfterFind` method after querying
 func afterQueryCallback(scope *Scope) {
 	if !scope.HasError() {
 		scope.CallMethod("AfterFind")
+		scope.callGlobalHooks(AfterFind)
+	}
+}
+
+// scanIntoMap scan current row into result, bytes are converted based on the column's database type
+func scanIntoMap(rows *sql.Rows, columns []string, columnTypes []*sql.ColumnType, result map[string]interface{}) error {
+	values := make([]interface{}, len(columns))
+	for idx := range values {
+		values[idx] = new(interface{})
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+
+	for idx, column := range columns {
+		value := *(values[idx].(*interface{}))
+		if bytes, ok := value.([]byte); ok && idx < len(columnTypes) {
+			value = convertColumnBytes(bytes, columnTypes[idx].DatabaseTypeName())
+		}
+		result[column] = value
+	}
+	return nil
+}
+
+func convertColumnBytes(bytes []byte, databaseType string) interface{} {
+	databaseType = strings.ToUpper(databaseType)
+
+	switch {
+	case strings.Contains(databaseType, "BLOB") || strings.Contains(databaseType, "BINARY") || databaseType == "BYTEA":
+		return bytes
+	case strings.Contains(databaseType, "INT"):
+		if value, err := strconv.ParseInt(string(bytes), 10, 64); err == nil {
+			return value
+		}
+		if value, err := strconv.ParseUint(string(bytes), 10, 64); err == nil {
+			return value
+		}
+	case strings.Contains(databaseType, "FLOAT") || strings.Contains(databaseType, "DOUBLE") || databaseType == "REAL":
+		if value, err := strconv.ParseFloat(string(bytes), 64); err == nil {
+			return value
+		}
+	case strings.HasPrefix(databaseType, "BOOL"):
+		if value, err := strconv.ParseBool(string(bytes)); err == nil {
+			return value
+		}
 	}
+	return string(bytes)
 }
@@ -0,0 +1,244 @@
+package gorm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+func init() {
+	// values of map destinations are interfaces, which are encoded with their registered types
+	gob.Register(time.Time{})
+}
+
+// loadQueryCacheCallback load results of `Cacheable` queries from cache, skip querying if found
+func loadQueryCacheCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:query_cache_ttl"); !ok || scope.HasError() || scope.Search.raw {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	cache, ok := scope.queryCache()
+	if !ok {
+		return
+	}
+
+	key := scope.queryCacheKey(cache)
+	if data, ok := cache.Get(key); ok {
+		if scope.Err(decodeQueryCache(scope, data, scope.queryDestination())) == nil {
+			if results := indirect(reflect.ValueOf(scope.queryDestination())); results.Kind() == reflect.Slice {
+				scope.db.RowsAffected = int64(results.Len())
+			} else {
+				scope.db.RowsAffected = 1
+			}
+			scope.InstanceSet("gorm:skip_query_callback", true)
+		}
+		return
+	}
+	scope.InstanceSet("gorm:query_cache_key", key)
+}
+
+// saveQueryCacheCallback save results of `Cacheable` queries to cache
+func saveQueryCacheCallback(scope *Scope) {
+	key, ok := scope.InstanceGet("gorm:query_cache_key")
+	if !ok || scope.HasError() {
+		return
+	}
+
+	cache, ok := scope.queryCache()
+	if !ok {
+		return
+	}
+
+	if data, err := encodeQueryCache(scope, scope.queryDestination()); err == nil {
+		ttl, _ := scope.Get("gorm:query_cache_ttl")
+		duration, _ := ttl.(time.Duration)
+		cache.Set(key.(string), data, duration)
+	}
+}
+
+// invalidateQueryCacheCallback invalidate cached results of the table after writing
+func invalidateQueryCacheCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if cache, ok := scope.queryCache(); ok {
+		invalidateQueryCache(cache, scope.TableName())
+	}
+}
+
+func (scope *Scope) queryDestination() interface{} {
+	if value, ok := scope.Get("gorm:query_destination"); ok {
+		return value
+	}
+	return scope.Value
+}
+
+// queryCacheKey generate cache key with the table's version, destination type and query SQL
+func (scope *Scope) queryCacheKey(cache QueryCache) string {
+	version, _ := cache.Get(queryCacheVersionKey(scope.TableName()))
+
+	queryScope := &Scope{db: scope.db, Search: scope.Search.clone(), Value: scope.Value}
+	queryScope.prepareQuerySQL()
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%v", version, queryScope.querySignature(scope.queryDestination()))))
+	return "gorm:query_cache:" + scope.TableName() + ":" + hex.EncodeToString(hash[:])
+}
+
+// querySignature identify the prepared query with its SQL, vars and destination type
+func (scope *Scope) querySignature(destination interface{}) string {
+	orderBy, _ := scope.Get("gorm:order_by_primary_key")
+	if sortKey, ok := scope.Get("gorm:sort_key"); ok {
+		orderBy = fmt.Sprintf("%v %v", sortKey, orderBy)
+	}
+	errorOnNotFound, ok := scope.Get("gorm:error_on_not_found")
+	if !ok {
+		errorOnNotFound = true
+	}
+
+	var vars []interface{}
+	for _, v := range scope.SQLVars {
+		if reflectValue := reflect.ValueOf(v); reflectValue.Kind() == reflect.Ptr && !reflectValue.IsNil() {
+			v = reflectValue.Elem().Interface()
+		}
+		vars = append(vars, v)
+	}
+	return fmt.Sprintf("%T|%v|%v|%v|%v", destination, orderBy, errorOnNotFound, scope.SQL, vars)
+}
+
+// encodeQueryCache encode results with fields of their models, so cached results are the same as results read from
+// the database, which isn't affected by json tags or custom marshalers. Values of fields are encoded with gob, nil
+// pointers are marked, as gob can't tell nil pointers from pointers of zero values. Results of types gob can't
+// encode, like interfaces of unregistered types, aren't cached
+func encodeQueryCache(scope *Scope, results interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := encodeCachedRecords(scope, gob.NewEncoder(&buf), reflect.ValueOf(results))
+	return buf.Bytes(), err
+}
+
+// decodeQueryCache decode results encoded with encodeQueryCache into the destination
+func decodeQueryCache(scope *Scope, data []byte, results interface{}) error {
+	return decodeCachedRecords(scope, gob.NewDecoder(bytes.NewReader(data)), reflect.ValueOf(results))
+}
+
+func encodeCachedRecords(scope *Scope, encoder *gob.Encoder, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if err := encoder.Encode(value.IsNil()); err != nil || value.IsNil() {
+			return err
+		}
+		return encodeCachedRecords(scope, encoder, value.Elem())
+	case reflect.Slice:
+		if err := encoder.Encode(value.Len()); err != nil {
+			return err
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := encodeCachedRecords(scope, encoder, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for _, field := range scope.New(value.Addr().Interface()).Fields() {
+			if field.IsIgnored {
+				continue
+			}
+
+			if field.Relationship != nil {
+				if err := encodeCachedRecords(scope, encoder, field.Field); err != nil {
+					return err
+				}
+			} else if err := encodeCachedValue(encoder, field.Field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return encodeCachedValue(encoder, value)
+}
+
+func decodeCachedRecords(scope *Scope, decoder *gob.Decoder, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		var isNil bool
+		if err := decoder.Decode(&isNil); err != nil {
+			return err
+		}
+		if isNil {
+			if value.CanSet() {
+				value.Set(reflect.Zero(value.Type()))
+			}
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return decodeCachedRecords(scope, decoder, value.Elem())
+	case reflect.Slice:
+		var length int
+		if err := decoder.Decode(&length); err != nil {
+			return err
+		}
+		value.Set(reflect.MakeSlice(value.Type(), length, length))
+		for i := 0; i < length; i++ {
+			if err := decodeCachedRecords(scope, decoder, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		value.Set(reflect.Zero(value.Type()))
+		for _, field := range scope.New(value.Addr().Interface()).Fields() {
+			if field.IsIgnored {
+				continue
+			}
+
+			if field.Relationship != nil {
+				if err := decodeCachedRecords(scope, decoder, field.Field); err != nil {
+					return err
+				}
+			} else if err := decodeCachedValue(decoder, field.Field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return decodeCachedValue(decoder, value)
+}
+
+func encodeCachedValue(encoder *gob.Encoder, value reflect.Value) error {
+	for value.Kind() == reflect.Ptr {
+		if err := encoder.Encode(value.IsNil()); err != nil || value.IsNil() {
+			return err
+		}
+		value = value.Elem()
+	}
+	return encoder.EncodeValue(value)
+}
+
+func decodeCachedValue(decoder *gob.Decoder, value reflect.Value) error {
+	for value.Kind() == reflect.Ptr {
+		var isNil bool
+		if err := decoder.Decode(&isNil); err != nil {
+			return err
+		}
+		if isNil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+
+	// gob doesn't reset values missing from the stream, e.g. zero fields of structs
+	value.Set(reflect.Zero(value.Type()))
+	return decoder.DecodeValue(value.Addr())
+}
@@ -8,6 +8,32 @@ import (
 	"strings"
 )
 
+// Associations could be used to preload all associations, e.g. `db.Preload(gorm.Associations)`, `db.Preload("Orders." + gorm.Associations)`,
+// or to delete all associations with records, e.g. `db.Select(gorm.Associations).Delete(&user)`
+const Associations = "*"
+
+// PreloadCondition build conditions of preloaded associations from their already loaded parent records, parents
+// is the value of the parent records, e.g. `*[]User` when finding users, or a slice of the parent associations for
+// nested preloads
+//     db.Preload("Addresses", gorm.PreloadCondition(func(db *gorm.DB, parents interface{}) *gorm.DB {
+//       var countries []string
+//       for _, user := range *parents.(*[]User) {
+//         countries = append(countries, user.Country)
+//       }
+//       return db.Where("country IN (?)", countries)
+//     })).Find(&users)
+type PreloadCondition func(db *DB, parents interface{}) *DB
+
+// Select return a preload condition selecting only the columns of preloaded associations, so large columns aren't
+// loaded when eager loading, keys to assign associations are always selected
+//     db.Preload("Orders", gorm.Select("id, total")).Find(&users)
+//     // SELECT id, total, "orders"."user_id" FROM "orders" WHERE ("user_id" IN (1,2,3))
+func Select(query interface{}, args ...interface{}) func(*DB) *DB {
+	return func(db *DB) *DB {
+		return db.Select(query, args...)
+	}
+}
+
 // preloadCallback used to preload associations
 func preloadCallback(scope *Scope) {
 	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
@@ -33,7 +59,7 @@ func preloadCallback(scope *Scope) {
 		fields       = scope.Fields()
 	)
 
-	for _, preload := range scope.Search.preload {
+	for _, preload := range scope.expandPreloads(scope.Search.preload) {
 		var (
 			preloadFields = strings.Split(preload.schema, ".")
 			currentScope  = scope
@@ -69,6 +95,8 @@ func preloadCallback(scope *Scope) {
 						currentScope.handleBelongsToPreload(field, currentPreloadConditions)
 					case "many_to_many":
 						currentScope.handleManyToManyPreload(field, currentPreloadConditions)
+					case "has_many_through":
+						currentScope.handleHasManyThroughPreload(field, currentPreloadConditions)
 					default:
 						scope.Err(errors.New("unsupported relation"))
 					}
@@ -113,16 +141,85 @@ func autoPreload(scope *Scope) {
 	}
 }
 
+// expandPreloads expand wildcard preloads like `*` or `Orders.*` to all associations of the model at that level
+func (scope *Scope) expandPreloads(preloads []searchPreload) []searchPreload {
+	var results []searchPreload
+	for _, preload := range preloads {
+		if !strings.Contains(preload.schema, Associations) {
+			results = append(results, preload)
+			continue
+		}
+
+		for _, schema := range scope.expandPreloadSchema(scope.GetModelStruct(), strings.Split(preload.schema, ".")) {
+			results = append(results, searchPreload{schema: schema, conditions: preload.conditions})
+		}
+	}
+	return results
+}
+
+func (scope *Scope) expandPreloadSchema(modelStruct *ModelStruct, preloadFields []string) (schemas []string) {
+	if len(preloadFields) == 0 {
+		return []string{""}
+	}
+
+	for _, field := range modelStruct.StructFields {
+		if field.Relationship == nil || (preloadFields[0] != Associations && field.Name != preloadFields[0]) {
+			continue
+		}
+
+		if val, ok := field.TagSettingsGet("PRELOAD"); ok && preloadFields[0] == Associations {
+			if preload, err := strconv.ParseBool(val); err == nil && !preload {
+				continue
+			}
+		}
+
+		fieldType := field.Struct.Type
+		for fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// preload the field itself if it doesn't have deeper associations
+		nestedSchemas := scope.expandPreloadSchema(scope.New(reflect.New(fieldType).Interface()).GetModelStruct(), preloadFields[1:])
+		if len(nestedSchemas) == 0 {
+			nestedSchemas = []string{""}
+		}
+
+		for _, schema := range nestedSchemas {
+			if schema == "" {
+				schemas = append(schemas, field.Name)
+			} else {
+				schemas = append(schemas, field.Name+"."+schema)
+			}
+		}
+	}
+
+	// keep unknown fields, so they could be reported when preloading
+	if len(schemas) == 0 && preloadFields[0] != Associations {
+		schemas = append(schemas, strings.Join(preloadFields, "."))
+	}
+	return
+}
+
 func (scope *Scope) generatePreloadDBWithConditions(conditions []interface{}) (*DB, []interface{}) {
 	var (
 		preloadDB         = scope.NewDB()
 		preloadConditions []interface{}
 	)
 
+	// inherit parent's Unscoped, so soft deleted associations of soft deleted records could be loaded
+	if scope.Search.Unscoped {
+		preloadDB = preloadDB.Unscoped()
+	}
+
 	for _, condition := range conditions {
-		if scopes, ok := condition.(func(*DB) *DB); ok {
-			preloadDB = scopes(preloadDB)
-		} else {
+		switch condition := condition.(type) {
+		case func(*DB) *DB:
+			preloadDB = condition(preloadDB)
+		case PreloadCondition:
+			preloadDB = condition(preloadDB, scope.Value)
+		case func(*DB, interface{}) *DB:
+			preloadDB = condition(preloadDB, scope.Value)
+		default:
 			preloadConditions = append(preloadConditions, condition)
 		}
 	}
@@ -130,6 +227,67 @@ func (scope *Scope) generatePreloadDBWithConditions(conditions []interface{}) (*
 	return preloadDB, preloadConditions
 }
 
+// selectPreloadKeys add columns of the table to the custom select of preloadDB if they aren't selected
+func (scope *Scope) selectPreloadKeys(preloadDB *DB, tableName string, columns []string) *DB {
+	if preloadDB.search == nil {
+		return preloadDB
+	}
+
+	var selected []string
+	switch query := preloadDB.search.selects["query"].(type) {
+	case string:
+		selected = splitSelects(query)
+	case []string:
+		selected = append(selected, query...)
+	default:
+		return preloadDB
+	}
+
+	selectedColumns := map[string]bool{}
+	for _, column := range selected {
+		column = strings.TrimSpace(column)
+		if column == "*" || strings.HasSuffix(column, ".*") {
+			return preloadDB
+		}
+		// expressions are selected as their aliases, expressions without aliases don't select keys
+		if index := strings.LastIndex(strings.ToLower(column), " as "); index >= 0 {
+			column = column[index+len(" as "):]
+		} else if strings.ContainsAny(column, "( ") {
+			continue
+		}
+		if index := strings.LastIndex(column, "."); index >= 0 {
+			column = column[index+1:]
+		}
+		selectedColumns[strings.ToLower(strings.Trim(column, "`\"[] "))] = true
+	}
+
+	for _, column := range columns {
+		if !selectedColumns[strings.ToLower(column)] {
+			selected = append(selected, fmt.Sprintf("%v.%v", scope.Quote(tableName), scope.Quote(column)))
+			selectedColumns[strings.ToLower(column)] = true
+		}
+	}
+
+	args, _ := preloadDB.search.selects["args"].([]interface{})
+	return preloadDB.Select(strings.Join(selected, ","), args...)
+}
+
+// preloadKeys return primary keys of the field's model and the columns, which are required to assign preloaded
+// associations of the field
+func (scope *Scope) preloadKeys(field *Field, columns ...string) (string, []string) {
+	columns = append([]string{}, columns...)
+	fieldType := field.Struct.Type
+	for fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	fieldScope := scope.New(reflect.New(fieldType).Interface())
+	for _, primaryField := range fieldScope.PrimaryFields() {
+		columns = append(columns, primaryField.DBName)
+	}
+	return fieldScope.TableName(), columns
+}
+
 // handleHasOnePreload used to preload has one associations
 func (scope *Scope) handleHasOnePreload(field *Field, conditions []interface{}) {
 	relation := field.Relationship
@@ -142,10 +300,14 @@ func (scope *Scope) handleHasOnePreload(field *Field, conditions []interface{})
 
 	// preload conditions
 	preloadDB, preloadConditions := scope.generatePreloadDBWithConditions(conditions)
+	tableName, keys := scope.preloadKeys(field, relation.ForeignDBNames...)
+	if relation.PolymorphicDBName != "" {
+		keys = append(keys, relation.PolymorphicDBName)
+	}
+	preloadDB = scope.selectPreloadKeys(preloadDB, tableName, keys)
 
 	// find relations
-	query := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relation.ForeignDBNames), toQueryMarks(primaryKeys))
-	values := toQueryValues(primaryKeys)
+	query, values := toInCondition(scope, relation.ForeignDBNames, primaryKeys)
 	if relation.PolymorphicType != "" {
 		query += fmt.Sprintf(" AND %v = ?", scope.Quote(relation.PolymorphicDBName))
 		values = append(values, relation.PolymorphicValue)
@@ -194,10 +356,14 @@ func (scope *Scope) handleHasManyPreload(field *Field, conditions []interface{})
 
 	// preload conditions
 	preloadDB, preloadConditions := scope.generatePreloadDBWithConditions(conditions)
+	tableName, keys := scope.preloadKeys(field, relation.ForeignDBNames...)
+	if relation.PolymorphicDBName != "" {
+		keys = append(keys, relation.PolymorphicDBName)
+	}
+	preloadDB = scope.selectPreloadKeys(preloadDB, tableName, keys)
 
 	// find relations
-	query := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relation.ForeignDBNames), toQueryMarks(primaryKeys))
-	values := toQueryValues(primaryKeys)
+	query, values := toInCondition(scope, relation.ForeignDBNames, primaryKeys)
 	if relation.PolymorphicType != "" {
 		query += fmt.Sprintf(" AND %v = ?", scope.Quote(relation.PolymorphicDBName))
 		values = append(values, relation.PolymorphicValue)
@@ -241,6 +407,8 @@ func (scope *Scope) handleBelongsToPreload(field *Field, conditions []interface{
 
 	// preload conditions
 	preloadDB, preloadConditions := scope.generatePreloadDBWithConditions(conditions)
+	tableName, keys := scope.preloadKeys(field, relation.AssociationForeignDBNames...)
+	preloadDB = scope.selectPreloadKeys(preloadDB, tableName, keys)
 
 	// get relations's primary keys
 	primaryKeys := scope.getColumnAsArray(relation.ForeignFieldNames, scope.Value)
@@ -250,7 +418,8 @@ func (scope *Scope) handleBelongsToPreload(field *Field, conditions []interface{
 
 	// find relations
 	results := makeSlice(field.Struct.Type)
-	scope.Err(preloadDB.Where(fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relation.AssociationForeignDBNames), toQueryMarks(primaryKeys)), toQueryValues(primaryKeys)...).Find(results, preloadConditions...).Error)
+	condition, vars := toInCondition(scope, relation.AssociationForeignDBNames, primaryKeys)
+	scope.Err(preloadDB.Where(condition, vars...).Find(results, preloadConditions...).Error)
 
 	// assign find results
 	var (
@@ -300,8 +469,20 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 	}
 
 	var sourceKeys = []string{}
+	var sourceKeyFields []*StructField
 	for _, key := range joinTableHandler.SourceForeignKeys() {
 		sourceKeys = append(sourceKeys, key.DBName)
+
+		// foreign keys are scanned like their source fields, e.g. binary UUIDs
+		sourceKeyField := &StructField{DBName: key.DBName, IsNormal: true}
+		if field, ok := scope.FieldByName(key.AssociationDBName); ok && hasSQLValue(field.StructField) {
+			sourceKeyField = field.StructField.clone()
+			sourceKeyField.DBName = key.DBName
+			if sourceKeyField.Struct.Type.Kind() != reflect.Ptr {
+				sourceKeyField.Struct.Type = reflect.PtrTo(sourceKeyField.Struct.Type)
+			}
+		}
+		sourceKeyFields = append(sourceKeyFields, sourceKeyField)
 	}
 
 	// preload conditions
@@ -313,6 +494,10 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 
 	if len(preloadDB.search.selects) == 0 {
 		preloadDB = preloadDB.Select("*")
+	} else {
+		tableName, keys := scope.preloadKeys(field)
+		preloadDB = scope.selectPreloadKeys(preloadDB, tableName, keys)
+		preloadDB = scope.selectPreloadKeys(preloadDB, joinTableHandler.Table(preloadDB), sourceKeys)
 	}
 
 	preloadDB = joinTableHandler.JoinWith(joinTableHandler, preloadDB, scope.Value)
@@ -338,8 +523,12 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 
 		// register foreign keys in join tables
 		var joinTableFields []*Field
-		for _, sourceKey := range sourceKeys {
-			joinTableFields = append(joinTableFields, &Field{StructField: &StructField{DBName: sourceKey, IsNormal: true}, Field: reflect.New(foreignKeyType).Elem()})
+		for _, sourceKeyField := range sourceKeyFields {
+			keyType := foreignKeyType
+			if sourceKeyField.Struct.Type != nil {
+				keyType = sourceKeyField.Struct.Type
+			}
+			joinTableFields = append(joinTableFields, &Field{StructField: sourceKeyField, Field: reflect.New(keyType).Elem()})
 		}
 
 		scope.scan(rows, columns, append(fields, joinTableFields...))
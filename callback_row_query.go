@@ -8,6 +8,10 @@ import (
 // Define callbacks for row query
 func init() {
 	DefaultCallback.RowQuery().Register("gorm:row_query", rowQueryCallback)
+	// conditions added by `BeforeFind` apply to Count, Pluck and Rows too, and are read by sharding
+	DefaultCallback.RowQuery().Before("gorm:row_query").Register("gorm:before_query", beforeQueryCallback)
+	DefaultCallback.RowQuery().Before("gorm:row_query").Register("gorm:sharding", shardingCallback)
+	DefaultCallback.RowQuery().Before("gorm:row_query").Register("gorm:tenancy", tenancyCallback)
 }
 
 type RowQueryResult struct {
@@ -24,14 +28,18 @@ func rowQueryCallback(scope *Scope) {
 	if result, ok := scope.InstanceGet("row_query_result"); ok {
 		scope.prepareQuerySQL()
 
+		if _, ok := scope.InstanceGet("gorm:count_subquery"); ok {
+			scope.SQL = fmt.Sprintf("SELECT count(*) FROM (%v) AS count_table", scope.SQL)
+		}
+
 		if str, ok := scope.Get("gorm:query_hint"); ok {
 			scope.SQL = fmt.Sprint(str) + scope.SQL
 		}
 
 		if rowResult, ok := result.(*RowQueryResult); ok {
-			rowResult.Row = scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...)
+			rowResult.Row = scope.sqlQueryRow(scope.SQL, scope.SQLVars...)
 		} else if rowsResult, ok := result.(*RowsQueryResult); ok {
-			rowsResult.Rows, rowsResult.Error = scope.SQLDB().Query(scope.SQL, scope.SQLVars...)
+			rowsResult.Rows, rowsResult.Error = scope.sqlQuery(scope.SQL, scope.SQLVars...)
 		}
 	}
 }
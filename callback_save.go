@@ -31,6 +31,10 @@ func saveAssociationCheck(scope *Scope, field *Field) (autoUpdate bool, autoCrea
 		if r = field.Relationship; r != nil {
 			autoUpdate, autoCreate, saveReference = true, true, true
 
+			if scope.fullSaveAssociations() {
+				return
+			}
+
 			if value, ok := scope.Get("gorm:save_associations"); ok {
 				autoUpdate = checkTruth(value)
 				autoCreate = autoUpdate
@@ -64,6 +68,17 @@ func saveAssociationCheck(scope *Scope, field *Field) (autoUpdate bool, autoCrea
 	return
 }
 
+// fullSaveAssociations report whether associations should be fully saved regardless of tags and settings, refer
+// `Session.FullSaveAssociations`, `UpdateColumns` still won't save associations
+func (scope *Scope) fullSaveAssociations() bool {
+	if _, ok := scope.Get("gorm:update_column"); ok {
+		return false
+	}
+
+	value, ok := scope.Get("gorm:full_save_associations")
+	return ok && value == true
+}
+
 func saveBeforeAssociationsCallback(scope *Scope) {
 	for _, field := range scope.Fields() {
 		autoUpdate, autoCreate, saveReference, relationship := saveAssociationCheck(scope, field)
@@ -74,10 +89,10 @@ func saveBeforeAssociationsCallback(scope *Scope) {
 
 			if newScope.PrimaryKeyZero() {
 				if autoCreate {
-					scope.Err(scope.NewDB().Save(fieldValue).Error)
+					scope.Err(scope.associationDB(field).Save(fieldValue).Error)
 				}
 			} else if autoUpdate {
-				scope.Err(scope.NewDB().Save(fieldValue).Error)
+				scope.Err(scope.associationDB(field).Save(fieldValue).Error)
 			}
 
 			if saveReference {
@@ -105,7 +120,7 @@ func saveAfterAssociationsCallback(scope *Scope) {
 			switch value.Kind() {
 			case reflect.Slice:
 				for i := 0; i < value.Len(); i++ {
-					newDB := scope.NewDB()
+					newDB := scope.associationDB(field)
 					elem := value.Index(i).Addr().Interface()
 					newScope := newDB.NewScope(elem)
 
@@ -159,10 +174,10 @@ func saveAfterAssociationsCallback(scope *Scope) {
 
 				if newScope.PrimaryKeyZero() {
 					if autoCreate {
-						scope.Err(scope.NewDB().Save(elem).Error)
+						scope.Err(scope.associationDB(field).Save(elem).Error)
 					}
 				} else if autoUpdate {
-					scope.Err(scope.NewDB().Save(elem).Error)
+					scope.Err(scope.associationDB(field).Save(elem).Error)
 				}
 			}
 		}
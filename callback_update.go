@@ -9,21 +9,32 @@ import (
 
 // Define callbacks for updating
 func init() {
+	DefaultCallback.Update().Register("gorm:sharding", shardingCallback)
 	DefaultCallback.Update().Register("gorm:assign_updating_attributes", assignUpdatingAttributesCallback)
+	DefaultCallback.Update().Register("gorm:tenancy", tenancyForUpdateCallback)
 	DefaultCallback.Update().Register("gorm:begin_transaction", beginTransactionCallback)
 	DefaultCallback.Update().Register("gorm:before_update", beforeUpdateCallback)
+	DefaultCallback.Update().Register("gorm:transform_fields", transformFieldsCallback)
+	DefaultCallback.Update().Register("gorm:validate", validateForUpdateCallback)
 	DefaultCallback.Update().Register("gorm:save_before_associations", saveBeforeAssociationsCallback)
 	DefaultCallback.Update().Register("gorm:update_time_stamp", updateTimeStampForUpdateCallback)
+	DefaultCallback.Update().Register("gorm:audit", auditForUpdateCallback)
+	DefaultCallback.Update().Register("gorm:normalize_times", normalizeTimesCallback)
+	DefaultCallback.Update().Register("gorm:find_history_records", findHistoryRecordsCallback)
 	DefaultCallback.Update().Register("gorm:update", updateCallback)
 	DefaultCallback.Update().Register("gorm:save_after_associations", saveAfterAssociationsCallback)
 	DefaultCallback.Update().Register("gorm:after_update", afterUpdateCallback)
+	DefaultCallback.Update().Register("gorm:save_history", saveHistoryForUpdateCallback)
 	DefaultCallback.Update().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
+	DefaultCallback.Update().Register("gorm:track_changes", trackChangesCallback)
+	DefaultCallback.Update().Register("gorm:invalidate_query_cache", invalidateQueryCacheCallback)
 }
 
 // assignUpdatingAttributesCallback assign updating attributes to model
 func assignUpdatingAttributesCallback(scope *Scope) {
 	if attrs, ok := scope.InstanceGet("gorm:update_interface"); ok {
-		if updateMaps, hasUpdate := scope.updatedAttrsWithValues(attrs); hasUpdate {
+		// associations assigned to the model are still saved when fully saving associations
+		if updateMaps, hasUpdate := scope.updatedAttrsWithValues(attrs); hasUpdate || scope.fullSaveAssociations() {
 			scope.InstanceSet("gorm:update_attrs", updateMaps)
 		} else {
 			scope.SkipLeft()
@@ -40,17 +51,22 @@ func beforeUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
 		if !scope.HasError() {
 			scope.CallMethod("BeforeSave")
+			scope.callGlobalHooks(BeforeSave)
 		}
 		if !scope.HasError() {
 			scope.CallMethod("BeforeUpdate")
+			scope.callGlobalHooks(BeforeUpdate)
 		}
 	}
 }
 
-// updateTimeStampForUpdateCallback will set `UpdatedAt` when updating
+// updateTimeStampForUpdateCallback will set `UpdatedAt` and fields tagged with `autoUpdateTime` when updating
 func updateTimeStampForUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
-		scope.SetColumn("UpdatedAt", scope.db.nowFunc())
+		now := scope.db.nowFunc()
+		for _, field := range scope.timestampFields(autoUpdateTime) {
+			scope.SetColumn(field, timestampValue(field, autoUpdateTime, now))
+		}
 	}
 }
 
@@ -73,11 +89,21 @@ func updateCallback(scope *Scope) {
 				sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(column), scope.AddToVars(value)))
 			}
 		} else {
+			// only update changed fields of tracked records, skip updating if only fields set automatically
+			// like `UpdatedAt` are changed
+			original, tracked := scope.originalValues()
+			changed := false
+
 			for _, field := range scope.Fields() {
+				if tracked && field.IsNormal && !original.changed(field) {
+					continue
+				}
+
 				if scope.changeableField(field) {
-					if !field.IsPrimaryKey && field.IsNormal && (field.Name != "CreatedAt" || !field.IsBlank) {
+					if !field.IsPrimaryKey && field.IsNormal && (!field.IsBlank || !isAutoTimeField(field, autoCreateTime)) {
+						changed = changed || !scope.isAutoUpdatedField(field)
 						if !field.IsForeignKey || !field.IsBlank || !field.HasDefaultValue {
-							sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.AddToVars(field.Field.Interface())))
+							sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.AddToVars(field.sqlValue())))
 						}
 					} else if relationship := field.Relationship; relationship != nil && relationship.Kind == "belongs_to" {
 						for _, foreignKey := range relationship.ForeignDBNames {
@@ -89,6 +115,11 @@ func updateCallback(scope *Scope) {
 					}
 				}
 			}
+
+			if tracked && !changed {
+				sqls = nil
+				scope.InstanceSet("gorm:unchanged", true)
+			}
 		}
 
 		var extraOption string
@@ -101,7 +132,7 @@ func updateCallback(scope *Scope) {
 				"UPDATE %v SET %v%v%v",
 				scope.QuotedTableName(),
 				strings.Join(sqls, ", "),
-				addExtraSpaceIfExist(scope.CombinedConditionSql()),
+				addExtraSpaceIfExist(scope.writeConditionSQL()),
 				addExtraSpaceIfExist(extraOption),
 			)).Exec()
 		}
@@ -113,9 +144,11 @@ func afterUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
 		if !scope.HasError() {
 			scope.CallMethod("AfterUpdate")
+			scope.callGlobalHooks(AfterUpdate)
 		}
 		if !scope.HasError() {
 			scope.CallMethod("AfterSave")
+			scope.callGlobalHooks(AfterSave)
 		}
 	}
 }
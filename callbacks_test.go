@@ -3,7 +3,9 @@ package gorm_test
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zanmato/gorm"
 )
@@ -247,3 +249,310 @@ func TestUseDefaultCallback(t *testing.T) {
 		t.Errorf("`%s` should be `2, true` but `%v, %v`", scopeValueName, v, ok)
 	}
 }
+
+func TestSkipHooks(t *testing.T) {
+	p := Product{Code: "skip_hooks", Price: 100}
+	if err := DB.SkipHooks().Save(&p).Error; err != nil {
+		t.Fatalf("Failed to save with hooks skipped, got %v", err)
+	}
+
+	if !reflect.DeepEqual(p.GetCallTimes(), []int64{0, 0, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("Hooks shouldn't be invoked when creating, %v", p.GetCallTimes())
+	}
+
+	p.Price = 200
+	DB.Session(&gorm.Session{SkipHooks: true}).Save(&p)
+	DB.SkipHooks().Where("code = ?", "skip_hooks").First(&p)
+	if !reflect.DeepEqual(p.GetCallTimes(), []int64{0, 0, 0, 0, 0, 0, 0, 0, 0}) || p.Price != 200 {
+		t.Errorf("Hooks shouldn't be invoked when updating and querying, %v", p.GetCallTimes())
+	}
+
+	if err := DB.SkipHooks().Save(&Product{Code: "Invalid", Price: 100}).Error; err != nil {
+		t.Errorf("Validations in hooks should be skipped, got %v", err)
+	}
+
+	DB.SkipHooks().Delete(&p)
+	if p.BeforeDeleteCallTimes != 0 || p.AfterDeleteCallTimes != 0 {
+		t.Errorf("Hooks shouldn't be invoked when deleting, %v", p.GetCallTimes())
+	}
+
+	p2 := Product{Code: "skip_hooks_2", Price: 100}
+	DB.Save(&p2)
+	if p2.BeforeSaveCallTimes != 1 {
+		t.Errorf("Hooks should be invoked without SkipHooks, %v", p2.GetCallTimes())
+	}
+}
+
+type StatementProduct struct {
+	ID        uint
+	Code      string
+	Price     int
+	CheckedAt int64
+	statement struct {
+		operation, conditions, sql string
+		changes                    map[string]interface{}
+		priceChanged               bool
+	} `sql:"-"`
+}
+
+func (product *StatementProduct) BeforeSave(tx *gorm.DB) error {
+	stmt := tx.Statement()
+	if stmt.Model() != product {
+		return errors.New("statement should describe the record running hooks")
+	}
+
+	product.statement.operation = stmt.Operation()
+	product.statement.conditions, _ = stmt.Conditions()
+	product.statement.sql, _ = stmt.SQL()
+	product.statement.changes = stmt.Changes()
+	product.statement.priceChanged = stmt.Changed("Price")
+	return stmt.SetColumn("CheckedAt", int64(100))
+}
+
+func TestHookStatement(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&StatementProduct{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&StatementProduct{})
+
+	product := StatementProduct{Code: "statement", Price: 10}
+	DB.Create(&product)
+	if product.statement.operation != "create" || !strings.HasPrefix(product.statement.sql, "INSERT INTO") {
+		t.Errorf("Statement should describe creating, got %#v", product.statement)
+	}
+
+	if product.statement.changes["code"] != "statement" || product.statement.changes["price"] != 10 || !product.statement.priceChanged {
+		t.Errorf("Statement should include written columns, got %#v", product.statement.changes)
+	}
+
+	var result StatementProduct
+	if DB.First(&result, product.ID); result.CheckedAt != 100 {
+		t.Errorf("SetColumn in before create hooks should be saved, got %v", result.CheckedAt)
+	}
+
+	DB.Model(&result).Updates(map[string]interface{}{"code": "statement2"})
+	if result.statement.operation != "update" || !strings.Contains(result.statement.conditions, "id") ||
+		!strings.HasPrefix(result.statement.sql, "UPDATE") || result.statement.priceChanged {
+		t.Errorf("Statement should describe updating, got %#v", result.statement)
+	}
+
+	if _, ok := result.statement.changes["code"]; !ok || len(result.statement.changes) != 1 {
+		t.Errorf("Statement should only include updated columns, got %#v", result.statement.changes)
+	}
+
+	DB.Model(&StatementProduct{}).Where("id = ?", product.ID).UpdateColumn("checked_at", 0)
+	result.CheckedAt = 0
+	DB.Model(&result).Updates(map[string]interface{}{"price": 20})
+	if DB.First(&result, product.ID); result.CheckedAt != 100 || result.Price != 20 {
+		t.Errorf("SetColumn in before update hooks should be saved, got %v", result.CheckedAt)
+	}
+}
+
+type AuditedDocument struct {
+	ID        uint
+	Title     string
+	CreatedBy uint
+	UpdatedBy uint
+	Reviewer  uint `gorm:"updated_by"`
+	DeletedAt *time.Time
+	DeletedBy *uint
+}
+
+func TestAuditFields(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&AuditedDocument{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&AuditedDocument{})
+
+	document := AuditedDocument{Title: "audit"}
+	DB.Create(&document)
+	if document.CreatedBy != 0 || document.UpdatedBy != 0 {
+		t.Errorf("Audit fields should not be set without current user, got %#v", document)
+	}
+
+	document2 := AuditedDocument{Title: "audit2"}
+	DB.Set("gorm:current_user", 1).Create(&document2)
+	if document2.CreatedBy != 1 || document2.Reviewer != 1 || document2.UpdatedBy != 1 {
+		t.Errorf("Audit fields should be set to current user when creating, got %#v", document2)
+	}
+
+	DB.Set("gorm:current_user", 2).Model(&document2).Update("title", "audit2 updated")
+	var result AuditedDocument
+	if DB.First(&result, document2.ID); result.CreatedBy != 1 || result.UpdatedBy != 2 || result.Reviewer != 2 || result.Title != "audit2 updated" {
+		t.Errorf("Updated by fields should be set to current user when updating, got %#v", result)
+	}
+
+	DB.Set("gorm:current_user", uint(3)).Delete(&result)
+	if DB.Unscoped().First(&result, document2.ID); result.DeletedBy == nil || *result.DeletedBy != 3 {
+		t.Errorf("Deleted by fields should be set to current user when deleting, got %#v", result.DeletedBy)
+	}
+
+	documents := []AuditedDocument{{Title: "audit_batch"}, {Title: "audit_batch"}}
+	DB.Set("gorm:current_user", 4).CreateInBatches(&documents, 10)
+	var count int
+	if DB.Model(&AuditedDocument{}).Where("title = ? AND created_by = ? AND updated_by = ?", "audit_batch", 4, 4).Count(&count); count != 2 {
+		t.Errorf("Audit fields should be set to current user when creating in batches, got %v", count)
+	}
+}
+
+var currentTenantID uint
+
+type TenantNote struct {
+	ID       uint
+	TenantID uint
+	Body     string
+}
+
+func (note *TenantNote) BeforeFind(scope *gorm.Scope) {
+	scope.Search.Where("tenant_id = ?", currentTenantID)
+}
+
+func TestBeforeFind(t *testing.T) {
+	DB.Set("gorm:table_options", "").AutoMigrate(&TenantNote{})
+	DB.Delete(&TenantNote{})
+	DB.Create(&TenantNote{TenantID: 1, Body: "first tenant"})
+	DB.Create(&TenantNote{TenantID: 2, Body: "second tenant"})
+	DB.Create(&TenantNote{TenantID: 2, Body: "second tenant again"})
+	defer func() { currentTenantID = 0 }()
+
+	currentTenantID = 2
+	var notes []TenantNote
+	if err := DB.Order("id").Find(&notes).Error; err != nil || len(notes) != 2 || notes[0].Body != "second tenant" {
+		t.Errorf("BeforeFind should add conditions to queries of slices, got %v, %v", notes, err)
+	}
+
+	var pointers []*TenantNote
+	DB.Where("body LIKE ?", "%again").Find(&pointers)
+	if len(pointers) != 1 || pointers[0].TenantID != 2 {
+		t.Errorf("BeforeFind should be combined with other conditions, got %v", len(pointers))
+	}
+
+	currentTenantID = 1
+	var note TenantNote
+	if err := DB.Where("body = ?", "second tenant").First(&note).Error; !gorm.IsRecordNotFoundError(err) {
+		t.Errorf("BeforeFind should add conditions to queries of records, got %v, %v", note, err)
+	}
+
+	currentTenantID = 2
+	var count int
+	if DB.Model(&TenantNote{}).Count(&count); count != 2 {
+		t.Errorf("BeforeFind should add conditions to counting, got %v", count)
+	}
+
+	var bodies []string
+	if DB.Model(&TenantNote{}).Order("id").Pluck("body", &bodies); !reflect.DeepEqual(bodies, []string{"second tenant", "second tenant again"}) {
+		t.Errorf("BeforeFind should add conditions to plucking, got %v", bodies)
+	}
+
+	notes = nil
+	DB.SkipHooks().Find(&notes)
+	if len(notes) != 3 {
+		t.Errorf("BeforeFind shouldn't be invoked when hooks are skipped, got %v", len(notes))
+	}
+}
+
+func TestGlobalHooks(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var calls []string
+	for _, hook := range []gorm.Hook{gorm.BeforeSave, gorm.BeforeCreate, gorm.AfterCreate, gorm.AfterSave, gorm.BeforeUpdate, gorm.AfterUpdate, gorm.BeforeDelete, gorm.AfterDelete, gorm.BeforeFind, gorm.AfterFind} {
+		hook := hook
+		db.RegisterGlobalHook(hook, func(scope *gorm.Scope) {
+			calls = append(calls, string(hook)+":"+scope.TableName())
+		})
+	}
+	db.RegisterGlobalHook(gorm.BeforeCreate, func(scope *gorm.Scope) {
+		if product, ok := scope.Value.(*Product); ok && product.Code == "global_invalid" {
+			scope.Err(errors.New("invalid product"))
+		}
+	})
+
+	product := Product{Code: "global_hooks", Price: 100}
+	db.Save(&product)
+	db.Model(&product).Update("price", 200)
+	db.First(&product, product.Id)
+	db.Delete(&product)
+
+	expected := []string{
+		"BeforeSave:products", "BeforeCreate:products", "AfterCreate:products", "AfterSave:products",
+		"BeforeSave:products", "BeforeUpdate:products", "AfterUpdate:products", "AfterSave:products",
+		"BeforeFind:products", "AfterFind:products",
+		"BeforeDelete:products", "AfterDelete:products",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Global hooks should be called for every operation, got %v", calls)
+	}
+
+	if err := db.Save(&Product{Code: "global_invalid"}).Error; err == nil || err.Error() != "invalid product" {
+		t.Errorf("Errors of global hooks should abort operations, got %v", err)
+	}
+
+	calls = nil
+	DB.Save(&Product{Code: "global_hooks_other_db"})
+	if len(calls) != 0 {
+		t.Errorf("Global hooks shouldn't be called for other connections, got %v", calls)
+	}
+}
+
+type ValidatedAccount struct {
+	ID    uint
+	Name  string
+	Email string
+}
+
+func (account *ValidatedAccount) Validate() error {
+	var errs gorm.ValidationErrors
+	if account.Name == "" {
+		errs = append(errs, gorm.FieldError{Field: "Name", Message: "can't be blank"})
+	}
+	if !strings.Contains(account.Email, "@") {
+		errs = append(errs, gorm.FieldError{Field: "Email", Message: "is invalid"})
+	}
+	return errs
+}
+
+func TestValidation(t *testing.T) {
+	DB.Set("gorm:table_options", "").AutoMigrate(&ValidatedAccount{})
+
+	err := DB.Create(&ValidatedAccount{Email: "invalid"}).Error
+	if errs, ok := err.(gorm.ValidationErrors); !ok || len(errs) != 2 || errs[0].Field != "Name" || errs[1].Field != "Email" {
+		t.Errorf("Invalid records shouldn't be created, got %v", err)
+	} else if err.Error() != "Name can't be blank; Email is invalid" {
+		t.Errorf("Validation errors should describe fields, got %v", err)
+	}
+
+	account := ValidatedAccount{Name: "valid", Email: "valid@example.org"}
+	if err := DB.Create(&account).Error; err != nil {
+		t.Fatalf("Valid records should be created, got %v", err)
+	}
+
+	if err := DB.Model(&account).Update("email", "invalid").Error; err == nil {
+		t.Errorf("Records shouldn't be updated to be invalid")
+	}
+
+	var found ValidatedAccount
+	DB.First(&found, account.ID)
+	if found.Email != "valid@example.org" {
+		t.Errorf("Invalid updates shouldn't be saved, got %v", found.Email)
+	}
+
+	if err := DB.Model(&account).UpdateColumn("email", "imported").Error; err != nil {
+		t.Errorf("Records shouldn't be validated when updating columns, got %v", err)
+	}
+
+	if err := DB.Model(&ValidatedAccount{}).Where("id = ?", account.ID).Update("name", "bulk").Error; err != nil {
+		t.Errorf("Blank models of bulk updates shouldn't be validated, got %v", err)
+	}
+
+	if err := DB.SkipValidation().Create(&ValidatedAccount{}).Error; err != nil {
+		t.Errorf("Validations should be skipped, got %v", err)
+	}
+
+	if err := DB.Session(&gorm.Session{SkipValidation: true}).CreateInBatches([]ValidatedAccount{{}}, 10).Error; err != nil {
+		t.Errorf("Validations should be skipped in sessions, got %v", err)
+	}
+
+	if err := DB.CreateInBatches([]ValidatedAccount{{Name: "batch", Email: "batch@example.org"}, {}}, 10).Error; err == nil {
+		t.Errorf("Invalid records shouldn't be created in batches")
+	}
+}
@@ -0,0 +1,190 @@
+package gorm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// changeTracker keep original values of records loaded or saved in sessions tracking changes
+type changeTracker struct {
+	mutex   sync.RWMutex
+	records map[interface{}]map[string]interface{}
+}
+
+// TrackChanges track original values of records loaded or saved with the returned db, so `Save` only updates fields
+// changed after that, and `Scope.Changed` could report changed fields in hooks. Original values are kept as long as
+// the returned db is used, so it is meant for short lived sessions, like a request
+//     tx := db.TrackChanges()
+//     tx.First(&user)
+//     user.Name = "jinzhu 2"
+//     tx.Save(&user) // UPDATE users SET name = 'jinzhu 2', updated_at = '2013-11-17 21:34:10' WHERE id = 111
+func (s *DB) TrackChanges() *DB {
+	return s.Set("gorm:change_tracker", &changeTracker{records: map[interface{}]map[string]interface{}{}})
+}
+
+// Changed report whether fields with names have been changed, or any field if no name is given, in the record
+//     func (user *User) BeforeUpdate(scope *gorm.Scope) error {
+//       if scope.Changed("Role") && user.Role == "admin" {
+//         return errors.New("admin role is not allowed")
+//       }
+//       return nil
+//     }
+// Updating with `Update` or `Updates` changes given attributes, saving records tracked with `DB.TrackChanges` changes
+// fields different from original values, all fields are changed when saving records not tracked
+func (scope *Scope) Changed(names ...string) bool {
+	var (
+		updateAttrs     map[string]interface{}
+		hasUpdateAttrs  bool
+		original, track = scope.originalValues()
+	)
+
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		updateAttrs, hasUpdateAttrs = attrs.(map[string]interface{})
+	}
+
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored || !fieldNamed(field, names) {
+			continue
+		}
+
+		if hasUpdateAttrs {
+			if _, ok := updateAttrs[field.DBName]; !ok {
+				continue
+			}
+		}
+
+		if !track || original.changed(field) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldNamed(field *Field, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, name := range names {
+		if field.Name == name || field.DBName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isAutoUpdatedField report whether the field is set automatically when updating
+func (scope *Scope) isAutoUpdatedField(field *Field) bool {
+	if isAutoTimeField(field, autoUpdateTime) {
+		return true
+	}
+
+	if _, ok := scope.currentUser(); ok {
+		for _, auditField := range scope.auditFields(auditUpdatedBy) {
+			if auditField.StructField == field.StructField {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// originalValues is the original values of a record with their db names
+type originalValues map[string]interface{}
+
+func (original originalValues) changed(field *Field) bool {
+	value, ok := original[field.DBName]
+	return !ok || !reflect.DeepEqual(value, field.Field.Interface())
+}
+
+// originalValues return original values of the scope's value if it is tracked
+func (scope *Scope) originalValues() (originalValues, bool) {
+	tracker, ok := scope.changeTracker()
+	if !ok || scope.IndirectValue().Kind() != reflect.Struct || !scope.IndirectValue().CanAddr() {
+		return nil, false
+	}
+
+	tracker.mutex.RLock()
+	defer tracker.mutex.RUnlock()
+	values, ok := tracker.records[scope.IndirectValue().Addr().Interface()]
+	return values, ok
+}
+
+func (scope *Scope) changeTracker() (*changeTracker, bool) {
+	if value, ok := scope.Get("gorm:change_tracker"); ok {
+		tracker, ok := value.(*changeTracker)
+		return tracker, ok && tracker != nil
+	}
+	return nil, false
+}
+
+// trackChangesCallback save original values of records after they are loaded or saved
+func trackChangesCallback(scope *Scope) {
+	tracker, ok := scope.changeTracker()
+	if !ok || scope.HasError() {
+		return
+	}
+
+	results := scope.IndirectValue()
+	if value, ok := scope.Get("gorm:query_destination"); ok {
+		results = indirect(reflect.ValueOf(value))
+	}
+
+	switch results.Kind() {
+	case reflect.Slice:
+		for i := 0; i < results.Len(); i++ {
+			if elem := reflect.Indirect(results.Index(i)); elem.Kind() == reflect.Struct {
+				tracker.track(scope, elem)
+			}
+		}
+	case reflect.Struct:
+		if results.CanAddr() {
+			tracker.track(scope, results)
+		}
+	}
+}
+
+func (tracker *changeTracker) track(scope *Scope, record reflect.Value) {
+	values := originalValues{}
+	for _, field := range scope.New(record.Addr().Interface()).Fields() {
+		if field.IsNormal && !field.IsIgnored {
+			values[field.DBName] = copyValue(field.Field).Interface()
+		}
+	}
+
+	tracker.mutex.Lock()
+	tracker.records[record.Addr().Interface()] = values
+	tracker.mutex.Unlock()
+}
+
+// copyValue copy the value, pointers, slices and maps are copied so later changes to them are not shared
+func copyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(copyValue(value.Elem()))
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for _, key := range value.MapKeys() {
+			copied.SetMapIndex(key, copyValue(value.MapIndex(key)))
+		}
+		return copied
+	}
+	return value
+}
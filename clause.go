@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+)
+
+// Clause is a custom SQL fragment, like window functions or extensions of dialects, which could be used as queries of
+// `Select`, `Where`, `Or`, `Not`, `Having` and `Order`, or as values of placeholders. Clauses write their SQL with
+// the builder, which quotes identifiers and binds values as vars, so they don't need to concatenate strings
+//     type RowNumber struct{ PartitionBy, OrderBy string }
+//
+//     func (rowNumber RowNumber) Build(builder gorm.ClauseBuilder) {
+//       builder.WriteString("ROW_NUMBER() OVER (PARTITION BY ")
+//       builder.WriteQuoted(rowNumber.PartitionBy)
+//       builder.WriteString(" ORDER BY ")
+//       builder.WriteQuoted(rowNumber.OrderBy)
+//       builder.WriteString(")")
+//     }
+//
+//     db.Select("*, ? AS position", RowNumber{"user_id", "created_at"}).Find(&orders)
+//     // SELECT *, ROW_NUMBER() OVER (PARTITION BY "user_id" ORDER BY "created_at") AS position FROM "orders"
+type Clause interface {
+	Build(builder ClauseBuilder)
+}
+
+// ClauseBuilder build SQL of clauses
+type ClauseBuilder interface {
+	// WriteString write raw SQL
+	WriteString(sql string)
+	// WriteQuoted write the quoted identifier, like `name` or `users.name`
+	WriteQuoted(identifier string)
+	// AddVar write values bound as vars separated with commas, elements of slices are bound separately, clauses and
+	// expressions like `Expr` are built in place
+	AddVar(values ...interface{})
+	// Dialect return the dialect the SQL is built for
+	Dialect() Dialect
+}
+
+// ClauseFunc is a clause built with the function
+//     db.Where(gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+//       builder.WriteQuoted("age")
+//       builder.WriteString(" BETWEEN ")
+//       builder.AddVar(18)
+//       builder.WriteString(" AND ")
+//       builder.AddVar(30)
+//     })).Find(&users)
+//     // SELECT * FROM "users" WHERE ("age" BETWEEN 18 AND 30)
+type ClauseFunc func(builder ClauseBuilder)
+
+// Build build the clause with the function
+func (f ClauseFunc) Build(builder ClauseBuilder) {
+	f(builder)
+}
+
+type clauseBuilder struct {
+	scope *Scope
+	sql   strings.Builder
+}
+
+// buildClause return the SQL of the clause, its values are added to vars of the scope
+func (scope *Scope) buildClause(clause Clause) string {
+	builder := &clauseBuilder{scope: scope}
+	clause.Build(builder)
+	return builder.sql.String()
+}
+
+func (builder *clauseBuilder) WriteString(sql string) {
+	builder.sql.WriteString(sql)
+}
+
+func (builder *clauseBuilder) WriteQuoted(identifier string) {
+	builder.sql.WriteString(builder.scope.Quote(identifier))
+}
+
+func (builder *clauseBuilder) AddVar(values ...interface{}) {
+	for idx, value := range values {
+		if idx > 0 {
+			builder.sql.WriteString(",")
+		}
+
+		if _, isValuer := value.(driver.Valuer); !isValuer {
+			if _, isBytes := value.([]byte); !isBytes && reflect.ValueOf(value).Kind() == reflect.Slice {
+				reflectValue := reflect.ValueOf(value)
+				elements := make([]interface{}, reflectValue.Len())
+				for i := range elements {
+					elements[i] = reflectValue.Index(i).Interface()
+				}
+				builder.AddVar(elements...)
+				continue
+			}
+		}
+		builder.sql.WriteString(builder.scope.AddToVars(value))
+	}
+}
+
+func (builder *clauseBuilder) Dialect() Dialect {
+	return builder.scope.Dialect()
+}
@@ -0,0 +1,68 @@
+package gorm
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Commenter is implemented by dialects which could comment tables and columns, column comments are declared with tag
+// `comment`, table comments with method `TableComment` of models, they are applied by CreateTable and AutoMigrate
+//     type User struct {
+//       ID   uint
+//       Name string `gorm:"comment:full name of the user"`
+//     }
+//
+//     func (User) TableComment() string {
+//       return "registered users"
+//     }
+// Column comments of mysql are part of column definitions, so they are only applied when columns are created
+type Commenter interface {
+	// CommentTableSQL return SQL to comment the table
+	CommentTableSQL(tableName, comment string) string
+	// CommentColumnSQL return SQL to comment the column, blank if comments are part of column definitions
+	CommentColumnSQL(tableName, columnName, comment string) string
+}
+
+type tableCommenter interface {
+	TableComment() string
+}
+
+// QuoteComment quote the comment as a SQL string literal, comments already quoted are unquoted first
+func QuoteComment(comment string) string {
+	if len(comment) >= 2 && strings.HasPrefix(comment, "'") && strings.HasSuffix(comment, "'") {
+		comment = strings.Replace(comment[1:len(comment)-1], "''", "'", -1)
+	}
+	return "'" + strings.Replace(comment, "'", "''", -1) + "'"
+}
+
+// migrateComments comment the table and its columns if the dialect is a Commenter
+func (scope *Scope) migrateComments() {
+	commenter, ok := scope.Dialect().(Commenter)
+	if !ok || scope.HasError() {
+		return
+	}
+
+	var (
+		tableName   = scope.TableName()
+		modelStruct = scope.GetModelStruct()
+	)
+	if modelStruct.ModelType != nil {
+		if model, ok := reflect.New(modelStruct.ModelType).Interface().(tableCommenter); ok {
+			if comment := model.TableComment(); comment != "" {
+				scope.execComment(commenter.CommentTableSQL(tableName, comment))
+			}
+		}
+	}
+
+	for _, field := range modelStruct.StructFields {
+		if comment, ok := field.TagSettingsGet("COMMENT"); ok && field.IsNormal {
+			scope.execComment(commenter.CommentColumnSQL(tableName, field.DBName, comment))
+		}
+	}
+}
+
+func (scope *Scope) execComment(sql string) {
+	if sql != "" && !scope.HasError() {
+		scope.Raw(sql).Exec()
+	}
+}
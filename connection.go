@@ -0,0 +1,107 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// OnConnection run hooks when a connection is acquired with `Connection` or a transaction is started, including
+// transactions started by gorm to save records, before the first statement of the connection or transaction, e.g.
+// to set the role or session variables
+//     db = db.OnConnection(func(tx *gorm.DB) error {
+//       return tx.Exec("SET LOCAL ROLE tenant_reader").Error
+//     })
+// hooks of the DB are kept, statements of DBs without connections or transactions run with pooled connections,
+// which hooks can't run with, session variables set by hooks of `Connection` are left on the connection when it is
+// put back to the pool, so settings local to transactions are preferred
+func (s *DB) OnConnection(hooks ...func(tx *DB) error) *DB {
+	return s.Set("gorm:connection_hooks", s.appendConnectionHooks(hooks))
+}
+
+func (s *DB) appendConnectionHooks(hooks []func(tx *DB) error) []func(tx *DB) error {
+	var results []func(tx *DB) error
+	if value, ok := s.Get("gorm:connection_hooks"); ok {
+		results = append(results, value.([]func(tx *DB) error)...)
+	}
+	return append(results, hooks...)
+}
+
+// runConnectionHooks run hooks with the DB of the acquired connection or started transaction
+func (s *DB) runConnectionHooks() error {
+	value, ok := s.Get("gorm:connection_hooks")
+	if !ok {
+		return nil
+	}
+
+	for _, hook := range value.([]func(tx *DB) error) {
+		if err := hook(s.New()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Connection run fc with a connection dedicated to it, statements of fc and transactions started by it are executed
+// with the connection, hooks of `OnConnection` are run before fc
+//     db.Connection(func(conn *gorm.DB) error {
+//       conn.Exec("CREATE TEMPORARY TABLE imported_ids (id bigint)")
+//       return conn.Raw("SELECT id FROM imported_ids").Scan(&ids).Error
+//     })
+// fc is called with the DB directly if it is already in a connection or transaction
+func (s *DB) Connection(fc func(conn *DB) error) error {
+	switch s.db.(type) {
+	case *sql.Tx, *twoPhaseConn, *pinnedConn:
+		return fc(s)
+	}
+
+	sqlDB, ok := s.db.(*sql.DB)
+	if !ok {
+		return errors.New("connections can't be acquired from the DB")
+	}
+
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	db := s.clone()
+	db.db = &pinnedConn{sqlConn{conn}}
+	db.dialect.SetDB(db.db)
+
+	if err := db.runConnectionHooks(); err != nil {
+		return err
+	}
+	return fc(db)
+}
+
+// sqlConn execute statements with a dedicated connection
+type sqlConn struct {
+	*sql.Conn
+}
+
+func (conn sqlConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return conn.ExecContext(context.Background(), query, args...)
+}
+
+func (conn sqlConn) Prepare(query string) (*sql.Stmt, error) {
+	return conn.PrepareContext(context.Background(), query)
+}
+
+func (conn sqlConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return conn.QueryContext(context.Background(), query, args...)
+}
+
+func (conn sqlConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return conn.QueryRowContext(context.Background(), query, args...)
+}
+
+// pinnedConn is a connection acquired with `Connection`, transactions could be started with it
+type pinnedConn struct {
+	sqlConn
+}
+
+func (conn *pinnedConn) Begin() (*sql.Tx, error) {
+	return conn.BeginTx(context.Background(), nil)
+}
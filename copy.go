@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Copier is implemented by dialects supporting bulk loading with `COPY ... FROM STDIN`, rows are sent by executing
+// the prepared statement with values of each row, and flushed by executing it without values, which is how lib/pq
+// implements the COPY protocol. Only lib/pq copies this way, other drivers like pgx insert records in batches instead.
+// The statement is commented like others executed by the DB, refer `WithSQLCommenter`
+type Copier interface {
+	// CopyFromSQL return the statement copying columns of rows into the table, blank if the driver can't copy, so
+	// records are inserted instead
+	CopyFromSQL(tableName string, columns []string) string
+}
+
+// copyFromFallbackBatchSize is the batch size of multi rows inserts used by CopyFrom for dialects which can't copy
+const copyFromFallbackBatchSize = 1000
+
+// CopyFrom bulk load records of the slice with the COPY protocol for postgres, which is much faster than inserts,
+// other dialects insert records with `CreateInBatches`.
+// `BeforeSave`, `BeforeCreate`, `AfterCreate` and `AfterSave` hooks are called for each record, associations aren't
+// saved and primary keys generated by the database aren't set back when copying. Records are copied in a transaction,
+// which is started if the DB isn't in one
+//     db.CopyFrom(&events)
+func (s *DB) CopyFrom(values interface{}) *DB {
+	copier, ok := s.Dialect().(Copier)
+	if !ok || copier.CopyFromSQL(s.NewScope(values).TableName(), nil) == "" {
+		return s.CreateInBatches(values, copyFromFallbackBatchSize)
+	}
+
+	db := s.clone()
+	records := indirect(reflect.ValueOf(values))
+	if records.Kind() != reflect.Slice {
+		db.AddError(errors.New("values of CopyFrom should be a slice"))
+		return db
+	}
+
+	if records.Len() == 0 {
+		return db
+	}
+
+	if _, inTransaction := s.db.(sqlTx); !inTransaction && !s.NewScope(values).isDryRun() {
+		db.AddError(s.Transaction(func(tx *DB) error {
+			result := tx.CopyFrom(values)
+			db.RowsAffected = result.RowsAffected
+			return result.Error
+		}))
+		return db
+	}
+
+	scopes, failed := s.batchScopes(records)
+	if failed != nil {
+		return failed
+	}
+
+	var (
+		scope        = s.NewScope(records.Index(0).Interface())
+		fieldIndexes = scope.batchFieldIndexes(scopes)
+		columns      []string
+	)
+	for _, index := range fieldIndexes {
+		columns = append(columns, scope.Fields()[index].DBName)
+	}
+
+	scope.Raw(copier.CopyFromSQL(scope.TableName(), columns))
+	if scope.dryRun() {
+		return scope.db
+	}
+
+	defer scope.trace(NowFunc())
+	stmt, err := scope.sqlPrepare(scope.SQL)
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+	defer stmt.Close()
+
+	for _, recordScope := range scopes {
+		var vars []interface{}
+		for _, index := range fieldIndexes {
+			vars = append(vars, recordScope.Fields()[index].sqlValue())
+		}
+
+		if _, err := stmt.Exec(vars...); scope.Err(err) != nil {
+			return scope.db
+		}
+	}
+
+	result, err := stmt.Exec()
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+	scope.db.RowsAffected, _ = result.RowsAffected()
+
+	scope.afterBatchCallbacks(scopes)
+	return scope.db
+}
@@ -0,0 +1,110 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// createFromMaps create records from maps, keys could be field names or column names
+//     db.Model(&User{}).Create(map[string]interface{}{"Name": "jinzhu", "age": 18})
+//     db.Table("users").Create(map[string]interface{}{"name": "jinzhu", "age": 18})
+// With `Model`, values are assigned to a new record of the model, so default values, timestamps and hooks work
+// the same as creating from struct, the primary key will be written back to the map.
+// With `Table` only, the values are inserted as they are without callbacks.
+func (s *DB) createFromMaps(values ...map[string]interface{}) *DB {
+	db := s.clone()
+	create := func(tx *DB) error {
+		db.RowsAffected = 0
+		for _, value := range values {
+			var result *DB
+			if modelType, ok := tx.createModelType(); ok {
+				result = tx.createMapWithModel(modelType, value)
+			} else if tx.search != nil && tx.search.tableName != "" {
+				result = tx.createMapWithTable(value)
+			} else {
+				return errors.New("model or table is required to create from map")
+			}
+
+			db.RowsAffected += result.RowsAffected
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	}
+
+	// several maps are created in a transaction, so none of them is created if any of them failed
+	if len(values) > 1 {
+		if err := s.Transaction(create); err != nil {
+			db.RowsAffected = 0
+			db.AddError(err)
+		}
+	} else if err := create(s); err != nil {
+		db.AddError(err)
+	}
+	return db
+}
+
+func (s *DB) createModelType() (reflect.Type, bool) {
+	if s.Value == nil {
+		return nil, false
+	}
+
+	modelType := reflect.TypeOf(s.Value)
+	for modelType.Kind() == reflect.Ptr || modelType.Kind() == reflect.Slice {
+		modelType = modelType.Elem()
+	}
+	return modelType, modelType.Kind() == reflect.Struct
+}
+
+func (s *DB) createMapWithModel(modelType reflect.Type, value map[string]interface{}) *DB {
+	scope := s.NewScope(reflect.New(modelType).Interface())
+	for key, v := range value {
+		field, ok := scope.FieldByName(key)
+		if !ok || !field.IsNormal {
+			scope.Err(fmt.Errorf("can't create %v with unknown field %v", modelType, key))
+			return scope.db
+		}
+		if scope.Err(field.Set(v)) != nil {
+			return scope.db
+		}
+	}
+
+	db := scope.callCallbacks(s.parent.callbacks.creates).db
+	if db.Error == nil {
+		for _, field := range scope.PrimaryFields() {
+			value[field.DBName] = field.Field.Interface()
+		}
+	}
+	return db
+}
+
+func (s *DB) createMapWithTable(value map[string]interface{}) *DB {
+	var (
+		scope   = s.NewScope(value)
+		keys    []string
+		columns []string
+		marks   []string
+	)
+
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		columns = append(columns, scope.Quote(ToColumnName(key)))
+		marks = append(marks, scope.AddToVars(value[key]))
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"INSERT INTO %v (%v) VALUES (%v)",
+		scope.QuotedTableName(),
+		strings.Join(columns, ","),
+		strings.Join(marks, ","),
+	)).Exec()
+	return scope.db
+}
@@ -210,6 +210,152 @@ func TestCreateWithNoStdPrimaryKeyAndDefaultValues(t *testing.T) {
 	}
 }
 
+func TestCreateWithUUIDPrimaryKey(t *testing.T) {
+	type UUIDUser struct {
+		ID        string `gorm:"primary_key;default:uuid"`
+		Name      string
+		Reference []byte `gorm:"default:uuid_v7"`
+	}
+	DB.DropTableIfExists(&UUIDUser{})
+	DB.AutoMigrate(&UUIDUser{})
+
+	user := UUIDUser{Name: "uuid_user"}
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("No error should happen when create with uuid primary key, but got %v", err)
+	}
+
+	if len(user.ID) != 36 || user.ID[14] != '4' {
+		t.Errorf("Should generate version 4 uuid, but got %v", user.ID)
+	}
+
+	if len(user.Reference) != 16 || user.Reference[6]>>4 != 7 {
+		t.Errorf("Should generate version 7 uuid, but got %x", user.Reference)
+	}
+
+	var result UUIDUser
+	if err := DB.First(&result, "id = ?", user.ID).Error; err != nil || result.Name != user.Name {
+		t.Errorf("Should find user with generated uuid, got %v", err)
+	}
+
+	existing := UUIDUser{ID: "existing-id", Name: "uuid_user"}
+	if err := DB.Create(&existing).Error; err != nil || existing.ID != "existing-id" {
+		t.Errorf("Should not override assigned primary key, but got %v", existing.ID)
+	}
+}
+
+func TestCreateWithBinaryUUID(t *testing.T) {
+	type BinaryUUIDUser struct {
+		ID        string `gorm:"primary_key;type:uuid_binary;default:uuid_v7"`
+		Name      string
+		SessionID *string `gorm:"type:uuid_binary;swap_time"`
+	}
+	DB.Set("gorm:table_options", "").DropTableIfExists(&BinaryUUIDUser{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&BinaryUUIDUser{})
+
+	sessionID := "6ccd780c-baba-1026-9564-5b8c656024db"
+	user := BinaryUUIDUser{Name: "binary_uuid", SessionID: &sessionID}
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("No error should happen when create with binary uuid, but got %v", err)
+	}
+
+	if len(user.ID) != 36 {
+		t.Errorf("Should generate uuid in the canonical format, but got %v", user.ID)
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" {
+		var id, session string
+		DB.Raw("SELECT hex(id), hex(session_id) FROM binary_uuid_users WHERE name = ?", "binary_uuid").Row().Scan(&id, &session)
+		if id != strings.ToUpper(strings.Replace(user.ID, "-", "", -1)) {
+			t.Errorf("Should store uuid as 16 bytes, but got %v", id)
+		}
+
+		if session != "1026BABA6CCD780C95645B8C656024DB" {
+			t.Errorf("Should swap time bytes of uuid, but got %v", session)
+		}
+	}
+
+	var result BinaryUUIDUser
+	if err := DB.Where(&BinaryUUIDUser{ID: user.ID}).First(&result).Error; err != nil {
+		t.Fatalf("Should find user with binary uuid, got %v", err)
+	}
+
+	if result.ID != user.ID || result.SessionID == nil || *result.SessionID != sessionID {
+		t.Errorf("Should scan binary uuid, but got %v, %v", result.ID, result.SessionID)
+	}
+
+	if err := DB.Model(&result).Updates(map[string]interface{}{"name": "binary_uuid_new", "session_id": nil}).Error; err != nil {
+		t.Errorf("Should update user with binary uuid primary key, got %v", err)
+	}
+
+	var updated BinaryUUIDUser
+	if DB.First(&updated, "name = ?", "binary_uuid_new"); updated.ID != user.ID || updated.SessionID != nil {
+		t.Errorf("Should update user by binary uuid primary key, but got %+v", updated)
+	}
+
+	if err := DB.Delete(&updated).Error; err != nil {
+		t.Errorf("Should delete user with binary uuid primary key, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&BinaryUUIDUser{}).Count(&count); count != 0 {
+		t.Errorf("Should delete user by binary uuid primary key, but got %v", count)
+	}
+}
+
+func TestBinaryUUIDAssociations(t *testing.T) {
+	type BinaryUUIDChild struct {
+		ID       uint
+		ParentID string `gorm:"type:uuid_binary"`
+		Name     string
+	}
+	type BinaryUUIDTag struct {
+		ID   string `gorm:"primary_key;type:uuid_binary;default:uuid_v7"`
+		Name string
+	}
+	type BinaryUUIDParent struct {
+		ID       string            `gorm:"primary_key;type:uuid_binary;default:uuid_v7"`
+		Children []BinaryUUIDChild `gorm:"foreignkey:ParentID"`
+		Tags     []BinaryUUIDTag   `gorm:"many2many:binary_uuid_parent_tags"`
+	}
+	DB.Set("gorm:table_options", "").DropTableIfExists(&BinaryUUIDParent{}, &BinaryUUIDChild{}, &BinaryUUIDTag{}, "binary_uuid_parent_tags")
+	DB.Set("gorm:table_options", "").AutoMigrate(&BinaryUUIDParent{}, &BinaryUUIDChild{}, &BinaryUUIDTag{})
+
+	parent := BinaryUUIDParent{
+		Children: []BinaryUUIDChild{{Name: "child1"}, {Name: "child2"}},
+		Tags:     []BinaryUUIDTag{{Name: "tag1"}, {Name: "tag2"}},
+	}
+	if err := DB.Create(&parent).Error; err != nil {
+		t.Fatalf("No error should happen when create with binary uuid associations, but got %v", err)
+	}
+
+	var preloaded BinaryUUIDParent
+	if err := DB.Preload("Children").Preload("Tags").Where(&BinaryUUIDParent{ID: parent.ID}).First(&preloaded).Error; err != nil {
+		t.Fatalf("Should find parent with binary uuid, got %v", err)
+	}
+
+	if len(preloaded.Children) != 2 || len(preloaded.Tags) != 2 {
+		t.Errorf("Should preload associations by binary uuid keys, but got %v children, %v tags", len(preloaded.Children), len(preloaded.Tags))
+	}
+
+	if count := DB.Model(&parent).Association("Children").Count(); count != 2 {
+		t.Errorf("Should count associations by binary uuid keys, but got %v", count)
+	}
+
+	var children []BinaryUUIDChild
+	if DB.Model(&parent).Related(&children, "Children"); len(children) != 2 {
+		t.Errorf("Should find related records by binary uuid keys, but got %v", len(children))
+	}
+
+	if DB.Model(&parent).Association("Tags").Delete(&parent.Tags[0]); DB.Model(&parent).Association("Tags").Count() != 1 {
+		t.Errorf("Should delete associations by binary uuid keys")
+	}
+
+	var parents []BinaryUUIDParent
+	if DB.Find(&parents, []string{parent.ID}); len(parents) != 1 {
+		t.Errorf("Should find records by inline binary uuid primary keys, but got %v", len(parents))
+	}
+}
+
 func TestAnonymousScanner(t *testing.T) {
 	user := User{Name: "anonymous_scanner", Role: Role{Name: "admin"}}
 	DB.Save(&user)
@@ -311,3 +457,385 @@ func TestFixFullTableScanWhenInsertIgnore(t *testing.T) {
 		t.Error("Should ignore duplicate panda insert by insert modifier:IGNORE ")
 	}
 }
+
+func TestCreateFromMap(t *testing.T) {
+	values := map[string]interface{}{"Name": "create_from_map", "age": 18}
+	if err := DB.Model(&User{}).Create(values).Error; err != nil {
+		t.Fatalf("Failed to create from map, got %v", err)
+	}
+
+	var user User
+	if DB.Where("name = ?", "create_from_map").First(&user).RecordNotFound() {
+		t.Fatalf("Should find user created from map")
+	}
+	if user.Age != 18 || user.CreatedAt.IsZero() {
+		t.Errorf("Should assign values and timestamps when creating from map, but got %#v", user)
+	}
+	if values["id"] != user.Id {
+		t.Errorf("Should write primary key back to map, but got %v", values["id"])
+	}
+
+	err := DB.Table("users").Create([]map[string]interface{}{
+		{"name": "create_from_maps_1", "age": 20},
+		{"Name": "create_from_maps_2", "age": 20},
+	}).Error
+	if err != nil {
+		t.Fatalf("Failed to create from maps with table, got %v", err)
+	}
+
+	var count int
+	DB.Model(&User{}).Where("age = ? AND name LIKE ?", 20, "create_from_maps_%").Count(&count)
+	if count != 2 {
+		t.Errorf("Should create 2 users from maps, but got %v", count)
+	}
+
+	if err := DB.Model(&User{}).Create(map[string]interface{}{"unknown": 1}).Error; err == nil {
+		t.Errorf("Should return error when creating with unknown field")
+	}
+
+	err = DB.Model(&User{}).Create([]map[string]interface{}{
+		{"Name": "create_from_maps_partial", "age": 21},
+		{"Name": "create_from_maps_partial", "unknown": 1},
+	}).Error
+	if err == nil {
+		t.Errorf("Should return error when creating maps with unknown field")
+	}
+	if !DB.Where("name = ?", "create_from_maps_partial").First(&User{}).RecordNotFound() {
+		t.Errorf("Should roll back maps created before the failed one")
+	}
+
+	if err := DB.Create(map[string]interface{}{"name": "no_model"}).Error; err == nil {
+		t.Errorf("Should return error when creating from map without model or table")
+	}
+}
+
+type BatchProduct struct {
+	ID        uint
+	Code      string `gorm:"unique_index"`
+	Price     int
+	Stock     int
+	CreatedAt time.Time
+	Hooked    bool `sql:"-"`
+}
+
+func (product *BatchProduct) BeforeCreate() {
+	product.Hooked = true
+}
+
+func TestCreateInBatches(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&BatchProduct{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&BatchProduct{})
+
+	products := []BatchProduct{{Code: "batch1", Price: 10, Stock: 1}, {Code: "batch2", Price: 20, Stock: 2}, {Code: "batch3", Price: 30, Stock: 3}}
+	if err := DB.CreateInBatches(&products, 2).Error; err != nil {
+		t.Fatalf("Failed to create in batches, got %v", err)
+	}
+
+	for _, product := range products {
+		var result BatchProduct
+		if DB.First(&result, product.ID); result.Code != product.Code || product.ID == 0 || !product.Hooked || result.CreatedAt.IsZero() {
+			t.Errorf("Records should be created with primary keys, hooks and timestamps, got %#v", product)
+		}
+	}
+
+	upserts := []*BatchProduct{{Code: "batch1", Price: 11, Stock: 11}, {Code: "batch4", Price: 40, Stock: 4}}
+	if err := DB.OnConflict(gorm.OnConflict{Columns: []string{"code"}, DoUpdates: []string{"price"}}).CreateInBatches(upserts, 0).Error; err != nil {
+		t.Fatalf("Failed to upsert in batches, got %v", err)
+	}
+
+	var results []BatchProduct
+	DB.Order("code").Find(&results)
+	if len(results) != 4 || results[0].Price != 11 || results[0].Stock != 1 || results[3].Price != 40 {
+		t.Errorf("Should only update given columns of conflicting rows, got %#v", results)
+	}
+
+	upserts = []*BatchProduct{{Code: "batch2", Price: 22, Stock: 22}}
+	DB.OnConflict(gorm.OnConflict{Columns: []string{"code"}, UpdateAll: true}).CreateInBatches(&upserts, 0)
+	upserts = []*BatchProduct{{Code: "batch3", Price: 33, Stock: 33}}
+	DB.OnConflict(gorm.OnConflict{Columns: []string{"code"}, DoNothing: true}).CreateInBatches(&upserts, 0)
+
+	DB.Order("code").Find(&results)
+	if results[1].Price != 22 || results[1].Stock != 22 || results[2].Price != 30 {
+		t.Errorf("Should update all columns or nothing of conflicting rows, got %#v", results)
+	}
+}
+
+type KeylessBatchLog struct {
+	Message string
+}
+
+func TestOnConflictWithoutKeys(t *testing.T) {
+	mysqlDB, err := gorm.Open("mysql", DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open with the mysql dialect, got %v", err)
+	}
+
+	for _, db := range []*gorm.DB{DB, mysqlDB} {
+		logs := []KeylessBatchLog{{Message: "keyless"}}
+		if err := db.OnConflict(gorm.OnConflict{DoNothing: true}).CreateInBatches(&logs, 0).Error; err == nil {
+			t.Errorf("%v: Conflicts of models without primary keys shouldn't be handled without conflict columns", db.Dialect().GetName())
+		}
+	}
+}
+
+type DefaultExpression struct {
+	ID        uint
+	Name      string
+	Token     string    `gorm:"default:lower(hex(randomblob(8)))"`
+	CreatedOn time.Time `gorm:"default:now()"`
+}
+
+func TestCreateWithDefaultExpressions(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&DefaultExpression{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&DefaultExpression{}).Error; err != nil {
+		t.Fatalf("Failed to migrate default expressions, got %v", err)
+	}
+
+	record := DefaultExpression{Name: "default_expression"}
+	if err := DB.Create(&record).Error; err != nil {
+		t.Fatalf("Failed to create record with default expressions, got %v", err)
+	}
+
+	if record.ID == 0 || record.Token == "" || record.CreatedOn.IsZero() {
+		t.Errorf("Values generated by default expressions should be read back, got %#v", record)
+	}
+
+	var result DefaultExpression
+	if DB.First(&result, record.ID); result.Token != record.Token || !result.CreatedOn.Equal(record.CreatedOn) {
+		t.Errorf("Default expressions should be evaluated by database, got %#v", result)
+	}
+
+	record2 := DefaultExpression{Name: "default_expression", Token: "given"}
+	if DB.Create(&record2); record2.Token != "given" || record2.CreatedOn.IsZero() {
+		t.Errorf("Given values should be inserted, got %#v", record2)
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&BatchProduct{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&BatchProduct{})
+
+	var products []BatchProduct
+	for i := 0; i < 5; i++ {
+		products = append(products, BatchProduct{Code: "copy" + string(rune('a'+i)), Price: i * 10, Stock: i})
+	}
+
+	result := DB.CopyFrom(&products)
+	if result.Error != nil {
+		t.Fatalf("Failed to copy records, got %v", result.Error)
+	}
+
+	if result.RowsAffected != 5 {
+		t.Errorf("Rows affected should be 5, got %v", result.RowsAffected)
+	}
+
+	var count int
+	if DB.Model(&BatchProduct{}).Where("code LIKE ?", "copy%").Count(&count); count != 5 {
+		t.Errorf("All records should be copied, got %v", count)
+	}
+
+	for _, product := range products {
+		if !product.Hooked || product.CreatedAt.IsZero() {
+			t.Errorf("Hooks and timestamps should be called for copied records, got %#v", product)
+		}
+	}
+
+	if err := DB.CopyFrom(BatchProduct{}).Error; err == nil {
+		t.Errorf("Copying non slice values should fail")
+	}
+
+	dialect, _ := gorm.GetDialect("postgres")
+	if sql := dialect.(gorm.Copier).CopyFromSQL("batch_products", []string{"code", "price"}); sql != `COPY "batch_products" ("code", "price") FROM STDIN` {
+		t.Errorf("Wrong copy SQL for postgres, got %v", sql)
+	}
+}
+
+type PreciseEvent struct {
+	ID         uint
+	OccurredAt time.Time `gorm:"precision:3"`
+	ReceivedAt *time.Time
+}
+
+func TestTimePrecision(t *testing.T) {
+	db := DB.Set("gorm:table_options", "").TimePrecision(6)
+	db.DropTableIfExists(&PreciseEvent{})
+	if err := db.AutoMigrate(&PreciseEvent{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	occurred := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	event := PreciseEvent{OccurredAt: occurred, ReceivedAt: &occurred}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if event.OccurredAt.Nanosecond() != 123000000 || event.ReceivedAt.Nanosecond() != 123456000 || occurred.Nanosecond() != 123456789 {
+		t.Errorf("Times should be truncated to their precisions, got %v, %v", event.OccurredAt, event.ReceivedAt)
+	}
+
+	var found PreciseEvent
+	db.First(&found, event.ID)
+	if !found.OccurredAt.Equal(event.OccurredAt) || !found.ReceivedAt.Equal(*event.ReceivedAt) {
+		t.Errorf("Times should be read back as they are written, got %v, %v", found.OccurredAt, found.ReceivedAt)
+	}
+
+	db.Model(&event).Updates(map[string]interface{}{"occurred_at": occurred.Add(time.Millisecond), "received_at": occurred})
+	db.First(&found, event.ID)
+	if found.OccurredAt.Nanosecond() != 124000000 || found.ReceivedAt.Nanosecond() != 123456000 {
+		t.Errorf("Updated times should be truncated, got %v, %v", found.OccurredAt, found.ReceivedAt)
+	}
+
+	DB.Model(&event).Update("received_at", occurred)
+	DB.First(&found, event.ID)
+	if found.ReceivedAt.Nanosecond() != 123456789 {
+		t.Errorf("Times without precisions shouldn't be truncated, got %v", found.ReceivedAt)
+	}
+
+	field, _ := DB.NewScope(&PreciseEvent{}).FieldByName("OccurredAt")
+	for name, sqlType := range map[string]string{"mysql": "DATETIME(3) NULL", "postgres": "timestamp(3) with time zone"} {
+		if dialect, ok := gorm.GetDialect(name); ok {
+			if result := dialect.DataTypeOf(field.StructField); result != sqlType {
+				t.Errorf("%v columns should be created with the precision, got %v", name, result)
+			}
+		}
+	}
+}
+
+func TestTimeZone(t *testing.T) {
+	frozen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+8", 8*60*60))
+	db := DB.Session(&gorm.Session{NowFunc: func() time.Time { return frozen }, TimeZone: time.UTC})
+
+	user := User{Name: "TimeZoneUser"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if !user.CreatedAt.Equal(frozen) || user.CreatedAt.Location() != time.UTC || user.UpdatedAt.Location() != time.UTC {
+		t.Errorf("Timestamps should be the frozen time in UTC, got %v, %v", user.CreatedAt, user.UpdatedAt)
+	}
+
+	local := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC-5", -5*60*60))
+	DB.Delete(&PreciseEvent{})
+	event := PreciseEvent{OccurredAt: local, ReceivedAt: &local}
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&PreciseEvent{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+	if err := DB.TimeZone(time.UTC).Create(&event).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if event.OccurredAt.Location() != time.UTC || event.ReceivedAt.Location() != time.UTC || local.Location() == time.UTC {
+		t.Errorf("Times should be converted to UTC, got %v, %v", event.OccurredAt, event.ReceivedAt)
+	}
+
+	if !event.OccurredAt.Equal(local) || event.OccurredAt.Nanosecond() != 0 {
+		t.Errorf("Times should be the same instants, got %v", event.OccurredAt)
+	}
+
+	DB.Model(&event).TimeZone(time.UTC).Updates(map[string]interface{}{"received_at": local})
+	var found PreciseEvent
+	DB.First(&found, event.ID)
+	if !found.ReceivedAt.Equal(local) {
+		t.Errorf("Updated times should be the same instants, got %v", found.ReceivedAt)
+	}
+}
+
+type TransformedContact struct {
+	ID       uint
+	Email    string  `gorm:"transform:trim,lower"`
+	Code     *string `gorm:"transform:upper"`
+	Nickname string  `gorm:"transform:reverse"`
+}
+
+func TestTransformFields(t *testing.T) {
+	gorm.RegisterTransformer("reverse", func(value interface{}) (interface{}, error) {
+		runes := []rune(value.(string))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	})
+	DB.Set("gorm:table_options", "").AutoMigrate(&TransformedContact{})
+
+	code := "ab-1"
+	contact := TransformedContact{Email: "  Jinzhu@Example.ORG ", Code: &code, Nickname: "uhznij"}
+	if err := DB.Create(&contact).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if contact.Email != "jinzhu@example.org" || *contact.Code != "AB-1" || contact.Nickname != "jinzhu" {
+		t.Errorf("Fields should be transformed when creating, got %v, %v, %v", contact.Email, *contact.Code, contact.Nickname)
+	}
+
+	DB.Model(&contact).Updates(map[string]interface{}{"email": " NEW@Example.org", "code": "cd-2"})
+	var found TransformedContact
+	DB.First(&found, contact.ID)
+	if found.Email != "new@example.org" || *found.Code != "CD-2" {
+		t.Errorf("Fields should be transformed when updating, got %v, %v", found.Email, *found.Code)
+	}
+
+	contacts := []TransformedContact{{Email: " BATCH@example.org"}}
+	DB.CreateInBatches(contacts, 10)
+	if contacts[0].Email != "batch@example.org" || contacts[0].Code != nil {
+		t.Errorf("Fields should be transformed when creating in batches, got %v", contacts[0].Email)
+	}
+
+	type UnknownTransformer struct {
+		ID   uint
+		Name string `gorm:"transform:unknown"`
+	}
+	if err := DB.Create(&UnknownTransformer{Name: "name"}).Error; err == nil {
+		t.Errorf("Unregistered transformers should fail")
+	}
+}
+
+type GeneratedIDOrder struct {
+	ID        int64  `gorm:"primary_key;id_generator:snowflake"`
+	Reference string `gorm:"id_generator:ulid"`
+	TraceID   string `gorm:"id_generator:ksuid"`
+	Number    string `gorm:"id_generator:snowflake"`
+}
+
+func TestIDGenerator(t *testing.T) {
+	snowflake, err := gorm.NewSnowflake(7)
+	if err != nil {
+		t.Fatalf("Failed to create snowflake, got %v", err)
+	}
+	db := DB.New().RegisterIDGenerator("snowflake", snowflake)
+
+	DB.DropTableIfExists(&GeneratedIDOrder{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&GeneratedIDOrder{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	order := GeneratedIDOrder{}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if order.ID == 0 || (order.ID>>12)&1023 != 7 || len(order.Reference) != 26 || len(order.TraceID) != 27 || order.Number == "" {
+		t.Errorf("IDs should be generated, got %#v", order)
+	}
+
+	orders := []GeneratedIDOrder{{}, {Reference: "given"}}
+	if err := db.CreateInBatches(orders, 10).Error; err != nil {
+		t.Fatalf("Failed to create in batches, got %v", err)
+	}
+
+	if orders[0].ID <= order.ID || orders[1].ID <= orders[0].ID || orders[1].Reference != "given" || len(orders[0].Reference) != 26 {
+		t.Errorf("IDs should be time ordered and given IDs kept, got %#v", orders)
+	}
+
+	var found GeneratedIDOrder
+	if err := DB.First(&found, orders[1].ID).Error; err != nil || found.Reference != "given" {
+		t.Errorf("Records should be found by generated IDs, got %#v, %v", found, err)
+	}
+
+	if err := DB.Create(&GeneratedIDOrder{}).Error; err == nil {
+		t.Errorf("Unregistered id generators should fail")
+	}
+
+	if _, err := gorm.NewSnowflake(1024); err == nil {
+		t.Errorf("Invalid worker ids should fail")
+	}
+}
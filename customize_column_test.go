@@ -355,3 +355,57 @@ func TestSelfReferencingMany2ManyColumn(t *testing.T) {
 		t.Errorf("All friends should be deleted")
 	}
 }
+
+type SocialMember struct {
+	ID        uint
+	Name      string
+	Friends   []*SocialMember `gorm:"many2many:social_friendships"`
+	Followers []SocialMember  `gorm:"many2many:social_follows;joinForeignKey:followee_id;joinReferences:follower_id"`
+}
+
+func TestSelfReferencingMany2ManyDefaultColumns(t *testing.T) {
+	DB.DropTableIfExists(&SocialMember{}, "social_friendships", "social_follows")
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&SocialMember{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	for table, columns := range map[string][]string{"social_friendships": {"social_member_id", "friend_id"}, "social_follows": {"followee_id", "follower_id"}} {
+		for _, column := range columns {
+			if !DB.Dialect().HasColumn(table, column) {
+				t.Errorf("Join table %v should have column %v", table, column)
+			}
+		}
+	}
+
+	alice := SocialMember{Name: "alice", Friends: []*SocialMember{{Name: "bob"}, {Name: "carol"}}}
+	if err := DB.Save(&alice).Error; err != nil {
+		t.Fatalf("Failed to save, got %v", err)
+	}
+
+	if err := DB.Model(&alice).Association("Followers").Append(SocialMember{Name: "dave"}).Error; err != nil {
+		t.Fatalf("Failed to append followers, got %v", err)
+	}
+
+	var found SocialMember
+	DB.Preload("Friends").Preload("Followers").First(&found, alice.ID)
+	if len(found.Friends) != 2 || len(found.Followers) != 1 || found.Followers[0].Name != "dave" {
+		t.Errorf("Self referencing associations should be preloaded, got %v friends, %v followers", len(found.Friends), len(found.Followers))
+	}
+
+	var bob SocialMember
+	DB.First(&bob, "name = ?", "bob")
+	if count := DB.Model(&bob).Association("Friends").Count(); count != 0 {
+		t.Errorf("Associations should be of the source, got %v", count)
+	}
+
+	DB.Model(&alice).Association("Friends").Delete(&bob)
+	if count := DB.Model(&alice).Association("Friends").Count(); count != 1 {
+		t.Errorf("Self referencing associations should be deleted, got %v", count)
+	}
+
+	DB.Model(&alice).Association("Friends").Replace(&bob)
+	DB.Model(&alice).Association("Friends").Find(&found.Friends)
+	if len(found.Friends) != 1 || found.Friends[0].Name != "bob" {
+		t.Errorf("Self referencing associations should be replaced, got %v", found.Friends)
+	}
+}
@@ -1,8 +1,13 @@
 package gorm_test
 
 import (
+	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/zanmato/gorm"
 )
 
 func TestDelete(t *testing.T) {
@@ -89,3 +94,284 @@ func TestSoftDeleteWithCustomizedDeletedAtColumnName(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+func TestSoftDeleteWithFlag(t *testing.T) {
+	type LegacyUser struct {
+		Id        int64
+		Name      string
+		IsDeleted int8   `gorm:"soft_delete:flag;not null;default:0"`
+		DeletedBy *int64 `gorm:"deleted_by"`
+	}
+	DB.DropTableIfExists(&LegacyUser{})
+	DB.AutoMigrate(&LegacyUser{})
+
+	user := LegacyUser{Name: "soft_delete_flag"}
+	DB.Save(&user)
+	DB.Set("gorm:deleted_by", 42).Delete(&user)
+
+	if !DB.First(&LegacyUser{}, "name = ?", user.Name).RecordNotFound() {
+		t.Errorf("Can't find a soft deleted record")
+	}
+
+	var deleted LegacyUser
+	if err := DB.Unscoped().First(&deleted, "name = ?", user.Name).Error; err != nil {
+		t.Errorf("Should be able to find soft deleted record with Unscoped, but err=%s", err)
+	}
+
+	if deleted.IsDeleted != 1 || deleted.DeletedBy == nil || *deleted.DeletedBy != 42 {
+		t.Errorf("Should mark record deleted with deleted flag & deleted by, but got %v, %v", deleted.IsDeleted, deleted.DeletedBy)
+	}
+
+	DB.Unscoped().Delete(&user)
+	if !DB.Unscoped().First(&LegacyUser{}, "name = ?", user.Name).RecordNotFound() {
+		t.Errorf("Can't find permanently deleted record")
+	}
+}
+
+func TestSoftDeleteWithUnixMilli(t *testing.T) {
+	type MilliUser struct {
+		Id        int64
+		Name      string
+		DeletedAt int64 `gorm:"soft_delete:milli"`
+	}
+	DB.DropTableIfExists(&MilliUser{})
+	DB.AutoMigrate(&MilliUser{})
+
+	user := MilliUser{Name: "soft_delete_milli"}
+	DB.Save(&user)
+	DB.Delete(&user)
+
+	if !DB.First(&MilliUser{}, "name = ?", user.Name).RecordNotFound() {
+		t.Errorf("Can't find a soft deleted record")
+	}
+
+	var deleted MilliUser
+	DB.Unscoped().First(&deleted, "name = ?", user.Name)
+	if now := time.Now().UnixNano() / int64(time.Millisecond); deleted.DeletedAt <= 0 || now-deleted.DeletedAt > 60*1000 {
+		t.Errorf("Should set deleted at with unix milliseconds, but got %v", deleted.DeletedAt)
+	}
+}
+
+func TestSoftDeleteWithNullTime(t *testing.T) {
+	type NullTimeUser struct {
+		Id        int64
+		Name      string
+		DeletedAt sql.NullTime
+	}
+	DB.DropTableIfExists(&NullTimeUser{})
+	DB.AutoMigrate(&NullTimeUser{})
+
+	user := NullTimeUser{Name: "soft_delete_null_time"}
+	DB.Save(&user)
+	DB.Delete(&user)
+
+	if !DB.First(&NullTimeUser{}, "name = ?", user.Name).RecordNotFound() {
+		t.Errorf("Can't find a soft deleted record")
+	}
+
+	var deleted NullTimeUser
+	DB.Unscoped().First(&deleted, "name = ?", user.Name)
+	if !deleted.DeletedAt.Valid || time.Since(deleted.DeletedAt.Time) > time.Minute {
+		t.Errorf("Should set deleted at with the current time, but got %v", deleted.DeletedAt)
+	}
+}
+
+func TestDeleteInBatches(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		DB.Save(&User{Name: "delete_in_batches", Age: int64(i)})
+		DB.Save(&Email{Email: "delete_in_batches@example.com"})
+	}
+	DB.Save(&User{Name: "delete_in_batches_kept"})
+
+	if result := DB.DeleteInBatches(&User{}, 2, "name = ?", "delete_in_batches"); result.Error != nil || result.RowsAffected != 5 {
+		t.Errorf("Should delete all matching records in batches, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var count int
+	if DB.Model(&User{}).Where("name LIKE ?", "delete_in_batches%").Count(&count); count != 1 {
+		t.Errorf("Only matching records should be deleted, got %v left", count)
+	}
+
+	if result := DB.Where("email = ?", "delete_in_batches@example.com").DeleteInBatches(&Email{}, 10); result.Error != nil || result.RowsAffected != 5 {
+		t.Errorf("Should delete records matching conditions of the chain, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	for i := 0; i < 3; i++ {
+		DB.Save(&CreditCard{Number: "delete_in_batches"})
+	}
+
+	if result := DB.DeleteInBatches(&CreditCard{}, 2, "number = ?", "delete_in_batches"); result.Error != nil || result.RowsAffected != 3 {
+		t.Errorf("Should soft delete all matching records in batches, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if DB.Unscoped().Model(&CreditCard{}).Where("number = ?", "delete_in_batches").Count(&count); count != 3 {
+		t.Errorf("Records should be soft deleted, got %v", count)
+	}
+}
+
+func TestDeleteAndUpdateWithOrderAndLimit(t *testing.T) {
+	DB.Unscoped().Where("name = ?", "ordered_limit").Delete(&User{})
+	for i := 0; i < 5; i++ {
+		DB.Save(&User{Name: "ordered_limit", Age: int64(i)})
+	}
+
+	if result := DB.Model(&User{}).Where("name = ?", "ordered_limit").Order("age desc").Limit(2).Update("age", 100); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("Should update limited records, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var ages []int64
+	DB.Model(&User{}).Where("name = ?", "ordered_limit").Order("id").Pluck("age", &ages)
+	if fmt.Sprint(ages) != "[0 1 2 100 100]" {
+		t.Errorf("Records should be updated in order, got %v", ages)
+	}
+
+	if result := DB.Where("name = ?", "ordered_limit").Order("age").Limit(2).Delete(&User{}); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("Should delete limited records, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	ages = nil
+	DB.Model(&User{}).Where("name = ?", "ordered_limit").Order("id").Pluck("age", &ages)
+	if fmt.Sprint(ages) != "[2 100 100]" {
+		t.Errorf("Oldest records should be deleted, got %v", ages)
+	}
+
+	if result := DB.Where("name = ?", "ordered_limit").Order("age").Offset(1).Limit(1).Delete(&User{}); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Should delete records with offsets, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	type RecordWithoutPrimaryKey struct {
+		Name string
+	}
+	if result := DB.Where("name = ?", "ordered_limit").Limit(1).Delete(&RecordWithoutPrimaryKey{}); result.Error == nil || !strings.Contains(result.Error.Error(), "primary keys") {
+		t.Errorf("Records without primary keys shouldn't be deleted with limits, got %v", result.Error)
+	}
+}
+
+func TestDeleteWithAssociations(t *testing.T) {
+	user := User{
+		Name:       "delete_with_associations",
+		Emails:     []Email{{Email: "delete1@example.com"}, {Email: "delete2@example.com"}},
+		CreditCard: CreditCard{Number: "4111111111111111"},
+		Languages:  []Language{{Name: "DeleteWithAssociationsEN"}},
+	}
+	other := User{Name: "delete_with_associations_other", Emails: []Email{{Email: "other@example.com"}}}
+	DB.Save(&user).Save(&other)
+
+	if err := DB.Select("Emails", "CreditCard").Delete(&user).Error; err != nil {
+		t.Fatalf("Failed to delete with associations, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&Email{}).Where("user_id = ?", user.Id).Count(&count); count != 0 {
+		t.Errorf("Selected has many associations should be deleted, got %v", count)
+	}
+
+	if DB.Model(&Email{}).Where("user_id = ?", other.Id).Count(&count); count != 1 {
+		t.Errorf("Associations of other records shouldn't be deleted, got %v", count)
+	}
+
+	if DB.Model(&CreditCard{}).Where("user_id = ?", user.Id).Count(&count); count != 0 {
+		t.Errorf("Selected has one associations should be soft deleted, got %v", count)
+	}
+
+	if DB.Unscoped().Model(&CreditCard{}).Where("user_id = ?", user.Id).Count(&count); count != 1 {
+		t.Errorf("Soft deletable associations should be soft deleted, got %v", count)
+	}
+
+	if DB.Table("user_languages").Where("user_id = ?", user.Id).Count(&count); count != 1 {
+		t.Errorf("Associations not selected shouldn't be deleted, got %v", count)
+	}
+
+	users := []User{
+		{Name: "delete_all_associations1", Languages: []Language{{Name: "DeleteAllAssociationsEN"}}, Emails: []Email{{Email: "all1@example.com"}}},
+		{Name: "delete_all_associations2", Languages: []Language{{Name: "DeleteAllAssociationsDE"}}},
+	}
+	DB.Save(&users[0]).Save(&users[1])
+
+	ids := []int64{users[0].Id, users[1].Id}
+	if err := DB.Select(gorm.Associations).Where("id IN (?)", ids).Delete(&users).Error; err != nil {
+		t.Fatalf("Failed to delete all associations, got %v", err)
+	}
+
+	if DB.Table("user_languages").Where("user_id IN (?)", ids).Count(&count); count != 0 {
+		t.Errorf("Relations of many to many associations should be deleted, got %v", count)
+	}
+
+	if DB.Model(&Language{}).Where("name LIKE ?", "DeleteAllAssociations%").Count(&count); count != 2 {
+		t.Errorf("Records of many to many associations should be kept, got %v", count)
+	}
+
+	if DB.Model(&Email{}).Where("user_id = ?", users[0].Id).Count(&count); count != 0 {
+		t.Errorf("All associations should be deleted, got %v", count)
+	}
+}
+
+type PurgedUser struct {
+	Id        int64
+	Name      string
+	DeletedAt *time.Time
+}
+
+func TestPurgeSoftDeleted(t *testing.T) {
+	DB.DropTableIfExists(&PurgedUser{})
+	DB.AutoMigrate(&PurgedUser{})
+
+	for _, name := range []string{"kept", "recently_deleted", "deleted_long_ago", "deleted_long_ago"} {
+		user := PurgedUser{Name: name}
+		DB.Save(&user)
+		if name != "kept" {
+			DB.Delete(&user)
+		}
+	}
+	DB.Unscoped().Model(&PurgedUser{}).Where("name = ?", "deleted_long_ago").UpdateColumn("deleted_at", time.Now().AddDate(0, 0, -100))
+
+	if result := DB.PurgeSoftDeleted(&PurgedUser{}, 90*24*time.Hour); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("Should purge records soft deleted before retention, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var names []string
+	DB.Unscoped().Model(&PurgedUser{}).Order("id").Pluck("name", &names)
+	if len(names) != 2 || names[0] != "kept" || names[1] != "recently_deleted" {
+		t.Errorf("Only records soft deleted before retention should be purged, got %v", names)
+	}
+
+	purger := gorm.NewSoftDeletePurger(DB).BatchSize(1).Register(&PurgedUser{}, 0)
+	if rowsAffected, err := purger.Purge(); err != nil || rowsAffected != 1 {
+		t.Errorf("Should purge records of registered models, got %v, %v", rowsAffected, err)
+	}
+
+	if DB.Unscoped().Model(&PurgedUser{}).Pluck("name", &names); len(names) != 1 || names[0] != "kept" {
+		t.Errorf("Records which aren't soft deleted should be kept, got %v", names)
+	}
+
+	if err := DB.PurgeSoftDeleted(&Email{}, time.Hour).Error; err == nil {
+		t.Errorf("Should return error for models which aren't soft deleted")
+	}
+
+	stop := purger.Start(time.Millisecond)
+	stop()
+	stop()
+
+	logger := purgeErrorLogger(make(chan []interface{}, 1))
+	failing := gorm.NewSoftDeletePurger(DB.Session(&gorm.Session{Logger: logger}).LogMode(false)).Register(&Email{}, time.Hour)
+	stop = failing.Start(time.Millisecond)
+	defer stop()
+	select {
+	case values := <-logger:
+		if values[0] != "error" {
+			t.Errorf("Errors of scheduled purges should be logged as errors, got %v", values)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Errors of scheduled purges should be logged")
+	}
+}
+
+// purgeErrorLogger send values printed by gorm to the channel, dropping them if it is full
+type purgeErrorLogger chan []interface{}
+
+func (logger purgeErrorLogger) Print(values ...interface{}) {
+	select {
+	case logger <- values:
+	default:
+	}
+}
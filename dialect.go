@@ -61,7 +61,10 @@ var dialectsMap = map[string]Dialect{}
 
 func newDialect(name string, db SQLCommon) Dialect {
 	if value, ok := dialectsMap[name]; ok {
-		dialect := reflect.New(reflect.TypeOf(value).Elem()).Interface().(Dialect)
+		// copy the registered dialect, so dialects could be registered with settings
+		reflectValue := reflect.New(reflect.TypeOf(value).Elem())
+		reflectValue.Elem().Set(reflect.ValueOf(value).Elem())
+		dialect := reflectValue.Interface().(Dialect)
 		dialect.SetDB(db)
 		return dialect
 	}
@@ -72,6 +75,21 @@ func newDialect(name string, db SQLCommon) Dialect {
 	return commontDialect
 }
 
+// cloneDialect copy the dialect with the db, so settings of the dialect detected with the DB it was opened with, like
+// pgx's driver, are kept by clones and transactions, dialects which aren't pointers are created with their names
+func cloneDialect(dialect Dialect, db SQLCommon) Dialect {
+	reflectValue := reflect.ValueOf(dialect)
+	if reflectValue.Kind() != reflect.Ptr || reflectValue.IsNil() {
+		return newDialect(dialect.GetName(), db)
+	}
+
+	clone := reflect.New(reflectValue.Type().Elem())
+	clone.Elem().Set(reflectValue.Elem())
+	result := clone.Interface().(Dialect)
+	result.SetDB(db)
+	return result
+}
+
 // RegisterDialect register new dialect
 func RegisterDialect(name string, dialect Dialect) {
 	dialectsMap[name] = dialect
@@ -104,6 +122,14 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 		dataType = gormDataType.GormDataType(dialect)
 	}
 
+	if serializedType, ok := serializedDataType(field, dialect); ok {
+		dataType = serializedType
+	}
+
+	if isBinaryUUIDField(field) {
+		dataType = binaryUUIDDataType(dialect)
+	}
+
 	// Get scanner's real value
 	if dataType == "" {
 		var getScannerValue func(reflect.Value)
@@ -128,16 +154,50 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 	unique, _ := field.TagSettingsGet("UNIQUE")
 	additionalType = notNull + " " + unique
 	if value, ok := field.TagSettingsGet("DEFAULT"); ok {
-		additionalType = additionalType + " DEFAULT " + value
+		// UUID defaults are generated by the create callback, not the database
+		if _, isUUID := uuidGeneratorOf(field); !isUUID {
+			additionalType = additionalType + " DEFAULT " + defaultValueSQL(dialect, value)
+		}
 	}
 
-	if value, ok := field.TagSettingsGet("COMMENT"); ok && dialect.GetName() != "sqlite3" {
-		additionalType = additionalType + " COMMENT " + value
+	// other dialects comment columns with separate statements, see Commenter
+	if value, ok := field.TagSettingsGet("COMMENT"); ok && dialect.GetName() == "mysql" {
+		additionalType = additionalType + " COMMENT " + QuoteComment(value)
 	}
 
 	return fieldValue, dataType, size, strings.TrimSpace(additionalType)
 }
 
+// defaultValueSQL return the default value of tag `default` for the dialect, default expressions like `now()` or
+// `uuid_generate_v4()` are evaluated by the database when inserting, `now()` is converted to `CURRENT_TIMESTAMP`
+// which is supported by all dialects, and expressions are wrapped with parentheses for sqlite and mysql
+func defaultValueSQL(dialect Dialect, value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch strings.ToLower(trimmed) {
+	case "now()", "current_timestamp", "current_timestamp()":
+		return "CURRENT_TIMESTAMP"
+	}
+
+	isExpression := strings.HasSuffix(trimmed, ")") && !strings.HasPrefix(trimmed, "(") &&
+		!strings.HasPrefix(trimmed, "'") && !strings.HasPrefix(trimmed, "\"")
+	if isExpression {
+		switch dialect.GetName() {
+		case "sqlite3", "mysql":
+			return "(" + trimmed + ")"
+		}
+	}
+	return value
+}
+
+// quoteTableName quote the table name with the dialect, qualified table names like `schema.table` are quoted by parts
+func quoteTableName(dialect Dialect, tableName string) string {
+	var parts []string
+	for _, part := range strings.Split(tableName, ".") {
+		parts = append(parts, dialect.Quote(part))
+	}
+	return strings.Join(parts, ".")
+}
+
 func currentDatabaseAndTable(dialect Dialect, tableName string) (string, string) {
 	if strings.Contains(tableName, ".") {
 		splitStrings := strings.SplitN(tableName, ".", 2)
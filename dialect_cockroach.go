@@ -0,0 +1,79 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cockroach is the dialect for CockroachDB, which speaks the postgres wire protocol
+//     db, err := gorm.Open("cockroach", "postgres", "postgresql://root@localhost:26257/defaultdb?sslmode=disable")
+type cockroach struct {
+	postgres
+}
+
+// cockroachTransactionRetries is the max times to retry transactions failed with serialization errors
+var cockroachTransactionRetries = 5
+
+func init() {
+	RegisterDialect("cockroach", &cockroach{})
+}
+
+func (cockroach) GetName() string {
+	return "cockroach"
+}
+
+func (s *cockroach) DataTypeOf(field *StructField) string {
+	sqlType := s.postgres.DataTypeOf(field)
+
+	// SERIAL columns are INT8 with unique_rowid() as default value in CockroachDB
+	for _, serial := range []string{"bigserial", "serial"} {
+		if strings.HasPrefix(sqlType, serial) {
+			return "INT8 DEFAULT unique_rowid()" + strings.TrimPrefix(sqlType, serial)
+		}
+	}
+	return sqlType
+}
+
+// MigrationLockSQL return blank as CockroachDB doesn't support advisory locks, schema changes are coordinated by it
+func (cockroach) MigrationLockSQL() string {
+	return ""
+}
+
+// shouldRetryTransaction retry transactions failed with serialization error 40001
+func (cockroach) shouldRetryTransaction(err error, attempt int) bool {
+	if attempt >= cockroachTransactionRetries || err == nil {
+		return false
+	}
+
+	if sqlState, ok := err.(interface{ SQLState() string }); ok {
+		return sqlState.SQLState() == "40001"
+	}
+	return strings.Contains(err.Error(), "40001") || strings.Contains(err.Error(), "restart transaction")
+}
+
+// transactionRetrier is implemented by dialects whose transactions should be retried on some errors
+type transactionRetrier interface {
+	shouldRetryTransaction(err error, attempt int) bool
+}
+
+// AsOfSystemTime query historical data as of the time, supported by CockroachDB, accepts `time.Time`,
+// an interval like `-10s`, or an expression like `follower_read_timestamp()`
+//     db.AsOfSystemTime("-10s").Find(&users)
+//     // SELECT * FROM "users" AS OF SYSTEM TIME '-10s'
+func (s *DB) AsOfSystemTime(value interface{}) *DB {
+	var clause string
+	switch v := value.(type) {
+	case time.Time:
+		clause = fmt.Sprintf("'%v'", v.UTC().Format("2006-01-02 15:04:05.999999"))
+	case string:
+		if strings.Contains(v, "(") {
+			clause = v
+		} else {
+			clause = "'" + strings.Replace(v, "'", "''", -1) + "'"
+		}
+	default:
+		clause = fmt.Sprint(v)
+	}
+	return s.Set("gorm:as_of_system_time", clause)
+}
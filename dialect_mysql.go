@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 )
@@ -15,12 +17,54 @@ var mysqlIndexRegex = regexp.MustCompile(`^(.+)\((\d+)\)$`)
 
 type mysql struct {
 	commonDialect
+	// version caches the version of the server, it is set for DBs opened with `gorm.Open`
+	version *mysqlVersion
+}
+
+type mysqlVersion struct {
+	once      sync.Once
+	axisOrder bool
 }
 
 func init() {
 	RegisterDialect("mysql", &mysql{})
 }
 
+// SetDB set db of the dialect, the version of the server is queried once when it's needed, and shared by clones
+// and transactions
+func (s *mysql) SetDB(db SQLCommon) {
+	s.commonDialect.SetDB(db)
+	if _, ok := db.(*sql.DB); ok && s.version == nil {
+		s.version = &mysqlVersion{}
+	}
+}
+
+// supportsAxisOrder report whether spatial functions of the server accept the `axis-order` option, which is
+// supported since mysql 8, but not by mariadb
+func (s *mysql) supportsAxisOrder() bool {
+	if s.version == nil {
+		return mysqlSupportsAxisOrder(s.db)
+	}
+
+	s.version.once.Do(func() {
+		s.version.axisOrder = mysqlSupportsAxisOrder(s.db)
+	})
+	return s.version.axisOrder
+}
+
+func mysqlSupportsAxisOrder(db SQLCommon) bool {
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return false
+	}
+
+	if matches := versionRegexp.FindStringSubmatch(version); matches != nil && !strings.Contains(strings.ToLower(version), "mariadb") {
+		major, _ := strconv.Atoi(matches[1])
+		return major >= 8
+	}
+	return false
+}
+
 func (mysql) GetName() string {
 	return "mysql"
 }
@@ -139,6 +183,23 @@ func (s mysql) ModifyColumn(tableName string, columnName string, typ string) err
 	return err
 }
 
+func (s mysql) CommentTableSQL(tableName, comment string) string {
+	return fmt.Sprintf("ALTER TABLE %v COMMENT = %v", quoteTableName(&s, tableName), QuoteComment(comment))
+}
+
+// CommentColumnSQL return blank as column comments of mysql are part of column definitions
+func (mysql) CommentColumnSQL(tableName, columnName, comment string) string {
+	return ""
+}
+
+func (mysql) MigrationLockSQL() string {
+	return "SELECT GET_LOCK(?, -1)"
+}
+
+func (mysql) MigrationUnlockSQL() string {
+	return "SELECT RELEASE_LOCK(?)"
+}
+
 func (s mysql) LimitAndOffsetSQL(limit, offset interface{}) (sql string, err error) {
 	if limit != nil {
 		parsedLimit, err := s.parseInt(limit)
@@ -193,6 +254,30 @@ func (s mysql) HasIndex(tableName string, indexName string) bool {
 	}
 }
 
+func (s mysql) IndexNames(tableName string) (names []string, err error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	// unique indexes are the unique constraints of mysql, indexes whose first column is the first column of a foreign key
+	// back the foreign key, they can't be dropped without their constraints
+	rows, err := s.db.Query(`SELECT DISTINCT s.INDEX_NAME FROM INFORMATION_SCHEMA.STATISTICS s
+	WHERE s.TABLE_SCHEMA = ? AND s.TABLE_NAME = ? AND s.INDEX_NAME <> 'PRIMARY' AND s.NON_UNIQUE = 1
+	AND s.INDEX_NAME NOT IN (SELECT f.INDEX_NAME FROM INFORMATION_SCHEMA.STATISTICS f
+		JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k ON k.TABLE_SCHEMA = f.TABLE_SCHEMA AND k.TABLE_NAME = f.TABLE_NAME AND k.COLUMN_NAME = f.COLUMN_NAME
+		WHERE f.TABLE_SCHEMA = s.TABLE_SCHEMA AND f.TABLE_NAME = s.TABLE_NAME AND f.SEQ_IN_INDEX = 1 AND k.ORDINAL_POSITION = 1 AND k.REFERENCED_TABLE_NAME IS NOT NULL)`, currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 func (s mysql) HasColumn(tableName string, columnName string) bool {
 	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
 	if rows, err := s.db.Query(fmt.Sprintf("SHOW COLUMNS FROM `%s` FROM `%s` WHERE Field = ?", tableName, currentDatabase), columnName); err != nil {
@@ -244,3 +329,56 @@ func (mysql) NormalizeIndexAndColumn(indexName, columnName string) (string, stri
 func (mysql) DefaultValueStr() string {
 	return "VALUES()"
 }
+
+func (mysql) BeginTwoPhaseSQL(xid string) []string {
+	return []string{fmt.Sprintf("XA START '%v'", xid)}
+}
+
+func (mysql) PrepareTwoPhaseSQL(xid string) []string {
+	return []string{fmt.Sprintf("XA END '%v'", xid), fmt.Sprintf("XA PREPARE '%v'", xid)}
+}
+
+func (mysql) CommitPreparedSQL(xid string) []string {
+	return []string{fmt.Sprintf("XA COMMIT '%v'", xid)}
+}
+
+func (mysql) RollbackTwoPhaseSQL(xid string, prepared bool) []string {
+	if prepared {
+		return []string{fmt.Sprintf("XA ROLLBACK '%v'", xid)}
+	}
+	return []string{fmt.Sprintf("XA END '%v'", xid), fmt.Sprintf("XA ROLLBACK '%v'", xid)}
+}
+
+// SupportWriteLimit mysql supports ORDER BY and LIMIT in UPDATE and DELETE statements
+func (mysql) SupportWriteLimit() bool {
+	return true
+}
+
+// TranslateError translate mysql errors to typed errors by error number
+func (mysql) TranslateError(err error) error {
+	switch driverErrorCode(err, "Number") {
+	case "1062":
+		return DialectError{Kind: ErrDuplicatedKey, Err: err}
+	case "1451", "1452":
+		return DialectError{Kind: ErrForeignKeyViolated, Err: err}
+	case "3819":
+		return DialectError{Kind: ErrCheckConstraintViolated, Err: err}
+	case "1213":
+		return DialectError{Kind: ErrDeadlockDetected, Err: err}
+	}
+	return err
+}
+
+func (s mysql) TableNames() ([]string, error) {
+	return queryStrings(s.db, "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME", s.CurrentDatabase())
+}
+
+func (s mysql) ColumnInfos(tableName string) ([]ColumnInfo, error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	return queryColumnInfos(s.db, "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", currentDatabase, tableName)
+}
+
+func (s mysql) ForeignKeyInfos(tableName string) ([]ForeignKeyInfo, error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	return queryForeignKeyInfos(s.db, "SELECT COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL ORDER BY ORDINAL_POSITION", currentDatabase, tableName)
+}
@@ -1,6 +1,8 @@
 package gorm
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -10,11 +12,33 @@ import (
 
 type postgres struct {
 	commonDialect
+	// pgx is set if the DB is opened with pgx's database/sql driver, which doesn't implement the COPY protocol with
+	// prepared statements
+	pgx bool
 }
 
 func init() {
 	RegisterDialect("postgres", &postgres{})
 	RegisterDialect("cloudsqlpostgres", &postgres{})
+	// pgx's database/sql driver is registered as `pgx`, so it could be opened with `gorm.Open("pgx", dsn)` after
+	// importing `github.com/jackc/pgx/v5/stdlib`, statements use the same placeholders in its simple protocol mode
+	RegisterDialect("pgx", &postgres{pgx: true})
+}
+
+// SetDB set db of the dialect, the driver is detected with DBs opened with `gorm.Open("postgres", "pgx", dsn)`
+func (s *postgres) SetDB(db SQLCommon) {
+	s.commonDialect.SetDB(db)
+	if sqlDB, ok := db.(*sql.DB); ok && isPgxDriver(sqlDB.Driver()) {
+		s.pgx = true
+	}
+}
+
+func isPgxDriver(d driver.Driver) bool {
+	reflectType := reflect.TypeOf(d)
+	for reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	return strings.HasPrefix(reflectType.PkgPath(), "github.com/jackc/pgx")
 }
 
 func (postgres) GetName() string {
@@ -60,7 +84,11 @@ func (s *postgres) DataTypeOf(field *StructField) string {
 			}
 		case reflect.Struct:
 			if _, ok := dataValue.Interface().(time.Time); ok {
-				sqlType = "timestamp with time zone"
+				if precision, ok := field.TagSettingsGet("PRECISION"); ok {
+					sqlType = fmt.Sprintf("timestamp(%v) with time zone", precision)
+				} else {
+					sqlType = "timestamp with time zone"
+				}
 			}
 		case reflect.Map:
 			if dataValue.Type().Name() == "Hstore" {
@@ -93,10 +121,37 @@ func (s *postgres) DataTypeOf(field *StructField) string {
 
 func (s postgres) HasIndex(tableName string, indexName string) bool {
 	var count int
-	s.db.QueryRow("SELECT count(*) FROM pg_indexes WHERE tablename = $1 AND indexname = $2 AND schemaname = CURRENT_SCHEMA()", tableName, indexName).Scan(&count)
+	schema, tableName := postgresSchemaAndTable(tableName)
+	s.db.QueryRow("SELECT count(*) FROM pg_indexes WHERE tablename = $1 AND indexname = $2 AND schemaname = COALESCE(NULLIF($3, ''), CURRENT_SCHEMA())", tableName, indexName, schema).Scan(&count)
 	return count > 0
 }
 
+// IndexNames list indexes of the table, indexes of constraints like `users_email_key` of unique columns, and indexes
+// whose first column is the first column of a foreign key are skipped
+func (s postgres) IndexNames(tableName string) (names []string, err error) {
+	schema, tableName := postgresSchemaAndTable(tableName)
+	rows, err := s.db.Query(`SELECT i.relname FROM pg_index x
+	JOIN pg_class i ON i.oid = x.indexrelid
+	JOIN pg_class t ON t.oid = x.indrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	WHERE t.relname = $1 AND n.nspname = COALESCE(NULLIF($2, ''), CURRENT_SCHEMA()) AND NOT x.indisprimary
+	AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = x.indexrelid)
+	AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conrelid = x.indrelid AND c.contype = 'f' AND c.conkey[1] = x.indkey[0])`, tableName, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 func (s postgres) HasForeignKey(tableName string, foreignKeyName string) bool {
 	var count int
 	s.db.QueryRow("SELECT count(con.conname) FROM pg_constraint con WHERE $1::regclass::oid = con.conrelid AND con.conname = $2 AND con.contype='f'", tableName, foreignKeyName).Scan(&count)
@@ -105,13 +160,15 @@ func (s postgres) HasForeignKey(tableName string, foreignKeyName string) bool {
 
 func (s postgres) HasTable(tableName string) bool {
 	var count int
-	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.tables WHERE table_name = $1 AND table_type = 'BASE TABLE' AND table_schema = CURRENT_SCHEMA()", tableName).Scan(&count)
+	schema, tableName := postgresSchemaAndTable(tableName)
+	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.tables WHERE table_name = $1 AND table_type = 'BASE TABLE' AND table_schema = COALESCE(NULLIF($2, ''), CURRENT_SCHEMA())", tableName, schema).Scan(&count)
 	return count > 0
 }
 
 func (s postgres) HasColumn(tableName string, columnName string) bool {
 	var count int
-	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.columns WHERE table_name = $1 AND column_name = $2 AND table_schema = CURRENT_SCHEMA()", tableName, columnName).Scan(&count)
+	schema, tableName := postgresSchemaAndTable(tableName)
+	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.columns WHERE table_name = $1 AND column_name = $2 AND table_schema = COALESCE(NULLIF($3, ''), CURRENT_SCHEMA())", tableName, columnName, schema).Scan(&count)
 	return count > 0
 }
 
@@ -128,10 +185,57 @@ func (s postgres) LastInsertIDReturningSuffix(tableName, key string) string {
 	return fmt.Sprintf("RETURNING %v.%v", tableName, key)
 }
 
+func (s postgres) CommentTableSQL(tableName, comment string) string {
+	return fmt.Sprintf("COMMENT ON TABLE %v IS %v", quoteTableName(&s, tableName), QuoteComment(comment))
+}
+
+func (s postgres) CommentColumnSQL(tableName, columnName, comment string) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %v.%v IS %v", quoteTableName(&s, tableName), s.Quote(columnName), QuoteComment(comment))
+}
+
+func (s postgres) CopyFromSQL(tableName string, columns []string) string {
+	if s.pgx {
+		return ""
+	}
+
+	var quotedColumns []string
+	for _, column := range columns {
+		quotedColumns = append(quotedColumns, s.Quote(column))
+	}
+	return fmt.Sprintf("COPY %v (%v) FROM STDIN", quoteTableName(&s, tableName), strings.Join(quotedColumns, ", "))
+}
+
+func (postgres) MigrationLockSQL() string {
+	return "SELECT 1 FROM pg_advisory_lock(hashtext($1))"
+}
+
+func (postgres) MigrationUnlockSQL() string {
+	return "SELECT pg_advisory_unlock(hashtext($1))"
+}
+
 func (postgres) SupportLastInsertID() bool {
 	return false
 }
 
+func (postgres) BeginTwoPhaseSQL(xid string) []string {
+	return []string{"BEGIN"}
+}
+
+func (postgres) PrepareTwoPhaseSQL(xid string) []string {
+	return []string{fmt.Sprintf("PREPARE TRANSACTION '%v'", xid)}
+}
+
+func (postgres) CommitPreparedSQL(xid string) []string {
+	return []string{fmt.Sprintf("COMMIT PREPARED '%v'", xid)}
+}
+
+func (postgres) RollbackTwoPhaseSQL(xid string, prepared bool) []string {
+	if prepared {
+		return []string{fmt.Sprintf("ROLLBACK PREPARED '%v'", xid)}
+	}
+	return []string{"ROLLBACK"}
+}
+
 func isUUID(value reflect.Value) bool {
 	if value.Kind() != reflect.Array || value.Type().Len() != 16 {
 		return false
@@ -145,3 +249,57 @@ func isJSON(value reflect.Value) bool {
 	_, ok := value.Interface().(json.RawMessage)
 	return ok
 }
+
+// postgresSchemaAndTable split schema qualified table name, schema is blank for the current schema
+func postgresSchemaAndTable(tableName string) (string, string) {
+	if strings.Contains(tableName, ".") {
+		splitStrings := strings.SplitN(tableName, ".", 2)
+		return splitStrings[0], splitStrings[1]
+	}
+	return "", tableName
+}
+
+// TranslateError translate postgres errors to typed errors by SQLSTATE
+func (postgres) TranslateError(err error) error {
+	code := driverErrorCode(err, "Code")
+	if sqlState, ok := err.(interface{ SQLState() string }); ok && code == "" {
+		code = sqlState.SQLState()
+	}
+
+	switch code {
+	case "23505":
+		return DialectError{Kind: ErrDuplicatedKey, Err: err}
+	case "23503":
+		return DialectError{Kind: ErrForeignKeyViolated, Err: err}
+	case "23514":
+		return DialectError{Kind: ErrCheckConstraintViolated, Err: err}
+	case "40P01":
+		return DialectError{Kind: ErrDeadlockDetected, Err: err}
+	case "40001":
+		return DialectError{Kind: ErrSerializationFailure, Err: err}
+	}
+	return err
+}
+
+func (s postgres) TableNames() ([]string, error) {
+	return queryStrings(s.db, "SELECT table_name FROM INFORMATION_SCHEMA.tables WHERE table_type = 'BASE TABLE' AND table_schema = CURRENT_SCHEMA() ORDER BY table_name")
+}
+
+func (s postgres) ColumnInfos(tableName string) ([]ColumnInfo, error) {
+	schema, tableName := postgresSchemaAndTable(tableName)
+	return queryColumnInfos(s.db, `SELECT c.column_name, c.data_type, c.is_nullable,
+	CASE WHEN EXISTS (SELECT 1 FROM INFORMATION_SCHEMA.table_constraints tc
+		JOIN INFORMATION_SCHEMA.key_column_usage k ON k.constraint_name = tc.constraint_name AND k.table_schema = tc.table_schema
+		WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema AND tc.table_name = c.table_name AND k.column_name = c.column_name)
+	THEN 'PRI' ELSE '' END
+	FROM INFORMATION_SCHEMA.columns c WHERE c.table_name = $1 AND c.table_schema = COALESCE(NULLIF($2, ''), CURRENT_SCHEMA()) ORDER BY c.ordinal_position`, tableName, schema)
+}
+
+func (s postgres) ForeignKeyInfos(tableName string) ([]ForeignKeyInfo, error) {
+	schema, tableName := postgresSchemaAndTable(tableName)
+	return queryForeignKeyInfos(s.db, `SELECT k.column_name, cu.table_name, cu.column_name FROM INFORMATION_SCHEMA.table_constraints tc
+	JOIN INFORMATION_SCHEMA.key_column_usage k ON k.constraint_name = tc.constraint_name AND k.table_schema = tc.table_schema
+	JOIN INFORMATION_SCHEMA.constraint_column_usage cu ON cu.constraint_name = tc.constraint_name AND cu.table_schema = tc.table_schema
+	WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_name = $1 AND tc.table_schema = COALESCE(NULLIF($2, ''), CURRENT_SCHEMA())
+	ORDER BY k.ordinal_position`, tableName, schema)
+}
@@ -13,6 +13,8 @@ type sqlite3 struct {
 
 func init() {
 	RegisterDialect("sqlite3", &sqlite3{})
+	// cgo-free drivers like modernc.org/sqlite are registered as `sqlite`, they share DDL and types with sqlite3
+	RegisterDialect("sqlite", &sqlite3{})
 }
 
 func (sqlite3) GetName() string {
@@ -72,19 +74,41 @@ func (s *sqlite3) DataTypeOf(field *StructField) string {
 
 func (s sqlite3) HasIndex(tableName string, indexName string) bool {
 	var count int
-	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM sqlite_master WHERE tbl_name = ? AND sql LIKE '%%INDEX %v ON%%'", indexName), tableName).Scan(&count)
+	master, tableName := sqliteMasterAndTable(tableName)
+	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %v WHERE tbl_name = ? AND sql LIKE '%%INDEX %v ON%%'", master, indexName), tableName).Scan(&count)
 	return count > 0
 }
 
+func (s sqlite3) IndexNames(tableName string) (names []string, err error) {
+	// indexes created implicitly for primary keys & unique constraints have no sql
+	master, tableName := sqliteMasterAndTable(tableName)
+	rows, err := s.db.Query(fmt.Sprintf("SELECT name FROM %v WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", master), tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 func (s sqlite3) HasTable(tableName string) bool {
 	var count int
-	s.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name=?", tableName).Scan(&count)
+	master, tableName := sqliteMasterAndTable(tableName)
+	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %v WHERE type='table' AND name=?", master), tableName).Scan(&count)
 	return count > 0
 }
 
 func (s sqlite3) HasColumn(tableName string, columnName string) bool {
 	var count int
-	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM sqlite_master WHERE tbl_name = ? AND (sql LIKE '%%\"%v\" %%' OR sql LIKE '%%%v %%');\n", columnName, columnName), tableName).Scan(&count)
+	master, tableName := sqliteMasterAndTable(tableName)
+	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %v WHERE tbl_name = ? AND (sql LIKE '%%\"%v\" %%' OR sql LIKE '%%%v %%');\n", master, columnName, columnName), tableName).Scan(&count)
 	return count > 0
 }
 
@@ -105,3 +129,39 @@ func (s sqlite3) CurrentDatabase() (name string) {
 	}
 	return
 }
+
+// sqliteMasterAndTable return the sqlite_master table of the attached database (schema) of the table name
+func sqliteMasterAndTable(tableName string) (string, string) {
+	if strings.Contains(tableName, ".") {
+		splitStrings := strings.SplitN(tableName, ".", 2)
+		return fmt.Sprintf(`"%v".sqlite_master`, splitStrings[0]), splitStrings[1]
+	}
+	return "sqlite_master", tableName
+}
+
+// TranslateError translate sqlite constraint errors to typed errors by their messages
+func (sqlite3) TranslateError(err error) error {
+	switch message := err.Error(); {
+	case strings.Contains(message, "UNIQUE constraint failed"), strings.Contains(message, "PRIMARY KEY constraint failed"):
+		return DialectError{Kind: ErrDuplicatedKey, Err: err}
+	case strings.Contains(message, "FOREIGN KEY constraint failed"):
+		return DialectError{Kind: ErrForeignKeyViolated, Err: err}
+	case strings.Contains(message, "CHECK constraint failed"):
+		return DialectError{Kind: ErrCheckConstraintViolated, Err: err}
+	}
+	return err
+}
+
+func (s sqlite3) TableNames() ([]string, error) {
+	return queryStrings(s.db, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+}
+
+func (s sqlite3) ColumnInfos(tableName string) ([]ColumnInfo, error) {
+	return queryColumnInfos(s.db, `SELECT name, type, CASE WHEN "notnull" = 0 AND pk = 0 THEN 'YES' ELSE 'NO' END,
+	CASE WHEN pk > 0 THEN 'PRI' ELSE '' END FROM pragma_table_info(?) ORDER BY cid`, tableName)
+}
+
+func (s sqlite3) ForeignKeyInfos(tableName string) ([]ForeignKeyInfo, error) {
+	// the referenced column is NULL if it references the primary key
+	return queryForeignKeyInfos(s.db, `SELECT "from", "table", COALESCE("to", 'id') FROM pragma_foreign_key_list(?) ORDER BY id, seq`, tableName)
+}
@@ -11,7 +11,7 @@ import (
 	"time"
 
 	// Importing mssql driver package only in dialect file, otherwide not needed
-	_ "github.com/denisenkom/go-mssqldb"
+	mssqldb "github.com/denisenkom/go-mssqldb"
 	"github.com/zanmato/gorm"
 )
 
@@ -92,7 +92,11 @@ func (s *mssql) DataTypeOf(field *gorm.StructField) string {
 			}
 		case reflect.Struct:
 			if _, ok := dataValue.Interface().(time.Time); ok {
-				sqlType = "datetimeoffset"
+				if precision, ok := field.TagSettingsGet("PRECISION"); ok {
+					sqlType = fmt.Sprintf("datetimeoffset(%v)", precision)
+				} else {
+					sqlType = "datetimeoffset"
+				}
 			}
 		default:
 			if gorm.IsByteArrayOrSlice(dataValue) {
@@ -147,14 +151,14 @@ func (s mssql) HasForeignKey(tableName string, foreignKeyName string) bool {
 func (s mssql) HasTable(tableName string) bool {
 	var count int
 	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
-	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.tables WHERE table_name = ? AND table_catalog = ?", tableName, currentDatabase).Scan(&count)
+	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.tables WHERE table_name = ? AND (table_catalog = ? OR table_schema = ?)", tableName, currentDatabase, currentDatabase).Scan(&count)
 	return count > 0
 }
 
 func (s mssql) HasColumn(tableName string, columnName string) bool {
 	var count int
 	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
-	s.db.QueryRow("SELECT count(*) FROM information_schema.columns WHERE table_catalog = ? AND table_name = ? AND column_name = ?", currentDatabase, tableName, columnName).Scan(&count)
+	s.db.QueryRow("SELECT count(*) FROM information_schema.columns WHERE (table_catalog = ? OR table_schema = ?) AND table_name = ? AND column_name = ?", currentDatabase, currentDatabase, tableName, columnName).Scan(&count)
 	return count > 0
 }
 
@@ -194,6 +198,49 @@ func (mssql) LimitAndOffsetSQL(limit, offset interface{}) (sql string, err error
 	return
 }
 
+func (s mssql) CommentTableSQL(tableName, comment string) string {
+	return descriptionSQL(tableName, "", comment)
+}
+
+func (s mssql) CommentColumnSQL(tableName, columnName, comment string) string {
+	return descriptionSQL(tableName, columnName, comment)
+}
+
+// descriptionSQL add or update extended property `MS_Description` of the table, or the column if given
+func descriptionSQL(tableName, columnName, comment string) string {
+	schema := "dbo"
+	if strings.Contains(tableName, ".") {
+		splitStrings := strings.SplitN(tableName, ".", 2)
+		schema, tableName = splitStrings[0], splitStrings[1]
+	}
+
+	var (
+		objectID = fmt.Sprintf("OBJECT_ID(N'%v.%v')", schema, tableName)
+		minorID  = "0"
+		levels   = fmt.Sprintf("@level0type = N'SCHEMA', @level0name = N'%v', @level1type = N'TABLE', @level1name = N'%v'", schema, tableName)
+		value    = "N" + gorm.QuoteComment(comment)
+	)
+	if columnName != "" {
+		minorID = fmt.Sprintf("COLUMNPROPERTY(%v, N'%v', 'ColumnId')", objectID, columnName)
+		levels += fmt.Sprintf(", @level2type = N'COLUMN', @level2name = N'%v'", columnName)
+	}
+
+	return fmt.Sprintf("IF EXISTS (SELECT 1 FROM sys.extended_properties WHERE major_id = %v AND minor_id = %v AND name = N'MS_Description') "+
+		"EXEC sp_updateextendedproperty @name = N'MS_Description', @value = %v, %v "+
+		"ELSE EXEC sp_addextendedproperty @name = N'MS_Description', @value = %v, %v",
+		objectID, minorID, value, levels, value, levels)
+}
+
+func (mssql) MigrationLockSQL() string {
+	return "DECLARE @result int; " +
+		"EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = -1; " +
+		"SELECT CASE WHEN @result >= 0 THEN 1 ELSE 0 END"
+}
+
+func (mssql) MigrationUnlockSQL() string {
+	return "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'"
+}
+
 func (mssql) SelectFromDummyTable() string {
 	return ""
 }
@@ -220,6 +267,28 @@ func (mssql) NormalizeIndexAndColumn(indexName, columnName string) (string, stri
 	return indexName, columnName
 }
 
+// TranslateError translate mssql errors to typed errors by error number
+func (mssql) TranslateError(err error) error {
+	sqlErr, ok := err.(mssqldb.Error)
+	if !ok {
+		return err
+	}
+
+	switch sqlErr.Number {
+	case 2601, 2627:
+		return gorm.DialectError{Kind: gorm.ErrDuplicatedKey, Err: err}
+	case 547:
+		if strings.Contains(sqlErr.Message, "CHECK") {
+			return gorm.DialectError{Kind: gorm.ErrCheckConstraintViolated, Err: err}
+		}
+		return gorm.DialectError{Kind: gorm.ErrForeignKeyViolated, Err: err}
+	case 1205:
+		return gorm.DialectError{Kind: gorm.ErrDeadlockDetected, Err: err}
+	}
+	return err
+}
+
+// currentDatabaseAndTable split qualified table name, the qualifier could be a database or a schema (set with `DB.Schema`)
 func currentDatabaseAndTable(dialect gorm.Dialect, tableName string) (string, string) {
 	if strings.Contains(tableName, ".") {
 		splitStrings := strings.SplitN(tableName, ".", 2)
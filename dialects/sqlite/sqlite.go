@@ -1,3 +1,14 @@
+//go:build !purego
+// +build !purego
+
+// Package sqlite import the sqlite driver, the cgo driver github.com/mattn/go-sqlite3 is used by default, build with
+// tag `purego` to use the cgo-free driver modernc.org/sqlite, which is registered as `sqlite`, so DBs are opened with
+// `gorm.Open("sqlite", dsn)`, or `gorm.Open("sqlite3", sqlite.DriverName, dsn)` to work with both of them,
+// DSN parameters of the drivers differ though, e.g. foreign keys are enabled with `_foreign_keys=1` for mattn and
+// `_pragma=foreign_keys(1)` for modernc
 package sqlite
 
 import _ "github.com/mattn/go-sqlite3"
+
+// DriverName is the name github.com/mattn/go-sqlite3 is registered with
+const DriverName = "sqlite3"
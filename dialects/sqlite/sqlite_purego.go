@@ -0,0 +1,10 @@
+//go:build purego
+// +build purego
+
+package sqlite
+
+import _ "modernc.org/sqlite"
+
+// DriverName is the name modernc.org/sqlite is registered with, it isn't registered as `sqlite3` too, as that would
+// panic in binaries importing github.com/mattn/go-sqlite3 as well
+const DriverName = "sqlite"
@@ -0,0 +1,231 @@
+package gorm
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// DriverHooks intercept statements at the driver level, under gorm and `database/sql`, so middleware like tracing,
+// query logging and fault injection see every statement, including statements of transactions and raw SQL
+//     hooks := &gorm.DriverHooks{
+//       Before: func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, error) {
+//         return context.WithValue(ctx, startKey, time.Now()), nil
+//       },
+//       After: func(ctx context.Context, query string, args []driver.NamedValue, err error) {
+//         log.Printf("%v took %v", query, time.Since(ctx.Value(startKey).(time.Time)))
+//       },
+//     }
+//     db, err := gorm.Open("postgres", gorm.WrapConnector(connector, hooks))
+//     // or register the wrapped driver
+//     sql.Register("postgres-hooked", gorm.WrapDriver(&pq.Driver{}, hooks))
+//     db, err := gorm.Open("postgres", "postgres-hooked", dsn)
+type DriverHooks struct {
+	// Before is called before statements are executed, the returned context is passed to the driver and After,
+	// statements aren't executed if it returns an error
+	Before func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, error)
+	// After is called after statements are executed with their errors, rows of queries might not be read yet
+	After func(ctx context.Context, query string, args []driver.NamedValue, err error)
+}
+
+func (hooks *DriverHooks) before(ctx context.Context, query string, args []driver.NamedValue) (context.Context, error) {
+	if hooks.Before == nil {
+		return ctx, nil
+	}
+	return hooks.Before(ctx, query, args)
+}
+
+func (hooks *DriverHooks) after(ctx context.Context, query string, args []driver.NamedValue, err error) {
+	if hooks.After != nil {
+		hooks.After(ctx, query, args, err)
+	}
+}
+
+// WrapDriver wrap the driver to intercept statements with hooks, the wrapped driver could be registered with
+// `sql.Register`
+func WrapDriver(d driver.Driver, hooks *DriverHooks) driver.Driver {
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		return &hookedDriverContext{hookedDriver{Driver: d, hooks: hooks}, driverContext}
+	}
+	return &hookedDriver{Driver: d, hooks: hooks}
+}
+
+// WrapConnector wrap the connector to intercept statements with hooks, the wrapped connector could be opened with
+// `gorm.Open`
+func WrapConnector(connector driver.Connector, hooks *DriverHooks) driver.Connector {
+	return &hookedConnector{Connector: connector, hooks: hooks}
+}
+
+type hookedDriver struct {
+	driver.Driver
+	hooks *DriverHooks
+}
+
+func (d *hookedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedConn{Conn: conn, hooks: d.hooks}, nil
+}
+
+type hookedDriverContext struct {
+	hookedDriver
+	driverContext driver.DriverContext
+}
+
+func (d *hookedDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	connector, err := d.driverContext.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedConnector{Connector: connector, hooks: d.hooks, driver: d}, nil
+}
+
+type hookedConnector struct {
+	driver.Connector
+	hooks  *DriverHooks
+	driver driver.Driver
+}
+
+func (connector *hookedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := connector.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedConn{Conn: conn, hooks: connector.hooks}, nil
+}
+
+func (connector *hookedConnector) Driver() driver.Driver {
+	if connector.driver != nil {
+		return connector.driver
+	}
+	return &hookedDriver{Driver: connector.Connector.Driver(), hooks: connector.hooks}
+}
+
+// hookedConn run hooks for statements of the connection, optional interfaces of the connection are forwarded, or
+// driver.ErrSkip is returned so `database/sql` falls back to prepared statements, which run hooks too
+type hookedConn struct {
+	driver.Conn
+	hooks *DriverHooks
+}
+
+func (conn *hookedConn) Prepare(query string) (driver.Stmt, error) {
+	return conn.PrepareContext(context.Background(), query)
+}
+
+func (conn *hookedConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	if preparer, ok := conn.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hookedStmt{Stmt: stmt, query: query, hooks: conn.hooks}, nil
+}
+
+func (conn *hookedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := conn.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return conn.Conn.Begin()
+}
+
+func (conn *hookedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	execer, ok := conn.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	if ctx, err = conn.hooks.before(ctx, query, args); err != nil {
+		return nil, err
+	}
+	result, err = execer.ExecContext(ctx, query, args)
+	conn.hooks.after(ctx, query, args, err)
+	return result, err
+}
+
+func (conn *hookedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	queryer, ok := conn.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	if ctx, err = conn.hooks.before(ctx, query, args); err != nil {
+		return nil, err
+	}
+	rows, err = queryer.QueryContext(ctx, query, args)
+	conn.hooks.after(ctx, query, args, err)
+	return rows, err
+}
+
+func (conn *hookedConn) Ping(ctx context.Context) error {
+	if pinger, ok := conn.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (conn *hookedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := conn.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (conn *hookedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := conn.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type hookedStmt struct {
+	driver.Stmt
+	query string
+	hooks *DriverHooks
+}
+
+func (stmt *hookedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	if ctx, err = stmt.hooks.before(ctx, stmt.query, args); err != nil {
+		return nil, err
+	}
+
+	if execer, ok := stmt.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = stmt.Stmt.Exec(namedValuesToValues(args))
+	}
+	stmt.hooks.after(ctx, stmt.query, args, err)
+	return result, err
+}
+
+func (stmt *hookedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	if ctx, err = stmt.hooks.before(ctx, stmt.query, args); err != nil {
+		return nil, err
+	}
+
+	if queryer, ok := stmt.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = stmt.Stmt.Query(namedValuesToValues(args))
+	}
+	stmt.hooks.after(ctx, stmt.query, args, err)
+	return rows, err
+}
+
+func (stmt *hookedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := stmt.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -0,0 +1,101 @@
+package gorm_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type driverHooksQueryKey struct{}
+
+var (
+	registerOnce          sync.Once
+	registeredDriverHooks = &gorm.DriverHooks{}
+)
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (connector dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return connector.driver.Open(connector.dsn)
+}
+
+func (connector dsnConnector) Driver() driver.Driver {
+	return connector.driver
+}
+
+func TestDriverHooks(t *testing.T) {
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("driver hooks are tested with sqlite")
+	}
+
+	var (
+		mutex   sync.Mutex
+		queries []string
+	)
+	hooks := &gorm.DriverHooks{
+		Before: func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, error) {
+			if strings.Contains(query, "chaos") {
+				return ctx, errors.New("injected fault")
+			}
+			return context.WithValue(ctx, driverHooksQueryKey{}, query), nil
+		},
+		After: func(ctx context.Context, query string, args []driver.NamedValue, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if ctx.Value(driverHooksQueryKey{}) == query {
+				queries = append(queries, query)
+			}
+		},
+	}
+
+	connector := dsnConnector{dsn: filepath.Join(os.TempDir(), "gorm.db"), driver: DB.DB().Driver()}
+	db, err := gorm.Open("sqlite3", gorm.WrapConnector(connector, hooks))
+	if err != nil {
+		t.Fatalf("Failed to open the connector, got %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.Model(&User{}).Where("name = ?", "driver_hooks").Count(&count).Error; err != nil {
+		t.Errorf("Failed to query with hooks, got %v", err)
+	}
+
+	db.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&User{}).Where("name = ?", "driver_hooks").Update("age", 1).Error
+	})
+
+	if len(queries) != 2 || !strings.Contains(queries[0], "SELECT count(*)") || !strings.Contains(queries[1], "UPDATE") {
+		t.Errorf("Hooks should run for statements of the connection and transactions, got %v", queries)
+	}
+
+	if err := db.Exec("SELECT 'chaos'").Error; err == nil || err.Error() != "injected fault" {
+		t.Errorf("Statements should fail with errors of Before, got %v", err)
+	}
+
+	*registeredDriverHooks = *hooks
+	registerOnce.Do(func() {
+		sql.Register("sqlite3_driver_hooks_test", gorm.WrapDriver(DB.DB().Driver(), registeredDriverHooks))
+	})
+	registered, err := gorm.Open("sqlite3", "sqlite3_driver_hooks_test", filepath.Join(os.TempDir(), "gorm.db"))
+	if err != nil {
+		t.Fatalf("Failed to open the registered driver, got %v", err)
+	}
+	defer registered.Close()
+
+	queries = nil
+	registered.Model(&User{}).Where("name = ?", "driver_hooks").Count(&count)
+	if len(queries) != 1 {
+		t.Errorf("Hooks of wrapped drivers should run, got %v", queries)
+	}
+}
@@ -0,0 +1,71 @@
+package gorm
+
+// MigrateOption is an option of `AutoMigrate`, which is passed together with models
+//     db.AutoMigrate(&User{}, &Product{}, gorm.WithDropUnused())
+type MigrateOption struct {
+	dropUnused bool
+	dryRun     func(diff SchemaDiff)
+}
+
+// WithDropUnused drop columns and indexes of existing tables which aren't defined by the models anymore, it is
+// destructive and meant for development and test databases, sqlite before 3.35 can't drop columns
+func WithDropUnused() MigrateOption {
+	return MigrateOption{dropUnused: true}
+}
+
+// WithDropUnusedDryRun list columns and indexes which would be dropped by `WithDropUnused` with the function instead
+// of dropping them, diffs only have extra columns and indexes, tables without unused columns and indexes are skipped
+//     db.AutoMigrate(&User{}, gorm.WithDropUnusedDryRun(func(diff gorm.SchemaDiff) {
+//       log.Println(diff)
+//     }))
+func WithDropUnusedDryRun(list func(diff SchemaDiff)) MigrateOption {
+	return MigrateOption{dropUnused: true, dryRun: list}
+}
+
+// migrateOptions split options of `AutoMigrate` from models
+func migrateOptions(values []interface{}) (models []interface{}, option MigrateOption) {
+	for _, value := range values {
+		if o, ok := value.(MigrateOption); ok {
+			option.dropUnused = option.dropUnused || o.dropUnused
+			if o.dryRun != nil {
+				option.dryRun = o.dryRun
+			}
+		} else {
+			models = append(models, value)
+		}
+	}
+	return
+}
+
+// dropUnused drop columns and indexes of the table which aren't defined by the model, indexes are dropped first as
+// they may use the columns. Indexes backing unique constraints and foreign keys aren't listed by dialects, so they
+// aren't dropped
+func (scope *Scope) dropUnused(option MigrateOption) *Scope {
+	if !option.dropUnused || scope.HasError() {
+		return scope
+	}
+
+	diff, err := scope.schemaDiff()
+	if scope.Err(err) != nil || diff.MissingTable {
+		return scope
+	}
+
+	unused := SchemaDiff{Model: diff.Model, Table: diff.Table, ExtraColumns: diff.ExtraColumns, ExtraIndexes: diff.ExtraIndexes}
+	if unused.Empty() {
+		return scope
+	}
+
+	if option.dryRun != nil {
+		option.dryRun(unused)
+		return scope
+	}
+
+	for _, name := range unused.ExtraIndexes {
+		scope.Err(scope.Dialect().RemoveIndex(unused.Table, name))
+	}
+
+	for _, column := range unused.ExtraColumns {
+		scope.dropColumn(column)
+	}
+	return scope
+}
@@ -1,6 +1,9 @@
 package gorm_test
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 type BasePost struct {
 	Id    int64
@@ -89,3 +92,70 @@ func TestEmbeddedPointerTypeStruct(t *testing.T) {
 		t.Errorf("Should find correct value for embedded pointer type")
 	}
 }
+
+type EmbeddedGeo struct {
+	Lat float64
+	Lng float64
+}
+
+type EmbeddedAddress struct {
+	Street string
+	Geo    *EmbeddedGeo `gorm:"embedded;embedded_prefix:geo_"`
+}
+
+type EmbeddedCompany struct {
+	ID       uint
+	Name     string
+	Street   string
+	Home     EmbeddedAddress  `gorm:"embedded;embedded_prefix:home_"`
+	Work     *EmbeddedAddress `gorm:"embedded;embedded_prefix:work_"`
+	Location EmbeddedAddress  `gorm:"embedded;embedded_prefix:-"`
+}
+
+func TestNestedEmbeddedStruct(t *testing.T) {
+	columns := map[string][]string{}
+	for _, field := range DB.NewScope(&EmbeddedCompany{}).GetModelStruct().StructFields {
+		if field.IsNormal {
+			columns[field.DBName] = field.Names
+		}
+	}
+
+	for column, names := range map[string]string{
+		"street":       "Street",
+		"home_street":  "Home.Street",
+		"home_geo_lat": "Home.Geo.Lat",
+		"work_geo_lng": "Work.Geo.Lng",
+		"lat":          "Location.Geo.Lat",
+	} {
+		if strings.Join(columns[column], ".") != names {
+			t.Errorf("Column %v should be field %v, but got %v", column, names, columns[column])
+		}
+	}
+
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&EmbeddedCompany{})
+	if err := db.AutoMigrate(&EmbeddedCompany{}).Error; err != nil {
+		t.Fatalf("Failed to migrate nested embedded struct, got %v", err)
+	}
+
+	company := EmbeddedCompany{
+		Name:     "company",
+		Street:   "main street",
+		Home:     EmbeddedAddress{Street: "home street", Geo: &EmbeddedGeo{Lat: 1, Lng: 2}},
+		Work:     &EmbeddedAddress{Street: "work street", Geo: &EmbeddedGeo{Lat: 3, Lng: 4}},
+		Location: EmbeddedAddress{Geo: &EmbeddedGeo{Lat: 5, Lng: 6}},
+	}
+	if err := db.Create(&company).Error; err != nil {
+		t.Fatalf("Failed to create nested embedded struct, got %v", err)
+	}
+
+	var result EmbeddedCompany
+	if err := db.First(&result, company.ID).Error; err != nil {
+		t.Fatalf("Failed to find nested embedded struct, got %v", err)
+	}
+
+	if result.Street != "main street" || result.Home.Geo.Lng != 2 || result.Work.Street != "work street" ||
+		result.Work.Geo.Lat != 3 || result.Location.Geo.Lat != 5 {
+		t.Errorf("Nested embedded struct should be saved and loaded, but got %#v", result)
+	}
+}
@@ -0,0 +1,173 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var enumTypeRegexp = regexp.MustCompile(`(?i)^\s*enum\s*\((.*)\)\s*$`)
+
+// enumValues return the values of an enum field, declared with tag `type:enum('a','b')`
+func enumValues(field *StructField) ([]string, bool) {
+	dataType, ok := field.TagSettingsGet("TYPE")
+	if !ok {
+		return nil, false
+	}
+
+	matches := enumTypeRegexp.FindStringSubmatch(dataType)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return parseEnumValues(matches[1]), true
+}
+
+// parseEnumValues parse quoted values like `'a','b'`, single quotes in values are escaped by doubling them
+func parseEnumValues(str string) (values []string) {
+	var (
+		value   []rune
+		inQuote bool
+		runes   = []rune(str)
+	)
+
+	for idx := 0; idx < len(runes); idx++ {
+		switch char := runes[idx]; {
+		case char == '\'' && inQuote && idx+1 < len(runes) && runes[idx+1] == '\'':
+			value = append(value, char)
+			idx++
+		case char == '\'':
+			if inQuote {
+				values = append(values, string(value))
+				value = nil
+			}
+			inQuote = !inQuote
+		case inQuote:
+			value = append(value, char)
+		}
+	}
+	return
+}
+
+func quoteEnumValues(values []string) string {
+	var quoted []string
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.Replace(value, "'", "''", -1)+"'")
+	}
+	return strings.Join(quoted, ",")
+}
+
+// dataTypeOf return field's sql data type, enum fields are mapped to the dialect's enum support:
+// ENUM on mysql, a named type created with CREATE TYPE on postgres, and a CHECK constraint on others,
+// time fields without `precision` tags get the precision of `TimePrecision`
+func (scope *Scope) dataTypeOf(field *StructField) string {
+	field = scope.withTimePrecision(field)
+	values, ok := enumValues(field)
+	if !ok {
+		return scope.Dialect().DataTypeOf(field)
+	}
+
+	enumField := field.clone()
+	switch scope.Dialect().GetName() {
+	case "mysql":
+		enumField.TagSettingsSet("TYPE", fmt.Sprintf("enum(%v)", quoteEnumValues(values)))
+	case "postgres", "cockroach":
+		typeName := scope.enumTypeName(field)
+		scope.migrateEnumType(typeName, values)
+		enumField.TagSettingsSet("TYPE", scope.Quote(typeName))
+	default:
+		size := 255
+		if num, ok := field.TagSettingsGet("SIZE"); ok {
+			size, _ = strconv.Atoi(num)
+		}
+		enumField.TagSettingsSet("TYPE", fmt.Sprintf("varchar(%d) CHECK (%v IN (%v))", size, scope.Quote(field.DBName), quoteEnumValues(values)))
+	}
+	return scope.Dialect().DataTypeOf(enumField)
+}
+
+// enumTypeName return the name of postgres enum type, which could be specified with tag `enum_name`,
+// defaults to `<table>_<column>_enum`
+func (scope *Scope) enumTypeName(field *StructField) string {
+	if name, ok := field.TagSettingsGet("ENUM_NAME"); ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("%v_%v_enum", scope.TableName(), field.DBName)
+}
+
+// migrateEnumType create postgres enum type if not exists, or add missing values to it
+func (scope *Scope) migrateEnumType(typeName string, values []string) {
+	var count int
+	// types are created in the current schema, types of the same name in other schemas are different types
+	if scope.Err(scope.SQLDB().QueryRow("SELECT count(*) FROM pg_type t JOIN pg_namespace n ON t.typnamespace = n.oid WHERE t.typname = $1 AND n.nspname = current_schema()", typeName).Scan(&count)) != nil {
+		return
+	}
+
+	if count == 0 {
+		scope.Err(scope.NewDB().Exec(fmt.Sprintf("CREATE TYPE %v AS ENUM (%v)", scope.Quote(typeName), quoteEnumValues(values))).Error)
+		return
+	}
+
+	rows, err := scope.SQLDB().Query("SELECT e.enumlabel FROM pg_enum e JOIN pg_type t ON e.enumtypid = t.oid JOIN pg_namespace n ON t.typnamespace = n.oid WHERE t.typname = $1 AND n.nspname = current_schema()", typeName)
+	if scope.Err(err) != nil {
+		return
+	}
+
+	var existing []string
+	for rows.Next() {
+		var label string
+		if scope.Err(rows.Scan(&label)) == nil {
+			existing = append(existing, label)
+		}
+	}
+	rows.Close()
+
+	for _, value := range values {
+		if !strInSlice(value, existing) {
+			if scope.Err(scope.NewDB().Exec(fmt.Sprintf("ALTER TYPE %v ADD VALUE %v", scope.Quote(typeName), quoteEnumValues([]string{value}))).Error) != nil {
+				return
+			}
+		}
+	}
+}
+
+// migrateEnumColumn add new values to existing enum column, existing values are kept so stored data stays valid.
+// Values of CHECK constraints (e.g. sqlite) can't be changed without rebuilding the table, so they are left as is
+func (scope *Scope) migrateEnumColumn(field *StructField) {
+	values, ok := enumValues(field)
+	if !ok {
+		return
+	}
+
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroach":
+		scope.migrateEnumType(scope.enumTypeName(field), values)
+	case "mysql":
+		var columnType string
+		if scope.Err(scope.SQLDB().QueryRow(
+			"SELECT COLUMN_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+			scope.TableName(), field.DBName,
+		).Scan(&columnType)) != nil {
+			return
+		}
+
+		var (
+			existing []string
+			changed  bool
+		)
+		if matches := enumTypeRegexp.FindStringSubmatch(columnType); len(matches) > 0 {
+			existing = parseEnumValues(matches[1])
+		}
+		for _, value := range values {
+			if !strInSlice(value, existing) {
+				existing = append(existing, value)
+				changed = true
+			}
+		}
+
+		if changed {
+			enumField := field.clone()
+			enumField.TagSettingsSet("TYPE", fmt.Sprintf("enum(%v)", quoteEnumValues(existing)))
+			scope.modifyColumn(field.DBName, scope.Dialect().DataTypeOf(enumField))
+		}
+	}
+}
@@ -2,11 +2,13 @@ package gorm
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 )
 
 var (
-	// ErrRecordNotFound returns a "record not found error". Occurs only when attempting to query the database with a struct; querying with a slice won't return this error
+	// ErrRecordNotFound returns a "record not found error". Occurs only when attempting to query the database with a struct; querying with a slice won't return this error, it could be disabled with `DB.ErrorOnNotFound`
 	ErrRecordNotFound = errors.New("record not found")
 	// ErrInvalidSQL occurs when you attempt a query with invalid SQL
 	ErrInvalidSQL = errors.New("invalid SQL")
@@ -16,8 +18,105 @@ var (
 	ErrCantStartTransaction = errors.New("can't start transaction")
 	// ErrUnaddressable unaddressable value
 	ErrUnaddressable = errors.New("using unaddressable value")
+	// ErrMissingShardingKey occurs when the sharding key's value of a sharded table can't be found from the value or conditions
+	ErrMissingShardingKey = errors.New("sharding key is missing")
+	// ErrMissingTenant occurs when statements of models scoped with `RegisterTenancy` have no tenant in their context
+	ErrMissingTenant = errors.New("tenant is missing")
+	// ErrTenantMismatch occurs when creating records of other tenants or moving records to other tenants
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+	// ErrDuplicatedKey occurs when violating unique constraints
+	ErrDuplicatedKey = errors.New("duplicated key not allowed")
+	// ErrForeignKeyViolated occurs when violating foreign key constraints
+	ErrForeignKeyViolated = errors.New("violates foreign key constraint")
+	// ErrCheckConstraintViolated occurs when violating check constraints
+	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrDeadlockDetected occurs when the transaction is chosen as the victim of a deadlock
+	ErrDeadlockDetected = errors.New("deadlock detected")
+	// ErrSerializationFailure occurs when the transaction can't be serialized with concurrent transactions
+	ErrSerializationFailure = errors.New("could not serialize access")
 )
 
+// ErrorTranslator is implemented by dialects to translate driver errors to typed errors like ErrDuplicatedKey,
+// errors added to DB are translated with the dialect, translated errors should keep driver errors reachable with
+// `Unwrap` like DialectError
+type ErrorTranslator interface {
+	TranslateError(err error) error
+}
+
+// DialectError is a driver error translated to a typed error, its message is the driver error's message
+//     if errors.Is(db.Create(&user).Error, gorm.ErrDuplicatedKey) {
+//       // handle duplicated user
+//     }
+// Use IsError to check it with Go versions before 1.13.
+// Errors of drivers are wrapped, so type assertions like `err.(*pq.Error)` don't match translated errors, use
+// `errors.As`, which finds the driver error with `Unwrap`
+//     var pqErr *pq.Error
+//     if errors.As(db.Create(&user).Error, &pqErr) {
+//       // handle pqErr.Code
+//     }
+type DialectError struct {
+	// Kind is the typed error, e.g. ErrDuplicatedKey
+	Kind error
+	// Err is the driver error
+	Err error
+}
+
+func (err DialectError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap return the driver error
+func (err DialectError) Unwrap() error {
+	return err.Err
+}
+
+// Is report whether the error is the typed error
+func (err DialectError) Is(target error) bool {
+	return err.Kind == target
+}
+
+// IsError report whether err is target, or is a DialectError of target, or contains one of them if it is Errors
+func IsError(err, target error) bool {
+	switch e := err.(type) {
+	case Errors:
+		return e.Is(target)
+	case DialectError:
+		return e.Is(target) || e.Err == target
+	}
+	return err == target
+}
+
+func translateError(dialect Dialect, err error) error {
+	if _, ok := err.(DialectError); ok {
+		return err
+	}
+
+	if translator, ok := dialect.(ErrorTranslator); ok {
+		return translator.TranslateError(err)
+	}
+	return err
+}
+
+// driverErrorCode return the value of the error code field of driver errors, e.g. `Number` of mysql errors
+func driverErrorCode(err error, fieldName string) string {
+	reflectValue := reflect.ValueOf(err)
+	for reflectValue.Kind() == reflect.Ptr && !reflectValue.IsNil() {
+		reflectValue = reflectValue.Elem()
+	}
+
+	if reflectValue.Kind() == reflect.Struct {
+		if field := reflectValue.FieldByName(fieldName); field.IsValid() {
+			return fmt.Sprint(field.Interface())
+		}
+	}
+
+	// errors of drivers like pgx might be wrapped
+	if wrapper, ok := err.(interface{ Unwrap() error }); ok && wrapper.Unwrap() != nil {
+		return driverErrorCode(wrapper.Unwrap(), fieldName)
+	}
+	return ""
+}
+
 // Errors contains all happened errors
 type Errors []error
 
@@ -33,6 +132,21 @@ func IsRecordNotFoundError(err error) bool {
 	return err == ErrRecordNotFound
 }
 
+// Is report whether any of the errors is target, supports `errors.Is`
+func (errs Errors) Is(target error) bool {
+	for _, err := range errs {
+		if IsError(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap return the errors, so `errors.As` finds errors like driver errors of DialectError in any of them
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
 // GetErrors gets all errors that have occurred and returns a slice of errors (Error type)
 func (errs Errors) GetErrors() []error {
 	return errs
@@ -50,7 +164,8 @@ func (errs Errors) Add(newErrors ...error) Errors {
 		} else {
 			ok = true
 			for _, e := range errs {
-				if err == e {
+				// errors like ValidationErrors aren't comparable
+				if reflect.TypeOf(err).Comparable() && err == e {
 					ok = false
 				}
 			}
@@ -2,6 +2,7 @@ package gorm_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/zanmato/gorm"
@@ -18,3 +19,90 @@ func TestErrorsCanBeUsedOutsideGorm(t *testing.T) {
 		t.Fatalf("Gave wrong error, got %s", gErrs.Error())
 	}
 }
+
+type ErrorTranslatedUser struct {
+	ID    uint
+	Email string `gorm:"unique_index"`
+	Role  string `gorm:"type:enum('admin','member')"`
+}
+
+func TestTranslatedErrors(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&ErrorTranslatedUser{})
+	if err := db.AutoMigrate(&ErrorTranslatedUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	if err := db.Create(&ErrorTranslatedUser{Email: "dup@example.com", Role: "admin"}).Error; err != nil {
+		t.Fatalf("Failed to create user, got %v", err)
+	}
+
+	err := db.Create(&ErrorTranslatedUser{Email: "dup@example.com", Role: "admin"}).Error
+	if !gorm.IsError(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Should return ErrDuplicatedKey, but got %#v", err)
+	}
+
+	if dialectErr, ok := err.(gorm.DialectError); !ok || dialectErr.Unwrap() == nil || dialectErr.Error() != dialectErr.Err.Error() {
+		t.Errorf("Should wrap the driver error, but got %#v", err)
+	} else {
+		driverErr := reflect.New(reflect.TypeOf(dialectErr.Err))
+		if !errors.As(err, driverErr.Interface()) || !errors.As(gorm.Errors{errors.New("other"), err}, driverErr.Interface()) {
+			t.Errorf("Driver errors should be found with errors.As, but got %#v", err)
+		}
+	}
+
+	// enums are CHECK constraints with sqlite and mssql
+	if name := DB.Dialect().GetName(); name == "sqlite3" || name == "mssql" {
+		err = db.Create(&ErrorTranslatedUser{Email: "check@example.com", Role: "guest"}).Error
+		if !gorm.IsError(err, gorm.ErrCheckConstraintViolated) {
+			t.Errorf("Should return ErrCheckConstraintViolated, but got %#v", err)
+		}
+	}
+
+	errs := gorm.Errors{}.Add(errors.New("other"), gorm.DialectError{Kind: gorm.ErrForeignKeyViolated, Err: errors.New("fk")})
+	if !gorm.IsError(errs, gorm.ErrForeignKeyViolated) || gorm.IsError(errs, gorm.ErrDuplicatedKey) {
+		t.Errorf("Errors should contain ErrForeignKeyViolated only")
+	}
+}
+
+// PgError is like errors of pgx, which have SQLSTATE codes in field Code
+type PgError struct {
+	Code    string
+	Message string
+}
+
+func (err *PgError) Error() string {
+	return err.Message
+}
+
+type wrappedError struct {
+	err error
+}
+
+func (err wrappedError) Error() string {
+	return "query failed: " + err.err.Error()
+}
+
+func (err wrappedError) Unwrap() error {
+	return err.err
+}
+
+func TestPgxErrors(t *testing.T) {
+	dialect, ok := gorm.GetDialect("pgx")
+	if !ok || dialect.GetName() != "postgres" {
+		t.Fatalf("pgx should be registered as the postgres dialect")
+	}
+
+	translator := dialect.(gorm.ErrorTranslator)
+	if err := translator.TranslateError(&PgError{Code: "23505", Message: "duplicate key"}); !gorm.IsError(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Should translate pgx errors, got %#v", err)
+	}
+
+	if err := translator.TranslateError(wrappedError{&PgError{Code: "40001"}}); !gorm.IsError(err, gorm.ErrSerializationFailure) {
+		t.Errorf("Should translate wrapped pgx errors, got %#v", err)
+	}
+
+	if copier, ok := dialect.(gorm.Copier); !ok || copier.CopyFromSQL("users", []string{"name"}) != "" {
+		t.Errorf("pgx shouldn't copy with prepared statements")
+	}
+}
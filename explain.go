@@ -0,0 +1,101 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueryPlan is the plan of a query returned by `Explain` and `ExplainAnalyze`
+type QueryPlan struct {
+	// SQL is the explained statement, including the EXPLAIN keyword
+	SQL     string
+	Columns []string
+	// Rows are rows returned by the database, keyed by columns
+	Rows []map[string]interface{}
+}
+
+// String return the plan as text, one row per line with values separated by tabs
+func (plan *QueryPlan) String() string {
+	var lines []string
+	for _, row := range plan.Rows {
+		var values []string
+		for _, column := range plan.Columns {
+			values = append(values, fmt.Sprint(row[column]))
+		}
+		lines = append(lines, strings.Join(values, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Explain return the plan of the query finding out with current conditions, the query itself isn't executed,
+// the plan is got with `EXPLAIN` of postgres and mysql, `EXPLAIN QUERY PLAN` of sqlite
+//     plan, err := db.Where("name = ?", "jinzhu").Explain(&users)
+//     fmt.Println(plan)
+func (s *DB) Explain(out interface{}) (*QueryPlan, error) {
+	return s.explain(out, false)
+}
+
+// ExplainAnalyze return the plan of the query like `Explain` with actual costs, the query is executed by the
+// database to collect them, it is supported by postgres and mysql 8.0.18+
+func (s *DB) ExplainAnalyze(out interface{}) (*QueryPlan, error) {
+	return s.explain(out, true)
+}
+
+func (s *DB) explain(out interface{}, analyze bool) (*QueryPlan, error) {
+	var prefix string
+	switch name := s.Dialect().GetName(); name {
+	case "postgres", "cloudsqlpostgres", "cockroach", "mysql":
+		if prefix = "EXPLAIN "; analyze {
+			prefix = "EXPLAIN ANALYZE "
+		}
+	case "sqlite3":
+		if analyze {
+			return nil, fmt.Errorf("explain analyze isn't supported by %v", name)
+		}
+		prefix = "EXPLAIN QUERY PLAN "
+	default:
+		return nil, fmt.Errorf("explain isn't supported by %v", name)
+	}
+
+	tx := s.Session(&Session{DryRun: true}).Find(out)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	sql, vars := tx.DryRunSQL()
+	plan := &QueryPlan{SQL: s.NewScope(nil).commentSQL(prefix + sql)}
+
+	t := NowFunc()
+	rows, err := s.CommonDB().Query(plan.SQL, vars...)
+	s.slog(plan.SQL, t, vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if plan.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(plan.Columns))
+		for idx := range values {
+			values[idx] = new(interface{})
+		}
+
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+
+		row := map[string]interface{}{}
+		for idx, column := range plan.Columns {
+			value := *(values[idx].(*interface{}))
+			if bytes, ok := value.([]byte); ok {
+				value = string(bytes)
+			}
+			row[column] = value
+		}
+		plan.Rows = append(plan.Rows, row)
+	}
+	return plan, rows.Err()
+}
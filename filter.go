@@ -0,0 +1,227 @@
+package gorm
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a condition of a field of the model, usually parsed from query parameters of APIs with `ParseFilters`
+type Filter struct {
+	// Field is the name or column of the field
+	Field string
+	// Operator is one of eq, ne, gt, gte, lt, lte, like, ilike, in, nin and null, eq is used if it is blank
+	Operator string
+	// Value is the value compared with the field, strings are converted to the type of the field, it should be a
+	// slice for in and nin, and true or false for null
+	Value interface{}
+}
+
+var filterOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+var filterKeyRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)(\[([a-z]+)\])?$`)
+
+// ParseFilters parse filters from query parameters like `age[gte]=18&name[like]=jin%&role[in]=admin,owner`, the
+// operator is eq if it isn't given, values of in and nin are separated by commas.
+// Only parameters of fields are parsed if fields are given, so parameters like `page` could be kept in the query
+//     filters, err := gorm.ParseFilters(r.URL.Query(), "name", "age", "role")
+//     db.Filter(filters...).Find(&users)
+func ParseFilters(values url.Values, fields ...string) ([]Filter, error) {
+	var (
+		filters []Filter
+		keys    []string
+	)
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		submatch := filterKeyRegexp.FindStringSubmatch(key)
+		if len(submatch) == 0 {
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("invalid filter %v", key)
+			}
+			continue
+		}
+
+		if len(fields) > 0 && !filterFieldOf(fields, submatch[1]) {
+			continue
+		}
+
+		operator := submatch[3]
+		if operator == "" {
+			operator = "eq"
+		}
+
+		for _, value := range values[key] {
+			filter := Filter{Field: submatch[1], Operator: operator, Value: value}
+			if operator == "in" || operator == "nin" {
+				filter.Value = strings.Split(value, ",")
+			}
+
+			if err := filter.validOperator(); err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+func filterFieldOf(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter filter records with filters, fields of filters are validated against the model, ErrInvalidSQL is returned
+// when querying with unknown fields or operators
+//     db.Filter(gorm.Filter{Field: "age", Operator: "gte", Value: "18"}).Find(&users)
+//     // SELECT * FROM users WHERE (age >= 18)
+func (s *DB) Filter(filters ...Filter) *DB {
+	if len(filters) == 0 {
+		return s
+	}
+	return s.Where(filterExpression(filters))
+}
+
+type filterExpression []Filter
+
+func (filters filterExpression) conditionSQL(scope *Scope) string {
+	var sqls []string
+	for _, filter := range filters {
+		sql, err := filter.conditionSQL(scope)
+		if err != nil {
+			scope.Err(err)
+			return ""
+		}
+		sqls = append(sqls, sql)
+	}
+	return strings.Join(sqls, " AND ")
+}
+
+func (filter Filter) validOperator() error {
+	switch filter.Operator {
+	case "", "eq", "ne", "gt", "gte", "lt", "lte", "like", "ilike", "in", "nin", "null":
+		return nil
+	}
+	return fmt.Errorf("%v: unknown operator %v of filter %v", ErrInvalidSQL, filter.Operator, filter.Field)
+}
+
+func (filter Filter) conditionSQL(scope *Scope) (string, error) {
+	if err := filter.validOperator(); err != nil {
+		return "", err
+	}
+
+	var field *Field
+	if scope.Value != nil {
+		field, _ = scope.FieldByName(filter.Field)
+	}
+
+	if field == nil || !field.IsNormal || field.IsIgnored {
+		return "", fmt.Errorf("%v: unknown field %v of filter", ErrInvalidSQL, filter.Field)
+	}
+
+	column := fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName))
+	switch filter.Operator {
+	case "like":
+		return fmt.Sprintf("%v LIKE %v", column, scope.AddToVars(filter.Value)), nil
+	case "ilike":
+		return (&ILikeExpression{column: field.DBName, pattern: filter.Value}).conditionSQL(scope), nil
+	case "null":
+		isNull, err := strconv.ParseBool(fmt.Sprint(filter.Value))
+		if err != nil {
+			return "", fmt.Errorf("%v: value of null filter %v should be true or false", ErrInvalidSQL, filter.Field)
+		}
+
+		if isNull {
+			return fmt.Sprintf("%v IS NULL", column), nil
+		}
+		return fmt.Sprintf("%v IS NOT NULL", column), nil
+	case "in", "nin":
+		values := reflect.ValueOf(filter.Value)
+		if values.Kind() != reflect.Slice || values.Len() == 0 {
+			return "", fmt.Errorf("%v: value of %v filter %v should be a non-empty slice", ErrInvalidSQL, filter.Operator, filter.Field)
+		}
+
+		var marks []string
+		for i := 0; i < values.Len(); i++ {
+			value, err := filterValue(field, values.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			marks = append(marks, scope.AddToVars(value))
+		}
+
+		if filter.Operator == "nin" {
+			return fmt.Sprintf("%v NOT IN (%v)", column, strings.Join(marks, ",")), nil
+		}
+		return fmt.Sprintf("%v IN (%v)", column, strings.Join(marks, ",")), nil
+	}
+
+	operator, ok := filterOperators[filter.Operator]
+	if !ok {
+		operator = "="
+	}
+
+	value, err := filterValue(field, filter.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v %v %v", column, operator, scope.AddToVars(value)), nil
+}
+
+// filterValue convert strings to the type of the field, other values are returned as they are
+func filterValue(field *Field, value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	fieldType := field.Struct.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	var (
+		result interface{} = str
+		err    error
+	)
+
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result, err = strconv.ParseInt(str, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result, err = strconv.ParseUint(str, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		result, err = strconv.ParseFloat(str, 64)
+	case reflect.Bool:
+		result, err = strconv.ParseBool(str)
+	case reflect.Struct:
+		if fieldType == reflect.TypeOf(time.Time{}) {
+			result, err = time.Parse(time.RFC3339, str)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%v: invalid value %q of filter %v", ErrInvalidSQL, str, field.Name)
+	}
+	return result, nil
+}
@@ -0,0 +1,282 @@
+// Package field contains typed columns used by query code generated with gen, conditions built with them are
+// checked by the compiler instead of failing at runtime
+//     query.User.Where(query.User.Age.Gt(18), query.User.Name.Like("j%")).Find()
+package field
+
+import (
+	"strings"
+	"time"
+
+	"github.com/zanmato/gorm"
+)
+
+// Expr is a condition built from columns
+type Expr struct {
+	build func(quote func(string) string) (string, []interface{})
+}
+
+// Build build SQL and vars of the condition for the db's dialect
+func (expr Expr) Build(db *gorm.DB) (string, []interface{}) {
+	return expr.build(db.Dialect().Quote)
+}
+
+// Or combine conditions with OR
+func Or(exprs ...Expr) Expr {
+	return combine(" OR ", exprs)
+}
+
+// And combine conditions with AND
+func And(exprs ...Expr) Expr {
+	return combine(" AND ", exprs)
+}
+
+// Not negate the condition
+func Not(expr Expr) Expr {
+	return Expr{build: func(quote func(string) string) (string, []interface{}) {
+		sql, vars := expr.build(quote)
+		return "NOT (" + sql + ")", vars
+	}}
+}
+
+func combine(separator string, exprs []Expr) Expr {
+	return Expr{build: func(quote func(string) string) (string, []interface{}) {
+		var (
+			sqls []string
+			vars []interface{}
+		)
+		for _, expr := range exprs {
+			sql, exprVars := expr.build(quote)
+			sqls = append(sqls, "("+sql+")")
+			vars = append(vars, exprVars...)
+		}
+		return strings.Join(sqls, separator), vars
+	}}
+}
+
+// Order is an order by clause built from columns
+type Order struct {
+	column Column
+	desc   bool
+}
+
+// Build build the order by clause for the db's dialect
+func (order Order) Build(db *gorm.DB) string {
+	if order.desc {
+		return order.column.quoted(db.Dialect().Quote) + " DESC"
+	}
+	return order.column.quoted(db.Dialect().Quote)
+}
+
+// Column is a column of a table, typed columns embed it
+type Column struct {
+	Table string
+	Name  string
+}
+
+// NewColumn create column of the table
+func NewColumn(table, name string) Column {
+	return Column{Table: table, Name: name}
+}
+
+func (column Column) quoted(quote func(string) string) string {
+	if column.Table == "" {
+		return quote(column.Name)
+	}
+	return quote(column.Table) + "." + quote(column.Name)
+}
+
+func (column Column) expr(operator string, values ...interface{}) Expr {
+	return Expr{build: func(quote func(string) string) (string, []interface{}) {
+		return column.quoted(quote) + " " + operator, values
+	}}
+}
+
+// IsNull column IS NULL
+func (column Column) IsNull() Expr {
+	return column.expr("IS NULL")
+}
+
+// IsNotNull column IS NOT NULL
+func (column Column) IsNotNull() Expr {
+	return column.expr("IS NOT NULL")
+}
+
+// Asc order by the column ascending
+func (column Column) Asc() Order {
+	return Order{column: column}
+}
+
+// Desc order by the column descending
+func (column Column) Desc() Order {
+	return Order{column: column, desc: true}
+}
+
+// ColumnName return the column's name
+func (column Column) ColumnName() string {
+	return column.Name
+}
+
+// Field is a column of types without a typed column, e.g. sql.NullString
+type Field struct{ Column }
+
+// Eq column = value
+func (field Field) Eq(value interface{}) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Field) Neq(value interface{}) Expr { return field.expr("<> ?", value) }
+
+// In column IN values
+func (field Field) In(values ...interface{}) Expr { return field.expr("IN (?)", values) }
+
+// Int is a column of int
+type Int struct{ Column }
+
+// Eq column = value
+func (field Int) Eq(value int) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Int) Neq(value int) Expr { return field.expr("<> ?", value) }
+
+// Gt column > value
+func (field Int) Gt(value int) Expr { return field.expr("> ?", value) }
+
+// Gte column >= value
+func (field Int) Gte(value int) Expr { return field.expr(">= ?", value) }
+
+// Lt column < value
+func (field Int) Lt(value int) Expr { return field.expr("< ?", value) }
+
+// Lte column <= value
+func (field Int) Lte(value int) Expr { return field.expr("<= ?", value) }
+
+// Between column BETWEEN min AND max
+func (field Int) Between(min, max int) Expr { return field.expr("BETWEEN ? AND ?", min, max) }
+
+// In column IN values
+func (field Int) In(values ...int) Expr { return field.expr("IN (?)", values) }
+
+// Int64 is a column of int64
+type Int64 struct{ Column }
+
+// Eq column = value
+func (field Int64) Eq(value int64) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Int64) Neq(value int64) Expr { return field.expr("<> ?", value) }
+
+// Gt column > value
+func (field Int64) Gt(value int64) Expr { return field.expr("> ?", value) }
+
+// Gte column >= value
+func (field Int64) Gte(value int64) Expr { return field.expr(">= ?", value) }
+
+// Lt column < value
+func (field Int64) Lt(value int64) Expr { return field.expr("< ?", value) }
+
+// Lte column <= value
+func (field Int64) Lte(value int64) Expr { return field.expr("<= ?", value) }
+
+// Between column BETWEEN min AND max
+func (field Int64) Between(min, max int64) Expr { return field.expr("BETWEEN ? AND ?", min, max) }
+
+// In column IN values
+func (field Int64) In(values ...int64) Expr { return field.expr("IN (?)", values) }
+
+// Uint is a column of uint
+type Uint struct{ Column }
+
+// Eq column = value
+func (field Uint) Eq(value uint) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Uint) Neq(value uint) Expr { return field.expr("<> ?", value) }
+
+// Gt column > value
+func (field Uint) Gt(value uint) Expr { return field.expr("> ?", value) }
+
+// Gte column >= value
+func (field Uint) Gte(value uint) Expr { return field.expr(">= ?", value) }
+
+// Lt column < value
+func (field Uint) Lt(value uint) Expr { return field.expr("< ?", value) }
+
+// Lte column <= value
+func (field Uint) Lte(value uint) Expr { return field.expr("<= ?", value) }
+
+// Between column BETWEEN min AND max
+func (field Uint) Between(min, max uint) Expr { return field.expr("BETWEEN ? AND ?", min, max) }
+
+// In column IN values
+func (field Uint) In(values ...uint) Expr { return field.expr("IN (?)", values) }
+
+// Float64 is a column of float64
+type Float64 struct{ Column }
+
+// Eq column = value
+func (field Float64) Eq(value float64) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Float64) Neq(value float64) Expr { return field.expr("<> ?", value) }
+
+// Gt column > value
+func (field Float64) Gt(value float64) Expr { return field.expr("> ?", value) }
+
+// Gte column >= value
+func (field Float64) Gte(value float64) Expr { return field.expr(">= ?", value) }
+
+// Lt column < value
+func (field Float64) Lt(value float64) Expr { return field.expr("< ?", value) }
+
+// Lte column <= value
+func (field Float64) Lte(value float64) Expr { return field.expr("<= ?", value) }
+
+// Between column BETWEEN min AND max
+func (field Float64) Between(min, max float64) Expr { return field.expr("BETWEEN ? AND ?", min, max) }
+
+// String is a column of string
+type String struct{ Column }
+
+// Eq column = value
+func (field String) Eq(value string) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field String) Neq(value string) Expr { return field.expr("<> ?", value) }
+
+// Like column LIKE pattern
+func (field String) Like(pattern string) Expr { return field.expr("LIKE ?", pattern) }
+
+// NotLike column NOT LIKE pattern
+func (field String) NotLike(pattern string) Expr { return field.expr("NOT LIKE ?", pattern) }
+
+// In column IN values
+func (field String) In(values ...string) Expr { return field.expr("IN (?)", values) }
+
+// Bool is a column of bool
+type Bool struct{ Column }
+
+// Is column = value
+func (field Bool) Is(value bool) Expr { return field.expr("= ?", value) }
+
+// Time is a column of time.Time
+type Time struct{ Column }
+
+// Eq column = value
+func (field Time) Eq(value time.Time) Expr { return field.expr("= ?", value) }
+
+// Neq column <> value
+func (field Time) Neq(value time.Time) Expr { return field.expr("<> ?", value) }
+
+// Gt column > value
+func (field Time) Gt(value time.Time) Expr { return field.expr("> ?", value) }
+
+// Gte column >= value
+func (field Time) Gte(value time.Time) Expr { return field.expr(">= ?", value) }
+
+// Lt column < value
+func (field Time) Lt(value time.Time) Expr { return field.expr("< ?", value) }
+
+// Lte column <= value
+func (field Time) Lte(value time.Time) Expr { return field.expr("<= ?", value) }
+
+// Between column BETWEEN min AND max
+func (field Time) Between(min, max time.Time) Expr { return field.expr("BETWEEN ? AND ?", min, max) }
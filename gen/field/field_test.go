@@ -0,0 +1,50 @@
+package field_test
+
+import (
+	"testing"
+
+	"github.com/zanmato/gorm"
+	"github.com/zanmato/gorm/dialects/sqlite"
+	"github.com/zanmato/gorm/gen/field"
+)
+
+type User struct {
+	ID   uint
+	Name string
+	Age  int
+}
+
+func TestExpr(t *testing.T) {
+	db, err := gorm.Open("sqlite3", sqlite.DriverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.AutoMigrate(&User{})
+	for _, user := range []User{{Name: "jinzhu", Age: 20}, {Name: "jane", Age: 15}, {Name: "bob", Age: 30}} {
+		db.Create(&user)
+	}
+
+	var (
+		name = field.String{Column: field.NewColumn("users", "name")}
+		age  = field.Int{Column: field.NewColumn("", "age")}
+	)
+
+	sql, vars := field.And(age.Gt(18), name.Like("j%")).Build(db)
+	if sql != `("age" > ?) AND ("users"."name" LIKE ?)` || len(vars) != 2 {
+		t.Errorf("Expr should be built with quoted columns, got %v %v", sql, vars)
+	}
+
+	var users []User
+	sql, vars = field.Or(age.Lt(18), name.In("bob")).Build(db)
+	db.Where(sql, vars...).Order(age.Desc().Build(db)).Find(&users)
+	if len(users) != 2 || users[0].Name != "bob" || users[1].Name != "jane" {
+		t.Errorf("Should find users with expr, got %#v", users)
+	}
+
+	sql, vars = field.Not(age.Between(10, 25)).Build(db)
+	if db.Where(sql, vars...).Find(&users); len(users) != 1 || users[0].Name != "bob" {
+		t.Errorf("Should find users not matching expr, got %#v", users)
+	}
+}
@@ -0,0 +1,292 @@
+// Package gen generates gorm models from existing databases
+//     g := gen.New(db)
+//     source, err := g.Generate("users", "orders")
+//     ioutil.WriteFile("models/models.go", source, 0644)
+// The dialect needs to implement gorm.SchemaInspector, which is implemented by sqlite3, mysql and postgres
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"github.com/zanmato/gorm"
+)
+
+// ErrInspectorUnsupported is returned if the dialect can't inspect schemas of existing tables
+var ErrInspectorUnsupported = errors.New("dialect doesn't support inspecting schemas")
+
+// Generator generate models from tables of the database
+type Generator struct {
+	// Package is the package name of generated code, defaults to `models`
+	Package string
+	// TypeMapper return the Go type of columns, returned imports will be added to generated code,
+	// defaults to DefaultTypeMapper
+	TypeMapper func(column gorm.ColumnInfo) (goType string, imports []string)
+
+	db        *gorm.DB
+	inspector gorm.SchemaInspector
+}
+
+// Model is a model generated from a table
+type Model struct {
+	Name      string
+	TableName string
+	Fields    []*Field
+	Imports   []string
+}
+
+// Field is a field of generated model
+type Field struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// New create a generator with the db
+func New(db *gorm.DB) *Generator {
+	inspector, _ := db.Dialect().(gorm.SchemaInspector)
+	return &Generator{Package: "models", TypeMapper: DefaultTypeMapper, db: db, inspector: inspector}
+}
+
+// Models inspect tables and return models generated from them, all tables are inspected if no table is given.
+// Associations are inferred from foreign keys referencing inspected tables, a foreign key `orders.user_id`
+// referencing `users` adds belongs to field `User` to `Order` and has many field `Orders` to `User`
+func (g *Generator) Models(tables ...string) ([]*Model, error) {
+	if g.inspector == nil {
+		return nil, ErrInspectorUnsupported
+	}
+
+	if len(tables) == 0 {
+		var err error
+		if tables, err = g.inspector.TableNames(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		models      []*Model
+		modelsMap   = map[string]*Model{}
+		foreignKeys = map[string][]gorm.ForeignKeyInfo{}
+	)
+
+	for _, table := range tables {
+		columns, err := g.inspector.ColumnInfos(table)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("table %v doesn't exist", table)
+		}
+
+		model := &Model{Name: modelName(table), TableName: table}
+		for _, column := range columns {
+			goType, imports := g.TypeMapper(column)
+			model.Imports = appendImports(model.Imports, imports...)
+
+			tags := []string{"column:" + column.Name}
+			if column.PrimaryKey {
+				tags = append(tags, "primary_key")
+			} else if !column.Nullable {
+				tags = append(tags, "not null")
+			}
+
+			model.Fields = append(model.Fields, &Field{
+				Name: fieldName(column.Name),
+				Type: goType,
+				Tag:  fmt.Sprintf(`gorm:"%v"`, strings.Join(tags, ";")),
+			})
+		}
+
+		if foreignKeys[table], err = g.inspector.ForeignKeyInfos(table); err != nil {
+			return nil, err
+		}
+
+		models = append(models, model)
+		modelsMap[table] = model
+	}
+
+	for _, model := range models {
+		for _, foreignKey := range foreignKeys[model.TableName] {
+			referenced, ok := modelsMap[foreignKey.ReferencedTable]
+			if !ok {
+				continue
+			}
+
+			foreignKeyField := fieldName(foreignKey.Column)
+			associationForeignKeyField := fieldName(foreignKey.ReferencedColumn)
+
+			belongsToName := strings.TrimSuffix(foreignKeyField, "ID")
+			if belongsToName == "" || belongsToName == foreignKeyField || model.hasField(belongsToName) {
+				belongsToName = referenced.Name
+			}
+			if !model.hasField(belongsToName) {
+				model.Fields = append(model.Fields, &Field{
+					Name: belongsToName,
+					Type: "*" + referenced.Name,
+					Tag:  fmt.Sprintf(`gorm:"foreignkey:%v;association_foreignkey:%v"`, foreignKeyField, associationForeignKeyField),
+				})
+			}
+
+			hasManyName := inflection.Plural(model.Name)
+			if referenced.hasField(hasManyName) {
+				hasManyName += "By" + foreignKeyField
+			}
+			if !referenced.hasField(hasManyName) {
+				referenced.Fields = append(referenced.Fields, &Field{
+					Name: hasManyName,
+					Type: "[]" + model.Name,
+					Tag:  fmt.Sprintf(`gorm:"foreignkey:%v;association_foreignkey:%v"`, foreignKeyField, associationForeignKeyField),
+				})
+			}
+		}
+	}
+	return models, nil
+}
+
+// Generate generate go source of models of tables, all tables are generated if no table is given
+func (g *Generator) Generate(tables ...string) ([]byte, error) {
+	models, err := g.Models(tables...)
+	if err != nil {
+		return nil, err
+	}
+
+	var imports []string
+	for _, model := range models {
+		imports = appendImports(imports, model.Imports...)
+	}
+	sort.Strings(imports)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gorm gen. DO NOT EDIT.\n\npackage %v\n", g.Package)
+	if len(imports) > 0 {
+		buf.WriteString("\nimport (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n")
+	}
+
+	for _, model := range models {
+		fmt.Fprintf(&buf, "\n// %v is generated from table %v\ntype %v struct {\n", model.Name, model.TableName, model.Name)
+		for _, field := range model.Fields {
+			fmt.Fprintf(&buf, "\t%v %v `%v`\n", field.Name, field.Type, field.Tag)
+		}
+		buf.WriteString("}\n")
+		fmt.Fprintf(&buf, "\n// TableName return the table name of %v\nfunc (%v) TableName() string {\n\treturn %q\n}\n", model.Name, model.Name, model.TableName)
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// DefaultTypeMapper map columns to Go types by their data types, nullable columns are mapped to sql.Null types,
+// or pointers if no sql.Null type for it. Types are matched by their names without sizes, like `varchar` of
+// `varchar(255)`, types without Go types, like `point` or `interval`, are mapped to string
+func DefaultTypeMapper(column gorm.ColumnInfo) (string, []string) {
+	var (
+		dataType = strings.ToLower(strings.TrimSpace(column.DataType))
+		typeName = dataType
+		goType   string
+	)
+
+	if idx := strings.IndexAny(typeName, "( "); idx >= 0 {
+		typeName = typeName[:idx]
+	}
+
+	switch typeName {
+	case "bool", "boolean", "bit":
+		goType = "bool"
+	case "tinyint":
+		// mysql booleans are tinyint(1)
+		if strings.HasPrefix(dataType, "tinyint(1)") {
+			goType = "bool"
+		} else {
+			goType = "int64"
+		}
+	case "int", "integer", "smallint", "mediumint", "bigint", "int2", "int4", "int8",
+		"serial", "smallserial", "bigserial", "serial2", "serial4", "serial8":
+		goType = "int64"
+	case "real", "float", "float4", "float8", "double", "numeric", "decimal":
+		goType = "float64"
+	case "date", "datetime", "timestamp", "timestamptz", "time", "timetz":
+		goType = "time.Time"
+	case "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "bytea":
+		return "[]byte", nil
+	default:
+		goType = "string"
+	}
+
+	if column.Nullable {
+		switch goType {
+		case "bool":
+			return "sql.NullBool", []string{"database/sql"}
+		case "int64":
+			return "sql.NullInt64", []string{"database/sql"}
+		case "float64":
+			return "sql.NullFloat64", []string{"database/sql"}
+		case "string":
+			return "sql.NullString", []string{"database/sql"}
+		case "time.Time":
+			return "*time.Time", []string{"time"}
+		}
+	}
+
+	if goType == "time.Time" {
+		return goType, []string{"time"}
+	}
+	return goType, nil
+}
+
+func (model *Model) hasField(name string) bool {
+	for _, field := range model.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func appendImports(imports []string, paths ...string) []string {
+	for _, path := range paths {
+		exists := false
+		for _, existing := range imports {
+			exists = exists || existing == path
+		}
+		if !exists {
+			imports = append(imports, path)
+		}
+	}
+	return imports
+}
+
+var commonInitialisms = map[string]bool{"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "URL": true, "UUID": true}
+
+// fieldName convert column name to field name, e.g. `user_id` to `UserID`
+func fieldName(column string) string {
+	var name string
+	for _, word := range strings.FieldsFunc(column, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			name += upper
+		} else {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F" + name
+	}
+	return name
+}
+
+// modelName convert table name to model name, e.g. `order_items` to `OrderItem`
+func modelName(table string) string {
+	if index := strings.LastIndex(table, "."); index >= 0 {
+		table = table[index+1:]
+	}
+	return fieldName(inflection.Singular(table))
+}
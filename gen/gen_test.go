@@ -0,0 +1,141 @@
+package gen_test
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanmato/gorm"
+	"github.com/zanmato/gorm/dialects/sqlite"
+	"github.com/zanmato/gorm/gen"
+)
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorm_gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := gorm.Open("sqlite3", sqlite.DriverName, filepath.Join(dir, "gen.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, sql := range []string{
+		"CREATE TABLE users (id integer primary key autoincrement, name varchar(255) NOT NULL, age integer, avatar_url text, created_at datetime)",
+		"CREATE TABLE orders (id integer primary key autoincrement, user_id integer NOT NULL REFERENCES users(id), amount decimal(10,2) NOT NULL, paid boolean)",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	models, err := gen.New(db).Models()
+	if err != nil {
+		t.Fatalf("Failed to inspect models, got %v", err)
+	}
+
+	if len(models) != 2 || models[0].Name != "Order" || models[1].Name != "User" {
+		t.Fatalf("Should generate models of all tables, but got %#v", models)
+	}
+
+	source, err := gen.New(db).Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate models, got %v", err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", source, 0); err != nil {
+		t.Fatalf("Generated source should be valid go code, got %v\n%s", err, source)
+	}
+
+	// compare without alignment of gofmt
+	generated := strings.Join(strings.Fields(string(source)), " ")
+	for _, expected := range []string{
+		"package models",
+		`"database/sql"`,
+		"ID int64 `gorm:\"column:id;primary_key\"`",
+		"Name string `gorm:\"column:name;not null\"`",
+		"Age sql.NullInt64 `gorm:\"column:age\"`",
+		"AvatarURL sql.NullString `gorm:\"column:avatar_url\"`",
+		"CreatedAt *time.Time",
+		"Amount float64",
+		"Paid sql.NullBool",
+		"User *User `gorm:\"foreignkey:UserID;association_foreignkey:ID\"`",
+		"Orders []Order `gorm:\"foreignkey:UserID;association_foreignkey:ID\"`",
+		"func (User) TableName() string",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Generated source should contain %v, but got\n%s", expected, source)
+		}
+	}
+}
+
+func TestDefaultTypeMapper(t *testing.T) {
+	for dataType, expected := range map[string]string{
+		"tinyint(1)":               "bool",
+		"tinyint(4)":               "int64",
+		"int(10) unsigned":         "int64",
+		"bigint":                   "int64",
+		"double precision":         "float64",
+		"decimal(10,2)":            "float64",
+		"timestamp with time zone": "time.Time",
+		"varbinary(16)":            "[]byte",
+		"point":                    "string",
+		"interval":                 "string",
+		"character varying":        "string",
+	} {
+		if goType, _ := gen.DefaultTypeMapper(gorm.ColumnInfo{DataType: dataType}); goType != expected {
+			t.Errorf("%v should be mapped to %v, but got %v", dataType, expected, goType)
+		}
+	}
+}
+
+type QueryUser struct {
+	gorm.Model
+	Name     string
+	Age      int
+	Birthday *time.Time
+	Where    string
+}
+
+func TestGenerateQuery(t *testing.T) {
+	db, err := gorm.Open("sqlite3", sqlite.DriverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	source, err := gen.New(db).GenerateQuery(&QueryUser{})
+	if err != nil {
+		t.Fatalf("Failed to generate queries, got %v", err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "query.go", source, 0); err != nil {
+		t.Fatalf("Generated source should be valid go code, got %v\n%s", err, source)
+	}
+
+	generated := strings.Join(strings.Fields(string(source)), " ")
+	for _, expected := range []string{
+		"package query",
+		`gen_test "github.com/zanmato/gorm/gen_test"`,
+		"var QueryUser = newQueryUserQuery(nil)",
+		"ID field.Uint",
+		"DeletedAt field.Time",
+		"Age field.Int",
+		"Birthday field.Time",
+		"WhereField field.String",
+		`Name: field.String{Column: field.NewColumn("", "name")}`,
+		"func (q queryUserQuery) Find() ([]*gen_test.QueryUser, error)",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Generated source should contain %v, but got\n%s", expected, source)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueryPackage is the default package name of generated query code
+const QueryPackage = "query"
+
+// queryMethods are methods of generated queries, fields named with them are suffixed with `Field`
+var queryMethods = map[string]bool{
+	"WithDB": true, "Debug": true, "Where": true, "Not": true, "Or": true, "Order": true, "Limit": true, "Offset": true,
+	"Find": true, "First": true, "Last": true, "Take": true, "Count": true, "Create": true, "Save": true, "Updates": true, "Delete": true,
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GenerateQuery generate go source of typed queries of models, conditions of them are checked by the compiler
+//     source, err := gen.New(db).GenerateQuery(&models.User{}, &models.Order{})
+//     ioutil.WriteFile("query/query.go", source, 0644)
+//
+//     query.SetDefault(db)
+//     users, err := query.User.Where(query.User.Age.Gt(18)).Order(query.User.Name.Asc()).Find()
+// Generated code uses package `query` unless the generator's Package is changed from the default
+func (g *Generator) GenerateQuery(models ...interface{}) ([]byte, error) {
+	if len(models) == 0 {
+		return nil, errors.New("no model to generate queries")
+	}
+
+	packageName := g.Package
+	if packageName == "" || packageName == "models" {
+		packageName = QueryPackage
+	}
+
+	var (
+		buf     bytes.Buffer
+		body    bytes.Buffer
+		imports = map[string]string{}
+		names   []string
+	)
+
+	for _, model := range models {
+		modelType := reflect.TypeOf(model)
+		for modelType.Kind() == reflect.Ptr || modelType.Kind() == reflect.Slice {
+			modelType = modelType.Elem()
+		}
+
+		if modelType.Kind() != reflect.Struct || modelType.PkgPath() == "" {
+			return nil, fmt.Errorf("%v is not a named struct", modelType)
+		}
+
+		// String is `<package name>.<type name>`
+		qualifier := strings.SplitN(modelType.String(), ".", 2)[0]
+		if path, ok := imports[qualifier]; ok && path != modelType.PkgPath() {
+			return nil, fmt.Errorf("models of packages %v and %v have the same package name", path, modelType.PkgPath())
+		}
+		imports[qualifier] = modelType.PkgPath()
+
+		name := modelType.Name()
+		queryType := strings.ToLower(name[:1]) + name[1:] + "Query"
+		modelName := modelType.String()
+		names = append(names, name)
+
+		fmt.Fprintf(&body, "\n// %v is the query of %v\nvar %v = new%vQuery(nil)\n", name, modelName, name, name)
+		fmt.Fprintf(&body, "\ntype %v struct {\n\tdb *gorm.DB\n\n", queryType)
+
+		var columns bytes.Buffer
+		for _, field := range g.db.NewScope(reflect.New(modelType).Interface()).GetModelStruct().StructFields {
+			if field.IsIgnored || !field.IsNormal || field.DBName == "" {
+				continue
+			}
+
+			fieldName := field.Name
+			if queryMethods[fieldName] {
+				fieldName += "Field"
+			}
+			columnType := fieldColumnType(field.Struct.Type)
+			fmt.Fprintf(&body, "\t%v field.%v\n", fieldName, columnType)
+			fmt.Fprintf(&columns, "\t\t%v: field.%v{Column: field.NewColumn(\"\", %q)},\n", fieldName, columnType, field.DBName)
+		}
+		body.WriteString("}\n")
+
+		fmt.Fprintf(&body, "\nfunc new%vQuery(db *gorm.DB) %v {\n\treturn %v{\n\t\tdb: db,\n%v\t}\n}\n", name, queryType, queryType, columns.String())
+		writeQueryMethods(&body, queryType, modelName)
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by gorm gen. DO NOT EDIT.\n\npackage %v\n\nimport (\n", packageName)
+	buf.WriteString("\t\"github.com/zanmato/gorm\"\n\t\"github.com/zanmato/gorm/gen/field\"\n")
+	var qualifiers []string
+	for qualifier := range imports {
+		qualifiers = append(qualifiers, qualifier)
+	}
+	sort.Strings(qualifiers)
+	for _, qualifier := range qualifiers {
+		fmt.Fprintf(&buf, "\t%v %q\n", qualifier, imports[qualifier])
+	}
+	buf.WriteString(")\n")
+
+	buf.WriteString("\n// SetDefault set the db used by queries of all models\nfunc SetDefault(db *gorm.DB) {\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%v = new%vQuery(db)\n", name, name)
+	}
+	buf.WriteString("}\n")
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// fieldColumnType return the column type of gen/field for the field's type
+func fieldColumnType(fieldType reflect.Type) string {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType == timeType {
+		return "Time"
+	}
+
+	// named types like `type Status string` could not be passed to typed columns
+	if fieldType.PkgPath() != "" {
+		return "Field"
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Int:
+		return "Int"
+	case reflect.Int64:
+		return "Int64"
+	case reflect.Uint:
+		return "Uint"
+	case reflect.Float64:
+		return "Float64"
+	case reflect.String:
+		return "String"
+	case reflect.Bool:
+		return "Bool"
+	}
+	return "Field"
+}
+
+func writeQueryMethods(buf *bytes.Buffer, queryType, modelName string) {
+	fmt.Fprintf(buf, `
+// WithDB return the query using db
+func (q %[1]v) WithDB(db *gorm.DB) %[1]v {
+	q.db = db
+	return q
+}
+
+// Debug log SQL of the query
+func (q %[1]v) Debug() %[1]v {
+	q.db = q.db.Debug()
+	return q
+}
+
+// Where add conditions to the query
+func (q %[1]v) Where(conds ...field.Expr) %[1]v {
+	for _, cond := range conds {
+		sql, vars := cond.Build(q.db)
+		q.db = q.db.Where(sql, vars...)
+	}
+	return q
+}
+
+// Not add negated conditions to the query
+func (q %[1]v) Not(conds ...field.Expr) %[1]v {
+	for _, cond := range conds {
+		sql, vars := field.Not(cond).Build(q.db)
+		q.db = q.db.Where(sql, vars...)
+	}
+	return q
+}
+
+// Or add conditions to the query with OR
+func (q %[1]v) Or(conds ...field.Expr) %[1]v {
+	for _, cond := range conds {
+		sql, vars := cond.Build(q.db)
+		q.db = q.db.Or(sql, vars...)
+	}
+	return q
+}
+
+// Order order records of the query
+func (q %[1]v) Order(orders ...field.Order) %[1]v {
+	for _, order := range orders {
+		q.db = q.db.Order(order.Build(q.db))
+	}
+	return q
+}
+
+// Limit limit the number of records
+func (q %[1]v) Limit(limit int) %[1]v {
+	q.db = q.db.Limit(limit)
+	return q
+}
+
+// Offset skip offset records
+func (q %[1]v) Offset(offset int) %[1]v {
+	q.db = q.db.Offset(offset)
+	return q
+}
+
+// Find find records matching the query
+func (q %[1]v) Find() ([]*%[2]v, error) {
+	var results []*%[2]v
+	err := q.db.Find(&results).Error
+	return results, err
+}
+
+// First find the first record ordered by primary key
+func (q %[1]v) First() (*%[2]v, error) {
+	var result %[2]v
+	if err := q.db.First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Last find the last record ordered by primary key
+func (q %[1]v) Last() (*%[2]v, error) {
+	var result %[2]v
+	if err := q.db.Last(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Take find a record without order
+func (q %[1]v) Take() (*%[2]v, error) {
+	var result %[2]v
+	if err := q.db.Take(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Count count records matching the query
+func (q %[1]v) Count() (int64, error) {
+	var count int64
+	err := q.db.Model(&%[2]v{}).Count(&count).Error
+	return count, err
+}
+
+// Create insert records
+func (q %[1]v) Create(values ...*%[2]v) error {
+	for _, value := range values {
+		if err := q.db.Create(value).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save update records, or insert them if they have no primary key
+func (q %[1]v) Save(values ...*%[2]v) error {
+	for _, value := range values {
+		if err := q.db.Save(value).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Updates update attributes of records matching the query with non blank fields of value
+func (q %[1]v) Updates(value %[2]v) (int64, error) {
+	result := q.db.Model(&%[2]v{}).Updates(value)
+	return result.RowsAffected, result.Error
+}
+
+// Delete delete records matching the query
+func (q %[1]v) Delete() (int64, error) {
+	result := q.db.Delete(&%[2]v{})
+	return result.RowsAffected, result.Error
+}
+`, queryType, modelName)
+}
@@ -0,0 +1,49 @@
+package gorm
+
+// Hook is a point in lifecycles of records where global hooks are called, refer `DB.RegisterGlobalHook`
+type Hook string
+
+// Hook points of global hooks, they are called right after model hooks of the same names
+const (
+	BeforeSave   Hook = "BeforeSave"
+	AfterSave    Hook = "AfterSave"
+	BeforeCreate Hook = "BeforeCreate"
+	AfterCreate  Hook = "AfterCreate"
+	BeforeUpdate Hook = "BeforeUpdate"
+	AfterUpdate  Hook = "AfterUpdate"
+	BeforeDelete Hook = "BeforeDelete"
+	AfterDelete  Hook = "AfterDelete"
+	BeforeFind   Hook = "BeforeFind"
+	AfterFind    Hook = "AfterFind"
+)
+
+// RegisterGlobalHook register a hook called for records of every model at the hook point, so cross-cutting concerns
+// like cache invalidation and audit don't need hooks on each model
+//     db.RegisterGlobalHook(gorm.AfterCreate, func(scope *gorm.Scope) {
+//       cache.Invalidate(scope.TableName())
+//     })
+// errors set with `scope.Err` abort operations like errors of model hooks, global hooks aren't model hooks, so they
+// are called even if hooks are skipped with `SkipHooks`
+func (s *DB) RegisterGlobalHook(hook Hook, fn func(scope *Scope)) {
+	callbacks := s.Callback()
+	hooks := make(map[Hook][]func(scope *Scope), len(callbacks.hooks)+1)
+	for point, fns := range callbacks.hooks {
+		hooks[point] = fns[:len(fns):len(fns)]
+	}
+	hooks[hook] = append(hooks[hook], fn)
+	callbacks.hooks = hooks
+}
+
+// callGlobalHooks call global hooks registered at the hook point until one of them sets an error
+func (scope *Scope) callGlobalHooks(hook Hook) {
+	if scope.db == nil || scope.db.parent == nil || scope.db.parent.callbacks == nil {
+		return
+	}
+
+	for _, fn := range scope.db.parent.callbacks.hooks[hook] {
+		if scope.HasError() {
+			return
+		}
+		fn(scope)
+	}
+}
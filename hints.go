@@ -0,0 +1,123 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+type hintKind int
+
+const (
+	optimizerHint hintKind = iota
+	indexHint
+	tableHint
+)
+
+// Hint is a hint of queries, created with UseIndex, ForceIndex, IgnoreIndex, TableHint or OptimizerHint, refer `Hints`
+type Hint struct {
+	kind    hintKind
+	keyword string
+	values  []string
+}
+
+// UseIndex hint the query to use one of the indexes, it is `USE INDEX` of mysql and `INDEXED BY` of sqlite
+func UseIndex(names ...string) Hint {
+	return Hint{kind: indexHint, keyword: "USE INDEX", values: names}
+}
+
+// ForceIndex hint the query to use one of the indexes and avoid table scans, it is `FORCE INDEX` of mysql and
+// `INDEXED BY` of sqlite
+func ForceIndex(names ...string) Hint {
+	return Hint{kind: indexHint, keyword: "FORCE INDEX", values: names}
+}
+
+// IgnoreIndex hint the query not to use the indexes, it is `IGNORE INDEX` of mysql
+func IgnoreIndex(names ...string) Hint {
+	return Hint{kind: indexHint, keyword: "IGNORE INDEX", values: names}
+}
+
+// TableHint hint the query with table hints of mssql, e.g. `NOLOCK`
+func TableHint(hints ...string) Hint {
+	return Hint{kind: tableHint, values: hints}
+}
+
+// OptimizerHint hint the query with optimizer hints in a comment `/*+ ... */` after `SELECT`, which is supported by
+// mysql and postgres with pg_hint_plan
+func OptimizerHint(hints ...string) Hint {
+	return Hint{kind: optimizerHint, values: hints}
+}
+
+// Hints add hints to queries, hints not supported by the dialect are ignored
+//     db.Hints(gorm.UseIndex("idx_user_name")).Find(&users)
+//     // mysql: SELECT * FROM `users` USE INDEX (`idx_user_name`)
+//     // sqlite: SELECT * FROM "users" INDEXED BY "idx_user_name"
+//     db.Hints(gorm.TableHint("NOLOCK")).Find(&users)
+//     // mssql: SELECT * FROM "users" WITH (NOLOCK)
+//     db.Hints(gorm.OptimizerHint("MAX_EXECUTION_TIME(1000)")).Find(&users)
+//     // SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `users`
+func (s *DB) Hints(hints ...Hint) *DB {
+	var allHints []Hint
+	if value, ok := s.Get("gorm:hints"); ok {
+		allHints = append(allHints, value.([]Hint)...)
+	}
+	return s.Set("gorm:hints", append(allHints, hints...))
+}
+
+func (scope *Scope) hints(kind hintKind) (hints []Hint) {
+	if value, ok := scope.Get("gorm:hints"); ok {
+		for _, hint := range value.([]Hint) {
+			if hint.kind == kind && len(hint.values) > 0 {
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return
+}
+
+// optimizerHintsSQL return optimizer hints placed after `SELECT`
+func (scope *Scope) optimizerHintsSQL() string {
+	var values []string
+	for _, hint := range scope.hints(optimizerHint) {
+		values = append(values, hint.values...)
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("/*+ %v */ ", strings.Join(values, " "))
+}
+
+// tableHintsSQL return index hints and table hints of the dialect placed after the table name
+func (scope *Scope) tableHintsSQL() string {
+	var sqls []string
+	switch scope.Dialect().GetName() {
+	case "mysql":
+		for _, hint := range scope.hints(indexHint) {
+			var names []string
+			for _, name := range hint.values {
+				names = append(names, scope.Quote(name))
+			}
+			sqls = append(sqls, fmt.Sprintf("%v (%v)", hint.keyword, strings.Join(names, ",")))
+		}
+	case "sqlite3":
+		// sqlite could only use one index for the table
+		for _, hint := range scope.hints(indexHint) {
+			if hint.keyword != "IGNORE INDEX" {
+				sqls = []string{"INDEXED BY " + scope.Quote(hint.values[0])}
+			}
+		}
+	case "mssql":
+		var values []string
+		for _, hint := range scope.hints(tableHint) {
+			values = append(values, hint.values...)
+		}
+		if len(values) > 0 {
+			sqls = append(sqls, fmt.Sprintf("WITH (%v)", strings.Join(values, ", ")))
+		}
+	}
+
+	if len(sqls) == 0 {
+		return ""
+	}
+	return " " + strings.Join(sqls, " ")
+}
@@ -0,0 +1,208 @@
+package gorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// History is a change of a record saved to the history table of its table, which is named `<table>_histories`
+type History struct {
+	ID        uint   `gorm:"primary_key"`
+	RecordID  string `gorm:"index"`
+	Operation string `gorm:"size:16"`
+	// Changes is a JSON object of changed columns, values of them are objects with the old value `old` and the new
+	// value `new`, old values of updated columns are only known for records tracked with `DB.TrackChanges`
+	Changes   JSON
+	Actor     string
+	CreatedAt time.Time
+}
+
+// HistoryChange is the change of a column saved in `History.Changes`
+type HistoryChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// RegisterHistory save histories of models when they are created, updated or deleted, history tables are migrated
+// when registering. Histories are saved in the transaction of the operation, the actor is the value of setting
+// `gorm:current_user`. Records of updates and deletes without primary keys, like `db.Where(...).Delete(&User{})`,
+// are found before writing them, so each of them has its history with its old values
+//
+//	db.RegisterHistory(&User{})
+//	db.Set("gorm:current_user", admin.ID).Model(&user).Update("role", "admin")
+//	// INSERT INTO users_histories (record_id,operation,changes,actor,created_at)
+//	//   VALUES ('1','update','{"role":{"new":"admin"}}','7','2013-11-17 21:34:10')
+func (s *DB) RegisterHistory(models ...interface{}) error {
+	tables := map[string]bool{}
+	if value, ok := s.Get("gorm:history"); ok {
+		for table := range value.(map[string]bool) {
+			tables[table] = true
+		}
+	}
+
+	for _, model := range models {
+		table := s.NewScope(model).TableName()
+		if err := s.New().Table(historyTableName(table)).AutoMigrate(&History{}).Error; err != nil {
+			return err
+		}
+		tables[table] = true
+	}
+
+	s.InstantSet("gorm:history", tables)
+	return nil
+}
+
+func historyTableName(table string) string {
+	return table + "_histories"
+}
+
+func (scope *Scope) hasHistory() bool {
+	if value, ok := scope.Get("gorm:history"); ok {
+		return value.(map[string]bool)[scope.TableName()]
+	}
+	return false
+}
+
+// saveHistoryForCreateCallback save histories of created records
+func saveHistoryForCreateCallback(scope *Scope) {
+	if scope.HasError() || !scope.hasHistory() {
+		return
+	}
+
+	changes := map[string]HistoryChange{}
+	for _, field := range scope.Fields() {
+		if field.IsNormal && !field.IsIgnored {
+			changes[field.DBName] = HistoryChange{New: field.Field.Interface()}
+		}
+	}
+	scope.saveHistory(scope, "create", changes)
+}
+
+// findHistoryRecordsCallback find records of updates and deletes without primary keys before writing them
+func findHistoryRecordsCallback(scope *Scope) {
+	if scope.HasError() || !scope.hasHistory() || scope.GetModelStruct().ModelType == nil || !scope.PrimaryKeyZero() {
+		return
+	}
+
+	if _, ok := scope.InstanceGet("gorm:update_attrs"); !ok && scope.Statement().Operation() == "update" {
+		return
+	}
+
+	records := reflect.New(reflect.SliceOf(scope.GetModelStruct().ModelType))
+	db := scope.NewDB()
+	db.search = scope.Search.clone()
+	db.search.db = db
+	db.search.selects, db.search.omits, db.search.preload = nil, nil, nil
+	if scope.Err(db.Find(records.Interface()).Error) == nil {
+		scope.InstanceSet("gorm:history_records", records.Elem())
+	}
+}
+
+// historyRecords return records found with findHistoryRecordsCallback
+func (scope *Scope) historyRecords() (reflect.Value, bool) {
+	if records, ok := scope.InstanceGet("gorm:history_records"); ok {
+		return records.(reflect.Value), true
+	}
+	return reflect.Value{}, false
+}
+
+// saveHistoryForUpdateCallback save histories of updated records
+func saveHistoryForUpdateCallback(scope *Scope) {
+	if scope.HasError() || scope.db.RowsAffected == 0 || !scope.hasHistory() {
+		return
+	}
+
+	values := scope.Statement().Changes()
+	for column, value := range values {
+		if expr, ok := value.(*SqlExpr); ok {
+			values[column] = expr.expr
+		}
+	}
+
+	if records, ok := scope.historyRecords(); ok {
+		for i := 0; i < records.Len(); i++ {
+			recordScope := scope.New(records.Index(i).Addr().Interface())
+			changes := map[string]HistoryChange{}
+			for column, value := range values {
+				change := HistoryChange{New: value}
+				if field, ok := recordScope.FieldByName(column); ok {
+					change.Old = field.Field.Interface()
+				}
+				changes[column] = change
+			}
+
+			if scope.saveHistory(recordScope, "update", changes); scope.HasError() {
+				return
+			}
+		}
+		return
+	}
+
+	original, tracked := scope.originalValues()
+	changes := map[string]HistoryChange{}
+	for column, value := range values {
+		change := HistoryChange{New: value}
+		if tracked {
+			change.Old = original[column]
+		}
+		changes[column] = change
+	}
+	scope.saveHistory(scope, "update", changes)
+}
+
+// saveHistoryForDeleteCallback save histories of deleted records
+func saveHistoryForDeleteCallback(scope *Scope) {
+	if scope.HasError() || scope.db.RowsAffected == 0 || !scope.hasHistory() {
+		return
+	}
+
+	if records, ok := scope.historyRecords(); ok {
+		for i := 0; i < records.Len(); i++ {
+			recordScope := scope.New(records.Index(i).Addr().Interface())
+			if scope.saveHistory(recordScope, "delete", oldHistoryChanges(recordScope)); scope.HasError() {
+				return
+			}
+		}
+		return
+	}
+	scope.saveHistory(scope, "delete", oldHistoryChanges(scope))
+}
+
+// oldHistoryChanges return changes of all columns of the record with old values
+func oldHistoryChanges(recordScope *Scope) map[string]HistoryChange {
+	changes := map[string]HistoryChange{}
+	for _, field := range recordScope.Fields() {
+		if field.IsNormal && !field.IsIgnored {
+			changes[field.DBName] = HistoryChange{Old: field.Field.Interface()}
+		}
+	}
+	return changes
+}
+
+// saveHistory save the history of the record, histories of records without primary keys aren't saved, as they
+// can't be told apart, like records upserted in batches
+func (scope *Scope) saveHistory(recordScope *Scope, operation string, changes map[string]HistoryChange) {
+	if recordScope.PrimaryKeyZero() {
+		return
+	}
+
+	data, err := json.Marshal(changes)
+	if scope.Err(err) != nil {
+		return
+	}
+
+	history := History{
+		RecordID:  fmt.Sprint(recordScope.PrimaryKeyValue()),
+		Operation: operation,
+		Changes:   JSON(data),
+		CreatedAt: scope.db.nowFunc(),
+	}
+
+	if user, ok := scope.currentUser(); ok {
+		history.Actor = fmt.Sprint(user)
+	}
+
+	scope.Err(scope.NewDB().Table(historyTableName(scope.TableName())).Create(&history).Error)
+}
@@ -0,0 +1,97 @@
+package gorm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type HistoryProduct struct {
+	ID    uint
+	Code  string
+	Price int
+}
+
+func TestHistory(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&HistoryProduct{}, "history_products_histories")
+	db.AutoMigrate(&HistoryProduct{})
+
+	conn, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer conn.Close()
+
+	db = conn.Set("gorm:table_options", "")
+	if err := db.RegisterHistory(&HistoryProduct{}); err != nil {
+		t.Fatalf("Failed to register history, got %v", err)
+	}
+
+	product := HistoryProduct{Code: "history", Price: 10}
+	db.Set("gorm:current_user", 1).Create(&product)
+	tx := db.Set("gorm:current_user", 2).TrackChanges()
+	tx.First(&product, product.ID)
+	product.Price = 20
+	tx.Save(&product)
+	db.Model(&product).Update("code", "history2")
+	db.Delete(&product)
+
+	// failed operations do not save histories
+	db.Model(&HistoryProduct{}).Where("id = ?", product.ID).Update("unknown_column", 1)
+
+	var histories []gorm.History
+	db.Table("history_products_histories").Order("id").Find(&histories)
+	if len(histories) != 4 {
+		t.Fatalf("Should save histories of changes, got %#v", histories)
+	}
+
+	for idx, operation := range []string{"create", "update", "update", "delete"} {
+		if histories[idx].Operation != operation || histories[idx].RecordID != fmt.Sprint(product.ID) {
+			t.Errorf("History %v should be %v, got %#v", idx, operation, histories[idx])
+		}
+	}
+
+	if histories[0].Actor != "1" || histories[1].Actor != "2" || histories[2].Actor != "" {
+		t.Errorf("Histories should be saved with actors, got %v, %v, %v", histories[0].Actor, histories[1].Actor, histories[2].Actor)
+	}
+
+	var changes map[string]gorm.HistoryChange
+	json.Unmarshal(histories[1].Changes, &changes)
+	if len(changes) != 1 || changes["price"].Old != float64(10) || changes["price"].New != float64(20) {
+		t.Errorf("Histories of tracked records should include old values, got %#v", changes)
+	}
+
+	json.Unmarshal(histories[3].Changes, &changes)
+	if changes["code"].Old != "history2" {
+		t.Errorf("Histories of deleting should include old values, got %#v", changes)
+	}
+
+	products := []HistoryProduct{{Code: "history_batch", Price: 1}, {Code: "history_batch", Price: 2}}
+	if err := db.CreateInBatches(&products, 10).Error; err != nil {
+		t.Fatalf("Failed to create in batches, got %v", err)
+	}
+	db.Model(&HistoryProduct{}).Where("code = ?", "history_batch").Update("price", 3)
+	db.Where("code = ?", "history_batch").Delete(&HistoryProduct{})
+
+	for _, product := range products {
+		histories = nil
+		db.Table("history_products_histories").Where("record_id = ?", fmt.Sprint(product.ID)).Order("id").Find(&histories)
+		if len(histories) != 3 || histories[0].Operation != "create" || histories[1].Operation != "update" || histories[2].Operation != "delete" {
+			t.Fatalf("Should save histories of each record created in batches and written without primary keys, got %#v", histories)
+		}
+
+		json.Unmarshal(histories[1].Changes, &changes)
+		if changes["price"].Old != float64(product.Price) || changes["price"].New != float64(3) {
+			t.Errorf("Histories of updating without primary keys should include old values, got %#v", changes)
+		}
+	}
+
+	var count int
+	db.Table("history_products_histories").Where("record_id = ?", "").Count(&count)
+	if count != 0 {
+		t.Errorf("Histories should be saved with record ids, but got %v without them", count)
+	}
+}
@@ -0,0 +1,203 @@
+package gorm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IDGenerator generate IDs of fields tagged with `id_generator`, e.g. primary keys of distributed services which
+// can't rely on auto increment, IDs are generated before inserting if fields are blank, and fields aren't auto
+// incremented
+//     type Order struct {
+//       ID    int64  `gorm:"primary_key;id_generator:snowflake"`
+//       Token string `gorm:"id_generator:ulid"`
+//     }
+// Generators `ksuid` and `ulid` are built in, others like `snowflake` are configured with `DB.RegisterIDGenerator`
+type IDGenerator interface {
+	NextID() (interface{}, error)
+}
+
+// IDGeneratorFunc is a function implementing IDGenerator
+type IDGeneratorFunc func() (interface{}, error)
+
+// NextID return the ID generated by the function
+func (fn IDGeneratorFunc) NextID() (interface{}, error) {
+	return fn()
+}
+
+var idGenerators = map[string]IDGenerator{
+	"ksuid": IDGeneratorFunc(func() (interface{}, error) { return NewKSUID() }),
+	"ulid":  IDGeneratorFunc(func() (interface{}, error) { return NewULID() }),
+}
+
+// RegisterIDGenerator register the ID generator with name for the DB, fields use it with tag `id_generator:name`
+//     snowflake, err := gorm.NewSnowflake(workerID)
+//     db.RegisterIDGenerator("snowflake", snowflake)
+func (s *DB) RegisterIDGenerator(name string, generator IDGenerator) *DB {
+	generators := map[string]IDGenerator{}
+	if value, ok := s.Get("gorm:id_generators"); ok {
+		for name, generator := range value.(map[string]IDGenerator) {
+			generators[name] = generator
+		}
+	}
+
+	generators[name] = generator
+	s.InstantSet("gorm:id_generators", generators)
+	return s
+}
+
+// idGeneratorOf return the ID generator of the field's tag `id_generator`, the error is set if it isn't registered
+func (scope *Scope) idGeneratorOf(field *StructField) (IDGenerator, bool, error) {
+	name, ok := field.TagSettingsGet("ID_GENERATOR")
+	if !ok {
+		return nil, false, nil
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if value, ok := scope.Get("gorm:id_generators"); ok {
+		if generator, ok := value.(map[string]IDGenerator)[name]; ok {
+			return generator, true, nil
+		}
+	}
+
+	if generator, ok := idGenerators[name]; ok {
+		return generator, true, nil
+	}
+	return nil, true, fmt.Errorf("id generator %v of field %v is not registered", name, field.Name)
+}
+
+// generateIDCallback set blank fields tagged with `id_generator` to generated IDs before creating
+func generateIDCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	for _, field := range scope.Fields() {
+		generator, ok, err := scope.idGeneratorOf(field.StructField)
+		if !ok || !field.IsBlank {
+			continue
+		}
+
+		if scope.Err(err) != nil {
+			return
+		}
+
+		id, err := generator.NextID()
+		if scope.Err(err) != nil {
+			return
+		}
+
+		// numeric IDs like snowflake IDs are formatted for string fields
+		if indirectType := reflect.Indirect(field.Field).Type(); indirectType.Kind() == reflect.String {
+			if _, ok := id.(string); !ok {
+				id = fmt.Sprint(id)
+			}
+		}
+
+		if scope.Err(field.Set(id)) != nil {
+			return
+		}
+	}
+}
+
+// Snowflake generate time ordered 64 bits IDs, which are composed of 41 bits of milliseconds since the epoch, 10 bits
+// of the worker ID and 12 bits of a sequence, workers of a service should have different worker IDs
+type Snowflake struct {
+	// Epoch is the start of timestamps of IDs, the default is the twitter epoch 2010-11-04 01:42:54.657 UTC
+	Epoch time.Time
+
+	mu           sync.Mutex
+	workerID     int64
+	lastMilli    int64
+	lastSequence int64
+}
+
+// NewSnowflake return a snowflake ID generator of the worker, worker IDs are from 0 to 1023
+func NewSnowflake(workerID int64) (*Snowflake, error) {
+	if workerID < 0 || workerID > 1023 {
+		return nil, fmt.Errorf("snowflake worker id %v should be from 0 to 1023", workerID)
+	}
+	return &Snowflake{Epoch: time.Unix(0, 1288834974657*int64(time.Millisecond)), workerID: workerID}, nil
+}
+
+// NextID return the next snowflake ID as int64, IDs can't be generated if the clock moved backwards
+func (snowflake *Snowflake) NextID() (interface{}, error) {
+	snowflake.mu.Lock()
+	defer snowflake.mu.Unlock()
+
+	milli := snowflake.milliseconds()
+	if milli < snowflake.lastMilli {
+		return nil, errors.New("clock moved backwards, refusing to generate snowflake ids")
+	}
+
+	if milli == snowflake.lastMilli {
+		snowflake.lastSequence = (snowflake.lastSequence + 1) & 4095
+		// wait for the next millisecond if the sequence is exhausted
+		for snowflake.lastSequence == 0 && milli <= snowflake.lastMilli {
+			milli = snowflake.milliseconds()
+		}
+	} else {
+		snowflake.lastSequence = 0
+	}
+
+	snowflake.lastMilli = milli
+	return milli<<22 | snowflake.workerID<<12 | snowflake.lastSequence, nil
+}
+
+func (snowflake *Snowflake) milliseconds() int64 {
+	return int64(time.Since(snowflake.Epoch) / time.Millisecond)
+}
+
+const (
+	base62Alphabet        = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	crockfordBase32       = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	ksuidEpoch      int64 = 1400000000
+)
+
+// NewKSUID generate a KSUID, which is 27 base62 characters of 32 bits of seconds since the KSUID epoch and 128 random
+// bits, KSUIDs are ordered by time when sorted as strings
+func NewKSUID() (string, error) {
+	var id [20]byte
+	if _, err := rand.Read(id[4:]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint32(id[:4], uint32(NowFunc().Unix()-ksuidEpoch))
+	return encodeID(id[:], base62Alphabet, 27), nil
+}
+
+// NewULID generate a ULID, which is 26 Crockford's base32 characters of 48 bits of milliseconds since the unix epoch
+// and 80 random bits, ULIDs are ordered by time when sorted as strings
+func NewULID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(NowFunc().UnixNano()/1e6))
+	copy(id[:6], timestamp[2:])
+	return encodeID(id[:], crockfordBase32, 26), nil
+}
+
+// encodeID encode the big endian bytes with the alphabet, padded to the length with the alphabet's zero
+func encodeID(id []byte, alphabet string, length int) string {
+	var (
+		number  = new(big.Int).SetBytes(id)
+		base    = big.NewInt(int64(len(alphabet)))
+		mod     = new(big.Int)
+		encoded = make([]byte, length)
+	)
+
+	for i := length - 1; i >= 0; i-- {
+		number.DivMod(number, base, mod)
+		encoded[i] = alphabet[mod.Int64()]
+	}
+	return string(encoded)
+}
@@ -0,0 +1,29 @@
+package gorm
+
+import "fmt"
+
+// ILikeExpression build case-insensitive LIKE conditions for a column, could be used with `Where`, `Or` and `Not`
+type ILikeExpression struct {
+	column  string
+	pattern interface{}
+}
+
+// ILike return records whose column matches the pattern case-insensitively, it is `ILIKE` for postgres and
+// `LOWER(column) LIKE LOWER(pattern)` for others
+//     db.Where(gorm.ILike("name", "%jinzhu%")).Find(&users)
+//     // postgres: SELECT * FROM "users" WHERE ("name" ILIKE '%jinzhu%')
+//     // mysql: SELECT * FROM `users` WHERE (LOWER(`name`) LIKE LOWER('%jinzhu%'))
+func ILike(column string, pattern interface{}) *ILikeExpression {
+	return &ILikeExpression{column: column, pattern: pattern}
+}
+
+func (expr *ILikeExpression) conditionSQL(scope *Scope) string {
+	column := scope.Quote(expr.column)
+
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroach":
+		return fmt.Sprintf("%v ILIKE %v", column, scope.AddToVars(expr.pattern))
+	default:
+		return fmt.Sprintf("LOWER(%v) LIKE LOWER(%v)", column, scope.AddToVars(expr.pattern))
+	}
+}
@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrIteratorClosed returned when scanning records with an iterator which is finished or closed
+var ErrIteratorClosed = errors.New("iterator is closed")
+
+// Iterator iterate records of a query one by one, it is created with `DB.Iterate`
+type Iterator struct {
+	db     *DB
+	rows   *sql.Rows
+	err    error
+	closed bool
+}
+
+// Iterate query records and return an iterator of them, records are scanned when iterating, so they don't need to be
+// loaded into memory at once. Rows are closed when iterating is finished or failed, `Close` need to be called if
+// iterating is stopped early
+//     iterator := db.Model(&User{}).Where("age > ?", 18).Preload("Emails").Iterate()
+//     defer iterator.Close()
+//     for iterator.Next() {
+//       var user User
+//       if err := iterator.Scan(&user); err != nil {
+//         return err
+//       }
+//     }
+//     return iterator.Err()
+// Scanned records are preloaded and their `AfterFind` hooks are called, like records found with `Find`
+func (s *DB) Iterate() *Iterator {
+	iterator := &Iterator{db: s}
+	iterator.rows, iterator.err = s.Rows()
+	if iterator.err != nil {
+		iterator.closed = true
+	}
+	return iterator
+}
+
+// Next prepare the next record for scanning, it returns false if no more record or iterating failed
+func (iterator *Iterator) Next() bool {
+	if iterator.closed {
+		return false
+	}
+
+	if iterator.rows.Next() {
+		return true
+	}
+
+	iterator.err = iterator.rows.Err()
+	iterator.Close()
+	return false
+}
+
+// Scan scan the current record to dest, which is a pointer to a struct
+func (iterator *Iterator) Scan(dest interface{}) error {
+	if iterator.closed {
+		if iterator.err != nil {
+			return iterator.err
+		}
+		return ErrIteratorClosed
+	}
+
+	if err := iterator.db.ScanRows(iterator.rows, dest); err != nil {
+		return iterator.fail(err)
+	}
+
+	scope := iterator.db.NewScope(dest)
+	for _, callback := range []func(*Scope){preloadCallback, afterQueryCallback, trackChangesCallback} {
+		if callback(scope); scope.HasError() {
+			return iterator.fail(scope.db.Error)
+		}
+	}
+	return nil
+}
+
+// Err return the error happened when iterating
+func (iterator *Iterator) Err() error {
+	return iterator.err
+}
+
+// Close close rows of the iterator, it is safe to call it more than once
+func (iterator *Iterator) Close() error {
+	if iterator.closed {
+		return nil
+	}
+
+	iterator.closed = true
+	return iterator.rows.Close()
+}
+
+func (iterator *Iterator) fail(err error) error {
+	iterator.err = err
+	iterator.Close()
+	return err
+}
@@ -79,7 +79,7 @@ func (s *JoinTableHandler) Setup(relationship *Relationship, tableName string, s
 
 // Table return join table's table name
 func (s JoinTableHandler) Table(db *DB) string {
-	return DefaultTableNameHandler(db, s.TableName)
+	return db.NewScope(nil).qualifyTableName(DefaultTableNameHandler(db, db.getTablePrefix()+s.TableName))
 }
 
 func (s JoinTableHandler) updateConditionMap(conditionMap map[string]interface{}, db *DB, joinTableSources []JoinTableSource, sources ...interface{}) {
@@ -91,7 +91,7 @@ func (s JoinTableHandler) updateConditionMap(conditionMap map[string]interface{}
 			if joinTableSource.ModelType == modelType {
 				for _, foreignKey := range joinTableSource.ForeignKeys {
 					if field, ok := scope.FieldByName(foreignKey.AssociationDBName); ok {
-						conditionMap[foreignKey.DBName] = field.Field.Interface()
+						conditionMap[foreignKey.DBName] = field.sqlValue()
 					}
 				}
 				break
@@ -194,16 +194,13 @@ func (s JoinTableHandler) JoinWith(handler JoinTableHandlerInterface, db *DB, so
 				quotedForeignDBNames = append(quotedForeignDBNames, tableName+"."+dbName)
 			}
 
-			condString = fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, quotedForeignDBNames), toQueryMarks(foreignFieldValues))
-
-			keys := scope.getColumnAsArray(foreignFieldNames, scope.Value)
-			values = append(values, toQueryValues(keys))
+			condString, values = toInCondition(scope, quotedForeignDBNames, foreignFieldValues)
 		} else {
 			condString = fmt.Sprintf("1 <> 1")
 		}
 
 		return db.Joins(fmt.Sprintf("INNER JOIN %v ON %v", quotedTableName, strings.Join(joinConditions, " AND "))).
-			Where(condString, toQueryValues(foreignFieldValues)...)
+			Where(condString, values...)
 	}
 
 	db.Error = errors.New("wrong source type for join table handler")
@@ -0,0 +1,208 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// joinedColumnSeparator separate the association name and column name of columns selected for joined associations
+const joinedColumnSeparator = "__"
+
+// joinedAssociation is a belongs to or has one association loaded with `Joins("Association")`
+type joinedAssociation struct {
+	field *StructField
+	scope *Scope
+}
+
+// joinedAssociation return the association if the join query is the name of a belongs to or has one association
+func (scope *Scope) joinedAssociation(clause map[string]interface{}) (*joinedAssociation, bool) {
+	name, ok := clause["query"].(string)
+	if !ok || name == "" || strings.ContainsAny(name, " .\"`") {
+		return nil, false
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.Name != name || field.Relationship == nil {
+			continue
+		}
+
+		if kind := field.Relationship.Kind; kind != "belongs_to" && kind != "has_one" {
+			return nil, false
+		}
+
+		fieldType := field.Struct.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		return &joinedAssociation{field: field, scope: scope.New(reflect.New(fieldType).Interface())}, true
+	}
+	return nil, false
+}
+
+// joinedAssociations return associations loaded with joins
+func (scope *Scope) joinedAssociations() (associations []*joinedAssociation) {
+	for _, clause := range scope.Search.joinConditions {
+		if association, ok := scope.joinedAssociation(clause); ok {
+			associations = append(associations, association)
+		}
+	}
+	return
+}
+
+// joinSQL return LEFT JOIN clause of the association, the associated table is aliased as the association's name
+func (association *joinedAssociation) joinSQL(scope *Scope) string {
+	var (
+		relationship    = association.field.Relationship
+		alias           = scope.Quote(association.field.Name)
+		quotedTableName = scope.QuotedTableName()
+		conditions      []string
+	)
+
+	for idx, foreignKey := range relationship.ForeignDBNames {
+		associationForeignKey := relationship.AssociationForeignDBNames[idx]
+		if relationship.Kind == "belongs_to" {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(associationForeignKey), quotedTableName, scope.Quote(foreignKey)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(foreignKey), quotedTableName, scope.Quote(associationForeignKey)))
+		}
+	}
+
+	if relationship.PolymorphicType != "" {
+		conditions = append(conditions, fmt.Sprintf("%v.%v = %v", alias, scope.Quote(relationship.PolymorphicDBName), scope.AddToVars(relationship.PolymorphicValue)))
+	}
+
+	if !scope.Search.Unscoped {
+		if query, args, ok := association.scope.softDeleteQuery(); ok {
+			query = strings.Replace(query, association.scope.QuotedTableName()+".", alias+".", -1)
+			for _, arg := range args {
+				query = strings.Replace(query, "?", scope.AddToVars(arg), 1)
+			}
+			conditions = append(conditions, query)
+		}
+	}
+
+	return fmt.Sprintf("LEFT JOIN %v %v ON %v", association.scope.QuotedTableName(), alias, strings.Join(conditions, " AND "))
+}
+
+// selectSQL return columns of the association selected as `"Association"."column" AS "Association__column"`,
+// nested fields selected or omitted like `Select("Company.Name")` and `Omit("Company.Logo")` are respected, primary
+// keys are always selected to find out whether the association is matched
+func (association *joinedAssociation) selectSQL(scope *Scope) string {
+	var (
+		alias   = association.field.Name
+		selects = nestedAttrs(scope.SelectAttrs(), alias)
+		omits   = nestedAttrs(scope.OmitAttrs(), alias)
+		columns []string
+	)
+
+	for _, field := range association.scope.GetModelStruct().StructFields {
+		if !field.IsPrimaryKey && (len(selects) > 0 && !containsAttr(selects, field) || containsAttr(omits, field)) {
+			continue
+		}
+
+		if field.IsNormal && !field.IsIgnored {
+			columns = append(columns, fmt.Sprintf("%v.%v AS %v", scope.Quote(alias), scope.Quote(field.DBName), scope.Quote(alias+joinedColumnSeparator+field.DBName)))
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// joinedSelectSQL return columns selected with field paths like `Select("Name", "Company.Name")` for queries
+// loading associations with joins, it returns false unless the selects are fields of the model and joined
+// associations, and at least one of the joined associations is selected
+func (scope *Scope) joinedSelectSQL() (string, bool) {
+	var (
+		associations = scope.joinedAssociations()
+		columns      []string
+		selected     = map[string]bool{}
+	)
+
+	for _, attr := range scope.SelectAttrs() {
+		var isAssociation bool
+		for _, association := range associations {
+			if attr == association.field.Name || strings.HasPrefix(attr, association.field.Name+".") {
+				isAssociation = true
+				selected[association.field.Name] = true
+			}
+		}
+
+		if !isAssociation {
+			field, ok := scope.FieldByName(attr)
+			if !ok || !field.IsNormal || strings.Contains(attr, ".") {
+				return "", false
+			}
+			columns = append(columns, fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName)))
+		}
+	}
+
+	if len(selected) == 0 {
+		return "", false
+	}
+
+	for _, association := range associations {
+		if selected[association.field.Name] {
+			columns = append(columns, association.selectSQL(scope))
+		}
+	}
+	return strings.Join(columns, ", "), true
+}
+
+func containsAttr(attrs []string, field *StructField) bool {
+	for _, attr := range attrs {
+		if attr == field.Name || attr == field.DBName {
+			return true
+		}
+	}
+	return false
+}
+
+// joinedFields return fields of associations loaded with joins for the record, their db names are prefixed with
+// association names to match selected columns
+func joinedFields(fields []*Field, associations []*joinedAssociation) (joinedFields []*Field) {
+	for _, association := range associations {
+		for _, field := range fields {
+			if field.Name != association.field.Name || field.Relationship == nil {
+				continue
+			}
+
+			value := field.Field
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+			} else {
+				value = value.Addr()
+			}
+
+			for _, associationField := range association.scope.New(value.Interface()).Fields() {
+				if associationField.IsNormal && !associationField.IsIgnored {
+					structField := associationField.StructField.clone()
+					structField.DBName = association.field.Name + joinedColumnSeparator + structField.DBName
+					joinedFields = append(joinedFields, &Field{StructField: structField, Field: associationField.Field})
+				}
+			}
+		}
+	}
+	return
+}
+
+// resetUnmatchedJoins reset associations loaded with joins if no record is matched
+func resetUnmatchedJoins(fields []*Field, associations []*joinedAssociation) {
+	for _, association := range associations {
+		for _, field := range fields {
+			if field.Name != association.field.Name || field.Relationship == nil {
+				continue
+			}
+
+			value := field.Field
+			if value.Kind() != reflect.Ptr {
+				value = value.Addr()
+			}
+
+			if associationScope := association.scope.New(value.Interface()); associationScope.PrimaryField() != nil && associationScope.PrimaryKeyZero() {
+				field.Field.Set(reflect.Zero(field.Field.Type()))
+			}
+		}
+	}
+}
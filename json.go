@@ -0,0 +1,150 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSON defines a JSON data type, which will be migrated to JSONB for postgres, JSON for mysql and TEXT for others
+//     type User struct {
+//       Attributes gorm.JSON
+//     }
+type JSON json.RawMessage
+
+// Value return JSON value, implement driver.Valuer interface
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan scan value into JSON, implements sql.Scanner interface
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSON{}, v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return errors.New(fmt.Sprint("failed to unmarshal JSON value: ", value))
+	}
+	return nil
+}
+
+// MarshalJSON return the raw JSON
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON set the raw JSON
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if j == nil {
+		return errors.New("gorm.JSON: UnmarshalJSON on nil pointer")
+	}
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+// String return the raw JSON as string
+func (j JSON) String() string {
+	return string(j)
+}
+
+// GormDataType return the data type for the dialect
+func (JSON) GormDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres", "cockroach":
+		return "JSONB"
+	case "mysql":
+		return "JSON"
+	case "mssql":
+		return "NVARCHAR(MAX)"
+	default:
+		return "TEXT"
+	}
+}
+
+// JSONQueryExpression build JSON conditions for a column, could be used with `Where`, `Or` and `Not`
+type JSONQueryExpression struct {
+	column  string
+	keys    []string
+	hasKey  bool
+	equals  bool
+	operand interface{}
+}
+
+// JSONQuery query JSON column
+//     db.Where(gorm.JSONQuery("attributes").HasKey("role")).Find(&users)
+//     db.Where(gorm.JSONQuery("attributes").Equals("admin", "role")).Find(&users)
+//     db.Where(gorm.JSONQuery("attributes").Equals("tokyo", "address", "city")).Find(&users)
+func JSONQuery(column string) *JSONQueryExpression {
+	return &JSONQueryExpression{column: column}
+}
+
+// HasKey return records whose JSON column has the key (path)
+func (expr *JSONQueryExpression) HasKey(keys ...string) *JSONQueryExpression {
+	expr.keys = keys
+	expr.hasKey = true
+	return expr
+}
+
+// Equals return records whose value at the key (path) of JSON column equals to value
+func (expr *JSONQueryExpression) Equals(value interface{}, keys ...string) *JSONQueryExpression {
+	expr.keys = keys
+	expr.equals = true
+	expr.operand = value
+	return expr
+}
+
+func (expr *JSONQueryExpression) conditionSQL(scope *Scope) string {
+	if len(expr.keys) == 0 || (!expr.hasKey && !expr.equals) {
+		scope.Err(fmt.Errorf("invalid JSON query for %v, key is required", expr.column))
+		return ""
+	}
+
+	var (
+		column    = scope.Quote(expr.column)
+		extracted string
+	)
+
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroach":
+		var marks []string
+		for _, key := range expr.keys {
+			marks = append(marks, scope.AddToVars(key))
+		}
+
+		if expr.hasKey {
+			return fmt.Sprintf("jsonb_extract_path(%v, %v) IS NOT NULL", column, strings.Join(marks, ", "))
+		}
+		return fmt.Sprintf("jsonb_extract_path_text(%v, %v) = %v", column, strings.Join(marks, ", "), scope.AddToVars(fmt.Sprint(expr.operand)))
+	case "mysql":
+		extracted = fmt.Sprintf("JSON_EXTRACT(%v, %v)", column, scope.AddToVars(jsonPath(expr.keys)))
+	case "mssql":
+		extracted = fmt.Sprintf("JSON_VALUE(%v, %v)", column, scope.AddToVars(jsonPath(expr.keys)))
+	default:
+		extracted = fmt.Sprintf("json_extract(%v, %v)", column, scope.AddToVars(jsonPath(expr.keys)))
+	}
+
+	if expr.hasKey {
+		return fmt.Sprintf("%v IS NOT NULL", extracted)
+	}
+	return fmt.Sprintf("%v = %v", extracted, scope.AddToVars(expr.operand))
+}
+
+func jsonPath(keys []string) string {
+	var path = "$"
+	for _, key := range keys {
+		path += fmt.Sprintf(".%q", key)
+	}
+	return path
+}
@@ -0,0 +1,67 @@
+package gorm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type JSONUser struct {
+	ID         uint
+	Name       string
+	Attributes gorm.JSON
+}
+
+func TestJSON(t *testing.T) {
+	DB.DropTableIfExists(&JSONUser{})
+	if err := DB.AutoMigrate(&JSONUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate JSON column, got %v", err)
+	}
+
+	users := []JSONUser{
+		{Name: "json1", Attributes: gorm.JSON(`{"role": "admin", "address": {"city": "tokyo"}}`)},
+		{Name: "json2", Attributes: gorm.JSON(`{"role": "member"}`)},
+		{Name: "json3"},
+	}
+	for i := range users {
+		if err := DB.Save(&users[i]).Error; err != nil {
+			t.Fatalf("Failed to save JSON column, got %v", err)
+		}
+	}
+
+	var user JSONUser
+	DB.First(&user, users[0].ID)
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(user.Attributes, &attributes); err != nil || attributes["role"] != "admin" {
+		t.Errorf("Should load JSON column, but got %v, %v", string(user.Attributes), err)
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" && DB.Exec("SELECT json('{}')").Error != nil {
+		t.Skip("sqlite3 is built without JSON1 extension, build with tag sqlite_json to test JSON queries")
+	}
+
+	var count int
+	DB.Model(&JSONUser{}).Where(gorm.JSONQuery("attributes").HasKey("role")).Count(&count)
+	if count != 2 {
+		t.Errorf("Should find records with key, but got %v", count)
+	}
+
+	var found []JSONUser
+	DB.Where(gorm.JSONQuery("attributes").Equals("admin", "role")).Find(&found)
+	if len(found) != 1 || found[0].Name != "json1" {
+		t.Errorf("Should find records with equal value, but got %#v", found)
+	}
+
+	found = nil
+	DB.Where(gorm.JSONQuery("attributes").Equals("tokyo", "address", "city")).Find(&found)
+	if len(found) != 1 || found[0].Name != "json1" {
+		t.Errorf("Should find records with equal nested value, but got %#v", found)
+	}
+
+	found = nil
+	DB.Not(gorm.JSONQuery("attributes").Equals("admin", "role")).Where(gorm.JSONQuery("attributes").HasKey("role")).Find(&found)
+	if len(found) != 1 || found[0].Name != "json2" {
+		t.Errorf("Should find records without equal value, but got %#v", found)
+	}
+}
@@ -48,7 +48,12 @@ var LogFormatter = func(values ...interface{}) (messages []interface{}) {
 			messages = []interface{}{currentTime, source}
 		}
 
-		if level == "sql" {
+		if level == "slow sql" {
+			// threshold
+			messages = append(messages, fmt.Sprintf(" \033[33;1m[WARN] slow sql >= %v\033[0m", values[6]))
+		}
+
+		if level == "sql" || level == "slow sql" {
 			// duration
 			messages = append(messages, fmt.Sprintf(" \033[36;1m[%.2fms]\033[0m ", float64(values[2].(time.Duration).Nanoseconds()/1e4)/100.0))
 			// sql
@@ -3,6 +3,7 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
@@ -31,6 +32,7 @@ type DB struct {
 	callbacks     *Callback
 	dialect       Dialect
 	singularTable bool
+	tablePrefix   string
 
 	// function to be used to override the creating of a new timestamp
 	nowFuncOverride func() time.Time
@@ -55,6 +57,8 @@ const (
 //    // import _ "github.com/zanmato/gorm/dialects/postgres"
 //    // import _ "github.com/zanmato/gorm/dialects/sqlite"
 //    // import _ "github.com/zanmato/gorm/dialects/mssql"
+// Connectors like `driver.Connector`s wrapped with `WrapConnector` could also be opened
+//    db, err := gorm.Open("postgres", gorm.WrapConnector(connector, hooks))
 func Open(dialect string, args ...interface{}) (db *DB, err error) {
 	if len(args) == 0 {
 		err = errors.New("invalid database source")
@@ -75,6 +79,9 @@ func Open(dialect string, args ...interface{}) (db *DB, err error) {
 		}
 		dbSQL, err = sql.Open(driver, source)
 		ownDbSQL = true
+	case driver.Connector:
+		dbSQL = sql.OpenDB(value)
+		ownDbSQL = true
 	case SQLCommon:
 		dbSQL = value
 		ownDbSQL = false
@@ -167,6 +174,14 @@ func (s *DB) LogMode(enable bool) *DB {
 	return s
 }
 
+// InterpolateLogSQL log SQL with values quoted as literals of the dialect instead of placeholders like `$1`, so logged
+// statements could be copied to database clients, statements are still executed with bind vars
+//     db.LogMode(true).InterpolateLogSQL().Where("name = ?", "jinzhu").Find(&users)
+//     // SELECT * FROM "users"  WHERE (name = 'jinzhu')
+func (s *DB) InterpolateLogSQL() *DB {
+	return s.Set("gorm:interpolate_log_sql", true)
+}
+
 // SetNowFuncOverride set the function to be used when creating a new timestamp
 func (s *DB) SetNowFuncOverride(nowFuncOverride func() time.Time) *DB {
 	s.nowFuncOverride = nowFuncOverride
@@ -174,13 +189,17 @@ func (s *DB) SetNowFuncOverride(nowFuncOverride func() time.Time) *DB {
 }
 
 // Get a new timestamp, using the provided nowFuncOverride on the DB instance if set,
-// otherwise defaults to the global NowFunc()
+// otherwise defaults to the global NowFunc(), in the location of `TimeZone` if it is set
 func (s *DB) nowFunc() time.Time {
+	now := NowFunc
 	if s.nowFuncOverride != nil {
-		return s.nowFuncOverride()
+		now = s.nowFuncOverride
 	}
 
-	return NowFunc()
+	if location, ok := s.timeZone(); ok {
+		return now().In(location)
+	}
+	return now()
 }
 
 // BlockGlobalUpdate if true, generates an error on update/delete without where clause.
@@ -202,6 +221,24 @@ func (s *DB) SingularTable(enable bool) {
 	s.parent.singularTable = enable
 }
 
+// SetTablePrefix set the prefix of table names of models and join tables, e.g. `app1_` for `app1_users`,
+// models could override it by implementing `TablePrefix() string`, tables named with `TableName()` or `Table`
+// are not prefixed
+func (s *DB) SetTablePrefix(prefix string) {
+	s.parent.Lock()
+	defer s.parent.Unlock()
+	s.parent.tablePrefix = prefix
+}
+
+func (s *DB) getTablePrefix() string {
+	if s == nil || s.parent == nil {
+		return ""
+	}
+	s.parent.RLock()
+	defer s.parent.RUnlock()
+	return s.parent.tablePrefix
+}
+
 // NewScope create a scope for current operation
 func (s *DB) NewScope(value interface{}) *Scope {
 	dbClone := s.clone()
@@ -215,6 +252,72 @@ func (s *DB) NewScope(value interface{}) *Scope {
 	return scope
 }
 
+// newQueryScope create a scope to query records into out, if out is a partial struct of the model set with `Model`,
+// query the model's table and only select columns of out
+func (s *DB) newQueryScope(out interface{}) *Scope {
+	scope := s.NewScope(out)
+	if s.search == nil || s.search.model == nil || s.search.tableName != "" {
+		return scope
+	}
+
+	var (
+		modelScope  = s.NewScope(s.search.model)
+		modelStruct = modelScope.GetModelStruct()
+		destStruct  = scope.GetModelStruct()
+		columns     []string
+	)
+
+	if modelStruct.ModelType == nil {
+		return scope
+	}
+
+	// query maps from the model's table
+	if isMapDestination(out) {
+		scope.Search.Table(modelScope.TableName())
+		if !scope.Search.Unscoped {
+			if query, args, ok := modelScope.softDeleteQuery(); ok {
+				scope.Search.Where(query, args...)
+			}
+		}
+		return scope
+	}
+
+	if destStruct.ModelType == nil || modelStruct.ModelType == destStruct.ModelType {
+		return scope
+	}
+
+	for _, field := range destStruct.StructFields {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+
+		if modelField, ok := modelScope.FieldByName(field.DBName); !ok || !modelField.IsNormal {
+			return scope
+		}
+		columns = append(columns, fmt.Sprintf("%v.%v", modelScope.QuotedTableName(), scope.Quote(field.DBName)))
+	}
+
+	if len(columns) == 0 {
+		return scope
+	}
+
+	scope.Search.Table(modelScope.TableName())
+	if len(scope.Search.selects) == 0 {
+		scope.Search.Select(strings.Join(columns, ", "))
+	}
+
+	// keep model's soft delete scope if out doesn't have the soft delete field
+	if !scope.Search.Unscoped {
+		if field, _, ok := modelScope.softDeleteField(); ok {
+			if _, ok := scope.FieldByName(field.DBName); !ok {
+				query, args, _ := modelScope.softDeleteQuery()
+				scope.Search.Where(query, args...)
+			}
+		}
+	}
+	return scope
+}
+
 // QueryExpr returns the query as SqlExpr object
 func (s *DB) QueryExpr() *SqlExpr {
 	scope := s.NewScope(s.Value)
@@ -234,6 +337,11 @@ func (s *DB) SubQuery() *SqlExpr {
 }
 
 // Where return a new relation, filter records with given conditions, accepts `map`, `struct` or `string` as conditions, refer http://jinzhu.github.io/gorm/crud.html#query
+// The number of arguments should match placeholders `?` of string conditions, otherwise ErrInvalidSQL is set to Error,
+// placeholders in quoted strings aren't counted.
+// Blank fields of struct conditions are ignored, conditions could be built with specified fields, including blank ones
+//     db.Where(&User{Name: "jinzhu", Age: 0}, "Name", "Age").Find(&users)
+//     // SELECT * FROM users WHERE name = 'jinzhu' AND age = 0
 func (s *DB) Where(query interface{}, args ...interface{}) *DB {
 	return s.clone().search.Where(query, args...).db
 }
@@ -267,12 +375,16 @@ func (s *DB) Order(value interface{}, reorder ...bool) *DB {
 }
 
 // Select specify fields that you want to retrieve from database when querying, by default, will select all fields;
-// When creating/updating, specify fields that you want to save to database
+// When creating/updating, specify fields that you want to save to database, fields of associations could be selected
+// with nested paths
+//     db.Select("Name", "Company.Name").Save(&user)
 func (s *DB) Select(query interface{}, args ...interface{}) *DB {
 	return s.clone().search.Select(query, args...).db
 }
 
-// Omit specify fields that you want to ignore when saving to database for creating, updating
+// Omit specify fields that you want to ignore when saving to database for creating, updating, fields of associations
+// could be omitted with nested paths
+//     db.Omit("Orders.PaymentToken").Save(&user)
 func (s *DB) Omit(columns ...string) *DB {
 	return s.clone().search.Omit(columns...).db
 }
@@ -289,6 +401,8 @@ func (s *DB) Having(query interface{}, values ...interface{}) *DB {
 
 // Joins specify Joins conditions
 //     db.Joins("JOIN emails ON emails.user_id = users.id AND emails.email = ?", "jinzhu@example.org").Find(&user)
+// Belongs to and has one associations could be joined by name, their columns are loaded in the same query
+//     db.Joins("CreditCard").Where(`"CreditCard".number = ?`, "411111111111").Find(&users)
 func (s *DB) Joins(query string, args ...interface{}) *DB {
 	return s.clone().search.Joins(query, args...).db
 }
@@ -330,31 +444,35 @@ func (s *DB) Assign(attrs ...interface{}) *DB {
 
 // First find first record that match given conditions, order by primary key
 func (s *DB) First(out interface{}, where ...interface{}) *DB {
-	newScope := s.NewScope(out)
+	newScope := s.newQueryScope(out)
 	newScope.Search.Limit(1)
 
 	return newScope.Set("gorm:order_by_primary_key", "ASC").
 		inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
 }
 
-// Take return a record that match given conditions, the order will depend on the database implementation
+// Take return a record that match given conditions without ordering, the order will depend on the database
+// implementation, ErrRecordNotFound is returned if no record matches, refer `ErrorOnNotFound`
 func (s *DB) Take(out interface{}, where ...interface{}) *DB {
-	newScope := s.NewScope(out)
+	newScope := s.newQueryScope(out)
 	newScope.Search.Limit(1)
 	return newScope.inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
 }
 
 // Last find last record that match given conditions, order by primary key
 func (s *DB) Last(out interface{}, where ...interface{}) *DB {
-	newScope := s.NewScope(out)
+	newScope := s.newQueryScope(out)
 	newScope.Search.Limit(1)
 	return newScope.Set("gorm:order_by_primary_key", "DESC").
 		inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
 }
 
-// Find find records that match given conditions
+// Find find records that match given conditions, if out is a partial struct of the model set with `Model`,
+// will query the model's table and only select the columns of out, ErrRecordNotFound is only returned if out
+// is a struct, finding no records into slices isn't an error
+//    db.Model(&User{}).Find(&apiUsers) // SELECT users.id, users.name FROM users
 func (s *DB) Find(out interface{}, where ...interface{}) *DB {
-	return s.NewScope(out).inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
+	return s.newQueryScope(out).inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
 }
 
 //Preloads preloads relations, don`t touch out
@@ -399,7 +517,19 @@ func (s *DB) Pluck(column string, value interface{}) *DB {
 	return s.NewScope(s.Value).pluck(column, value).db
 }
 
-// Count get how many records for a model
+// PluckMap query two columns from a model into a map, keyed by values of keyColumn, later rows overwrite values of
+// duplicate keys
+//     var names map[int64]string
+//     db.Model(&User{}).PluckMap("id", "name", &names)
+//     // SELECT id, name FROM "users"
+func (s *DB) PluckMap(keyColumn, valueColumn string, value interface{}) *DB {
+	return s.NewScope(s.Value).pluckMap(keyColumn, valueColumn, value).db
+}
+
+// Count get how many records for a model into value, e.g. `*int64`, orders, limits and offsets are ignored, groups
+// and distinct rows selected with `Select("DISTINCT ...")` are counted by wrapping the query as a subquery
+//     db.Model(&User{}).Group("role").Count(&count)
+//     // SELECT count(*) FROM (SELECT 1 FROM "users" GROUP BY role) AS count_table
 func (s *DB) Count(value interface{}) *DB {
 	return s.NewScope(s.Value).count(value).db
 }
@@ -413,7 +543,7 @@ func (s *DB) Related(value interface{}, foreignKeys ...string) *DB {
 // https://jinzhu.github.io/gorm/crud.html#firstorinit
 func (s *DB) FirstOrInit(out interface{}, where ...interface{}) *DB {
 	c := s.clone()
-	if result := c.First(out, where...); result.Error != nil {
+	if result := c.ErrorOnNotFound(true).First(out, where...); result.Error != nil {
 		if !result.RecordNotFound() {
 			return result
 		}
@@ -428,7 +558,7 @@ func (s *DB) FirstOrInit(out interface{}, where ...interface{}) *DB {
 // https://jinzhu.github.io/gorm/crud.html#firstorcreate
 func (s *DB) FirstOrCreate(out interface{}, where ...interface{}) *DB {
 	c := s.clone()
-	if result := s.First(out, where...); result.Error != nil {
+	if result := s.ErrorOnNotFound(true).First(out, where...); result.Error != nil {
 		if !result.RecordNotFound() {
 			return result
 		}
@@ -441,6 +571,8 @@ func (s *DB) FirstOrCreate(out interface{}, where ...interface{}) *DB {
 
 // Update update attributes with callbacks, refer: https://jinzhu.github.io/gorm/crud.html#update
 // WARNING when update with struct, GORM will not update fields that with zero value
+// Values could be queries, which are used as sub queries
+//     db.Model(&user).Update("rank", db.Table("scores").Select("AVG(score)").Where("scores.user_id = users.id"))
 func (s *DB) Update(attrs ...interface{}) *DB {
 	return s.Updates(toSearchableMap(attrs...), true)
 }
@@ -472,7 +604,8 @@ func (s *DB) Save(value interface{}) *DB {
 	scope := s.NewScope(value)
 	if !scope.PrimaryKeyZero() {
 		newDB := scope.callCallbacks(s.parent.callbacks.updates).db
-		if newDB.Error == nil && newDB.RowsAffected == 0 {
+		// tracked records without changes aren't updated, which doesn't mean they don't exist
+		if _, unchanged := scope.InstanceGet("gorm:unchanged"); newDB.Error == nil && newDB.RowsAffected == 0 && !unchanged && !scope.isDryRun() {
 			return s.New().Table(scope.TableName()).FirstOrCreate(value)
 		}
 		return newDB
@@ -480,20 +613,34 @@ func (s *DB) Save(value interface{}) *DB {
 	return scope.callCallbacks(s.parent.callbacks.creates).db
 }
 
-// Create insert the value into database
+// Create insert the value into database, value could also be a map or maps with `Model` or `Table`
+//    db.Model(&User{}).Create(map[string]interface{}{"name": "jinzhu", "age": 18})
 func (s *DB) Create(value interface{}) *DB {
+	switch values := value.(type) {
+	case map[string]interface{}:
+		return s.createFromMaps(values)
+	case []map[string]interface{}:
+		return s.createFromMaps(values...)
+	}
+
 	scope := s.NewScope(value)
 	return scope.callCallbacks(s.parent.callbacks.creates).db
 }
 
 // Delete delete value match given conditions, if the value has primary key, then will including the primary key as condition
-// WARNING If model has DeletedAt field, GORM will only set field DeletedAt's value to current time
+// WARNING If model has DeletedAt field (or a field tagged with `soft_delete`), GORM will only mark the record as deleted
+// Has one, has many associations selected with `Select` are deleted together with the record in the same transaction,
+// relations in join tables of many to many associations are deleted, belongs to associations are kept
+//     db.Select("Emails", "CreditCard").Delete(&user)
+//     db.Select(gorm.Associations).Delete(&user)
 func (s *DB) Delete(value interface{}, where ...interface{}) *DB {
 	return s.NewScope(value).inlineCondition(where...).callCallbacks(s.parent.callbacks.deletes).db
 }
 
 // Raw use raw sql as conditions, won't run it unless invoked by other methods
 //    db.Raw("SELECT name, age FROM users WHERE name = ?", 3).Scan(&result)
+// named arguments are bound with fields of a struct, keys of a map or `sql.NamedArg`s, like conditions of `Where`
+//    db.Raw("SELECT name, age FROM users WHERE name = @Name AND age > @Age", filter).Scan(&result)
 func (s *DB) Raw(sql string, values ...interface{}) *DB {
 	return s.clone().search.Raw(true).Where(sql, values...).db
 }
@@ -515,9 +662,17 @@ func (s *DB) Exec(sql string, values ...interface{}) *DB {
 func (s *DB) Model(value interface{}) *DB {
 	c := s.clone()
 	c.Value = value
+	c.search.model = value
 	return c
 }
 
+// Schema qualify table names with the schema (database for mysql) for operations of the returned DB, including migrations
+//    db.Schema("tenant_42").Find(&users)
+//    // SELECT * FROM "tenant_42"."users"
+func (s *DB) Schema(name string) *DB {
+	return s.Set("gorm:schema", name)
+}
+
 // Table specify the table you would like to run db operations
 func (s *DB) Table(name string) *DB {
 	clone := s.clone()
@@ -526,6 +681,16 @@ func (s *DB) Table(name string) *DB {
 	return clone
 }
 
+// Alias refer the table of queries with the alias, so the table could be joined with itself, the DB could also be
+// passed to `Joins` to join the model's table with an alias, names are quoted for the dialect
+//     db.Model(&User{}).Alias("u").Select("u.name, managers.name").
+//       Joins("LEFT JOIN ? ON managers.id = u.manager_id", db.Model(&User{}).Alias("managers")).Rows()
+//     // SELECT u.name, managers.name FROM "users" AS "u" LEFT JOIN "users" AS "managers" ON managers.id = u.manager_id
+// aliases are only used by queries, records are created, updated and deleted with the table name
+func (s *DB) Alias(name string) *DB {
+	return s.clone().search.Alias(name).db
+}
+
 // Debug start debug mode
 func (s *DB) Debug() *DB {
 	return s.clone().LogMode(true)
@@ -533,14 +698,53 @@ func (s *DB) Debug() *DB {
 
 // Transaction start a transaction as a block,
 // return error will rollback, otherwise to commit.
-func (s *DB) Transaction(fc func(tx *DB) error) (err error) {
-
-	if _, ok := s.db.(*sql.Tx); ok {
+// Transactions failed with deadlocks or serialization errors are retried with the policy set with `SetRetryPolicy`,
+// with CockroachDB, they are retried by default.
+// The transaction is started with options if given, options are ignored if it is already in a transaction
+//     db.Transaction(func(tx *gorm.DB) error {
+//       return tx.Model(&account).Update("balance", gorm.Expr("balance - ?", amount)).Error
+//     }, &sql.TxOptions{Isolation: sql.LevelSerializable})
+func (s *DB) Transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err error) {
+	switch s.db.(type) {
+	case *sql.Tx, *twoPhaseConn:
 		return fc(s)
 	}
 
+	var opt *sql.TxOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if policy, ok := s.retryPolicy(); ok {
+		for attempt := 1; ; attempt++ {
+			if err = s.transaction(fc, opt); !policy.shouldRetry(s.Context(), err, attempt) {
+				return
+			}
+		}
+	}
+
+	// retry the transaction if the dialect asks to, e.g. serialization errors of CockroachDB
+	retrier, retryable := s.Dialect().(transactionRetrier)
+	for attempt := 0; ; attempt++ {
+		if err = s.transaction(fc, opt); !retryable || !retrier.shouldRetryTransaction(err, attempt) {
+			return
+		}
+	}
+}
+
+// ReadOnlyTransaction start a read only transaction as a block, like `Transaction`
+func (s *DB) ReadOnlyTransaction(fc func(tx *DB) error) error {
+	return s.Transaction(fc, &sql.TxOptions{ReadOnly: true})
+}
+
+func (s *DB) transaction(fc func(tx *DB) error, opts *sql.TxOptions) (err error) {
+	tx := s.BeginTx(s.Context(), opts)
+	if tx.Error != nil && tx.Error != s.Error {
+		// the transaction failed to start or hooks of its connection failed
+		return tx.Error
+	}
+
 	panicked := true
-	tx := s.Begin()
 	defer func() {
 		// Make sure to rollback when panic, Block error or Commit error
 		if panicked || err != nil {
@@ -558,19 +762,25 @@ func (s *DB) Transaction(fc func(tx *DB) error) (err error) {
 	return
 }
 
-// Begin begins a transaction
+// Begin begins a transaction with the context set with `WithContext`
 func (s *DB) Begin() *DB {
-	return s.BeginTx(context.Background(), &sql.TxOptions{})
+	return s.BeginTx(s.Context(), &sql.TxOptions{})
 }
 
 // BeginTx begins a transaction with options
 func (s *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) *DB {
 	c := s.clone()
 	if db, ok := c.db.(sqlDb); ok && db != nil {
+		_, pinned := db.(*pinnedConn)
 		tx, err := db.BeginTx(ctx, opts)
 		c.db = interface{}(tx).(SQLCommon)
 
 		c.dialect.SetDB(c.db)
+		if err == nil && !pinned {
+			if err = c.runConnectionHooks(); err != nil {
+				tx.Rollback()
+			}
+		}
 		c.AddError(err)
 	} else {
 		c.AddError(ErrCantStartTransaction)
@@ -624,6 +834,18 @@ func (s *DB) NewRecord(value interface{}) bool {
 	return s.NewScope(value).PrimaryKeyZero()
 }
 
+// ErrorOnNotFound set whether queries of single records with `First`, `Take`, `Last` and `Find` into structs
+// return ErrRecordNotFound if no record matches, it is enabled by default, if disabled the record is left unchanged,
+// so it is zero for new variables, and `RowsAffected` is 0
+//     db = db.ErrorOnNotFound(false)
+//     if err := db.First(&user, id).Error; err != nil { // errors of the query only
+//     }
+//     if user.ID == 0 { // not found
+//     }
+func (s *DB) ErrorOnNotFound(enable bool) *DB {
+	return s.Set("gorm:error_on_not_found", enable)
+}
+
 // RecordNotFound check if returning ErrRecordNotFound error
 func (s *DB) RecordNotFound() bool {
 	for _, err := range s.GetErrors() {
@@ -685,12 +907,22 @@ func (s *DB) HasTable(value interface{}) bool {
 	return has
 }
 
-// AutoMigrate run auto migration for given models, will only add missing fields, won't delete/change current data
+// AutoMigrate run auto migration for given models, will only add missing fields, won't delete/change current data,
+// unless unused columns and indexes are dropped with option `WithDropUnused`,
+// migrations are serialized with the lock set with `WithMigrationLock`
 func (s *DB) AutoMigrate(values ...interface{}) *DB {
 	db := s.Unscoped()
-	for _, value := range values {
-		db = db.NewScope(value).autoMigrate().db
+	unlock, err := db.lockMigration()
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	models, option := migrateOptions(values)
+	for _, value := range models {
+		db = db.NewScope(value).autoMigrate().dropUnused(option).db
 	}
+	db.AddError(unlock())
 	return db
 }
 
@@ -731,6 +963,8 @@ func (s *DB) RemoveIndex(indexName string) *DB {
 
 // AddForeignKey Add foreign key to the given scope, e.g:
 //     db.Model(&User{}).AddForeignKey("city_id", "cities(id)", "RESTRICT", "RESTRICT")
+// composite foreign keys are added with comma separated columns:
+//     db.Model(&OrderLine{}).AddForeignKey("company_code,order_no", "orders(company_code,order_no)", "CASCADE", "CASCADE")
 func (s *DB) AddForeignKey(field string, dest string, onDelete string, onUpdate string) *DB {
 	scope := s.NewScope(s.Value)
 	scope.addForeignKey(field, dest, onDelete, onUpdate)
@@ -754,7 +988,7 @@ func (s *DB) Association(column string) *Association {
 		err = errors.New("primary key can't be nil")
 	} else {
 		if field, ok := scope.FieldByName(column); ok {
-			if field.Relationship == nil || len(field.Relationship.ForeignFieldNames) == 0 {
+			if field.Relationship == nil || (len(field.Relationship.ForeignFieldNames) == 0 && field.Relationship.Through == "") {
 				err = fmt.Errorf("invalid association %v for %v", column, scope.IndirectValue().Type())
 			} else {
 				return &Association{scope: scope, column: column, field: field}
@@ -773,6 +1007,14 @@ func (s *DB) Preload(column string, conditions ...interface{}) *DB {
 	return s.clone().search.Preload(column, conditions...).db
 }
 
+// PreloadAll preload all associations up to the given depth, e.g. depth 2 equals to `Preload("*.*")`
+func (s *DB) PreloadAll(depth int, conditions ...interface{}) *DB {
+	if depth < 1 {
+		return s
+	}
+	return s.Preload(strings.TrimSuffix(strings.Repeat(Associations+".", depth), "."), conditions...)
+}
+
 // Set set setting by name, which could be used in callbacks, will clone a new db, and update its setting
 func (s *DB) Set(name string, value interface{}) *DB {
 	return s.clone().InstantSet(name, value)
@@ -811,6 +1053,10 @@ func (s *DB) SetJoinTableHandler(source interface{}, column string, handler Join
 // AddError add error to the db
 func (s *DB) AddError(err error) error {
 	if err != nil {
+		if s.dialect != nil {
+			err = translateError(s.dialect, err)
+		}
+
 		if err != ErrRecordNotFound {
 			if s.logMode == defaultLogMode {
 				go s.print("error", fileWithLineNum(), err)
@@ -853,7 +1099,7 @@ func (s *DB) clone() *DB {
 		Value:             s.Value,
 		Error:             s.Error,
 		blockGlobalUpdate: s.blockGlobalUpdate,
-		dialect:           newDialect(s.dialect.GetName(), s.db),
+		dialect:           cloneDialect(s.dialect, s.db),
 		nowFuncOverride:   s.nowFuncOverride,
 	}
 
@@ -883,7 +1129,22 @@ func (s *DB) log(v ...interface{}) {
 }
 
 func (s *DB) slog(sql string, t time.Time, vars ...interface{}) {
-	if s.logMode == detailedLogMode {
-		s.print("sql", fileWithLineNum(), NowFunc().Sub(t), sql, vars, s.RowsAffected)
+	duration := NowFunc().Sub(t)
+	threshold, slow := s.slowThreshold()
+	if slow = slow && duration >= threshold && s.logMode != noLogMode; !slow && s.logMode != detailedLogMode {
+		return
+	}
+
+	// print SQL with values, the statement has been executed with bind vars
+	if _, ok := s.Get("gorm:interpolate_log_sql"); ok {
+		if interpolatedSQL, err := interpolateSQL(s.Dialect(), sql, vars); err == nil {
+			sql, vars = interpolatedSQL, []interface{}{}
+		}
+	}
+
+	if slow {
+		s.print("slow sql", fileWithLineNum(), duration, sql, vars, s.RowsAffected, threshold)
+	} else {
+		s.print("sql", fileWithLineNum(), duration, sql, vars, s.RowsAffected)
 	}
 }
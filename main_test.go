@@ -27,7 +27,7 @@ import (
 	_ "github.com/zanmato/gorm/dialects/mssql"
 	_ "github.com/zanmato/gorm/dialects/mysql"
 	"github.com/zanmato/gorm/dialects/postgres"
-	_ "github.com/zanmato/gorm/dialects/sqlite"
+	"github.com/zanmato/gorm/dialects/sqlite"
 )
 
 var (
@@ -60,6 +60,12 @@ func OpenTestConnection() (db *gorm.DB, err error) {
 			dbDSN = "user=gorm password=gorm dbname=gorm port=9920 sslmode=disable"
 		}
 		db, err = gorm.Open("postgres", dbDSN)
+	case "cockroach":
+		fmt.Println("testing cockroach...")
+		if dbDSN == "" {
+			dbDSN = "postgresql://root@localhost:9940/defaultdb?sslmode=disable"
+		}
+		db, err = gorm.Open("cockroach", "postgres", dbDSN)
 	case "mssql":
 		// CREATE LOGIN gorm WITH PASSWORD = 'LoremIpsum86';
 		// CREATE DATABASE gorm;
@@ -73,7 +79,7 @@ func OpenTestConnection() (db *gorm.DB, err error) {
 		db, err = gorm.Open("mssql", dbDSN)
 	default:
 		fmt.Println("testing sqlite3...")
-		db, err = gorm.Open("sqlite3", filepath.Join(os.TempDir(), "gorm.db"))
+		db, err = gorm.Open("sqlite3", sqlite.DriverName, filepath.Join(os.TempDir(), "gorm.db"))
 	}
 
 	// db.SetLogger(Logger{log.New(os.Stdout, "\r\n", 0)})
@@ -558,6 +564,39 @@ func TestTransaction_NoErrorOnRollbackAfterCommit(t *testing.T) {
 	}
 }
 
+func TestTransactionWithOptions(t *testing.T) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(&User{Name: "transaction-options"}).Error
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+
+	if err != nil {
+		t.Errorf("No error should raise in transaction with options, got %v", err)
+	}
+
+	err = DB.ReadOnlyTransaction(func(tx *gorm.DB) error {
+		if err := tx.First(&User{}, "name = ?", "transaction-options").Error; err != nil {
+			t.Errorf("Should find committed record in read only transaction")
+		}
+
+		if _, ok := tx.CommonDB().(*sql.Tx); !ok {
+			t.Errorf("Read only transaction should be a transaction")
+		}
+
+		switch dialect := os.Getenv("GORM_DIALECT"); dialect {
+		case "", "sqlite", "mssql":
+		default:
+			if err := tx.Save(&User{Name: "transaction-options-2"}).Error; err == nil {
+				t.Errorf("Error should have been raised in a read only transaction")
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("No error should raise in read only transaction, got %v", err)
+	}
+}
+
 func TestTransactionReadonly(t *testing.T) {
 	dialect := os.Getenv("GORM_DIALECT")
 	if dialect == "" {
@@ -592,6 +631,82 @@ func TestTransactionReadonly(t *testing.T) {
 	tx.Rollback()
 }
 
+func TestConnectionHooks(t *testing.T) {
+	var calls []string
+	db := DB.OnConnection(func(tx *gorm.DB) error {
+		if _, ok := tx.CommonDB().(*sql.Tx); ok {
+			calls = append(calls, "transaction")
+		} else {
+			calls = append(calls, "connection")
+		}
+		return nil
+	})
+
+	db.Transaction(func(tx *gorm.DB) error { return nil })
+	if err := db.Save(&User{Name: "connection_hooks"}).Error; err != nil {
+		t.Errorf("No error should happen when saving with hooks, got %v", err)
+	}
+
+	if !reflect.DeepEqual(calls, []string{"transaction", "transaction"}) {
+		t.Errorf("Hooks should run when transactions are started, got %v", calls)
+	}
+
+	calls = nil
+	var users []User
+	db.Find(&users)
+	if len(calls) != 0 {
+		t.Errorf("Hooks shouldn't run with pooled connections, got %v", calls)
+	}
+
+	session := db.Session(&gorm.Session{ConnectionHooks: []func(tx *gorm.DB) error{
+		func(tx *gorm.DB) error {
+			calls = append(calls, "session")
+			return tx.Exec("CREATE TEMPORARY TABLE connection_hooks (id int)").Error
+		},
+	}})
+
+	err := session.Connection(func(conn *gorm.DB) error {
+		defer conn.Exec("DROP TABLE connection_hooks")
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("INSERT INTO connection_hooks (id) VALUES (1)").Error; err != nil {
+				return err
+			}
+
+			var count int
+			return tx.Table("connection_hooks").Count(&count).Error
+		})
+	})
+
+	if err != nil {
+		t.Errorf("Statements should be executed with the connection of hooks, got %v", err)
+	}
+
+	if !reflect.DeepEqual(calls, []string{"connection", "session"}) {
+		t.Errorf("Hooks should run once when the connection is acquired, got %v", calls)
+	}
+
+	failing := DB.OnConnection(func(tx *gorm.DB) error {
+		return errors.New("role denied")
+	})
+
+	err = failing.Transaction(func(tx *gorm.DB) error {
+		t.Errorf("Transaction shouldn't run if hooks failed")
+		return nil
+	})
+	if err == nil || err.Error() != "role denied" {
+		t.Errorf("Should return error of hooks, got %v", err)
+	}
+
+	if err := failing.Save(&User{Name: "connection_hooks_failed"}).Error; err == nil {
+		t.Errorf("Should return error of hooks when saving")
+	}
+
+	if !DB.Where("name = ?", "connection_hooks_failed").First(&User{}).RecordNotFound() {
+		t.Errorf("Records shouldn't be saved if hooks failed")
+	}
+}
+
 func TestRow(t *testing.T) {
 	user1 := User{Name: "RowUser1", Age: 1, Birthday: parseTime("2000-1-1")}
 	user2 := User{Name: "RowUser2", Age: 10, Birthday: parseTime("2010-1-1")}
@@ -777,6 +892,62 @@ func TestJoins(t *testing.T) {
 	}
 }
 
+func TestJoinsAssociation(t *testing.T) {
+	user1 := User{Name: "joins_association1", CreditCard: CreditCard{Number: "433333333333"}, Company: Company{Name: "joins_company"}}
+	user2 := User{Name: "joins_association2"}
+	DB.Save(&user1).Save(&user2)
+
+	var users []User
+	if err := DB.Joins("CreditCard").Joins("Company").Where("users.name IN (?)", []string{user1.Name, user2.Name}).Order("users.id").Find(&users).Error; err != nil {
+		t.Fatalf("Failed to find users with joined associations, got %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("Should find two users, but got %v", len(users))
+	}
+
+	if users[0].CreditCard.ID != user1.CreditCard.ID || users[0].CreditCard.Number != "433333333333" {
+		t.Errorf("Has one association should be loaded with joins, got %#v", users[0].CreditCard)
+	}
+
+	if users[0].Company.Id != user1.Company.Id || users[0].Company.Name != "joins_company" || users[0].Name != user1.Name {
+		t.Errorf("Belongs to association should be loaded with joins, got %#v", users[0].Company)
+	}
+
+	if users[1].CreditCard.ID != 0 || users[1].Company.Id != 0 || users[1].Name != user2.Name {
+		t.Errorf("Associations without records should be blank, got %#v, %#v", users[1].CreditCard, users[1].Company)
+	}
+
+	DB.Delete(&user1.CreditCard)
+	var user User
+	DB.Joins("CreditCard").First(&user, user1.Id)
+	if user.CreditCard.ID != 0 || user.Name != user1.Name {
+		t.Errorf("Soft deleted association shouldn't be loaded with joins, got %#v", user.CreditCard)
+	}
+}
+
+func TestJoinsAssociationWithNestedSelect(t *testing.T) {
+	user := User{Name: "joins_nested_select", Age: 18, CreditCard: CreditCard{Number: "455555555555"}, Company: Company{Name: "joins_nested_company"}}
+	DB.Save(&user)
+
+	var result User
+	if err := DB.Select("Name", "Company.Name", "CreditCard").Omit("CreditCard.Number").Joins("Company").Joins("CreditCard").First(&result, user.Id).Error; err != nil {
+		t.Fatalf("Failed to find user with nested selects of joined associations, got %v", err)
+	}
+
+	if result.Name != user.Name || result.Age != 0 {
+		t.Errorf("Only selected fields should be loaded, got %v, %v", result.Name, result.Age)
+	}
+
+	if result.Company.Id != user.Company.Id || result.Company.Name != "joins_nested_company" {
+		t.Errorf("Selected nested fields of joined association should be loaded, got %#v", result.Company)
+	}
+
+	if result.CreditCard.ID != user.CreditCard.ID || result.CreditCard.Number != "" {
+		t.Errorf("Omitted nested fields of joined association shouldn't be loaded, got %#v", result.CreditCard)
+	}
+}
+
 type JoinedIds struct {
 	UserID           int64 `gorm:"column:id"`
 	BillingAddressID int64 `gorm:"column:id"`
@@ -1134,6 +1305,27 @@ func TestOpenWithOneParameter(t *testing.T) {
 	}
 }
 
+func TestOpenWithSqliteDialectName(t *testing.T) {
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("the sqlite dialect name is tested with sqlite")
+	}
+
+	// cgo-free drivers are opened with the dialect name `sqlite`, it is tested with the driver of the build here
+	db, err := gorm.Open("sqlite", sqlite.DriverName, filepath.Join(os.TempDir(), "gorm.db"))
+	if err != nil {
+		t.Fatalf("Failed to open with the sqlite dialect, got %v", err)
+	}
+	defer db.Close()
+
+	if name := db.Dialect().GetName(); name != "sqlite3" {
+		t.Errorf("Should use the sqlite3 dialect, got %v", name)
+	}
+
+	if !db.HasTable(&User{}) {
+		t.Errorf("Should query with the sqlite3 dialect")
+	}
+}
+
 func TestSaveAssociations(t *testing.T) {
 	db := DB.New()
 	deltaAddressCount := 0
@@ -1392,6 +1584,51 @@ func TestQueryHint2(t *testing.T) {
 	}
 }
 
+type HintedUser struct {
+	ID   uint
+	Name string `gorm:"index:idx_hinted_users_name"`
+}
+
+func TestHints(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&HintedUser{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&HintedUser{})
+	DB.Create(&HintedUser{Name: "hinted"})
+
+	tx := DB.Session(&gorm.Session{DryRun: true})
+	sql, _ := tx.Hints(gorm.OptimizerHint("MAX_EXECUTION_TIME(1000)"), gorm.OptimizerHint("NO_ICP(users)")).Find(&[]HintedUser{}).DryRunSQL()
+	if !strings.HasPrefix(sql, "SELECT /*+ MAX_EXECUTION_TIME(1000) NO_ICP(users) */ * FROM") {
+		t.Errorf("Optimizer hints should be placed after SELECT, got %v", sql)
+	}
+
+	db := DB.Hints(gorm.UseIndex("idx_hinted_users_name"), gorm.TableHint("NOLOCK"))
+	sql, _ = db.Session(&gorm.Session{DryRun: true}).Where("name = ?", "hinted").Find(&[]HintedUser{}).DryRunSQL()
+
+	switch DB.Dialect().GetName() {
+	case "mysql":
+		if !strings.Contains(sql, "FROM `hinted_users` USE INDEX (`idx_hinted_users_name`)") {
+			t.Errorf("Index hints should be placed after the table, got %v", sql)
+		}
+	case "sqlite3":
+		if !strings.Contains(sql, `FROM "hinted_users" INDEXED BY "idx_hinted_users_name"`) {
+			t.Errorf("Index hints should be placed after the table, got %v", sql)
+		}
+	case "mssql":
+		if !strings.Contains(sql, "WITH (NOLOCK)") {
+			t.Errorf("Table hints should be placed after the table, got %v", sql)
+		}
+	}
+
+	var users []HintedUser
+	if err := db.Where("name = ?", "hinted").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Should find records with hints, got %v, %v", users, err)
+	}
+
+	var count int
+	if err := db.Model(&HintedUser{}).Where("name = ?", "hinted").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("Should count records with hints, got %v, %v", count, err)
+	}
+}
+
 func TestFloatColumnPrecision(t *testing.T) {
 	if dialect := os.Getenv("GORM_DIALECT"); dialect != "mysql" && dialect != "sqlite" {
 		t.Skip()
@@ -1477,3 +1714,276 @@ func parseTime(str string) *time.Time {
 	t := now.New(time.Now().UTC()).MustParse(str)
 	return &t
 }
+
+type serializationError struct{}
+
+func (serializationError) Error() string {
+	return "restart transaction: TransactionRetryWithProtoRefreshError"
+}
+func (serializationError) SQLState() string { return "40001" }
+
+func TestCockroachTransactionRetry(t *testing.T) {
+	db, err := gorm.Open("cockroach", DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open cockroach dialect, got %v", err)
+	}
+
+	var attempts int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if attempts++; attempts < 3 {
+			return serializationError{}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Should retry transaction failed with serialization error, but got %v after %v attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		attempts++
+		return errors.New("other error")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Should not retry transaction failed with other errors, but got %v after %v attempts", err, attempts)
+	}
+
+	attempts = 0
+	DB.Transaction(func(tx *gorm.DB) error {
+		attempts++
+		return serializationError{}
+	})
+	if attempts != 1 {
+		t.Errorf("Should not retry transaction for other dialects, but got %v attempts", attempts)
+	}
+}
+
+func TestCockroachDialect(t *testing.T) {
+	db, err := gorm.Open("cockroach", DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open cockroach dialect, got %v", err)
+	}
+
+	field, _ := db.NewScope(&User{}).FieldByName("Id")
+	if sqlType := db.Dialect().DataTypeOf(field.StructField); sqlType != "INT8 DEFAULT unique_rowid()" {
+		t.Errorf("Should use unique_rowid() for auto increment primary key, but got %v", sqlType)
+	}
+
+	expr := db.AsOfSystemTime("-10s").Table("users").Where("age > ?", 18).QueryExpr()
+	if sql := fmt.Sprint(expr); !strings.Contains(sql, `FROM "users" AS OF SYSTEM TIME '-10s'`) {
+		t.Errorf("Should query as of system time, but got %v", sql)
+	}
+}
+
+// detectedDialect detect settings with the DB it's opened with, like the pgx driver of postgres
+type detectedDialect struct {
+	gorm.Dialect
+	detected bool
+}
+
+func (d *detectedDialect) SetDB(db gorm.SQLCommon) {
+	if _, ok := db.(*sql.DB); ok {
+		d.detected = true
+	}
+}
+
+func TestDialectOfClonesAndTransactions(t *testing.T) {
+	gorm.RegisterDialect("detected_dialect", &detectedDialect{Dialect: DB.Dialect()})
+	db, err := gorm.Open("detected_dialect", DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open detected dialect, got %v", err)
+	}
+
+	tx := db.Table("users").Begin()
+	defer tx.Rollback()
+	for _, d := range []gorm.Dialect{db.Table("users").Dialect(), tx.Dialect(), tx.Table("users").Dialect()} {
+		if dialect, ok := d.(*detectedDialect); !ok || !dialect.detected {
+			t.Errorf("Dialects of clones and transactions should keep detected settings, got %#v", d)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var users []User
+	if err := DB.Timeout(time.Minute).Where("name = ?", "jinzhu").Find(&users).Error; err != nil {
+		t.Errorf("Should query with timeout, got %v", err)
+	}
+
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("slow query is only written for sqlite")
+	}
+
+	var count int
+	err := DB.Timeout(20 * time.Millisecond).Raw("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT count(*) FROM c").Row().Scan(&count)
+	if err == nil {
+		t.Errorf("Should cancel the query exceeding timeout")
+	}
+
+	if err := DB.Timeout(20 * time.Millisecond).Exec("UPDATE users SET age = age WHERE id IN (WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT x FROM c)").Error; err == nil {
+		t.Errorf("Should cancel the statement exceeding timeout")
+	}
+}
+
+// printedLogger record values printed by gorm
+type printedLogger struct {
+	values [][]interface{}
+}
+
+func (logger *printedLogger) Print(values ...interface{}) {
+	logger.values = append(logger.values, values)
+}
+
+func TestSlowThreshold(t *testing.T) {
+	logger := &printedLogger{}
+	tx := DB.Session(&gorm.Session{Logger: logger, SlowThreshold: time.Hour})
+	if tx.Where("name = ?", "jinzhu").Find(&[]User{}); len(logger.values) != 0 {
+		t.Errorf("Shouldn't log statements faster than threshold, got %v", logger.values)
+	}
+
+	if tx.SlowThreshold(time.Nanosecond).Where("name = ?", "jinzhu").Find(&[]User{}); len(logger.values) != 1 {
+		t.Fatalf("Should log slow statements, got %v", logger.values)
+	}
+
+	values := logger.values[0]
+	if values[0] != "slow sql" || !strings.Contains(values[3].(string), "name =") || values[6] != time.Nanosecond {
+		t.Errorf("Wrong values of slow statements, got %v", values)
+	}
+
+	if source := values[1].(string); !strings.Contains(source, "main_test.go:") {
+		t.Errorf("Should log the caller of slow statements, got %v", source)
+	}
+
+	if message := fmt.Sprint(gorm.LogFormatter(values...)...); !strings.Contains(message, "[WARN] slow sql >= 1ns") {
+		t.Errorf("Should format slow statements as warnings, got %v", message)
+	}
+
+	logger.values = nil
+	if tx.LogMode(false).SlowThreshold(time.Nanosecond).Find(&[]User{}); len(logger.values) != 0 {
+		t.Errorf("Shouldn't log slow statements if logs are disabled, got %v", logger.values)
+	}
+}
+
+func TestInterpolateLogSQL(t *testing.T) {
+	logger := &printedLogger{}
+	tx := DB.Session(&gorm.Session{Logger: logger, LogMode: &[]bool{true}[0], InterpolateLogSQL: true})
+	tx.Where("name = ? AND age > ? AND email <> '?'", "it's", 10).Find(&[]User{})
+
+	if len(logger.values) != 1 {
+		t.Fatalf("Should log the statement, got %v", logger.values)
+	}
+
+	if sql := logger.values[0][3].(string); !strings.Contains(sql, "name = 'it''s' AND age > 10 AND email <> '?'") {
+		t.Errorf("Should log SQL with values, got %v", sql)
+	}
+
+	if vars := logger.values[0][4].([]interface{}); len(vars) != 0 {
+		t.Errorf("Values should be interpolated, got %v", vars)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	var users []User
+	if err := DB.WithContext(context.Background()).Where("name = ?", "jinzhu").Find(&users).Error; err != nil {
+		t.Errorf("Should query with context, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := DB.WithContext(ctx).Where("name = ?", "jinzhu").Find(&users).Error; err != context.Canceled {
+		t.Errorf("Should cancel statements with the context, got %v", err)
+	}
+
+	if err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error { return nil }); err != context.Canceled {
+		t.Errorf("Transactions should be started with the context, got %v", err)
+	}
+
+	if DB.WithContext(ctx).Context() != ctx || DB.Context() != context.Background() {
+		t.Errorf("Context should be the one set with WithContext")
+	}
+}
+
+// recordingConn record statements executed with it
+type recordingConn struct {
+	gorm.SQLCommon
+	statements []string
+}
+
+func (conn *recordingConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	conn.statements = append(conn.statements, query)
+	return conn.SQLCommon.Exec(query, args...)
+}
+
+func (conn *recordingConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	conn.statements = append(conn.statements, query)
+	return conn.SQLCommon.Query(query, args...)
+}
+
+func (conn *recordingConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	conn.statements = append(conn.statements, query)
+	return conn.SQLCommon.QueryRow(query, args...)
+}
+
+type routeKey struct{}
+
+func TestSQLCommenter(t *testing.T) {
+	conn := &recordingConn{SQLCommon: DB.CommonDB()}
+	db, err := gorm.Open(DB.Dialect().GetName(), conn)
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+
+	db = db.WithSQLCommenter(&gorm.SQLCommenter{
+		Application: "gorm test",
+		Tags: func(ctx context.Context) map[string]string {
+			route, _ := ctx.Value(routeKey{}).(string)
+			return map[string]string{"route": route, "controller": ""}
+		},
+	})
+
+	ctx := context.WithValue(context.Background(), routeKey{}, "/users/:id")
+	var user User
+	db.WithContext(ctx).Where("name = ?", "commenter").First(&user)
+	db.Model(&user).Where("name = ?", "commenter").UpdateColumn("age", 20)
+
+	if len(conn.statements) != 2 {
+		t.Fatalf("Should record executed statements, got %v", conn.statements)
+	}
+
+	if comment := "/*application='gorm%20test',route='%2Fusers%2F%3Aid'*/"; !strings.HasSuffix(conn.statements[0], " "+comment) {
+		t.Errorf("Statements should be tagged with comments, got %v", conn.statements[0])
+	}
+
+	if comment := "/*application='gorm%20test'*/"; !strings.HasSuffix(conn.statements[1], " "+comment) {
+		t.Errorf("Statements without context should be tagged with the application, got %v", conn.statements[1])
+	}
+
+	logger := &printedLogger{}
+	db.Session(&gorm.Session{Logger: logger}).LogMode(true).WithContext(ctx).Where("name = ?", "commenter").Find(&[]User{})
+	if len(logger.values) != 1 || !strings.HasSuffix(logger.values[0][3].(string), " /*application='gorm%20test',route='%2Fusers%2F%3Aid'*/") {
+		t.Errorf("Logged statements should be tagged with comments, got %v", logger.values)
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	for i := 0; i < 100; i++ {
+		DB.Save(&User{Name: "benchmark_find", Age: int64(i)})
+	}
+
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		var users []User
+		DB.Where("name = ?", "benchmark_find").Find(&users)
+	}
+}
+
+func BenchmarkFirst(b *testing.B) {
+	user := User{Name: "benchmark_first"}
+	DB.Save(&user)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		var result User
+		DB.Where("name = ?", "benchmark_first").First(&result, user.Id)
+	}
+}
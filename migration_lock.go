@@ -0,0 +1,74 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// MigrationLocker is implemented by dialects supporting advisory locks, which are used to serialize migrations of
+// app instances started at the same time, see `WithMigrationLock`
+type MigrationLocker interface {
+	// MigrationLockSQL return SQL to acquire the lock with its name as the only bind var, it should block until the
+	// lock is acquired and return 1 if it succeeds, locking is skipped if it is blank
+	MigrationLockSQL() string
+	// MigrationUnlockSQL return SQL to release the lock with its name as the only bind var
+	MigrationUnlockSQL() string
+}
+
+// WithMigrationLock serialize `AutoMigrate` of the returned DB with an advisory lock named name, so app instances
+// started at the same time won't race on DDL, locks are skipped if the dialect isn't a MigrationLocker
+//     db.WithMigrationLock("app_migrations").AutoMigrate(&User{}, &Order{})
+func (s *DB) WithMigrationLock(name string) *DB {
+	return s.Set("gorm:migration_lock", name)
+}
+
+// lockMigration acquire the migration lock if it is set, the lock is held by a dedicated connection of the pool,
+// or the transaction, until unlock is called
+func (s *DB) lockMigration() (unlock func() error, err error) {
+	unlock = func() error { return nil }
+
+	value, _ := s.Get("gorm:migration_lock")
+	name, _ := value.(string)
+	locker, ok := s.Dialect().(MigrationLocker)
+	if name == "" || !ok || locker.MigrationLockSQL() == "" {
+		return unlock, nil
+	}
+
+	var (
+		ctx   = s.Context()
+		scope = s.NewScope(nil)
+		conn  sqlContextCommon
+		close = func() error { return nil }
+	)
+
+	switch db := s.CommonDB().(type) {
+	case *sql.DB:
+		sqlConn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conn, close = sqlConn, sqlConn.Close
+	case sqlContextCommon:
+		conn = db
+	default:
+		return nil, fmt.Errorf("can't acquire migration lock %v with connection %T", name, db)
+	}
+
+	var acquired int64
+	if err = conn.QueryRowContext(ctx, scope.commentSQL(locker.MigrationLockSQL()), name).Scan(&acquired); err == nil && acquired != 1 {
+		err = fmt.Errorf("failed to acquire migration lock %v", name)
+	}
+
+	if err != nil {
+		close()
+		return nil, err
+	}
+
+	// the lock is released even if the context is done, as connections holding it are returned to the pool
+	return func() error {
+		defer close()
+		_, err := conn.ExecContext(context.Background(), scope.commentSQL(locker.MigrationUnlockSQL()), name)
+		return err
+	}, nil
+}
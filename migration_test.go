@@ -8,6 +8,7 @@ import (
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -582,3 +583,426 @@ func TestIndexWithPrefixLength(t *testing.T) {
 		})
 	}
 }
+
+type EnumModel struct {
+	ID     uint
+	Status string `gorm:"type:enum('draft','published','it''s')"`
+}
+
+func TestEnumMigration(t *testing.T) {
+	DB.DropTableIfExists(&EnumModel{})
+	if err := DB.AutoMigrate(&EnumModel{}).Error; err != nil {
+		t.Fatalf("Failed to migrate enum field, got %v", err)
+	}
+
+	for _, status := range []string{"draft", "published", "it's"} {
+		if err := DB.Save(&EnumModel{Status: status}).Error; err != nil {
+			t.Errorf("Should save enum value %v, but got %v", status, err)
+		}
+	}
+
+	if DB.Dialect().GetName() != "mysql" {
+		if err := DB.Save(&EnumModel{Status: "unknown"}).Error; err == nil {
+			t.Errorf("Should not save invalid enum value")
+		}
+	}
+
+	if err := DB.AutoMigrate(&EnumModel{}).Error; err != nil {
+		t.Errorf("Should migrate enum field again, but got %v", err)
+	}
+
+	if DB.Dialect().GetName() == "postgres" {
+		// types of the same name in other schemas aren't the enum type of the table
+		DB.DropTableIfExists(&EnumModel{})
+		DB.Exec("DROP TYPE IF EXISTS enum_models_status_enum")
+		DB.Exec("CREATE SCHEMA IF NOT EXISTS enum_other")
+		DB.Exec("DROP TYPE IF EXISTS enum_other.enum_models_status_enum")
+		DB.Exec("CREATE TYPE enum_other.enum_models_status_enum AS ENUM ('other')")
+		defer DB.Exec("DROP SCHEMA enum_other CASCADE")
+
+		if err := DB.AutoMigrate(&EnumModel{}).Error; err != nil {
+			t.Fatalf("Should create the enum type in the current schema, but got %v", err)
+		}
+
+		if err := DB.Save(&EnumModel{Status: "draft"}).Error; err != nil {
+			t.Errorf("Should save values of the enum type in the current schema, but got %v", err)
+		}
+	}
+}
+
+type CommentedAccount struct {
+	ID   uint
+	Name string `gorm:"comment:name of the account's owner"`
+}
+
+func (CommentedAccount) TableComment() string {
+	return "accounts of customers"
+}
+
+func TestMigrateComments(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&CommentedAccount{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&CommentedAccount{}).Error; err != nil {
+		t.Fatalf("Failed to migrate commented model, got %v", err)
+	}
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&CommentedAccount{}).Error; err != nil {
+		t.Fatalf("Failed to migrate commented model again, got %v", err)
+	}
+
+	if quoted := gorm.QuoteComment("owner's name"); quoted != "'owner''s name'" {
+		t.Errorf("Comment should be quoted, got %v", quoted)
+	}
+
+	if quoted := gorm.QuoteComment("'owner''s name'"); quoted != "'owner''s name'" {
+		t.Errorf("Quoted comment shouldn't be quoted again, got %v", quoted)
+	}
+
+	dialect, _ := gorm.GetDialect("postgres")
+	commenter := dialect.(gorm.Commenter)
+	if sql := commenter.CommentTableSQL("accounts", "customers"); sql != `COMMENT ON TABLE "accounts" IS 'customers'` {
+		t.Errorf("Wrong table comment SQL for postgres, got %v", sql)
+	}
+
+	if sql := commenter.CommentColumnSQL("public.accounts", "name", "owner"); sql != `COMMENT ON COLUMN "public"."accounts"."name" IS 'owner'` {
+		t.Errorf("Wrong column comment SQL for postgres, got %v", sql)
+	}
+
+	dialect, _ = gorm.GetDialect("mysql")
+	if sql := dialect.(gorm.Commenter).CommentTableSQL("accounts", "customers"); sql != "ALTER TABLE `accounts` COMMENT = 'customers'" {
+		t.Errorf("Wrong table comment SQL for mysql, got %v", sql)
+	}
+
+	field, _ := DB.NewScope(&CommentedAccount{}).FieldByName("Name")
+	if dataType := dialect.DataTypeOf(field.StructField); !strings.HasSuffix(dataType, "COMMENT 'name of the account''s owner'") {
+		t.Errorf("Column comments of mysql should be part of column definitions, got %v", dataType)
+	}
+}
+
+type OptionedSetting struct {
+	Key   string `gorm:"primary_key"`
+	Value string
+}
+
+func (OptionedSetting) TableOptions(dialect gorm.Dialect) string {
+	if dialect.GetName() == "sqlite3" {
+		return "WITHOUT ROWID"
+	}
+	return ""
+}
+
+type OptionedFlag struct {
+	Key string `gorm:"primary_key"`
+}
+
+func TestTableOptions(t *testing.T) {
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" {
+		t.Skip("Skipping this because table options are checked with sqlite")
+	}
+
+	tableSQL := func(table string) (sql string) {
+		DB.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Row().Scan(&sql)
+		return
+	}
+
+	DB.Set("gorm:table_options", "").DropTableIfExists(&OptionedSetting{}, &OptionedFlag{})
+	if err := DB.Set("gorm:table_options", "CHARSET=utf8").AutoMigrate(&OptionedSetting{}).Error; err != nil {
+		t.Fatalf("Failed to migrate model with table options, got %v", err)
+	}
+
+	if sql := tableSQL("optioned_settings"); !strings.HasSuffix(sql, "WITHOUT ROWID") {
+		t.Errorf("Table options of model should override the setting, got %v", sql)
+	}
+
+	options := gorm.TableOptions{"mysql": "ENGINE=InnoDB", "sqlite3": "WITHOUT ROWID"}
+	if err := DB.Set("gorm:table_options", options).AutoMigrate(&OptionedFlag{}).Error; err != nil {
+		t.Fatalf("Failed to migrate with table options of dialects, got %v", err)
+	}
+
+	if sql := tableSQL("optioned_flags"); !strings.HasSuffix(sql, "WITHOUT ROWID") || strings.Contains(sql, "InnoDB") {
+		t.Errorf("Table options of the dialect should be used, got %v", sql)
+	}
+
+	DB.Set("gorm:table_options", "").DropTable(&OptionedFlag{})
+	if err := DB.Set("gorm:table_options", gorm.TableOptions{"mysql": "ENGINE=InnoDB"}).AutoMigrate(&OptionedFlag{}).Error; err != nil {
+		t.Errorf("Table options of other dialects should be ignored, got %v", err)
+	}
+}
+
+type ViewedProduct struct {
+	ID       uint
+	Name     string
+	Price    int
+	OnSale   bool
+	Released time.Time
+}
+
+type SaleProduct struct {
+	ViewedProduct
+}
+
+func (SaleProduct) TableName() string {
+	return "sale_products"
+}
+
+func TestCreateView(t *testing.T) {
+	DB.DropView("sale_products")
+	DB.Set("gorm:table_options", "").DropTableIfExists(&ViewedProduct{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&ViewedProduct{})
+
+	released := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	DB.Save(&ViewedProduct{Name: "it's on sale", Price: 100, OnSale: true, Released: released})
+	DB.Save(&ViewedProduct{Name: "full price", Price: 200, Released: released})
+	DB.Save(&ViewedProduct{Name: "unreleased", Price: 50, OnSale: true, Released: released.AddDate(1, 0, 0)})
+
+	if err := DB.CreateView("sale_products", gorm.ViewOption{}).Error; err != gorm.ErrViewQueryRequired {
+		t.Errorf("Creating view without query should fail, got %v", err)
+	}
+
+	query := DB.Model(&ViewedProduct{}).Where("on_sale = ? AND released <= ? AND name <> '?'", true, released)
+	if err := DB.CreateView("sale_products", gorm.ViewOption{Query: query}).Error; err != nil {
+		t.Fatalf("Failed to create view, got %v", err)
+	}
+
+	var products []SaleProduct
+	if DB.Find(&products); len(products) != 1 || products[0].Name != "it's on sale" {
+		t.Errorf("Records of the view should be found, got %#v", products)
+	}
+
+	if err := DB.CreateView("sale_products", gorm.ViewOption{Query: query}).Error; err == nil {
+		t.Errorf("Creating existing view without replacing should fail")
+	}
+
+	query = DB.Model(&ViewedProduct{}).Where("on_sale = ?", true).Where("name LIKE ?", "%'%")
+	if err := DB.CreateView("sale_products", gorm.ViewOption{Query: query, Replace: true}).Error; err != nil {
+		t.Fatalf("Failed to replace view, got %v", err)
+	}
+
+	if DB.Find(&products); len(products) != 1 || products[0].Name != "it's on sale" {
+		t.Errorf("Records of the replaced view should be found, got %#v", products)
+	}
+
+	if err := DB.DropView("sale_products").Error; err != nil {
+		t.Errorf("Failed to drop view, got %v", err)
+	}
+
+	if err := DB.Find(&products).Error; err == nil {
+		t.Errorf("Dropped view shouldn't be queried")
+	}
+}
+
+func TestMigrationLock(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&ViewedProduct{})
+	if err := DB.Set("gorm:table_options", "").WithMigrationLock("gorm_test_migrations").AutoMigrate(&ViewedProduct{}).Error; err != nil {
+		t.Errorf("Failed to migrate with migration lock, got %v", err)
+	}
+
+	if !DB.HasTable(&ViewedProduct{}) {
+		t.Errorf("Table should be migrated with migration lock")
+	}
+
+	for name, expected := range map[string]string{
+		"postgres":  "SELECT 1 FROM pg_advisory_lock(hashtext($1))",
+		"mysql":     "SELECT GET_LOCK(?, -1)",
+		"cockroach": "",
+	} {
+		dialect, _ := gorm.GetDialect(name)
+		if locker, ok := dialect.(gorm.MigrationLocker); !ok || locker.MigrationLockSQL() != expected {
+			t.Errorf("Wrong migration lock SQL for %v", name)
+		}
+	}
+}
+
+type DropUnusedModel struct {
+	ID      uint
+	Name    string `gorm:"index:idx_drop_unused_name"`
+	Code    string `gorm:"unique"`
+	Email   string
+	Country string `gorm:"index:idx_drop_unused_country"`
+}
+
+type DropUnusedModelV2 struct {
+	ID   uint
+	Name string `gorm:"index:idx_drop_unused_name"`
+	Code string `gorm:"unique"`
+}
+
+func (DropUnusedModelV2) TableName() string {
+	return "drop_unused_models"
+}
+
+type DropUnusedIndexModel struct {
+	ID      uint
+	Name    string `gorm:"index:idx_drop_unused_name"`
+	Code    string `gorm:"unique"`
+	Email   string
+	Country string
+}
+
+func (DropUnusedIndexModel) TableName() string {
+	return "drop_unused_models"
+}
+
+func TestAutoMigrateDropUnused(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&DropUnusedModel{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&DropUnusedModel{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	var diffs []gorm.SchemaDiff
+	err := DB.Set("gorm:table_options", "").AutoMigrate(&DropUnusedModelV2{}, gorm.WithDropUnusedDryRun(func(diff gorm.SchemaDiff) {
+		diffs = append(diffs, diff)
+	})).Error
+	if err != nil {
+		t.Fatalf("Failed to list unused columns and indexes, got %v", err)
+	}
+
+	if len(diffs) != 1 || !reflect.DeepEqual(diffs[0].ExtraColumns, []string{"email", "country"}) {
+		t.Fatalf("Should list unused columns, but got %v", diffs)
+	}
+
+	if dialect := DB.Dialect().GetName(); dialect != "mssql" && !reflect.DeepEqual(diffs[0].ExtraIndexes, []string{"idx_drop_unused_country"}) {
+		t.Errorf("Should list unused indexes, but got %v", diffs[0].ExtraIndexes)
+	}
+
+	if !DB.Dialect().HasColumn("drop_unused_models", "email") || !DB.Dialect().HasIndex("drop_unused_models", "idx_drop_unused_country") {
+		t.Errorf("Unused columns and indexes shouldn't be dropped in dry run mode")
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" {
+		// sqlite before 3.35 can't drop columns, only drop unused indexes
+		if err := DB.Set("gorm:table_options", "").AutoMigrate(&DropUnusedIndexModel{}, gorm.WithDropUnused()).Error; err != nil {
+			t.Fatalf("Failed to drop unused indexes, got %v", err)
+		}
+
+		if DB.Dialect().HasIndex("drop_unused_models", "idx_drop_unused_country") || !DB.Dialect().HasIndex("drop_unused_models", "idx_drop_unused_name") {
+			t.Errorf("Only unused indexes should be dropped")
+		}
+		return
+	}
+
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&DropUnusedModelV2{}, gorm.WithDropUnused()).Error; err != nil {
+		t.Fatalf("Failed to drop unused columns and indexes, got %v", err)
+	}
+
+	if DB.Dialect().HasColumn("drop_unused_models", "email") || DB.Dialect().HasColumn("drop_unused_models", "country") {
+		t.Errorf("Unused columns should be dropped")
+	}
+
+	if DB.Dialect().HasIndex("drop_unused_models", "idx_drop_unused_country") || !DB.Dialect().HasIndex("drop_unused_models", "idx_drop_unused_name") {
+		t.Errorf("Only unused indexes should be dropped")
+	}
+
+	DB.Create(&DropUnusedModelV2{Name: "drop_unused", Code: "unique"})
+	if DB.Create(&DropUnusedModelV2{Name: "drop_unused", Code: "unique"}).Error == nil {
+		t.Errorf("Unique constraints shouldn't be dropped")
+	}
+}
+
+type CompositeIndexUser struct {
+	ID      uint
+	Name    string `gorm:"index:idx_composite_age_name"`
+	Age     int    `gorm:"index:idx_composite_age_name:5"`
+	Code    string `gorm:"unique_index:uix_composite_org_code:2"`
+	OrgID   uint   `gorm:"unique_index:uix_composite_org_code:1"`
+	Country string `gorm:"index:uix_composite_org_code:3"`
+}
+
+func TestCompositeIndexesWithPriorities(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&CompositeIndexUser{})
+	if err := db.AutoMigrate(&CompositeIndexUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	scope := DB.NewScope(&CompositeIndexUser{})
+	for _, name := range []string{"idx_composite_age_name", "uix_composite_org_code"} {
+		if !scope.Dialect().HasIndex(scope.TableName(), name) {
+			t.Errorf("Index %v should be created", name)
+		}
+	}
+
+	if err := DB.Create(&CompositeIndexUser{Code: "c1", OrgID: 1, Country: "jp"}).Error; err != nil {
+		t.Fatalf("Failed to create, got %v", err)
+	}
+
+	if err := DB.Create(&CompositeIndexUser{Code: "c1", OrgID: 1, Country: "jp"}).Error; err == nil {
+		t.Errorf("Index shared with unique_index should be unique")
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" {
+		for name, expected := range map[string][]string{
+			"idx_composite_age_name": {"age", "name"},
+			"uix_composite_org_code": {"org_id", "code", "country"},
+		} {
+			var columns []string
+			rows, _ := DB.Raw(fmt.Sprintf("SELECT name FROM pragma_index_info('%v') ORDER BY seqno", name)).Rows()
+			for rows.Next() {
+				var column string
+				rows.Scan(&column)
+				columns = append(columns, column)
+			}
+			rows.Close()
+
+			if !reflect.DeepEqual(columns, expected) {
+				t.Errorf("Columns of index %v should be ordered by priorities, expected %v, got %v", name, expected, columns)
+			}
+		}
+	}
+}
+
+type PartitionedEvent struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time `gorm:"partition_by:range"`
+}
+
+func TestPartitions(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&PartitionedEvent{})
+	if err := db.AutoMigrate(&PartitionedEvent{}).Error; err != nil {
+		t.Fatalf("Failed to create partitioned table, got %v", err)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	if name := DB.PartitionName(&PartitionedEvent{}, from); name != "partitioned_events_p20240101" {
+		t.Errorf("Wrong partition name, got %v", name)
+	}
+
+	if DB.Dialect().GetName() != "postgres" {
+		if err := DB.CreatePartition(&PartitionedEvent{}, from, to).Error; err == nil {
+			t.Errorf("Should return error as partitions aren't supported")
+		}
+		return
+	}
+
+	if err := DB.CreatePartition(&PartitionedEvent{}, from, to).Error; err != nil {
+		t.Fatalf("Failed to create partition, got %v", err)
+	}
+
+	if err := DB.Create(&PartitionedEvent{Name: "in_partition", CreatedAt: from.AddDate(0, 0, 10)}).Error; err != nil {
+		t.Errorf("Should create records in the partition, got %v", err)
+	}
+
+	if err := DB.Create(&PartitionedEvent{Name: "out_of_partitions", CreatedAt: to.AddDate(0, 0, 10)}).Error; err == nil {
+		t.Errorf("Should fail to create records without partitions")
+	}
+
+	partition := DB.PartitionName(&PartitionedEvent{}, from)
+	if err := DB.DetachPartition(&PartitionedEvent{}, partition).Error; err != nil {
+		t.Errorf("Failed to detach partition, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&PartitionedEvent{}).Count(&count); count != 0 {
+		t.Errorf("Records of detached partitions shouldn't be found, got %v", count)
+	}
+
+	if err := DB.AttachPartition(&PartitionedEvent{}, partition, from, to).Error; err != nil {
+		t.Errorf("Failed to attach partition, got %v", err)
+	}
+
+	if DB.Model(&PartitionedEvent{}).Count(&count); count != 1 {
+		t.Errorf("Records of attached partitions should be found, got %v", count)
+	}
+	DB.DropTableIfExists(&PartitionedEvent{})
+}
@@ -0,0 +1,225 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Migration is a versioned migration run by `Migrator`, migrations are identified by their IDs, which are saved to
+// the migrations table once applied, e.g. `20240102_add_user_roles`
+type Migration struct {
+	ID string
+	// Up apply the migration
+	Up func(tx *DB) error
+	// Down revert the migration, the migration can't be rolled back if it is nil
+	Down func(tx *DB) error
+}
+
+// Migrator run registered migrations in order, applied migrations are tracked in table `schema_migrations`.
+// Each migration is run with saving its ID in a transaction, except for mysql, which commits DDL implicitly
+//     migrator := gorm.NewMigrator(db.WithMigrationLock("app_migrations"),
+//       &gorm.Migration{
+//         ID: "20240101_create_users",
+//         Up: func(tx *gorm.DB) error { return tx.AutoMigrate(&User{}).Error },
+//         Down: func(tx *gorm.DB) error { return tx.DropTable("users").Error },
+//       },
+//     )
+//     if err := migrator.Migrate(); err != nil {
+//       log.Fatal(err)
+//     }
+type Migrator struct {
+	db         *DB
+	tableName  string
+	migrations []*Migration
+}
+
+// schemaMigration is a migration applied to the database
+type schemaMigration struct {
+	ID        string `gorm:"primary_key;size:255"`
+	AppliedAt time.Time
+}
+
+// NewMigrator create a migrator running migrations with db, migrations are serialized with the lock set with
+// `WithMigrationLock`
+func NewMigrator(db *DB, migrations ...*Migration) *Migrator {
+	return &Migrator{db: db, tableName: "schema_migrations", migrations: migrations}
+}
+
+// TableName change the table tracking applied migrations, which is `schema_migrations` by default
+func (migrator *Migrator) TableName(name string) *Migrator {
+	migrator.tableName = name
+	return migrator
+}
+
+// Register append migrations to the migrator, they are run after migrations registered before
+func (migrator *Migrator) Register(migrations ...*Migration) *Migrator {
+	migrator.migrations = append(migrator.migrations, migrations...)
+	return migrator
+}
+
+// Migrate run all pending migrations
+func (migrator *Migrator) Migrate() error {
+	return migrator.MigrateTo("")
+}
+
+// MigrateTo run pending migrations up to and including the migration, all pending migrations are run if id is blank
+func (migrator *Migrator) MigrateTo(id string) error {
+	if id != "" && migrator.indexOf(id) < 0 {
+		return fmt.Errorf("unknown migration %v", id)
+	}
+
+	return migrator.locked(func(applied map[string]bool) error {
+		for _, migration := range migrator.migrations {
+			if !applied[migration.ID] {
+				if err := migrator.run(migration, true); err != nil {
+					return err
+				}
+			}
+
+			if migration.ID == id {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// Rollback revert the last applied migration
+func (migrator *Migrator) Rollback() error {
+	return migrator.locked(func(applied map[string]bool) error {
+		for i := len(migrator.migrations) - 1; i >= 0; i-- {
+			if migration := migrator.migrations[i]; applied[migration.ID] {
+				return migrator.run(migration, false)
+			}
+		}
+		return nil
+	})
+}
+
+// RollbackTo revert applied migrations after the migration in reverse order, the migration itself is kept
+func (migrator *Migrator) RollbackTo(id string) error {
+	index := migrator.indexOf(id)
+	if index < 0 {
+		return fmt.Errorf("unknown migration %v", id)
+	}
+
+	return migrator.locked(func(applied map[string]bool) error {
+		for i := len(migrator.migrations) - 1; i > index; i-- {
+			if migration := migrator.migrations[i]; applied[migration.ID] {
+				if err := migrator.run(migration, false); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Pending return IDs of registered migrations which aren't applied
+func (migrator *Migrator) Pending() (ids []string, err error) {
+	applied, err := migrator.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range migrator.migrations {
+		if !applied[migration.ID] {
+			ids = append(ids, migration.ID)
+		}
+	}
+	return
+}
+
+func (migrator *Migrator) indexOf(id string) int {
+	for idx, migration := range migrator.migrations {
+		if migration.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// locked validate migrations, then call fc with applied migrations holding the migration lock
+func (migrator *Migrator) locked(fc func(applied map[string]bool) error) (err error) {
+	ids := map[string]bool{}
+	for _, migration := range migrator.migrations {
+		if migration.ID == "" || migration.Up == nil {
+			return errors.New("migrations should have ID and Up")
+		}
+
+		if ids[migration.ID] {
+			return fmt.Errorf("duplicated migration %v", migration.ID)
+		}
+		ids[migration.ID] = true
+	}
+
+	unlock, err := migrator.db.lockMigration()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	db := migrator.db.New()
+	if !db.HasTable(migrator.tableName) {
+		if err = db.Table(migrator.tableName).CreateTable(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+	}
+
+	applied, err := migrator.applied()
+	if err != nil {
+		return err
+	}
+	return fc(applied)
+}
+
+func (migrator *Migrator) applied() (map[string]bool, error) {
+	db := migrator.db.New()
+	if !db.HasTable(migrator.tableName) {
+		return map[string]bool{}, nil
+	}
+
+	var ids []string
+	if err := db.Table(migrator.tableName).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	applied := map[string]bool{}
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
+}
+
+// run apply or revert the migration and track it, in a transaction if the dialect supports transactional DDL
+func (migrator *Migrator) run(migration *Migration, up bool) error {
+	if !up && migration.Down == nil {
+		return fmt.Errorf("migration %v can't be rolled back", migration.ID)
+	}
+
+	// the lock is held by the migrator, migrations calling AutoMigrate shouldn't acquire it again
+	db := migrator.db.New().Set("gorm:migration_lock", "")
+	fc := func(tx *DB) error {
+		if !up {
+			if err := migration.Down(tx); err != nil {
+				return fmt.Errorf("failed to roll back migration %v: %v", migration.ID, err)
+			}
+			return tx.Table(migrator.tableName).Where("id = ?", migration.ID).Delete(&schemaMigration{}).Error
+		}
+
+		if err := migration.Up(tx); err != nil {
+			return fmt.Errorf("failed to run migration %v: %v", migration.ID, err)
+		}
+		return tx.Table(migrator.tableName).Create(&schemaMigration{ID: migration.ID, AppliedAt: db.nowFunc()}).Error
+	}
+
+	if db.Dialect().GetName() == "mysql" {
+		return fc(db)
+	}
+	return db.Transaction(fc)
+}
@@ -0,0 +1,123 @@
+package gorm_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type MigratorProduct struct {
+	ID   uint
+	Code string
+}
+
+func TestMigrator(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&MigratorProduct{}, "test_schema_migrations")
+
+	var failing bool
+	migrator := gorm.NewMigrator(db,
+		&gorm.Migration{
+			ID: "20240101_create_products",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&MigratorProduct{}).Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.DropTable(&MigratorProduct{}).Error
+			},
+		},
+		&gorm.Migration{
+			ID: "20240102_seed_products",
+			Up: func(tx *gorm.DB) error {
+				if err := tx.Create(&MigratorProduct{Code: "seed"}).Error; err != nil {
+					return err
+				}
+
+				if failing {
+					return errors.New("failed to seed")
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Where("code = ?", "seed").Delete(&MigratorProduct{}).Error
+			},
+		},
+	).TableName("test_schema_migrations")
+
+	if pending, err := migrator.Pending(); err != nil || len(pending) != 2 {
+		t.Fatalf("All migrations should be pending, got %v, %v", pending, err)
+	}
+
+	if err := migrator.MigrateTo("20240101_create_products"); err != nil {
+		t.Fatalf("Failed to migrate to the first migration, got %v", err)
+	}
+
+	if !DB.HasTable(&MigratorProduct{}) {
+		t.Errorf("Table should be created by the first migration")
+	}
+
+	failing = true
+	if err := migrator.Migrate(); err == nil {
+		t.Errorf("Should return error of failed migrations")
+	}
+
+	if pending, _ := migrator.Pending(); !reflect.DeepEqual(pending, []string{"20240102_seed_products"}) {
+		t.Errorf("Failed migrations shouldn't be tracked, got %v", pending)
+	}
+
+	if DB.Dialect().GetName() != "mysql" {
+		var count int
+		if DB.Model(&MigratorProduct{}).Where("code = ?", "seed").Count(&count); count != 0 {
+			t.Errorf("Failed migrations should be rolled back, got %v", count)
+		}
+	}
+
+	failing = false
+	if err := migrator.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	if pending, _ := migrator.Pending(); len(pending) != 0 {
+		t.Errorf("All migrations should be applied, got %v", pending)
+	}
+
+	if err := migrator.Migrate(); err != nil {
+		t.Errorf("Applied migrations shouldn't be run again, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&MigratorProduct{}).Where("code = ?", "seed").Count(&count); count != 1 {
+		t.Errorf("Migrations should be run once, got %v", count)
+	}
+
+	if err := migrator.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back, got %v", err)
+	}
+
+	if DB.Model(&MigratorProduct{}).Where("code = ?", "seed").Count(&count); count != 0 {
+		t.Errorf("The last migration should be rolled back, got %v", count)
+	}
+
+	if err := migrator.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate again, got %v", err)
+	}
+
+	if err := migrator.RollbackTo("20240101_create_products"); err != nil {
+		t.Fatalf("Failed to roll back to the first migration, got %v", err)
+	}
+
+	if pending, _ := migrator.Pending(); !reflect.DeepEqual(pending, []string{"20240102_seed_products"}) {
+		t.Errorf("Migrations after the first migration should be rolled back, got %v", pending)
+	}
+
+	if err := migrator.MigrateTo("unknown"); err == nil {
+		t.Errorf("Should return error when migrating to unknown migrations")
+	}
+
+	duplicated := gorm.NewMigrator(db, &gorm.Migration{ID: "1", Up: func(*gorm.DB) error { return nil }}, &gorm.Migration{ID: "1", Up: func(*gorm.DB) error { return nil }})
+	if err := duplicated.Migrate(); err == nil {
+		t.Errorf("Should return error for duplicated migrations")
+	}
+}
@@ -20,8 +20,11 @@ var DefaultTableNameHandler = func(db *DB, defaultTableName string) string {
 // lock for mutating global cached model metadata
 var structsLock sync.Mutex
 
-// global cache of model metadata
-var modelStructsMap sync.Map
+// global cache of model metadata, keyed by types, models of DBs with singular table names are cached separately
+var (
+	modelStructsMap         sync.Map
+	singularModelStructsMap sync.Map
+)
 
 // ModelStruct model definition
 type ModelStruct struct {
@@ -30,6 +33,7 @@ type ModelStruct struct {
 	ModelType     reflect.Type
 
 	defaultTableName string
+	namedByTabler    bool
 	l                sync.Mutex
 }
 
@@ -42,6 +46,7 @@ func (s *ModelStruct) TableName(db *DB) string {
 		// Set default table name
 		if tabler, ok := reflect.New(s.ModelType).Interface().(tabler); ok {
 			s.defaultTableName = tabler.TableName()
+			s.namedByTabler = true
 		} else {
 			tableName := ToTableName(s.ModelType.Name())
 			db.parent.RLock()
@@ -53,7 +58,24 @@ func (s *ModelStruct) TableName(db *DB) string {
 		}
 	}
 
-	return DefaultTableNameHandler(db, s.defaultTableName)
+	if s.namedByTabler || s.defaultTableName == "" {
+		return DefaultTableNameHandler(db, s.defaultTableName)
+	}
+	return DefaultTableNameHandler(db, s.tablePrefix(db)+s.defaultTableName)
+}
+
+// tablePrefixer could be implemented by models to override the table prefix set with `SetTablePrefix`
+type tablePrefixer interface {
+	TablePrefix() string
+}
+
+func (s *ModelStruct) tablePrefix(db *DB) string {
+	if s.ModelType != nil {
+		if prefixer, ok := reflect.New(s.ModelType).Interface().(tablePrefixer); ok {
+			return prefixer.TablePrefix()
+		}
+	}
+	return db.getTablePrefix()
 }
 
 // StructField model field's struct definition
@@ -72,6 +94,8 @@ type StructField struct {
 	IsForeignKey    bool
 	Relationship    *Relationship
 
+	// embeddedPrefix is the prefix added to DBName by embedded structs
+	embeddedPrefix  string
 	tagSettingsLock sync.RWMutex
 }
 
@@ -111,6 +135,7 @@ func (sf *StructField) clone() *StructField {
 		TagSettings:     map[string]string{},
 		Struct:          sf.Struct,
 		IsForeignKey:    sf.IsForeignKey,
+		embeddedPrefix:  sf.embeddedPrefix,
 	}
 
 	if sf.Relationship != nil {
@@ -139,6 +164,7 @@ type Relationship struct {
 	AssociationForeignFieldNames []string
 	AssociationForeignDBNames    []string
 	JoinTableHandler             JoinTableHandlerInterface
+	Through                      string // name of the association the has many through relationship goes through
 }
 
 func getForeignField(column string, fields []*StructField) *StructField {
@@ -152,42 +178,45 @@ func getForeignField(column string, fields []*StructField) *StructField {
 
 // GetModelStruct get value's model struct, relationships based on struct and tag definition
 func (scope *Scope) GetModelStruct() *ModelStruct {
-	return scope.getModelStruct(scope, make([]*StructField, 0))
+	return scope.getModelStruct(scope, nil)
 }
 
 func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *ModelStruct {
-	var modelStruct ModelStruct
 	// Scope value can't be nil
 	if scope.Value == nil {
-		return &modelStruct
+		return &ModelStruct{}
 	}
 
-	reflectType := reflect.ValueOf(scope.Value).Type()
+	reflectType := reflect.TypeOf(scope.Value)
 	for reflectType.Kind() == reflect.Slice || reflectType.Kind() == reflect.Ptr {
 		reflectType = reflectType.Elem()
 	}
 
 	// Scope value need to be a struct
 	if reflectType.Kind() != reflect.Struct {
-		return &modelStruct
+		return &ModelStruct{}
 	}
 
-	// Get Cached model struct
-	isSingularTable := false
+	// Get Cached model struct, types are used as keys directly, so cached models are loaded without allocations
+	cachedModelStructs := &modelStructsMap
 	if scope.db != nil && scope.db.parent != nil {
 		scope.db.parent.RLock()
-		isSingularTable = scope.db.parent.singularTable
+		if scope.db.parent.singularTable {
+			cachedModelStructs = &singularModelStructsMap
+		}
 		scope.db.parent.RUnlock()
 	}
 
-	hashKey := struct {
-		singularTable bool
-		reflectType   reflect.Type
-	}{isSingularTable, reflectType}
-	if value, ok := modelStructsMap.Load(hashKey); ok && value != nil {
+	if value, ok := cachedModelStructs.Load(reflectType); ok && value != nil {
 		return value.(*ModelStruct)
 	}
+	return scope.buildModelStruct(rootScope, allFields, reflectType, cachedModelStructs)
+}
 
+// buildModelStruct build the model struct of the type and cache it, it is split from `getModelStruct` as its
+// variables escape to the heap, which would allocate them for cached model structs too
+func (scope *Scope) buildModelStruct(rootScope *Scope, allFields []*StructField, reflectType reflect.Type, cachedModelStructs *sync.Map) *ModelStruct {
+	var modelStruct ModelStruct
 	modelStruct.ModelType = reflectType
 
 	// Get all fields
@@ -211,7 +240,17 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 				}
 
 				if _, ok := field.TagSettingsGet("DEFAULT"); ok && !field.IsPrimaryKey {
-					field.HasDefaultValue = true
+					// UUID defaults are generated before inserting, so the column needs to be inserted
+					if _, isUUID := uuidGeneratorOf(field); !isUUID {
+						field.HasDefaultValue = true
+					}
+				}
+
+				// IDs are generated before inserting, so the primary key isn't auto incremented
+				if _, ok := field.TagSettingsGet("ID_GENERATOR"); ok && field.IsPrimaryKey {
+					if _, ok := field.TagSettingsGet("AUTO_INCREMENT"); !ok {
+						field.TagSettingsSet("AUTO_INCREMENT", "FALSE")
+					}
 				}
 
 				if _, ok := field.TagSettingsGet("AUTO_INCREMENT"); ok && !field.IsPrimaryKey {
@@ -224,7 +263,10 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 				}
 
 				fieldValue := reflect.New(indirectType).Interface()
-				if _, isScanner := fieldValue.(sql.Scanner); isScanner {
+				if _, ok := field.TagSettingsGet("SERIALIZER"); ok {
+					// is serialized by serializer
+					field.IsNormal = true
+				} else if _, isScanner := fieldValue.(sql.Scanner); isScanner {
 					// is scanner
 					field.IsScanner, field.IsNormal = true, true
 					if indirectType.Kind() == reflect.Struct {
@@ -245,7 +287,14 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 						subField = subField.clone()
 						subField.Names = append([]string{fieldStruct.Name}, subField.Names...)
 						if prefix, ok := field.TagSettingsGet("EMBEDDED_PREFIX"); ok {
-							subField.DBName = prefix + subField.DBName
+							if prefix == "-" {
+								// disable prefixes, including prefixes of nested embedded structs
+								subField.DBName = strings.TrimPrefix(subField.DBName, subField.embeddedPrefix)
+								subField.embeddedPrefix = ""
+							} else {
+								subField.DBName = prefix + subField.DBName
+								subField.embeddedPrefix = prefix + subField.embeddedPrefix
+							}
 						}
 
 						if subField.IsPrimaryKey {
@@ -282,13 +331,13 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 							)
 
 							if foreignKey, _ := field.TagSettingsGet("FOREIGNKEY"); foreignKey != "" {
-								foreignKeys = strings.Split(foreignKey, ",")
+								foreignKeys = splitKeys(foreignKey)
 							}
 
 							if foreignKey, _ := field.TagSettingsGet("ASSOCIATION_FOREIGNKEY"); foreignKey != "" {
-								associationForeignKeys = strings.Split(foreignKey, ",")
+								associationForeignKeys = splitKeys(foreignKey)
 							} else if foreignKey, _ := field.TagSettingsGet("ASSOCIATIONFOREIGNKEY"); foreignKey != "" {
-								associationForeignKeys = strings.Split(foreignKey, ",")
+								associationForeignKeys = splitKeys(foreignKey)
 							}
 
 							for elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Ptr {
@@ -296,14 +345,25 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 							}
 
 							if elemType.Kind() == reflect.Struct {
-								if many2many, _ := field.TagSettingsGet("MANY2MANY"); many2many != "" {
+								if through, _ := field.TagSettingsGet("THROUGH"); through != "" {
+									// User has many line items through orders, keys are resolved with the associations
+									// `User.Orders` and `Order.LineItems` when querying, as they might not be parsed yet
+									relationship.Kind = "has_many_through"
+									relationship.Through = through
+									field.Relationship = relationship
+								} else if many2many, _ := field.TagSettingsGet("MANY2MANY"); many2many != "" {
 									relationship.Kind = "many_to_many"
 
 									{ // Foreign Keys for Source
 										joinTableDBNames := []string{}
 
-										if foreignKey, _ := field.TagSettingsGet("JOINTABLE_FOREIGNKEY"); foreignKey != "" {
-											joinTableDBNames = strings.Split(foreignKey, ",")
+										// tag `joinForeignKey` is the same as `jointable_foreignkey`
+										foreignKey, _ := field.TagSettingsGet("JOINFOREIGNKEY")
+										if foreignKey == "" {
+											foreignKey, _ = field.TagSettingsGet("JOINTABLE_FOREIGNKEY")
+										}
+										if foreignKey != "" {
+											joinTableDBNames = splitKeys(foreignKey)
 										}
 
 										// if no foreign keys defined with tag
@@ -333,10 +393,19 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 									{ // Foreign Keys for Association (Destination)
 										associationJoinTableDBNames := []string{}
 
-										if foreignKey, _ := field.TagSettingsGet("ASSOCIATION_JOINTABLE_FOREIGNKEY"); foreignKey != "" {
-											associationJoinTableDBNames = strings.Split(foreignKey, ",")
+										// tag `joinReferences` is the same as `association_jointable_foreignkey`
+										foreignKey, _ := field.TagSettingsGet("JOINREFERENCES")
+										if foreignKey == "" {
+											foreignKey, _ = field.TagSettingsGet("ASSOCIATION_JOINTABLE_FOREIGNKEY")
+										}
+										if foreignKey != "" {
+											associationJoinTableDBNames = splitKeys(foreignKey)
 										}
 
+										// join table foreign keys of self referencing associations like `Friends []User` collide with
+										// keys of the source, so they are named after the field, e.g. `friend_id`
+										selfReferencingName := ToColumnName(inflection.Singular(field.Name))
+
 										// if no association foreign keys defined with tag
 										if len(associationForeignKeys) == 0 {
 											for _, field := range toScope.PrimaryFields() {
@@ -355,6 +424,9 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 												} else {
 													// join table foreign keys for association
 													joinTableDBName := ToColumnName(elemType.Name()) + "_" + field.DBName
+													if elemType == reflectType && strInSlice(joinTableDBName, relationship.ForeignDBNames) {
+														joinTableDBName = selfReferencingName + "_" + field.DBName
+													}
 													relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, joinTableDBName)
 												}
 											}
@@ -425,24 +497,7 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 										}
 									}
 
-									for idx, foreignKey := range foreignKeys {
-										if foreignField := getForeignField(foreignKey, toFields); foreignField != nil {
-											if associationField := getForeignField(associationForeignKeys[idx], allFields); associationField != nil {
-												// mark field as foreignkey, use global lock to avoid race
-												structsLock.Lock()
-												foreignField.IsForeignKey = true
-												structsLock.Unlock()
-
-												// association foreign keys
-												relationship.AssociationForeignFieldNames = append(relationship.AssociationForeignFieldNames, associationField.Name)
-												relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, associationField.DBName)
-
-												// association foreign keys
-												relationship.ForeignFieldNames = append(relationship.ForeignFieldNames, foreignField.Name)
-												relationship.ForeignDBNames = append(relationship.ForeignDBNames, foreignField.DBName)
-											}
-										}
-									}
+									setRelationshipKeys(relationship, foreignKeys, associationForeignKeys, toFields, allFields)
 
 									if len(relationship.ForeignFieldNames) != 0 {
 										field.Relationship = relationship
@@ -466,13 +521,13 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 							)
 
 							if foreignKey, _ := field.TagSettingsGet("FOREIGNKEY"); foreignKey != "" {
-								tagForeignKeys = strings.Split(foreignKey, ",")
+								tagForeignKeys = splitKeys(foreignKey)
 							}
 
 							if foreignKey, _ := field.TagSettingsGet("ASSOCIATION_FOREIGNKEY"); foreignKey != "" {
-								tagAssociationForeignKeys = strings.Split(foreignKey, ",")
+								tagAssociationForeignKeys = splitKeys(foreignKey)
 							} else if foreignKey, _ := field.TagSettingsGet("ASSOCIATIONFOREIGNKEY"); foreignKey != "" {
-								tagAssociationForeignKeys = strings.Split(foreignKey, ",")
+								tagAssociationForeignKeys = splitKeys(foreignKey)
 							}
 
 							if polymorphic, _ := field.TagSettingsGet("POLYMORPHIC"); polymorphic != "" {
@@ -533,24 +588,7 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 									}
 								}
 
-								for idx, foreignKey := range foreignKeys {
-									if foreignField := getForeignField(foreignKey, toFields); foreignField != nil {
-										if scopeField := getForeignField(associationForeignKeys[idx], allFields); scopeField != nil {
-											// mark field as foreignkey, use global lock to avoid race
-											structsLock.Lock()
-											foreignField.IsForeignKey = true
-											structsLock.Unlock()
-
-											// association foreign keys
-											relationship.AssociationForeignFieldNames = append(relationship.AssociationForeignFieldNames, scopeField.Name)
-											relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, scopeField.DBName)
-
-											// association foreign keys
-											relationship.ForeignFieldNames = append(relationship.ForeignFieldNames, foreignField.Name)
-											relationship.ForeignDBNames = append(relationship.ForeignDBNames, foreignField.DBName)
-										}
-									}
-								}
+								setRelationshipKeys(relationship, foreignKeys, associationForeignKeys, toFields, allFields)
 							}
 
 							if len(relationship.ForeignFieldNames) != 0 {
@@ -596,24 +634,7 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 									}
 								}
 
-								for idx, foreignKey := range foreignKeys {
-									if foreignField := getForeignField(foreignKey, modelStruct.StructFields); foreignField != nil {
-										if associationField := getForeignField(associationForeignKeys[idx], toFields); associationField != nil {
-											// mark field as foreignkey, use global lock to avoid race
-											structsLock.Lock()
-											foreignField.IsForeignKey = true
-											structsLock.Unlock()
-
-											// association foreign keys
-											relationship.AssociationForeignFieldNames = append(relationship.AssociationForeignFieldNames, associationField.Name)
-											relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, associationField.DBName)
-
-											// source foreign keys
-											relationship.ForeignFieldNames = append(relationship.ForeignFieldNames, foreignField.Name)
-											relationship.ForeignDBNames = append(relationship.ForeignDBNames, foreignField.DBName)
-										}
-									}
-								}
+								setRelationshipKeys(relationship, foreignKeys, associationForeignKeys, modelStruct.StructFields, toFields)
 
 								if len(relationship.ForeignFieldNames) != 0 {
 									relationship.Kind = "belongs_to"
@@ -639,6 +660,8 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 		}
 	}
 
+	ignoreShadowedFields(modelStruct.StructFields)
+
 	if len(modelStruct.PrimaryFields) == 0 {
 		if field := getForeignField("id", modelStruct.StructFields); field != nil {
 			field.IsPrimaryKey = true
@@ -646,11 +669,64 @@ func (scope *Scope) getModelStruct(rootScope *Scope, allFields []*StructField) *
 		}
 	}
 
-	modelStructsMap.Store(hashKey, &modelStruct)
+	cachedModelStructs.Store(reflectType, &modelStruct)
 
 	return &modelStruct
 }
 
+// setRelationshipKeys set foreign keys found in foreignFields and association foreign keys found in associationFields
+// to the relationship, keys are set only if all of them are found, so composite keys are never matched partially
+func setRelationshipKeys(relationship *Relationship, foreignKeys, associationForeignKeys []string, foreignFields, associationFields []*StructField) {
+	var matchedForeignFields, matchedAssociationFields []*StructField
+	for idx, foreignKey := range foreignKeys {
+		if idx >= len(associationForeignKeys) {
+			return
+		}
+
+		foreignField := getForeignField(foreignKey, foreignFields)
+		associationField := getForeignField(associationForeignKeys[idx], associationFields)
+		if foreignField == nil || associationField == nil {
+			return
+		}
+		matchedForeignFields = append(matchedForeignFields, foreignField)
+		matchedAssociationFields = append(matchedAssociationFields, associationField)
+	}
+
+	for idx, foreignField := range matchedForeignFields {
+		// mark field as foreignkey, use global lock to avoid race
+		structsLock.Lock()
+		foreignField.IsForeignKey = true
+		structsLock.Unlock()
+
+		// association foreign keys
+		relationship.AssociationForeignFieldNames = append(relationship.AssociationForeignFieldNames, matchedAssociationFields[idx].Name)
+		relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, matchedAssociationFields[idx].DBName)
+
+		// source foreign keys
+		relationship.ForeignFieldNames = append(relationship.ForeignFieldNames, foreignField.Name)
+		relationship.ForeignDBNames = append(relationship.ForeignDBNames, foreignField.DBName)
+	}
+}
+
+// ignoreShadowedFields ignore fields of embedded structs whose column collide with a shallower field, like Go
+// promotes the shallower field. Fields at the same depth are kept, they could be scanned from identical columns
+func ignoreShadowedFields(fields []*StructField) {
+	depths := map[string]int{}
+	for _, field := range fields {
+		if field.IsNormal && !field.IsIgnored {
+			if depth, ok := depths[field.DBName]; !ok || len(field.Names) < depth {
+				depths[field.DBName] = len(field.Names)
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if field.IsNormal && !field.IsIgnored && len(field.Names) > depths[field.DBName] {
+			field.IsNormal, field.IsIgnored = false, true
+		}
+	}
+}
+
 // GetStructFields get model's field structs
 func (scope *Scope) GetStructFields() (fields []*StructField) {
 	return scope.GetModelStruct().StructFields
@@ -4,7 +4,10 @@ import (
 	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/zanmato/gorm"
 )
 
 type Blog struct {
@@ -379,3 +382,78 @@ func TestManyToManyWithCustomizedForeignKeys2(t *testing.T) {
 		}
 	}
 }
+
+type ErpOrder struct {
+	ID          uint
+	CompanyCode string
+	OrderNo     string
+	Customer    ErpCustomer `gorm:"foreignkey:CompanyCode, CustomerNo;association_foreignkey:CompanyCode, No"`
+	CustomerNo  string
+	Lines       []ErpOrderLine `gorm:"foreignkey:CompanyCode, OrderNo;association_foreignkey:CompanyCode, OrderNo"`
+}
+
+type ErpCustomer struct {
+	ID          uint
+	CompanyCode string
+	No          string
+	Name        string
+}
+
+type ErpOrderLine struct {
+	ID          uint
+	CompanyCode string
+	OrderNo     string
+	Item        string
+}
+
+func TestCompositeForeignKeys(t *testing.T) {
+	DB.DropTableIfExists(&ErpOrder{}, &ErpCustomer{}, &ErpOrderLine{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&ErpOrder{}, &ErpCustomer{}, &ErpOrderLine{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	order := ErpOrder{
+		CompanyCode: "C1",
+		OrderNo:     "O1",
+		Customer:    ErpCustomer{CompanyCode: "C1", No: "K1", Name: "acme"},
+		Lines:       []ErpOrderLine{{Item: "bolt"}, {Item: "nut"}},
+	}
+	if err := DB.Save(&order).Error; err != nil {
+		t.Fatalf("Failed to save, got %v", err)
+	}
+	DB.Save(&ErpOrderLine{CompanyCode: "C2", OrderNo: "O1", Item: "other company"})
+
+	if order.CustomerNo != "K1" {
+		t.Errorf("Composite foreign keys of belongs to should be assigned, got %v", order.CustomerNo)
+	}
+	for _, line := range order.Lines {
+		if line.CompanyCode != "C1" || line.OrderNo != "O1" {
+			t.Errorf("Composite foreign keys of has many should be assigned, got %v, %v", line.CompanyCode, line.OrderNo)
+		}
+	}
+
+	var found ErpOrder
+	DB.Preload("Customer").Preload("Lines").First(&found, order.ID)
+	if found.Customer.Name != "acme" || len(found.Lines) != 2 {
+		t.Errorf("Associations should be preloaded with composite foreign keys, got %+v", found)
+	}
+
+	var lines []ErpOrderLine
+	DB.Model(&order).Related(&lines, "Lines")
+	if len(lines) != 2 {
+		t.Errorf("Related should find associations with composite foreign keys, got %v", len(lines))
+	}
+
+	var customer ErpCustomer
+	DB.Model(&order).Related(&customer, "Customer")
+	if customer.Name != "acme" {
+		t.Errorf("Related should find belongs to associations with composite foreign keys, got %+v", customer)
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).Model(&ErpOrderLine{}).AddForeignKey("company_code, order_no", "erp_orders(company_code, order_no)", "CASCADE", "CASCADE").DryRunSQL()
+	if !strings.Contains(sql, "FOREIGN KEY (company_code,order_no) REFERENCES erp_orders(company_code, order_no)") {
+		t.Errorf("Composite foreign key should be added with all columns, got %v", sql)
+	}
+
+	DB.DropTableIfExists(&ErpOrder{}, &ErpCustomer{}, &ErpOrderLine{})
+}
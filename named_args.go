@@ -0,0 +1,131 @@
+package gorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// namedArgs replace named parameters like `@name` of the query with `?` and return their values, the values are
+// found in `sql.NamedArg`s, or fields and keys of a single struct or map argument
+//     db.Raw("SELECT * FROM users WHERE name = @Name AND age > @Age", filter).Scan(&users)
+//     db.Where("name = @name OR nickname = @name", map[string]interface{}{"name": "jinzhu"}).Find(&users)
+//     db.Exec("UPDATE users SET age = @age", sql.Named("age", 18))
+// ok is false if the arguments aren't named, so the query is left as it is
+func (scope *Scope) namedArgs(query string, args []interface{}) (string, []interface{}, bool) {
+	lookup := namedArgsLookup(scope, args)
+	if lookup == nil {
+		return query, args, false
+	}
+
+	var (
+		runes   = []rune(query)
+		results []rune
+		values  []interface{}
+		quote   rune
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '@' && (i == 0 || runes[i-1] != '@') && i+1 < len(runes) && isNameStart(runes[i+1]):
+			end := i + 1
+			for end < len(runes) && isNamePart(runes[end]) {
+				end++
+			}
+
+			name := string(runes[i+1 : end])
+			value, ok := lookup(name)
+			if !ok {
+				scope.Err(fmt.Errorf("missing value of named argument @%v", name))
+				return query, args, false
+			}
+			results = append(results, '?')
+			values = append(values, value)
+			i = end - 1
+			continue
+		}
+		results = append(results, r)
+	}
+	if len(values) == 0 {
+		return query, args, false
+	}
+	return string(results), values, true
+}
+
+// namedArgsLookup return the function to find values of named arguments, nil if the arguments aren't named
+func namedArgsLookup(scope *Scope, args []interface{}) func(name string) (interface{}, bool) {
+	if len(args) == 0 {
+		return nil
+	}
+
+	if _, ok := args[0].(sql.NamedArg); ok {
+		namedArgs := map[string]interface{}{}
+		for _, arg := range args {
+			namedArg, ok := arg.(sql.NamedArg)
+			if !ok {
+				return nil
+			}
+			namedArgs[namedArg.Name] = namedArg.Value
+		}
+		return func(name string) (interface{}, bool) {
+			value, ok := namedArgs[name]
+			return value, ok
+		}
+	}
+
+	if len(args) != 1 {
+		return nil
+	}
+
+	switch args[0].(type) {
+	case driver.Valuer, time.Time, *time.Time:
+		return nil
+	}
+
+	reflectValue := reflect.ValueOf(args[0])
+	for reflectValue.Kind() == reflect.Ptr && !reflectValue.IsNil() {
+		reflectValue = reflectValue.Elem()
+	}
+
+	switch reflectValue.Kind() {
+	case reflect.Map:
+		if reflectValue.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		return func(name string) (interface{}, bool) {
+			value := reflectValue.MapIndex(reflect.ValueOf(name).Convert(reflectValue.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			return value.Interface(), true
+		}
+	case reflect.Struct:
+		record := reflect.New(reflectValue.Type())
+		record.Elem().Set(reflectValue)
+		newScope := scope.New(record.Interface())
+		return func(name string) (interface{}, bool) {
+			if field, ok := newScope.FieldByName(name); ok && !field.IsIgnored && field.Field.IsValid() {
+				return field.Field.Interface(), true
+			}
+			return nil, false
+		}
+	}
+	return nil
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isNamePart(r rune) bool {
+	return isNameStart(r) || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,109 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var orderColumnRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// orderByColumn is an order of a column which may come from user input, the column is validated and quoted
+type orderByColumn struct {
+	column  string
+	desc    bool
+	allowed []string
+}
+
+// OrderBy specify order with a column which could come from user input like sort params of APIs, the column is
+// quoted and should be one of allowed columns, or a field of the model if allowed columns are not given, otherwise
+// ErrInvalidSQL is returned when querying
+//     db.OrderBy(params.Sort, params.Desc, "name", "created_at").Find(&users)
+//     // SELECT * FROM "users" ORDER BY "created_at" DESC
+func (s *DB) OrderBy(column string, desc bool, allowed ...string) *DB {
+	return s.clone().search.Order(&orderByColumn{column: column, desc: desc, allowed: allowed}).db
+}
+
+func (order *orderByColumn) orderSQL(scope *Scope) string {
+	column, ok := order.validColumn(scope)
+	if !ok {
+		scope.Err(fmt.Errorf("%v: can't order by column %q", ErrInvalidSQL, order.column))
+		return ""
+	}
+
+	if order.desc {
+		return scope.Quote(column) + " DESC"
+	}
+	return scope.Quote(column)
+}
+
+// validColumn return the column to order by if it is allowed, fields of the model are allowed with their names
+func (order *orderByColumn) validColumn(scope *Scope) (string, bool) {
+	if !orderColumnRegexp.MatchString(order.column) {
+		return "", false
+	}
+
+	if len(order.allowed) > 0 {
+		for _, column := range order.allowed {
+			if column == order.column {
+				return column, true
+			}
+		}
+		return "", false
+	}
+
+	if scope.Value == nil {
+		return "", false
+	}
+
+	if field, ok := scope.FieldByName(order.column); ok && field.IsNormal && !field.IsIgnored {
+		return field.DBName, true
+	}
+	return "", false
+}
+
+// SortKey order records of `First` and `Last` by the fields instead of the primary key, fields of the model could
+// also be tagged with `sort_key` to order by them by default, primary keys are ordered after them to break ties,
+// all columns of composite primary keys are ordered
+//     type Event struct {
+//       ID        string `gorm:"primary_key"`
+//       CreatedAt time.Time `gorm:"sort_key"`
+//     }
+//     db.First(&event)
+//     // SELECT * FROM "events" ORDER BY "events"."created_at" ASC, "events"."id" ASC LIMIT 1
+//     db.SortKey("UpdatedAt").Last(&event)
+//     // SELECT * FROM "events" ORDER BY "events"."updated_at" DESC, "events"."id" DESC LIMIT 1
+func (s *DB) SortKey(fields ...string) *DB {
+	return s.Set("gorm:sort_key", fields)
+}
+
+// sortKeyColumns return quoted columns ordering records of `First` and `Last`
+func (scope *Scope) sortKeyColumns() ([]string, error) {
+	var fields []*StructField
+	if value, ok := scope.Get("gorm:sort_key"); ok {
+		for _, name := range value.([]string) {
+			field, ok := scope.FieldByName(name)
+			if !ok || !field.IsNormal || field.IsIgnored {
+				return nil, fmt.Errorf("%v: unknown sort key %v", ErrInvalidSQL, name)
+			}
+			fields = append(fields, field.StructField)
+		}
+	} else {
+		for _, field := range scope.GetModelStruct().StructFields {
+			if _, ok := field.TagSettingsGet("SORT_KEY"); ok && field.IsNormal {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	var (
+		columns []string
+		ordered = map[string]bool{}
+	)
+	for _, field := range append(fields, scope.GetModelStruct().PrimaryFields...) {
+		if !ordered[field.DBName] {
+			ordered[field.DBName] = true
+			columns = append(columns, fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName)))
+		}
+	}
+	return columns, nil
+}
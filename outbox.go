@@ -0,0 +1,169 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// OutboxMessage is a message published to the outbox table `outbox_messages`, messages are deleted once they are
+// dispatched
+type OutboxMessage struct {
+	ID      uint64 `gorm:"primary_key"`
+	Topic   string `gorm:"size:255;index"`
+	Payload JSON
+	// Attempts is the number of times the message was claimed to be dispatched, including the current attempt
+	Attempts  int
+	LastError string `gorm:"size:1024"`
+	// ClaimToken and ClaimedUntil are set when the message is claimed by a dispatcher, other dispatchers could claim
+	// it again after ClaimedUntil if it isn't dispatched
+	ClaimToken   string `gorm:"size:36;index"`
+	ClaimedUntil *time.Time
+	CreatedAt    time.Time
+}
+
+// Outbox publish messages to the outbox table with the DB, so messages are saved in the transaction of the changes
+// they are about and dispatched after the transaction is committed, messages are dispatched at least once
+//     db.Transaction(func(tx *gorm.DB) error {
+//       if err := tx.Create(&order).Error; err != nil {
+//         return err
+//       }
+//       return tx.Outbox().Publish("orders.created", order)
+//     })
+//
+//     // in dispatchers
+//     db.Outbox().Poll(ctx, time.Second, 100, func(message *gorm.OutboxMessage) error {
+//       return broker.Send(message.Topic, message.Payload)
+//     })
+type Outbox struct {
+	db *DB
+	// ClaimTimeout is how long claimed messages are reserved for the dispatcher, messages not dispatched in time are
+	// dispatched again, so handlers should be idempotent, the default is a minute
+	ClaimTimeout time.Duration
+}
+
+// Outbox return the outbox of the DB, refer `Outbox`
+func (s *DB) Outbox() *Outbox {
+	return &Outbox{db: s.New(), ClaimTimeout: time.Minute}
+}
+
+// Migrate create or migrate the outbox table
+func (outbox *Outbox) Migrate() error {
+	return outbox.db.AutoMigrate(&OutboxMessage{}).Error
+}
+
+// Publish save a message of the topic to the outbox table, payload is encoded as JSON unless it is `[]byte` or `JSON`
+func (outbox *Outbox) Publish(topic string, payload interface{}) error {
+	var data JSON
+	switch value := payload.(type) {
+	case JSON:
+		data = value
+	case []byte:
+		data = JSON(value)
+	default:
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		data = JSON(encoded)
+	}
+
+	return outbox.db.Create(&OutboxMessage{Topic: topic, Payload: data}).Error
+}
+
+// Dispatch claim up to batchSize messages in order of publishing and call handler with them, messages are deleted
+// if handler succeeds, otherwise the error is saved to `LastError` and they are dispatched again after the claim
+// timeout, the number of dispatched messages is returned
+func (outbox *Outbox) Dispatch(batchSize int, handler func(message *OutboxMessage) error) (int, error) {
+	if batchSize <= 0 {
+		return 0, errors.New("batch size of outbox dispatches should be positive")
+	}
+
+	messages, err := outbox.claim(batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var dispatched int
+	for i := range messages {
+		message := &messages[i]
+		if err := handler(message); err != nil {
+			lastError := err.Error()
+			if len(lastError) > 1024 {
+				lastError = lastError[:1024]
+			}
+			if err := outbox.db.Model(message).UpdateColumn("last_error", lastError).Error; err != nil {
+				return dispatched, err
+			}
+			continue
+		}
+
+		if err := outbox.db.Delete(message).Error; err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// Poll dispatch messages with `Dispatch` every interval until ctx is done, messages are dispatched without waiting
+// while batches are full, errors of dispatches are returned
+func (outbox *Outbox) Poll(ctx context.Context, interval time.Duration, batchSize int, handler func(message *OutboxMessage) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		count, err := outbox.Dispatch(batchSize, handler)
+		if err != nil {
+			return err
+		}
+
+		if count < batchSize {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+	}
+}
+
+// claim reserve messages which aren't claimed or whose claims expired for the dispatcher with a random token, so
+// concurrent dispatchers claim different messages without row locks
+func (outbox *Outbox) claim(batchSize int) ([]OutboxMessage, error) {
+	var (
+		now = outbox.db.nowFunc()
+		ids []uint64
+	)
+	err := outbox.db.Model(&OutboxMessage{}).Where("claimed_until IS NULL OR claimed_until < ?", now).
+		Order("id").Limit(batchSize).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	uuid, err := newUUIDv4()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		token        = formatUUID(uuid)
+		claimedUntil = now.Add(outbox.ClaimTimeout)
+	)
+	err = outbox.db.Model(&OutboxMessage{}).
+		Where("id IN (?) AND (claimed_until IS NULL OR claimed_until < ?)", ids, now).
+		UpdateColumns(map[string]interface{}{
+			"claim_token":   token,
+			"claimed_until": claimedUntil,
+			"attempts":      Expr("attempts + 1"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []OutboxMessage
+	err = outbox.db.Where("claim_token = ?", token).Order("id").Find(&messages).Error
+	return messages, err
+}
@@ -0,0 +1,88 @@
+package gorm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zanmato/gorm"
+)
+
+func TestOutbox(t *testing.T) {
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&gorm.OutboxMessage{})
+	if err := db.Outbox().Migrate(); err != nil {
+		t.Fatalf("Failed to migrate the outbox, got %v", err)
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Outbox().Publish("users.created", map[string]interface{}{"name": "outbox"}); err != nil {
+			return err
+		}
+		return tx.Outbox().Publish("users.deleted", []byte(`{"id":1}`))
+	})
+	if err != nil {
+		t.Fatalf("Failed to publish messages, got %v", err)
+	}
+
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.Outbox().Publish("users.rolled_back", nil)
+		return errors.New("rollback")
+	})
+
+	var (
+		topics   []string
+		payloads []map[string]interface{}
+	)
+	handler := func(message *gorm.OutboxMessage) error {
+		if message.Topic == "users.deleted" && message.Attempts == 1 {
+			return errors.New("broker unavailable")
+		}
+		var payload map[string]interface{}
+		json.Unmarshal(message.Payload, &payload)
+		topics = append(topics, message.Topic)
+		payloads = append(payloads, payload)
+		return nil
+	}
+
+	if count, err := DB.Outbox().Dispatch(10, handler); err != nil || count != 1 {
+		t.Errorf("Should dispatch messages of committed transactions, got %v, %v", count, err)
+	}
+	if len(topics) != 1 || topics[0] != "users.created" || payloads[0]["name"] != "outbox" {
+		t.Errorf("Should dispatch published messages, got %v, %v", topics, payloads)
+	}
+
+	var failed gorm.OutboxMessage
+	DB.First(&failed)
+	if failed.Topic != "users.deleted" || failed.LastError != "broker unavailable" || failed.Attempts != 1 {
+		t.Errorf("Failed messages should be kept with their errors, got %+v", failed)
+	}
+
+	if count, _ := DB.Outbox().Dispatch(10, handler); count != 0 {
+		t.Errorf("Claimed messages shouldn't be dispatched before their claims expire, got %v", count)
+	}
+
+	outbox := DB.Outbox()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	go func() {
+		for {
+			var count int
+			if DB.Model(&gorm.OutboxMessage{}).Count(&count); count == 0 {
+				cancel()
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	DB.Model(&gorm.OutboxMessage{}).UpdateColumn("claimed_until", time.Now().Add(-time.Second))
+	if err := outbox.Poll(ctx, 10*time.Millisecond, 10, handler); err != context.Canceled {
+		t.Errorf("Poll should run until the context is done, got %v", err)
+	}
+	if len(topics) != 2 || topics[1] != "users.deleted" {
+		t.Errorf("Messages should be dispatched again after their claims expire, got %v", topics)
+	}
+}
@@ -0,0 +1,51 @@
+package gorm
+
+import (
+	"errors"
+)
+
+// Page is a page of records returned by `Paginate`
+type Page struct {
+	// Page is the current page, starting from 1
+	Page int
+	// PerPage is the max number of records per page
+	PerPage int
+	// Total is the number of all matched records
+	Total int64
+	// Pages is the number of pages
+	Pages int
+	// Records is the destination records are found into
+	Records interface{}
+}
+
+// Paginate count matched records and find records of the page into out, pages start from 1
+//     page, err := db.Where("age > ?", 18).Order("id").Paginate(2, 20, &users)
+//     // SELECT count(*) FROM users WHERE age > 18
+//     // SELECT * FROM users WHERE age > 18 ORDER BY id LIMIT 20 OFFSET 20
+// the total is counted like `Count`, so limit, offset and order of the query are ignored, and queries with group or
+// distinct select are counted as sub queries
+func (s *DB) Paginate(page, perPage int, out interface{}) (*Page, error) {
+	if perPage <= 0 {
+		return nil, errors.New("records per page should be positive")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	tx := s
+	if tx.Value == nil {
+		tx = tx.Model(out)
+	}
+
+	result := &Page{Page: page, PerPage: perPage, Records: out}
+	if err := tx.Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	result.Pages = int((result.Total + int64(perPage) - 1) / int64(perPage))
+	if err := tx.Offset((page - 1) * perPage).Limit(perPage).Find(out).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
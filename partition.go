@@ -0,0 +1,108 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// partitionSQL return the partition clause of the model's table
+func (scope *Scope) partitionSQL() string {
+	if field, method, ok := scope.partitionField(); ok {
+		return fmt.Sprintf(" PARTITION BY %v (%v)", method, scope.Quote(field.DBName))
+	}
+	return ""
+}
+
+// partitionField return the partition key of the model's table and the partition method
+func (scope *Scope) partitionField() (*StructField, string, bool) {
+	if scope.Dialect().GetName() != "postgres" {
+		return nil, "", false
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if method, ok := field.TagSettingsGet("PARTITION_BY"); ok && field.IsNormal {
+			if method == "PARTITION_BY" || method == "" {
+				method = "RANGE"
+			}
+			return field, strings.ToUpper(method), true
+		}
+	}
+	return nil, "", false
+}
+
+// PartitionName return the name of the partition created with `CreatePartition` for values from `from`
+func (s *DB) PartitionName(value interface{}, from time.Time) string {
+	return fmt.Sprintf("%v_p%v", s.NewScope(value).TableName(), from.Format("20060102"))
+}
+
+// CreatePartition create the partition of the range partitioned table for values in [from, to), it is named with
+// the table name and from, refer `PartitionName`.
+// Range partitioned tables of postgres are declared with the tag `partition_by` of the partition key, which is added
+// to the primary key as postgres requires it, tables of other dialects aren't partitioned
+//     type Event struct {
+//       ID        uint
+//       CreatedAt time.Time `gorm:"partition_by:range"`
+//     }
+//     // CREATE TABLE "events" (... , PRIMARY KEY ("id","created_at")) PARTITION BY RANGE ("created_at")
+//     db.CreatePartition(&Event{}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+//     // CREATE TABLE IF NOT EXISTS "events_p20240101" PARTITION OF "events" FOR VALUES FROM ('2024-01-01 00:00:00+00:00') TO ('2024-02-01 00:00:00+00:00')
+func (s *DB) CreatePartition(value interface{}, from, to time.Time) *DB {
+	scope := s.NewScope(value)
+	bounds, err := scope.partitionBounds(from, to)
+	if err != nil {
+		db := s.clone()
+		db.AddError(err)
+		return db
+	}
+
+	return s.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v PARTITION OF %v %v",
+		scope.Quote(s.PartitionName(value, from)), scope.QuotedTableName(), bounds))
+}
+
+// AttachPartition attach the existing table as the partition of the range partitioned table for values in [from, to)
+func (s *DB) AttachPartition(value interface{}, partition string, from, to time.Time) *DB {
+	scope := s.NewScope(value)
+	bounds, err := scope.partitionBounds(from, to)
+	if err != nil {
+		db := s.clone()
+		db.AddError(err)
+		return db
+	}
+
+	return s.Exec(fmt.Sprintf("ALTER TABLE %v ATTACH PARTITION %v %v", scope.QuotedTableName(), scope.Quote(partition), bounds))
+}
+
+// DetachPartition detach the partition from the partitioned table, it is kept as a regular table
+func (s *DB) DetachPartition(value interface{}, partition string) *DB {
+	scope := s.NewScope(value)
+	if _, _, ok := scope.partitionField(); !ok {
+		db := s.clone()
+		db.AddError(scope.partitionError())
+		return db
+	}
+
+	return s.Exec(fmt.Sprintf("ALTER TABLE %v DETACH PARTITION %v", scope.QuotedTableName(), scope.Quote(partition)))
+}
+
+// partitionBounds return the bounds of range partitions, DDL can't have bind vars, so bounds are literals
+func (scope *Scope) partitionBounds(from, to time.Time) (string, error) {
+	if _, method, ok := scope.partitionField(); !ok || method != "RANGE" {
+		return "", scope.partitionError()
+	}
+
+	if !from.Before(to) {
+		return "", fmt.Errorf("partition of %v should start before %v, got %v", scope.TableName(), to, from)
+	}
+
+	fromLiteral, _ := sqlLiteral(scope.Dialect(), from)
+	toLiteral, _ := sqlLiteral(scope.Dialect(), to)
+	return fmt.Sprintf("FOR VALUES FROM (%v) TO (%v)", fromLiteral, toLiteral), nil
+}
+
+func (scope *Scope) partitionError() error {
+	if name := scope.Dialect().GetName(); name != "postgres" {
+		return fmt.Errorf("partitions aren't supported by %v", name)
+	}
+	return fmt.Errorf("%v isn't range partitioned, tag the partition key with `partition_by:range`", scope.TableName())
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zanmato/gorm"
 )
@@ -1695,7 +1697,209 @@ func TestPreloadManyToManyCallbacks(t *testing.T) {
 	}
 }
 
+func TestPreloadUnscoped(t *testing.T) {
+	type UnscopedPreloadOrder struct {
+		ID        uint
+		OwnerID   uint
+		DeletedAt *time.Time
+	}
+
+	type UnscopedPreloadOwner struct {
+		ID        uint
+		Orders    []UnscopedPreloadOrder `gorm:"foreignkey:OwnerID"`
+		DeletedAt *time.Time
+	}
+
+	DB.DropTableIfExists(&UnscopedPreloadOwner{}, &UnscopedPreloadOrder{})
+	if err := DB.AutoMigrate(&UnscopedPreloadOwner{}, &UnscopedPreloadOrder{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	owner := UnscopedPreloadOwner{Orders: []UnscopedPreloadOrder{{}, {}}}
+	DB.Save(&owner)
+	DB.Delete(&owner.Orders[0])
+
+	var got UnscopedPreloadOwner
+	DB.Preload("Orders").First(&got, owner.ID)
+	if len(got.Orders) != 1 {
+		t.Errorf("Soft deleted orders should not be preloaded, but got %v", len(got.Orders))
+	}
+
+	got = UnscopedPreloadOwner{}
+	DB.Preload("Orders", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&got, owner.ID)
+	if len(got.Orders) != 2 {
+		t.Errorf("Unscoped preload should load soft deleted orders, but got %v", len(got.Orders))
+	}
+
+	DB.Delete(&owner)
+	got = UnscopedPreloadOwner{}
+	DB.Unscoped().Preload("Orders").First(&got, owner.ID)
+	if got.ID != owner.ID || len(got.Orders) != 2 {
+		t.Errorf("Unscoped query should load soft deleted orders of soft deleted owner, but got %v", len(got.Orders))
+	}
+}
+
+func TestPreloadWildcard(t *testing.T) {
+	type WildcardPreloadItem struct {
+		ID      uint
+		OrderID uint
+	}
+
+	type WildcardPreloadOrder struct {
+		ID      uint
+		OwnerID uint
+		Items   []WildcardPreloadItem `gorm:"foreignkey:OrderID"`
+	}
+
+	type WildcardPreloadProfile struct {
+		ID      uint
+		OwnerID uint
+	}
+
+	type WildcardPreloadOwner struct {
+		ID      uint
+		Orders  []WildcardPreloadOrder `gorm:"foreignkey:OwnerID"`
+		Profile WildcardPreloadProfile `gorm:"foreignkey:OwnerID"`
+	}
+
+	DB.DropTableIfExists(&WildcardPreloadOwner{}, &WildcardPreloadOrder{}, &WildcardPreloadProfile{}, &WildcardPreloadItem{})
+	if err := DB.AutoMigrate(&WildcardPreloadOwner{}, &WildcardPreloadOrder{}, &WildcardPreloadProfile{}, &WildcardPreloadItem{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	owner := WildcardPreloadOwner{
+		Orders:  []WildcardPreloadOrder{{Items: []WildcardPreloadItem{{}, {}}}, {}},
+		Profile: WildcardPreloadProfile{},
+	}
+	DB.Save(&owner)
+
+	var got WildcardPreloadOwner
+	if err := DB.Preload(gorm.Associations).First(&got, owner.ID).Error; err != nil {
+		t.Fatalf("Failed to preload all associations, got %v", err)
+	}
+	if len(got.Orders) != 2 || got.Profile.ID != owner.Profile.ID {
+		t.Errorf("Should preload all first level associations, but got %v", string(toJSONString(got)))
+	}
+	if len(got.Orders[0].Items) != 0 || len(got.Orders[1].Items) != 0 {
+		t.Errorf("Should not preload nested associations, but got %v", string(toJSONString(got)))
+	}
+
+	got = WildcardPreloadOwner{}
+	DB.PreloadAll(2).First(&got, owner.ID)
+	if len(got.Orders) != 2 || got.Profile.ID != owner.Profile.ID || len(got.Orders[0].Items)+len(got.Orders[1].Items) != 2 {
+		t.Errorf("Should preload associations up to depth 2, but got %v", string(toJSONString(got)))
+	}
+
+	got = WildcardPreloadOwner{}
+	DB.Preload("Orders."+gorm.Associations).First(&got, owner.ID)
+	if got.Profile.ID != 0 || len(got.Orders[0].Items)+len(got.Orders[1].Items) != 2 {
+		t.Errorf("Should preload orders with all their associations, but got %v", string(toJSONString(got)))
+	}
+}
+
 func toJSONString(v interface{}) []byte {
 	r, _ := json.MarshalIndent(v, "", "  ")
 	return r
 }
+
+func TestPreloadSelect(t *testing.T) {
+	user := User{
+		Name:       "preload_select",
+		Emails:     []Email{{Email: "preload_select@example.org"}},
+		CreditCard: CreditCard{Number: "preload_select_card"},
+		Company:    Company{Name: "preload_select_company"},
+		Languages:  []Language{{Name: "preload_select_language"}},
+	}
+	if err := DB.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to save user, got %v", err)
+	}
+
+	var users []User
+	err := DB.Where("id = ?", user.Id).
+		Preload("Emails", gorm.Select("email")).
+		Preload("CreditCard", gorm.Select([]string{"number"})).
+		Preload("Company", gorm.Select("name")).
+		Preload("Languages", gorm.Select("languages.name")).
+		Find(&users).Error
+	if err != nil || len(users) != 1 {
+		t.Fatalf("Failed to preload with selects, got %v", err)
+	}
+
+	found := users[0]
+	if len(found.Emails) != 1 || found.Emails[0].Email != "preload_select@example.org" || found.Emails[0].UserId != int(user.Id) || !found.Emails[0].CreatedAt.IsZero() {
+		t.Errorf("Has many associations should be preloaded with selected columns and keys, got %#v", found.Emails)
+	}
+
+	if found.CreditCard.Number != "preload_select_card" || !found.CreditCard.UserId.Valid || !found.CreditCard.CreatedAt.IsZero() {
+		t.Errorf("Has one associations should be preloaded with selected columns and keys, got %#v", found.CreditCard)
+	}
+
+	if found.Company.Name != "preload_select_company" || found.Company.Id == 0 {
+		t.Errorf("Belongs to associations should be preloaded with selected columns and keys, got %#v", found.Company)
+	}
+
+	if len(found.Languages) != 1 || found.Languages[0].Name != "preload_select_language" || found.Languages[0].ID == 0 || !found.Languages[0].CreatedAt.IsZero() {
+		t.Errorf("Many to many associations should be preloaded with selected columns and keys, got %#v", found.Languages)
+	}
+
+	logger := &printedLogger{}
+	users = nil
+	err = DB.Session(&gorm.Session{Logger: logger}).LogMode(true).Where("id = ?", user.Id).
+		Preload("Emails", gorm.Select("COALESCE(email, '') AS email, COALESCE(user_id, 0) AS user_id")).
+		Find(&users).Error
+	if err != nil || len(users) != 1 || len(users[0].Emails) != 1 || users[0].Emails[0].Email != "preload_select@example.org" {
+		t.Fatalf("Failed to preload with selected expressions, got %v", err)
+	}
+
+	for _, values := range logger.values {
+		if sql, ok := values[3].(string); ok && strings.Contains(sql, "FROM \"emails\"") {
+			if !strings.Contains(sql, "COALESCE(email, '') AS email, COALESCE(user_id, 0) AS user_id") || strings.Contains(sql, "\"emails\".\"user_id\",") {
+				t.Errorf("Keys selected by expressions shouldn't be selected again, got %v", sql)
+			}
+		}
+	}
+}
+
+func TestPreloadConditionWithParents(t *testing.T) {
+	users := []User{
+		{Name: "preload_parents_1", Emails: []Email{{Email: "preload_parents_1@example.org"}, {Email: "other_1@example.org"}}},
+		{Name: "preload_parents_2", Emails: []Email{{Email: "preload_parents_2@example.org"}, {Email: "other_2@example.org"}}},
+	}
+	for i := range users {
+		DB.Save(&users[i])
+	}
+
+	var (
+		found   []User
+		parents interface{}
+	)
+	err := DB.Where("name LIKE ?", "preload_parents_%").Order("id").Preload("Emails", gorm.PreloadCondition(func(db *gorm.DB, value interface{}) *gorm.DB {
+		parents = value
+		var emails []string
+		for _, user := range *value.(*[]User) {
+			emails = append(emails, user.Name+"@example.org")
+		}
+		return db.Where("email IN (?)", emails)
+	})).Find(&found).Error
+	if err != nil || len(found) != 2 {
+		t.Fatalf("Failed to preload with conditions of parents, got %v", err)
+	}
+
+	if parents != &found {
+		t.Errorf("Conditions should be built from the parent records, got %T", parents)
+	}
+
+	for _, user := range found {
+		if len(user.Emails) != 1 || user.Emails[0].Email != user.Name+"@example.org" {
+			t.Errorf("Associations should be filtered with conditions of parents, got %v", user.Emails)
+		}
+	}
+
+	var user User
+	DB.Preload("Emails", func(db *gorm.DB, value interface{}) *gorm.DB {
+		return db.Where("email = ?", value.(*User).Name+"@example.org")
+	}).First(&user, users[1].Id)
+	if len(user.Emails) != 1 || user.Emails[0].Email != "preload_parents_2@example.org" {
+		t.Errorf("Associations of records should be filtered with conditions of parents, got %v", user.Emails)
+	}
+}
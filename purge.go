@@ -0,0 +1,134 @@
+package gorm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultPurgeBatchSize is the max number of records deleted by a statement when purging soft deleted records
+const defaultPurgeBatchSize = 1000
+
+// PurgeSoftDeleted permanently delete records which were soft deleted before retention, in batches like
+// `DeleteInBatches`, conditions are the same as `Delete`, records of models soft deleted with flags can't be purged
+// as their deletion time is unknown
+//     db.PurgeSoftDeleted(&User{}, 90*24*time.Hour)
+func (s *DB) PurgeSoftDeleted(value interface{}, retention time.Duration, where ...interface{}) *DB {
+	return s.purgeSoftDeleted(value, retention, defaultPurgeBatchSize, where...)
+}
+
+func (s *DB) purgeSoftDeleted(value interface{}, retention time.Duration, batchSize int, where ...interface{}) *DB {
+	scope := s.NewScope(value)
+	field, strategy, ok := scope.softDeleteField()
+	if !ok {
+		db := s.clone()
+		db.AddError(fmt.Errorf("%v isn't soft deleted", scope.TableName()))
+		return db
+	}
+
+	var (
+		deletedBefore = scope.db.nowFunc().Add(-retention)
+		column        = fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName))
+		condition     string
+		cutoff        interface{}
+	)
+
+	switch strategy {
+	case softDeleteTime:
+		condition, cutoff = fmt.Sprintf("%v < ?", column), deletedBefore
+	case softDeleteMilli:
+		condition, cutoff = fmt.Sprintf("%v > 0 AND %v < ?", column, column), deletedBefore.UnixNano()/int64(time.Millisecond)
+	case softDeleteUnix:
+		condition, cutoff = fmt.Sprintf("%v > 0 AND %v < ?", column, column), deletedBefore.Unix()
+	default:
+		db := s.clone()
+		db.AddError(fmt.Errorf("can't purge %v soft deleted with flags", scope.TableName()))
+		return db
+	}
+
+	tx := s.Unscoped().Where(condition, cutoff)
+	if len(where) > 0 {
+		tx = tx.Where(where[0], where[1:]...)
+	}
+	return tx.DeleteInBatches(value, batchSize)
+}
+
+// SoftDeletePurger purge soft deleted records of registered models with their retentions, once with `Purge` or
+// periodically with `Start`
+//     purger := gorm.NewSoftDeletePurger(db).Register(&User{}, 90*24*time.Hour).Register(&Order{}, 365*24*time.Hour)
+//     stop := purger.Start(time.Hour)
+//     defer stop()
+type SoftDeletePurger struct {
+	db        *DB
+	batchSize int
+	policies  []purgePolicy
+	mutex     sync.Mutex
+}
+
+type purgePolicy struct {
+	value     interface{}
+	retention time.Duration
+}
+
+// NewSoftDeletePurger create a purger deleting records with db
+func NewSoftDeletePurger(db *DB) *SoftDeletePurger {
+	return &SoftDeletePurger{db: db, batchSize: defaultPurgeBatchSize}
+}
+
+// BatchSize change the max number of records deleted by a statement, which is 1000 by default
+func (purger *SoftDeletePurger) BatchSize(batchSize int) *SoftDeletePurger {
+	purger.batchSize = batchSize
+	return purger
+}
+
+// Register purge records of the model soft deleted before retention
+func (purger *SoftDeletePurger) Register(value interface{}, retention time.Duration) *SoftDeletePurger {
+	purger.policies = append(purger.policies, purgePolicy{value: value, retention: retention})
+	return purger
+}
+
+// Purge purge soft deleted records of registered models, return the number of purged records, models after the
+// model failed to purge are still purged
+func (purger *SoftDeletePurger) Purge() (rowsAffected int64, err error) {
+	purger.mutex.Lock()
+	defer purger.mutex.Unlock()
+
+	for _, policy := range purger.policies {
+		result := purger.db.purgeSoftDeleted(policy.value, policy.retention, purger.batchSize)
+		rowsAffected += result.RowsAffected
+		if result.Error != nil && err == nil {
+			err = result.Error
+		}
+	}
+	return
+}
+
+// Start purge soft deleted records every interval in a goroutine until the returned function is called, errors are
+// logged with the logger of the DB, even if logs are disabled with `LogMode(false)`
+func (purger *SoftDeletePurger) Start(interval time.Duration) (stop func()) {
+	var (
+		ticker = time.NewTicker(interval)
+		done   = make(chan struct{})
+		once   sync.Once
+	)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := purger.Purge(); err != nil {
+					purger.db.print("error", fileWithLineNum(), err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
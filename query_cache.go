@@ -0,0 +1,153 @@
+package gorm
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryCache is the store used to cache query results, could be implemented with redis, memcached etc
+type QueryCache interface {
+	Get(key string) ([]byte, bool)
+	// Set store the value, it never expires if ttl is zero
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// SetQueryCache set the store to cache query results of `Cacheable` queries, cached results of a table
+// will be invalidated when creating, updating or deleting records of the table with this DB
+//     db.SetQueryCache(gorm.NewMemoryQueryCache())
+//     db.Cacheable(time.Minute).Where("role = ?", "admin").Find(&users)
+// Tables are invalidated with the main table of write operations only, raw SQL executed with `Exec`
+// won't invalidate any cache
+func (s *DB) SetQueryCache(cache QueryCache) *DB {
+	s.InstantSet("gorm:query_cache", cache)
+	return s
+}
+
+// Cacheable cache results of queries for the ttl, requires a store set with `SetQueryCache`
+func (s *DB) Cacheable(ttl time.Duration) *DB {
+	return s.Set("gorm:query_cache_ttl", ttl)
+}
+
+func (scope *Scope) queryCache() (QueryCache, bool) {
+	if value, ok := scope.Get("gorm:query_cache"); ok {
+		cache, ok := value.(QueryCache)
+		return cache, ok && cache != nil
+	}
+	return nil, false
+}
+
+func queryCacheVersionKey(tableName string) string {
+	return "gorm:query_cache_version:" + tableName
+}
+
+// invalidateQueryCache invalidate cached results of the table by changing its version
+func invalidateQueryCache(cache QueryCache, tableName string) {
+	cache.Set(queryCacheVersionKey(tableName), []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), 0)
+}
+
+// MemoryQueryCache is an in-memory QueryCache, expired entries are swept when setting values, and results are evicted
+// once it holds MaxEntries values, versions of tables are never evicted, so invalidated results can't be served again
+type MemoryQueryCache struct {
+	mutex      sync.Mutex
+	entries    map[string]memoryQueryCacheEntry
+	maxEntries int
+	sweepAt    int
+}
+
+type memoryQueryCacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+const (
+	defaultMemoryQueryCacheEntries = 10000
+	minMemoryQueryCacheSweep       = 64
+)
+
+// NewMemoryQueryCache create an in-memory QueryCache holding at most 10000 values
+func NewMemoryQueryCache() *MemoryQueryCache {
+	return &MemoryQueryCache{entries: map[string]memoryQueryCacheEntry{}, maxEntries: defaultMemoryQueryCacheEntries, sweepAt: minMemoryQueryCacheSweep}
+}
+
+// MaxEntries change the max number of values held by the cache, no limit if zero
+func (cache *MemoryQueryCache) MaxEntries(maxEntries int) *MemoryQueryCache {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.maxEntries = maxEntries
+	return cache
+}
+
+// Len return the number of values held by the cache, including expired values which aren't swept yet
+func (cache *MemoryQueryCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return len(cache.entries)
+}
+
+// Get get cached value by key
+func (cache *MemoryQueryCache) Get(key string) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[key]
+	if ok && entry.expired(time.Now()) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+	return entry.value, ok
+}
+
+// Set cache value with key for the ttl
+func (cache *MemoryQueryCache) Set(key string, value []byte, ttl time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	entry := memoryQueryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	if _, ok := cache.entries[key]; !ok {
+		// sweep expired entries once the cache doubles, so sweeps take amortized constant time
+		if len(cache.entries) >= cache.sweepAt {
+			cache.sweep(now)
+		}
+
+		if cache.maxEntries > 0 && len(cache.entries) >= cache.maxEntries {
+			cache.evict()
+		}
+	}
+	cache.entries[key] = entry
+}
+
+func (entry memoryQueryCacheEntry) expired(now time.Time) bool {
+	return !entry.expiresAt.IsZero() && now.After(entry.expiresAt)
+}
+
+// sweep delete expired entries
+func (cache *MemoryQueryCache) sweep(now time.Time) {
+	for key, entry := range cache.entries {
+		if entry.expired(now) {
+			delete(cache.entries, key)
+		}
+	}
+
+	if cache.sweepAt = 2 * len(cache.entries); cache.sweepAt < minMemoryQueryCacheSweep {
+		cache.sweepAt = minMemoryQueryCacheSweep
+	}
+}
+
+// evict delete an arbitrary cached result, versions of tables are kept
+func (cache *MemoryQueryCache) evict() {
+	for key := range cache.entries {
+		if !strings.HasPrefix(key, queryCacheVersionKey("")) {
+			delete(cache.entries, key)
+			return
+		}
+	}
+}
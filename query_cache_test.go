@@ -0,0 +1,254 @@
+package gorm_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zanmato/gorm"
+)
+
+func TestQueryCache(t *testing.T) {
+	cache := gorm.NewMemoryQueryCache()
+	db := DB.New().SetQueryCache(cache)
+
+	user := User{Name: "query_cache", Age: 10}
+	db.Save(&user)
+
+	var first User
+	if err := db.Cacheable(time.Minute).First(&first, user.Id).Error; err != nil || first.Age != 10 {
+		t.Fatalf("Should find user, but got %v, %v", first.Age, err)
+	}
+
+	// update without invalidating cache
+	DB.Exec("UPDATE users SET age = ? WHERE id = ?", 20, user.Id)
+
+	var cached User
+	db.Cacheable(time.Minute).First(&cached, user.Id)
+	if cached.Age != 10 {
+		t.Errorf("Should load user from cache, but got age %v", cached.Age)
+	}
+
+	var uncached User
+	db.First(&uncached, user.Id)
+	if uncached.Age != 20 {
+		t.Errorf("Should not use cache if query isn't cacheable, but got age %v", uncached.Age)
+	}
+
+	db.Model(&user).Update("age", 30)
+
+	var reloaded User
+	db.Cacheable(time.Minute).First(&reloaded, user.Id)
+	if reloaded.Age != 30 {
+		t.Errorf("Cache should be invalidated after updating, but got age %v", reloaded.Age)
+	}
+
+	var users []User
+	db.Cacheable(time.Minute).Where("name = ?", "query_cache").Find(&users)
+	db.Delete(&user)
+
+	users = nil
+	db.Cacheable(time.Minute).Where("name = ?", "query_cache").Find(&users)
+	if len(users) != 0 {
+		t.Errorf("Cache should be invalidated after deleting, but got %v users", len(users))
+	}
+}
+
+type CachedAccount struct {
+	ID      uint
+	Secret  string `json:"-"`
+	Balance *int
+	Code    CachedAccountCode
+}
+
+type CachedAccountCode string
+
+func (code CachedAccountCode) MarshalJSON() ([]byte, error) {
+	return []byte(`"masked"`), nil
+}
+
+func TestQueryCacheWithFieldsOfModels(t *testing.T) {
+	db := DB.New().SetQueryCache(gorm.NewMemoryQueryCache())
+	DB.DropTableIfExists(&CachedAccount{})
+	DB.AutoMigrate(&CachedAccount{})
+
+	balance := 0
+	account := CachedAccount{Secret: "secret", Balance: &balance, Code: "A001"}
+	DB.Save(&account)
+
+	var first, cached CachedAccount
+	db.Cacheable(time.Minute).First(&first, account.ID)
+	if err := db.Cacheable(time.Minute).First(&cached, account.ID).Error; err != nil {
+		t.Fatalf("Should find account from cache, got %v", err)
+	}
+
+	if cached.Secret != "secret" || cached.Code != "A001" || cached.Balance == nil || *cached.Balance != 0 {
+		t.Errorf("Cached account should be the same as it is read from the database, but got %#v", cached)
+	}
+}
+
+func TestMemoryQueryCacheExpiration(t *testing.T) {
+	cache := gorm.NewMemoryQueryCache()
+	cache.Set("key", []byte("value"), time.Millisecond)
+	if value, ok := cache.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("Should get cached value, but got %v", string(value))
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Cached value should be expired")
+	}
+}
+
+func TestMemoryQueryCacheSize(t *testing.T) {
+	cache := gorm.NewMemoryQueryCache().MaxEntries(10)
+	cache.Set("gorm:query_cache_version:users", []byte("1"), 0)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key%v", i), []byte("value"), 0)
+	}
+
+	var cached int
+	for i := 0; i < 100; i++ {
+		if _, ok := cache.Get(fmt.Sprintf("key%v", i)); ok {
+			cached++
+		}
+	}
+	if cached != 9 {
+		t.Errorf("Cache should hold at most 10 values, got %v values and the version", cached)
+	}
+
+	if version, ok := cache.Get("gorm:query_cache_version:users"); !ok || string(version) != "1" {
+		t.Errorf("Versions of tables shouldn't be evicted, got %v", string(version))
+	}
+
+	cache = gorm.NewMemoryQueryCache().MaxEntries(0)
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("expiring%v", i), []byte("value"), time.Nanosecond)
+	}
+	time.Sleep(time.Millisecond)
+	cache.Set("kept", []byte("value"), 0)
+
+	if value, ok := cache.Get("kept"); !ok || string(value) != "value" || cache.Len() > 100 {
+		t.Errorf("Expired values should be swept when setting values, got %v values", cache.Len())
+	}
+}
+
+func TestSingleflight(t *testing.T) {
+	db, err := gorm.Open(DB.Dialect().GetName(), DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open db, got %v", err)
+	}
+
+	var queries int32
+	db.Callback().Query().Before("gorm:query").Register("test:slow_query", func(scope *gorm.Scope) {
+		if _, skip := scope.InstanceGet("gorm:skip_query_callback"); !skip {
+			atomic.AddInt32(&queries, 1)
+			time.Sleep(100 * time.Millisecond)
+		}
+	})
+
+	DB.Where("name = ?", "singleflight").Delete(&User{})
+	DB.Save(&User{Name: "singleflight", Age: 10, Emails: []Email{{Email: "singleflight@example.com"}}})
+	DB.Save(&User{Name: "singleflight", Age: 20})
+
+	var (
+		wg      sync.WaitGroup
+		results = make([][]User, 5)
+	)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			db.Singleflight().Where("name = ?", "singleflight").Order("age").Find(&results[i])
+		}(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if queries != 1 {
+		t.Errorf("Identical queries should be collapsed, got %v queries", queries)
+	}
+
+	for _, users := range results {
+		if len(users) != 2 || users[0].Age != 10 || users[1].Age != 20 {
+			t.Errorf("Results should be shared, got %v users", len(users))
+		}
+	}
+
+	results[0][0].Age = 30
+	if results[1][0].Age != 10 {
+		t.Errorf("Shared results should be copied")
+	}
+
+	var user User
+	if err := db.Singleflight().Where("name = ?", "singleflight_not_found").First(&user).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("Should return error of the query, got %v", err)
+	}
+
+	pointerResults := make([][]*User, 2)
+	for i := range pointerResults {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			db.Singleflight().Preload("Emails").Where("name = ?", "singleflight").Order("age").Find(&pointerResults[i])
+		}(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if len(pointerResults[0]) != 2 || len(pointerResults[1]) != 2 || pointerResults[0][0] == pointerResults[1][0] {
+		t.Fatalf("Records of shared results should be copied, got %v, %v", pointerResults[0], pointerResults[1])
+	}
+
+	if len(pointerResults[0][0].Emails) != 1 || len(pointerResults[1][0].Emails) != 1 {
+		t.Fatalf("Preloaded associations should be shared, got %v, %v", pointerResults[0][0].Emails, pointerResults[1][0].Emails)
+	}
+
+	if pointerResults[0][0].Emails[0].Email = "changed@example.com"; pointerResults[1][0].Emails[0].Email != "singleflight@example.com" {
+		t.Errorf("Preloaded associations of shared results should be copied, got %v", pointerResults[1][0].Emails[0].Email)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		db.Singleflight().Where("name = ?", "singleflight").Find(&[]User{})
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if err := db.WithContext(ctx).Singleflight().Where("name = ?", "singleflight").Find(&[]User{}).Error; err != context.Canceled {
+		t.Errorf("Waiting queries should stop when their contexts are done, got %v", err)
+	}
+	wg.Wait()
+
+	// the running query skips left callbacks, including the one sharing its results
+	db.Callback().Query().After("gorm:query").Register("test:skip_left", func(scope *gorm.Scope) {
+		if _, ok := scope.Get("test:skip_left"); ok {
+			scope.SkipLeft()
+		}
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		db.Set("test:skip_left", true).Singleflight().Where("name = ?", "singleflight").Find(&[]User{})
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	released := make(chan error)
+	go func() {
+		var users []User
+		released <- db.Singleflight().Where("name = ?", "singleflight").Find(&users).Error
+	}()
+	select {
+	case err := <-released:
+		if err != nil {
+			t.Errorf("Waiting queries should get results of the running query, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Waiting queries should be released when the running query skips sharing its results")
+	}
+	wg.Wait()
+}
@@ -1,8 +1,12 @@
 package gorm_test
 
 import (
+	"database/sql"
 	"fmt"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/zanmato/gorm"
 
@@ -857,3 +861,884 @@ func TestPluckWithSelect(t *testing.T) {
 		t.Errorf("Should correctly pluck with select, got: %s", userAges)
 	}
 }
+
+func TestFindIntoPartialStruct(t *testing.T) {
+	type PartialSelectArticle struct {
+		ID        uint
+		Title     string
+		Body      string
+		DeletedAt *time.Time
+	}
+
+	type APIArticle struct {
+		ID    uint
+		Title string
+	}
+
+	DB.DropTableIfExists(&PartialSelectArticle{})
+	DB.AutoMigrate(&PartialSelectArticle{})
+
+	DB.Save(&PartialSelectArticle{Title: "partial1", Body: "long body"})
+	deleted := PartialSelectArticle{Title: "partial2", Body: "long body"}
+	DB.Save(&deleted)
+	DB.Delete(&deleted)
+
+	var articles []APIArticle
+	if err := DB.Model(&PartialSelectArticle{}).Find(&articles).Error; err != nil {
+		t.Fatalf("Failed to find into partial struct, got %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "partial1" {
+		t.Errorf("Should find non deleted articles into partial struct, but got %#v", articles)
+	}
+
+	var article APIArticle
+	db := DB.Model(&PartialSelectArticle{}).Where("title = ?", "partial1").First(&article)
+	if db.Error != nil || article.Title != "partial1" {
+		t.Errorf("Should find first article into partial struct, but got %#v, %v", article, db.Error)
+	}
+
+	articles = nil
+	DB.Unscoped().Model(&PartialSelectArticle{}).Find(&articles)
+	if len(articles) != 2 {
+		t.Errorf("Should find soft deleted articles with Unscoped, but got %#v", articles)
+	}
+}
+
+func TestFindIntoMap(t *testing.T) {
+	DB.Save(&User{Name: "find_into_map1", Age: 31})
+	DB.Save(&User{Name: "find_into_map2", Age: 32})
+
+	var results []map[string]interface{}
+	if err := DB.Table("users").Where("name LIKE ?", "find_into_map%").Order("age").Find(&results).Error; err != nil {
+		t.Fatalf("Failed to find into maps, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Should find 2 records, but got %v", len(results))
+	}
+	if results[0]["name"] != "find_into_map1" || results[1]["age"] != int64(32) {
+		t.Errorf("Should convert column values, but got %#v", results)
+	}
+
+	result := map[string]interface{}{}
+	if err := DB.Model(&User{}).Where("name = ?", "find_into_map2").First(&result).Error; err != nil {
+		t.Fatalf("Failed to find first into map, got %v", err)
+	}
+	if result["name"] != "find_into_map2" {
+		t.Errorf("Should find first record into map, but got %#v", result)
+	}
+
+	if err := DB.Table("users").Where("name = ?", "find_into_map_none").First(&result).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("Should return record not found error, but got %v", err)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	DB.Save(&User{Name: "set_operation1", Age: 41})
+	DB.Save(&User{Name: "set_operation2", Age: 42})
+	DB.Save(&User{Name: "set_operation3", Age: 43})
+
+	type result struct {
+		Name string
+	}
+
+	var (
+		results []result
+		older   = DB.Table("users").Select("name").Where("name LIKE ? AND age > ?", "set_operation%", 41)
+		younger = DB.Table("users").Select("name").Where("name LIKE ? AND age < ?", "set_operation%", 43)
+	)
+
+	if err := older.Union(younger).Order("name DESC").Limit(2).Find(&results).Error; err != nil {
+		t.Fatalf("Failed to query with union, got %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "set_operation3" || results[1].Name != "set_operation2" {
+		t.Errorf("Should union queries, but got %#v", results)
+	}
+
+	results = nil
+	older.UnionAll(younger).Find(&results)
+	if len(results) != 4 {
+		t.Errorf("Should union all queries, but got %#v", results)
+	}
+
+	results = nil
+	older.Intersect(younger).Find(&results)
+	if len(results) != 1 || results[0].Name != "set_operation2" {
+		t.Errorf("Should intersect queries, but got %#v", results)
+	}
+
+	results = nil
+	older.Except(younger).Find(&results)
+	if len(results) != 1 || results[0].Name != "set_operation3" {
+		t.Errorf("Should except queries, but got %#v", results)
+	}
+
+	var count int
+	DB.Table("users").Where("name IN (?)", older.Except(younger).QueryExpr()).Count(&count)
+	if count != 1 {
+		t.Errorf("Should use set operation as sub query, but got %v", count)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	for i := 1; i <= 3; i++ {
+		DB.Save(&User{Name: "iterate", Age: int64(i), Emails: []Email{{Email: fmt.Sprintf("iterate%v@example.com", i)}}})
+	}
+
+	iterator := DB.Model(&User{}).Where("name = ?", "iterate").Order("age").Preload("Emails").Iterate()
+	var users []User
+	for iterator.Next() {
+		var user User
+		if err := iterator.Scan(&user); err != nil {
+			t.Fatalf("Failed to scan record, got %v", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := iterator.Err(); err != nil {
+		t.Errorf("Should iterate without error, got %v", err)
+	}
+
+	if len(users) != 3 || users[2].Age != 3 || len(users[2].Emails) != 1 || users[2].Emails[0].Email != "iterate3@example.com" {
+		t.Errorf("Should iterate preloaded records, got %#v", users)
+	}
+
+	if iterator.Next() || iterator.Scan(&User{}) != gorm.ErrIteratorClosed {
+		t.Errorf("Finished iterator should be closed")
+	}
+
+	DB.Save(&Product{Code: "iterate"})
+	iterator = DB.Model(&Product{}).Where("code = ?", "iterate").Iterate()
+	defer iterator.Close()
+	var product Product
+	if !iterator.Next() || iterator.Scan(&product) != nil || product.AfterFindCallTimes != 1 {
+		t.Errorf("AfterFind hooks should be called when iterating, got %v", product.AfterFindCallTimes)
+	}
+
+	if iterator = DB.Table("not_existing").Iterate(); iterator.Next() || iterator.Err() == nil {
+		t.Errorf("Iterator should report errors of the query")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		DB.Save(&User{Name: "paginate", Age: int64(i % 3), Emails: []Email{{Email: "paginate@example.com"}}})
+	}
+
+	var users []User
+	page, err := DB.Where("name = ?", "paginate").Order("age desc").Limit(1).Paginate(2, 2, &users)
+	if err != nil {
+		t.Fatalf("Failed to paginate, got %v", err)
+	}
+
+	if page.Total != 5 || page.Pages != 3 || page.Page != 2 || len(users) != 2 || users[0].Age != 1 {
+		t.Errorf("Should paginate records, got %#v, %#v", page, users)
+	}
+
+	var ages []struct{ Age int64 }
+	page, err = DB.Model(&User{}).Select("age").Where("name = ?", "paginate").Group("age").Order("age").Paginate(1, 2, &ages)
+	if err != nil {
+		t.Fatalf("Failed to paginate grouped query, got %v", err)
+	}
+
+	if page.Total != 3 || page.Pages != 2 || len(ages) != 2 {
+		t.Errorf("Should count groups when paginating grouped query, got %#v, %#v", page, ages)
+	}
+
+	page, err = DB.Joins("JOIN emails ON emails.user_id = users.id").Where("users.name = ?", "paginate").Paginate(3, 2, &users)
+	if err != nil || page.Total != 5 || len(users) != 1 {
+		t.Errorf("Should paginate joined query, got %v, %#v, %v", err, page, len(users))
+	}
+}
+
+func TestILike(t *testing.T) {
+	DB.Save(&User{Name: "ILikeJinzhu", Age: 1}).Save(&User{Name: "ilike_jinzhu", Age: 1}).Save(&User{Name: "ILIKE_OTHER", Age: 2})
+
+	var users []User
+	DB.Where(gorm.ILike("name", "ilike%jinzhu")).Find(&users)
+	if len(users) != 2 {
+		t.Errorf("Should find users with case-insensitive pattern, got %v", len(users))
+	}
+
+	DB.Where(gorm.ILike("name", "ILIKE%")).Not(gorm.ILike("name", "%other")).Find(&users)
+	if len(users) != 2 {
+		t.Errorf("Should exclude users with case-insensitive pattern, got %v", len(users))
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).Where(gorm.ILike("name", "%jinzhu%")).Find(&[]User{}).DryRunSQL()
+	if DB.Dialect().GetName() == "postgres" {
+		if !strings.Contains(sql, `"name" ILIKE`) {
+			t.Errorf("Should use ILIKE for postgres, got %v", sql)
+		}
+	} else if !strings.Contains(sql, "LIKE LOWER(") {
+		t.Errorf("Should compare lower cased values, got %v", sql)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	DB.Save(&User{Name: "explain"})
+
+	var users []User
+	plan, err := DB.Where("name = ?", "explain").Explain(&users)
+	if DB.Dialect().GetName() == "mssql" {
+		if err == nil {
+			t.Errorf("Should return error for dialects without explain")
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("Failed to explain query, got %v", err)
+	}
+
+	if len(plan.Rows) == 0 || len(plan.Columns) == 0 || !strings.HasPrefix(plan.SQL, "EXPLAIN") || !strings.Contains(plan.SQL, "name = ") {
+		t.Errorf("Should return plan of the query, got %+v", plan)
+	}
+
+	if len(users) != 0 {
+		t.Errorf("Query shouldn't be executed when explaining, got %v", users)
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" {
+		if !strings.Contains(plan.String(), "users") {
+			t.Errorf("Plan should mention the table, got %v", plan)
+		}
+
+		if _, err := DB.ExplainAnalyze(&users); err == nil {
+			t.Errorf("Should return error as sqlite doesn't support explain analyze")
+		}
+	}
+}
+
+func TestWherePlaceholdersMismatch(t *testing.T) {
+	if err := DB.Where("name = ? AND age = ?", "jinzhu").Error; err == nil || !strings.Contains(err.Error(), "2 placeholders") {
+		t.Errorf("Should return error at Where if arguments are missing, got %v", err)
+	}
+
+	if err := DB.Where("name = ?", "jinzhu", 10).Find(&[]User{}).Error; err == nil {
+		t.Errorf("Should return error if arguments are excess")
+	}
+
+	if err := DB.Or("age > ?", 1, 2).Error; err == nil {
+		t.Errorf("Should check placeholders of Or")
+	}
+
+	if err := DB.Not("name = ?", 1, 2).Error; err == nil {
+		t.Errorf("Should check placeholders of Not")
+	}
+
+	if err := DB.Having("count(*) > ?", 1, 2).Error; err == nil {
+		t.Errorf("Should check placeholders of Having")
+	}
+
+	// `?` is an operator of postgres jsonb, it's escaped as `??` in conditions with arguments
+	if err := DB.Where("attributes ? 'color'").Error; err != nil {
+		t.Errorf("Conditions without arguments shouldn't be checked, got %v", err)
+	}
+
+	sql, vars := DB.Session(&gorm.Session{DryRun: true}).Where("attributes ?? 'color' AND name = ?", "jinzhu").Find(&[]User{}).DryRunSQL()
+	if !strings.Contains(sql, "attributes ? 'color' AND name = ") || len(vars) != 1 {
+		t.Errorf("Escaped placeholders should be kept as operators, got %v, %v", sql, vars)
+	}
+
+	DB.Save(&User{Name: "what?", Age: 30})
+	var user User
+	if err := DB.Where("name = 'what?' AND age = ?", 30).First(&user).Error; err != nil || user.Name != "what?" {
+		t.Errorf("Placeholders in quoted strings should be kept, got %+v, %v", user, err)
+	}
+
+	if err := DB.Not("name", []string{"jinzhu"}).Where("1").Find(&[]User{}).Error; err != nil {
+		t.Errorf("Conditions without placeholders shouldn't be checked, got %v", err)
+	}
+}
+
+func TestWhereStructWithSearchFields(t *testing.T) {
+	DB.Save(&User{Name: "search_fields", Age: 0})
+	DB.Save(&User{Name: "search_fields", Age: 20})
+
+	var users []User
+	DB.Where(&User{Name: "search_fields", Age: 0}).Find(&users)
+	if len(users) != 2 {
+		t.Errorf("Blank fields of struct conditions should be ignored, got %v", len(users))
+	}
+
+	DB.Where(&User{Name: "search_fields", Age: 0}, "Name", "age").Find(&users)
+	if len(users) != 1 || users[0].Age != 0 {
+		t.Errorf("Specified blank fields should be used in conditions, got %+v", users)
+	}
+
+	DB.Not(&User{Age: 0}, "Age").Where("name = ?", "search_fields").Find(&users)
+	if len(users) != 1 || users[0].Age != 20 {
+		t.Errorf("Specified blank fields should be used in not conditions, got %+v", users)
+	}
+
+	if err := DB.Where(&User{Name: "search_fields"}, "Unknown").Find(&users).Error; err == nil {
+		t.Errorf("Should return error for unknown search fields")
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	DB.Save(&User{Name: "order_by", Age: 10})
+	DB.Save(&User{Name: "order_by", Age: 20})
+
+	var users []User
+	if err := DB.Where("name = ?", "order_by").OrderBy("age", true).Find(&users).Error; err != nil || len(users) != 2 || users[0].Age != 20 {
+		t.Errorf("Should order by the column, got %+v, %v", users, err)
+	}
+
+	if err := DB.Where("name = ?", "order_by").OrderBy("Age", false).Find(&users).Error; err != nil || len(users) != 2 || users[0].Age != 10 {
+		t.Errorf("Should order by the field name, got %+v, %v", users, err)
+	}
+
+	if err := DB.OrderBy("age; DROP TABLE users", false).Find(&users).Error; err == nil {
+		t.Errorf("Should return error for invalid columns")
+	}
+
+	if err := DB.OrderBy("unknown", false).Find(&users).Error; err == nil {
+		t.Errorf("Should return error for columns which aren't fields of the model")
+	}
+
+	if err := DB.OrderBy("age", false, "name", "created_at").Find(&users).Error; err == nil {
+		t.Errorf("Should return error for columns which aren't allowed")
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).OrderBy("users.name", true, "users.name").Find(&users).DryRunSQL()
+	if !strings.Contains(sql, "ORDER BY "+DB.Dialect().Quote("users")+"."+DB.Dialect().Quote("name")+" DESC") {
+		t.Errorf("Allowed columns should be quoted, got %v", sql)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	DB.Save(&User{Name: "filter_jinzhu", Age: 17})
+	DB.Save(&User{Name: "filter_jinzhu", Age: 18})
+	DB.Save(&User{Name: "filter_jinzhu", Age: 30})
+
+	values, _ := url.ParseQuery("name[like]=filter_jin%25&age[gte]=18&age[nin]=30,40&page=2")
+	filters, err := gorm.ParseFilters(values, "name", "age")
+	if err != nil || len(filters) != 3 {
+		t.Fatalf("Failed to parse filters, got %+v, %v", filters, err)
+	}
+
+	var users []User
+	if err := DB.Filter(filters...).Find(&users).Error; err != nil || len(users) != 1 || users[0].Age != 18 {
+		t.Errorf("Should find records with filters, got %+v, %v", users, err)
+	}
+
+	if err := DB.Filter(gorm.Filter{Field: "Name", Value: "filter_jinzhu"}, gorm.Filter{Field: "age", Operator: "in", Value: []int{17, 30}}).Find(&users).Error; err != nil || len(users) != 2 {
+		t.Errorf("Should find records with filters of field names, got %+v, %v", users, err)
+	}
+
+	if _, err := gorm.ParseFilters(url.Values{"age[between]": {"1"}}); err == nil {
+		t.Errorf("Should return error for unknown operators")
+	}
+
+	if err := DB.Filter(gorm.Filter{Field: "unknown", Value: "x"}).Find(&users).Error; err == nil {
+		t.Errorf("Should return error for unknown fields")
+	}
+
+	if err := DB.Filter(gorm.Filter{Field: "age", Value: "x; DROP TABLE users"}).Find(&users).Error; err == nil {
+		t.Errorf("Should return error for invalid values")
+	}
+
+	if err := DB.Filter(gorm.Filter{Field: "name", Value: "filter_jinzhu"}, gorm.Filter{Field: "birthday", Operator: "null", Value: "true"}).Find(&users).Error; err != nil || len(users) != 3 {
+		t.Errorf("Should find records with null filters, got %+v, %v", users, err)
+	}
+}
+
+func TestBatchLoad(t *testing.T) {
+	db, err := gorm.Open(DB.Dialect().GetName(), DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open db, got %v", err)
+	}
+
+	var (
+		queries []string
+		mutex   sync.Mutex
+	)
+	db.Callback().Query().Before("gorm:query").Register("test:count_queries", func(scope *gorm.Scope) {
+		if _, skip := scope.InstanceGet("gorm:skip_query_callback"); !skip {
+			mutex.Lock()
+			queries = append(queries, scope.TableName())
+			mutex.Unlock()
+		}
+	})
+
+	DB.Where("name LIKE ?", "batch_load_%").Delete(&User{})
+	users := []User{{Name: "batch_load_1"}, {Name: "batch_load_2"}, {Name: "batch_load_3"}}
+	for i := range users {
+		DB.Save(&users[i])
+	}
+
+	load := func(db *gorm.DB, ids ...interface{}) ([]User, []error) {
+		var (
+			results = make([]User, len(ids))
+			errs    = make([]error, len(ids))
+			done    = make(chan int)
+		)
+		for i, id := range ids {
+			go func(i int, id interface{}) {
+				errs[i] = db.First(&results[i], id).Error
+				done <- i
+			}(i, id)
+		}
+		for range ids {
+			<-done
+		}
+		return results, errs
+	}
+
+	loader := gorm.NewBatchLoader(20 * time.Millisecond)
+	results, errs := load(db.BatchLoad(loader), users[0].Id, users[1].Id, fmt.Sprint(users[2].Id), users[0].Id, int64(0))
+	if len(queries) != 1 {
+		t.Errorf("Queries within the window should be batched, got %v queries", len(queries))
+	}
+
+	for i, name := range []string{"batch_load_1", "batch_load_2", "batch_load_3", "batch_load_1"} {
+		if errs[i] != nil || results[i].Name != name {
+			t.Errorf("Should load %v with the batch, got %v, %v", name, results[i].Name, errs[i])
+		}
+	}
+
+	if errs[4] != gorm.ErrRecordNotFound {
+		t.Errorf("Should return ErrRecordNotFound if the record isn't loaded, got %v", errs[4])
+	}
+
+	queries = nil
+	load(db.BatchLoad(gorm.NewBatchLoader(time.Hour).MaxBatch(2)), users[0].Id, users[1].Id, users[2].Id, users[0].Id+users[1].Id+users[2].Id)
+	if len(queries) != 2 {
+		t.Errorf("Full batches should be loaded without waiting, got %v queries", len(queries))
+	}
+
+	queries = nil
+	var user User
+	if err := db.BatchLoad(loader).Where("name = ?", "batch_load_2").First(&user).Error; err != nil || user.Id != users[1].Id {
+		t.Errorf("Queries with conditions shouldn't be batched, got %v", err)
+	}
+
+	var userInTransaction User
+	db.BatchLoad(loader).Transaction(func(tx *gorm.DB) error {
+		return tx.First(&userInTransaction, users[2].Id).Error
+	})
+	if len(queries) != 2 || userInTransaction.Id != users[2].Id {
+		t.Errorf("Queries with conditions or in transactions should be executed directly, got %v queries", len(queries))
+	}
+}
+
+func TestScanWithCachedPlans(t *testing.T) {
+	DB.Where("name = ?", "scan_plan").Delete(&User{})
+	birthday := parseTime("2000-1-1")
+	user := User{Name: "scan_plan", Age: 18, Birthday: birthday}
+	DB.Save(&user)
+
+	for i := 0; i < 2; i++ {
+		var users []User
+		if err := DB.Where("name = ?", "scan_plan").Find(&users).Error; err != nil || len(users) != 1 {
+			t.Fatalf("Should find records, got %v", err)
+		}
+
+		if users[0].Id != user.Id || users[0].Age != 18 || users[0].Birthday == nil || !users[0].Birthday.Equal(*birthday) {
+			t.Errorf("Records should be scanned with the cached plan, got %#v", users[0])
+		}
+
+		var reordered []*User
+		DB.Select("age, name").Where("name = ?", "scan_plan").Find(&reordered)
+		if len(reordered) != 1 || reordered[0].Id != 0 || reordered[0].Age != 18 || reordered[0].Name != "scan_plan" {
+			t.Errorf("Plans should be cached for selected columns, got %#v", reordered)
+		}
+
+		var unknown User
+		DB.Select("name, 1 AS unknown_column").Where("name = ?", "scan_plan").First(&unknown)
+		if unknown.Name != "scan_plan" || unknown.Age != 0 {
+			t.Errorf("Unknown columns should be ignored, got %#v", unknown)
+		}
+	}
+}
+
+func TestCountWithGroupAndDistinct(t *testing.T) {
+	DB.Where("name LIKE ?", "count_group_%").Delete(&User{})
+	for _, user := range []User{{Name: "count_group_1", Age: 10}, {Name: "count_group_2", Age: 10}, {Name: "count_group_3", Age: 20}} {
+		DB.Save(&user)
+	}
+
+	tx := DB.Model(&User{}).Where("name LIKE ?", "count_group_%")
+
+	var count int64
+	if err := tx.Order("age").Limit(1).Offset(1).Count(&count).Error; err != nil || count != 3 {
+		t.Errorf("Count should ignore order, limit and offset, got %v, %v", count, err)
+	}
+
+	if err := tx.Select("name, age").Count(&count).Error; err != nil || count != 3 {
+		t.Errorf("Count should replace selected columns, got %v, %v", count, err)
+	}
+
+	if err := tx.Group("age").Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("Count should count groups, got %v, %v", count, err)
+	}
+
+	if err := tx.Select("age, count(*)").Group("age").Order("age").Limit(1).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("Count should count groups with selected columns, got %v, %v", count, err)
+	}
+
+	if err := tx.Select("DISTINCT age").Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("Count should count distinct rows, got %v, %v", count, err)
+	}
+
+	if err := tx.Select("count(distinct age)").Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("Count should count with the selected counting expression, got %v, %v", count, err)
+	}
+}
+
+type SortedEvent struct {
+	ID        string `gorm:"primary_key"`
+	Name      string
+	Position  int
+	CreatedAt time.Time `gorm:"sort_key"`
+}
+
+type CompositeEvent struct {
+	Tenant string `gorm:"primary_key"`
+	Code   string `gorm:"primary_key"`
+}
+
+func TestFirstAndLastWithSortKey(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&SortedEvent{}, &CompositeEvent{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&SortedEvent{}, &CompositeEvent{})
+
+	now := time.Now().Round(time.Second)
+	DB.Create(&SortedEvent{ID: "c", Name: "first", Position: 3, CreatedAt: now.Add(-time.Hour)})
+	DB.Create(&SortedEvent{ID: "a", Name: "last", Position: 1, CreatedAt: now})
+	DB.Create(&SortedEvent{ID: "b", Name: "middle", Position: 2, CreatedAt: now.Add(-time.Minute)})
+
+	var first, last SortedEvent
+	DB.First(&first)
+	DB.Last(&last)
+	if first.Name != "first" || last.Name != "last" {
+		t.Errorf("First and Last should order by fields tagged with sort_key, got %v, %v", first.Name, last.Name)
+	}
+
+	var byPosition SortedEvent
+	if err := DB.SortKey("Position").Last(&byPosition).Error; err != nil || byPosition.Name != "first" {
+		t.Errorf("First and Last should order by the sort key, got %v, %v", byPosition.Name, err)
+	}
+
+	if err := DB.SortKey("unknown").First(&SortedEvent{}).Error; err == nil || !strings.Contains(err.Error(), "unknown sort key") {
+		t.Errorf("Should return error of unknown sort keys, got %v", err)
+	}
+
+	DB.Create(&CompositeEvent{Tenant: "b", Code: "a"})
+	DB.Create(&CompositeEvent{Tenant: "a", Code: "b"})
+	DB.Create(&CompositeEvent{Tenant: "a", Code: "a"})
+
+	var firstComposite, lastComposite CompositeEvent
+	DB.First(&firstComposite)
+	DB.Last(&lastComposite)
+	if firstComposite.Tenant != "a" || firstComposite.Code != "a" || lastComposite.Tenant != "b" || lastComposite.Code != "a" {
+		t.Errorf("Records should be ordered by all columns of composite primary keys, got %+v, %+v", firstComposite, lastComposite)
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).First(&SortedEvent{}).DryRunSQL()
+	if !strings.Contains(sql, `created_at" ASC,`) && !strings.Contains(sql, "created_at` ASC,") {
+		t.Errorf("Primary keys should be ordered after sort keys, got %v", sql)
+	}
+}
+
+type AliasEmployee struct {
+	ID        uint
+	Name      string
+	ManagerID *uint
+}
+
+func TestAlias(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&AliasEmployee{})
+	if err := DB.Set("gorm:table_options", "").CreateTable(&AliasEmployee{}).Error; err != nil {
+		t.Fatalf("Failed to create table, got %v", err)
+	}
+
+	manager := AliasEmployee{Name: "alias_manager"}
+	DB.Create(&manager)
+	employee := AliasEmployee{Name: "alias_employee", ManagerID: &manager.ID}
+	DB.Create(&employee)
+
+	var results []struct {
+		Name        string
+		ManagerName string
+	}
+	err := DB.Model(&AliasEmployee{}).Alias("e").Select("e.name, managers.name AS manager_name").
+		Joins("LEFT JOIN ? ON managers.id = e.manager_id", DB.Model(&AliasEmployee{}).Alias("managers")).
+		Where(&AliasEmployee{Name: "alias_employee"}).Scan(&results).Error
+	if err != nil {
+		t.Fatalf("Failed to query with aliases, got %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "alias_employee" || results[0].ManagerName != "alias_manager" {
+		t.Errorf("Should join the table with itself, got %+v", results)
+	}
+
+	var count int
+	DB.Model(&AliasEmployee{}).Alias("e").Where("e.manager_id IS NULL").Count(&count)
+	if count != 1 {
+		t.Errorf("Should count records with the alias, got %v", count)
+	}
+
+	if err := DB.Model(&employee).Alias("e").Update("name", "alias_renamed").Error; err != nil {
+		t.Errorf("Aliases shouldn't be used to update records, got %v", err)
+	}
+
+	var found AliasEmployee
+	if err := DB.Alias("e").Where("e.name = ?", "alias_renamed").First(&found).Error; err != nil || found.ID != employee.ID {
+		t.Errorf("Should find the record with the alias, got %v", err)
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).Alias("e").Find(&[]AliasEmployee{}).DryRunSQL()
+	if !strings.Contains(sql, DB.Dialect().Quote("alias_employees")+" AS "+DB.Dialect().Quote("e")) {
+		t.Errorf("Should select from the table with the alias, got %v", sql)
+	}
+}
+
+func TestNamedArgs(t *testing.T) {
+	DB.Where("name LIKE ?", "named_args%").Delete(&User{})
+	DB.Save(&User{Name: "named_args", Age: 20})
+	DB.Save(&User{Name: "named_args_old", Age: 40})
+
+	filter := struct {
+		Name string
+		Age  int
+	}{Name: "named_args%", Age: 30}
+
+	var users []User
+	if err := DB.Raw("SELECT * FROM users WHERE name LIKE @Name AND age > @Age", filter).Scan(&users).Error; err != nil || len(users) != 1 || users[0].Name != "named_args_old" {
+		t.Errorf("Should bind fields of the struct, got %v, %v", err, len(users))
+	}
+
+	users = nil
+	DB.Where("name = @name OR (name LIKE @name || '%' AND age = @age)", map[string]interface{}{"name": "named_args", "age": 40}).Order("age").Find(&users)
+	if len(users) != 2 {
+		t.Errorf("Should bind keys of the map, got %v", len(users))
+	}
+
+	if err := DB.Exec("UPDATE users SET age = @age WHERE name = @name", sql.Named("age", 50), sql.Named("name", "named_args")).Error; err != nil {
+		t.Errorf("Should bind named args, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&User{}).Where("name = @name AND age = @age", map[string]interface{}{"name": "named_args", "age": 50}).Count(&count); count != 1 {
+		t.Errorf("Named args should be bound when conditions are built again, got %v", count)
+	}
+
+	if err := DB.Where("name = @name AND age = @age", map[string]interface{}{"name": "named_args"}).Find(&users).Error; err == nil {
+		t.Errorf("Should return error if named args are missing")
+	}
+
+	users = nil
+	DB.Where("email = '@example' OR name = @Name", &User{Name: "named_args"}).Find(&users)
+	if len(users) != 1 {
+		t.Errorf("Named args in quotes shouldn't be bound, got %v", len(users))
+	}
+}
+
+func TestPluckMap(t *testing.T) {
+	DB.Where("name LIKE ?", "pluck_map%").Delete(&User{})
+	user1 := User{Name: "pluck_map_1", Age: 10}
+	user2 := User{Name: "pluck_map_2", Age: 20}
+	DB.Save(&user1).Save(&user2)
+
+	var names map[int64]string
+	if err := DB.Model(&User{}).Where("name LIKE ?", "pluck_map%").PluckMap("id", "name", &names).Error; err != nil {
+		t.Fatalf("Failed to pluck map, got %v", err)
+	}
+	if len(names) != 2 || names[user1.Id] != "pluck_map_1" || names[user2.Id] != "pluck_map_2" {
+		t.Errorf("Should pluck values keyed by ids, got %v", names)
+	}
+
+	ages := map[string]int{"stale": 1}
+	DB.Model(&User{}).Where("name LIKE ?", "pluck_map%").PluckMap("name", "age", &ages)
+	if len(ages) != 2 || ages["pluck_map_1"] != 10 || ages["pluck_map_2"] != 20 {
+		t.Errorf("Should pluck values into a new map, got %v", ages)
+	}
+
+	if err := DB.Model(&User{}).PluckMap("id", "name", names).Error; err == nil {
+		t.Errorf("Should return error if the destination isn't a pointer to a map")
+	}
+}
+
+func TestErrorOnNotFound(t *testing.T) {
+	DB.Where("name = ?", "error_on_not_found").Delete(&User{})
+
+	var user User
+	if err := DB.Take(&user, "name = ?", "error_on_not_found").Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("Take should return ErrRecordNotFound by default, got %v", err)
+	}
+
+	db := DB.ErrorOnNotFound(false)
+	for _, find := range []func(out interface{}) *gorm.DB{
+		func(out interface{}) *gorm.DB { return db.First(out, "name = ?", "error_on_not_found") },
+		func(out interface{}) *gorm.DB { return db.Take(out, "name = ?", "error_on_not_found") },
+		func(out interface{}) *gorm.DB { return db.Last(out, "name = ?", "error_on_not_found") },
+		func(out interface{}) *gorm.DB { return db.Where("name = ?", "error_on_not_found").Find(out) },
+	} {
+		var user User
+		if result := find(&user); result.Error != nil || result.RowsAffected != 0 || user.Id != 0 {
+			t.Errorf("Should leave the record zero without errors, got %v, %v", result.Error, user.Id)
+		}
+	}
+
+	var users []User
+	if err := DB.Where("name = ?", "error_on_not_found").Find(&users).Error; err != nil {
+		t.Errorf("Finding no records into slices shouldn't be an error, got %v", err)
+	}
+
+	user = User{}
+	if err := db.FirstOrCreate(&user, User{Name: "error_on_not_found"}).Error; err != nil || user.Id == 0 {
+		t.Errorf("FirstOrCreate should create the record, got %v", err)
+	}
+
+	var found User
+	if err := db.First(&found, "name = ?", "error_on_not_found").Error; err != nil || found.Id != user.Id {
+		t.Errorf("Should find the record, got %v", err)
+	}
+}
+
+type rowNumberClause struct {
+	partitionBy, orderBy string
+}
+
+func (rowNumber rowNumberClause) Build(builder gorm.ClauseBuilder) {
+	builder.WriteString("ROW_NUMBER() OVER (PARTITION BY ")
+	builder.WriteQuoted(rowNumber.partitionBy)
+	builder.WriteString(" ORDER BY ")
+	builder.WriteQuoted(rowNumber.orderBy)
+	builder.WriteString(" DESC)")
+}
+
+func TestClause(t *testing.T) {
+	DB.Save(&User{Name: "clause_a", Age: 10}).Save(&User{Name: "clause_a", Age: 20}).Save(&User{Name: "clause_b", Age: 30})
+	defer DB.Where("name LIKE ?", "clause_%").Delete(&User{})
+
+	inNames := gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+		builder.WriteQuoted("users.name")
+		builder.WriteString(" IN (")
+		builder.AddVar([]string{"clause_a", "clause_b"})
+		builder.WriteString(")")
+	})
+
+	var users []User
+	if err := DB.Where(inNames).Order(gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+		builder.WriteString("CASE WHEN ")
+		builder.WriteQuoted("name")
+		builder.WriteString(" = ")
+		builder.AddVar("clause_b")
+		builder.WriteString(" THEN 0 ELSE 1 END, ")
+		builder.WriteQuoted("age")
+	})).Find(&users).Error; err != nil {
+		t.Fatalf("Should find with clauses, got %v", err)
+	}
+	if len(users) != 3 || users[0].Name != "clause_b" || users[1].Age != 10 {
+		t.Errorf("Should filter and order with clauses, got %+v", users)
+	}
+
+	injection := gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+		builder.WriteQuoted("name")
+		builder.WriteString(" = ")
+		builder.AddVar("clause_a' OR '1' = '1")
+	})
+	if count := 0; DB.Model(&User{}).Where(injection).Count(&count).Error != nil || count != 0 {
+		t.Errorf("Values of clauses should be bound as vars, got %v", count)
+	}
+
+	if err := DB.Where(inNames).Not(gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+		builder.WriteQuoted("age")
+		builder.WriteString(" < ")
+		builder.AddVar(gorm.Expr("? + ?", 10, 5))
+	})).Find(&users).Error; err != nil || len(users) != 2 {
+		t.Errorf("Should exclude records with clauses and nested expressions, got %+v, %v", users, err)
+	}
+
+	var positions []struct {
+		Name     string
+		Age      int
+		Position int
+	}
+	if err := DB.Model(&User{}).Select("name, age, ? AS position", rowNumberClause{"name", "age"}).Where(inNames).Order("age").Scan(&positions).Error; err != nil {
+		t.Fatalf("Should select with clauses, got %v", err)
+	}
+	if len(positions) != 3 || positions[0].Position != 2 || positions[1].Position != 1 || positions[2].Position != 1 {
+		t.Errorf("Should select window functions built with clauses, got %+v", positions)
+	}
+
+	var groups []struct {
+		Name  string
+		Total int
+	}
+	DB.Model(&User{}).Select("name, SUM(age) AS total").Where(inNames).Group("name").Having(gorm.ClauseFunc(func(builder gorm.ClauseBuilder) {
+		builder.WriteString("COUNT(*) > ")
+		builder.AddVar(1)
+	})).Scan(&groups)
+	if len(groups) != 1 || groups[0].Name != "clause_a" || groups[0].Total != 30 {
+		t.Errorf("Should filter groups with clauses, got %+v", groups)
+	}
+
+	sql, vars := DB.Session(&gorm.Session{DryRun: true}).Where(inNames).Find(&[]User{}).DryRunSQL()
+	if quoted := DB.Dialect().Quote("users") + "." + DB.Dialect().Quote("name"); !strings.Contains(sql, quoted+" IN (") || len(vars) != 2 {
+		t.Errorf("Identifiers of clauses should be quoted and values bound, got %v, %v", sql, vars)
+	}
+}
+
+type WindowOrder struct {
+	ID        uint
+	UserID    uint
+	State     string
+	Amount    int
+	CreatedAt time.Time
+}
+
+func TestWindowFunctions(t *testing.T) {
+	DB.DropTableIfExists(&WindowOrder{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&WindowOrder{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	now := time.Now().Round(time.Second)
+	for _, order := range []WindowOrder{
+		{UserID: 1, State: "paid", Amount: 10, CreatedAt: now.Add(-3 * time.Hour)},
+		{UserID: 1, State: "paid", Amount: 20, CreatedAt: now.Add(-2 * time.Hour)},
+		{UserID: 1, State: "cancelled", Amount: 30, CreatedAt: now.Add(-1 * time.Hour)},
+		{UserID: 1, State: "paid", Amount: 40, CreatedAt: now},
+		{UserID: 2, State: "paid", Amount: 50, CreatedAt: now.Add(-1 * time.Hour)},
+	} {
+		DB.Save(&order)
+	}
+
+	var positions []struct {
+		Amount   int
+		Position int
+		Total    int
+	}
+	if err := DB.Model(&WindowOrder{}).Select("amount, ?, ?",
+		gorm.RowNumber().Over(gorm.PartitionBy("user_id").OrderBy("created_at desc")).As("position"),
+		gorm.WindowFunc("SUM", gorm.Column("amount")).Over(gorm.PartitionBy("user_id")).As("total"),
+	).Order("amount").Scan(&positions).Error; err != nil {
+		t.Fatalf("Should select window functions, got %v", err)
+	}
+	if len(positions) != 5 || positions[0].Position != 4 || positions[3].Position != 1 || positions[0].Total != 100 || positions[4].Total != 50 {
+		t.Errorf("Window functions should be selected, got %+v", positions)
+	}
+
+	sql, _ := DB.Session(&gorm.Session{DryRun: true}).Select("?", gorm.Rank().Over(gorm.PartitionBy("user_id").OrderBy("amount; DROP TABLE users"))).Find(&[]WindowOrder{}).DryRunSQL()
+	if quoted := DB.Dialect().Quote("amount; DROP TABLE users"); !strings.Contains(sql, "ORDER BY "+quoted+")") {
+		t.Errorf("Orders of windows should be quoted, got %v", sql)
+	}
+
+	for _, windowFunctions := range []bool{true, false} {
+		tx := DB.Set("gorm:window_functions", windowFunctions)
+
+		var orders []WindowOrder
+		if err := tx.Where("state = ?", "paid").LatestPerGroup(2, "user_id", "created_at desc").Order("amount").Find(&orders).Error; err != nil {
+			t.Fatalf("Should find latest records per group, got %v", err)
+		}
+		if len(orders) != 3 || orders[0].Amount != 20 || orders[1].Amount != 40 || orders[2].Amount != 50 {
+			t.Errorf("Latest records per group should be found after filtering, window functions %v, got %+v", windowFunctions, orders)
+		}
+
+		if err := tx.LatestPerGroup(1, "user_id", "created_at desc").Find(&orders).Error; err != nil || len(orders) != 2 {
+			t.Errorf("Latest record of each group should be found, window functions %v, got %+v, %v", windowFunctions, orders, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gorm
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy retry transactions and statements failed with deadlocks or serialization errors
+type RetryPolicy struct {
+	// MaxAttempts is the max times to run the transaction or statement, including the first attempt
+	MaxAttempts int
+	// Backoff is the time to wait before the first retry, it doubles for each retry
+	Backoff time.Duration
+	// MaxBackoff limits the time to wait between retries, no limit if zero
+	MaxBackoff time.Duration
+	// Statements retry statements run outside of transactions, otherwise only `Transaction` is retried
+	Statements bool
+}
+
+// SetRetryPolicy set the policy to retry transactions and statements failed with retryable errors, errors are
+// retryable if the dialect translate them to ErrDeadlockDetected or ErrSerializationFailure, e.g. MySQL 1213,
+// Postgres 40P01 and 40001
+//     db.SetRetryPolicy(gorm.RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond, Statements: true})
+// Only the transaction block's error is checked, return the error of db operations from the block to retry it
+func (s *DB) SetRetryPolicy(policy RetryPolicy) *DB {
+	s.InstantSet("gorm:retry_policy", policy)
+	return s
+}
+
+func (s *DB) retryPolicy() (RetryPolicy, bool) {
+	if value, ok := s.Get("gorm:retry_policy"); ok {
+		policy, ok := value.(RetryPolicy)
+		return policy, ok && policy.MaxAttempts > 1
+	}
+	return RetryPolicy{}, false
+}
+
+// IsRetryableError report whether the error is a deadlock or serialization error, which could be retried
+func IsRetryableError(err error) bool {
+	return IsError(err, ErrDeadlockDetected) || IsError(err, ErrSerializationFailure)
+}
+
+// shouldRetry report whether to retry after the attempt failed with err, it waits for the backoff if so, it doesn't
+// retry if ctx is done before the backoff ends
+func (policy RetryPolicy) shouldRetry(ctx context.Context, err error, attempt int) bool {
+	if err == nil || attempt >= policy.MaxAttempts || !IsRetryableError(err) {
+		return false
+	}
+
+	backoff := policy.Backoff << uint(attempt-1)
+	if policy.MaxBackoff > 0 && (backoff > policy.MaxBackoff || backoff < 0) {
+		backoff = policy.MaxBackoff
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
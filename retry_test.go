@@ -0,0 +1,89 @@
+package gorm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zanmato/gorm"
+)
+
+func TestTransactionRetryPolicy(t *testing.T) {
+	db := DB.New().SetRetryPolicy(gorm.RetryPolicy{MaxAttempts: 3})
+
+	var attempts int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		attempts++
+		if attempts < 3 {
+			return gorm.DialectError{Kind: gorm.ErrDeadlockDetected, Err: errors.New("deadlock")}
+		}
+		return tx.Save(&User{Name: "retried-transaction"}).Error
+	})
+
+	if err != nil || attempts != 3 {
+		t.Errorf("Transaction should be retried until succeeded, got %v after %v attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		attempts++
+		return gorm.DialectError{Kind: gorm.ErrSerializationFailure, Err: errors.New("serialization failure")}
+	})
+	if !gorm.IsError(err, gorm.ErrSerializationFailure) || attempts != 3 {
+		t.Errorf("Transaction should be retried at most 3 times, got %v after %v attempts", err, attempts)
+	}
+
+	attempts = 0
+	db.Transaction(func(tx *gorm.DB) error {
+		attempts++
+		return errors.New("not retryable")
+	})
+	if attempts != 1 {
+		t.Errorf("Transaction shouldn't be retried on other errors, but got %v attempts", attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts, started := 0, time.Now()
+	err = db.SetRetryPolicy(gorm.RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second}).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		attempts++
+		cancel()
+		return gorm.DialectError{Kind: gorm.ErrDeadlockDetected, Err: errors.New("deadlock")}
+	})
+	if !gorm.IsError(err, gorm.ErrDeadlockDetected) || attempts != 1 || time.Since(started) > time.Second {
+		t.Errorf("Transaction shouldn't wait for backoffs after the context is done, got %v after %v attempts in %v", err, attempts, time.Since(started))
+	}
+}
+
+func TestStatementRetryPolicy(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var (
+		attempts int
+		vars     []interface{}
+	)
+	db.Callback().Query().After("gorm:query").Register("test:deadlock", func(scope *gorm.Scope) {
+		vars = scope.SQLVars
+		if attempts++; attempts < 2 {
+			scope.Err(gorm.DialectError{Kind: gorm.ErrDeadlockDetected, Err: errors.New("deadlock")})
+		}
+	})
+
+	var users []User
+	if err := db.Where("name = ?", "retried-statement").Find(&users).Error; err == nil || attempts != 1 {
+		t.Errorf("Statements shouldn't be retried without retry policy, got %v after %v attempts", err, attempts)
+	}
+
+	attempts = 0
+	db.SetRetryPolicy(gorm.RetryPolicy{MaxAttempts: 2, Statements: true})
+	if err := db.Where("name = ?", "retried-statement").Find(&users).Error; err != nil || attempts != 2 {
+		t.Errorf("Statements should be retried, got %v after %v attempts", err, attempts)
+	}
+	if len(vars) != 1 {
+		t.Errorf("Statements should be built again when retried, but got vars %v", vars)
+	}
+}
@@ -0,0 +1,176 @@
+package gorm
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// scanPlan is the mapping from columns of query results to fields of the destination type, it is cached for the
+// pair of columns and destination type, so rows of repeated queries are scanned without matching columns with
+// fields and building fields for every row
+type scanPlan struct {
+	columns []*scanColumn
+}
+
+// scanColumn is the field a column is scanned into, field is nil if the column is ignored, serializers are looked up
+// when scanning as they could be registered again
+type scanColumn struct {
+	field         *StructField
+	indexes       [][]int
+	hasSerializer bool
+	binaryUUID    bool
+}
+
+var (
+	scanPlans sync.Map
+	// scanValuesPool reuse buffers of values passed to `Rows.Scan`
+	scanValuesPool = sync.Pool{New: func() interface{} { return &[]interface{}{} }}
+)
+
+type scanPlanKey struct {
+	reflectType reflect.Type
+	columns     string
+}
+
+// scanPlan return the scan plan of columns for records of the type, columns are matched with fields like `scan`,
+// nil is returned if fields can't be found by their indexes or their serializers aren't registered
+func (scope *Scope) scanPlan(reflectType reflect.Type, columns []string) *scanPlan {
+	key := scanPlanKey{reflectType: reflectType, columns: strings.Join(columns, "\x00")}
+	if plan, ok := scanPlans.Load(key); ok {
+		return plan.(*scanPlan)
+	}
+
+	var (
+		plan               = &scanPlan{columns: make([]*scanColumn, len(columns))}
+		fields             = scope.New(reflect.New(reflectType).Interface()).GetModelStruct().StructFields
+		selectedColumnsMap = map[string]int{}
+	)
+
+	for index, column := range columns {
+		plan.columns[index] = &scanColumn{}
+
+		selectFields := fields
+		offset := 0
+		if idx, ok := selectedColumnsMap[column]; ok {
+			offset = idx + 1
+			selectFields = selectFields[offset:]
+		}
+
+		for fieldIndex, field := range selectFields {
+			if field.DBName == column {
+				_, hasSerializer, err := serializerOf(field)
+				indexes, ok := fieldIndexes(reflectType, field.Names)
+				if err != nil || !ok {
+					return nil
+				}
+
+				plan.columns[index] = &scanColumn{field: field, indexes: indexes, hasSerializer: hasSerializer, binaryUUID: !hasSerializer && isBinaryUUIDField(field)}
+				selectedColumnsMap[column] = offset + fieldIndex
+
+				if field.IsNormal {
+					break
+				}
+			}
+		}
+	}
+
+	scanPlans.Store(key, plan)
+	return plan
+}
+
+// fieldIndexes return indexes of the field in each level of embedded structs
+func fieldIndexes(reflectType reflect.Type, names []string) (indexes [][]int, ok bool) {
+	for _, name := range names {
+		for reflectType.Kind() == reflect.Ptr {
+			reflectType = reflectType.Elem()
+		}
+
+		if reflectType.Kind() != reflect.Struct {
+			return nil, false
+		}
+
+		structField, ok := reflectType.FieldByName(name)
+		if !ok {
+			return nil, false
+		}
+		indexes = append(indexes, structField.Index)
+		reflectType = structField.Type
+	}
+	return indexes, true
+}
+
+// resetField is a field scanned through a pointer, which is set after scanning if the column isn't NULL
+type resetField struct {
+	index int
+	field reflect.Value
+}
+
+// scan scan the current row into the record with the plan
+func (plan *scanPlan) scan(rows *sql.Rows, record reflect.Value) error {
+	var (
+		ignored     interface{}
+		buffer      = scanValuesPool.Get().(*[]interface{})
+		values      = *buffer
+		resetFields []resetField
+	)
+
+	if cap(values) < len(plan.columns) {
+		values = make([]interface{}, len(plan.columns))
+	}
+	values = values[:len(plan.columns)]
+
+	defer func() {
+		for index := range values {
+			values[index] = nil
+		}
+		*buffer = values
+		scanValuesPool.Put(buffer)
+	}()
+
+	for index, column := range plan.columns {
+		if column.field == nil {
+			values[index] = &ignored
+			continue
+		}
+
+		fieldValue := record
+		for _, index := range column.indexes {
+			if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			fieldValue = reflect.Indirect(fieldValue).FieldByIndex(index)
+		}
+
+		switch {
+		case column.hasSerializer:
+			serializer, _, err := serializerOf(column.field)
+			if err != nil {
+				return err
+			}
+			values[index] = &serializerScanner{serializer: serializer, field: &Field{StructField: column.field, Field: fieldValue}}
+		case column.binaryUUID:
+			values[index] = &binaryUUIDScanner{field: &Field{StructField: column.field, Field: fieldValue}}
+		case fieldValue.Kind() == reflect.Ptr:
+			values[index] = fieldValue.Addr().Interface()
+		default:
+			// scan into a pointer of the field, so NULL leaves the field unchanged
+			reflectValue := reflect.New(reflect.PtrTo(fieldValue.Type()))
+			reflectValue.Elem().Set(fieldValue.Addr())
+			values[index] = reflectValue.Interface()
+			resetFields = append(resetFields, resetField{index: index, field: fieldValue})
+		}
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+
+	for _, reset := range resetFields {
+		if v := reflect.ValueOf(values[reset.index]).Elem().Elem(); v.IsValid() {
+			reset.field.Set(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package gorm
+
+import (
+	"database/sql"
+)
+
+// SchemaInspector is implemented by dialects which could inspect schemas of existing tables, used to generate models
+// from existing databases
+type SchemaInspector interface {
+	// TableNames return names of tables of current database/schema
+	TableNames() ([]string, error)
+	// ColumnInfos return columns of the table in their order
+	ColumnInfos(tableName string) ([]ColumnInfo, error)
+	// ForeignKeyInfos return foreign keys of the table
+	ForeignKeyInfos(tableName string) ([]ForeignKeyInfo, error)
+}
+
+// ColumnInfo describes a column of an existing table
+type ColumnInfo struct {
+	Name       string
+	DataType   string
+	Nullable   bool
+	PrimaryKey bool
+}
+
+// ForeignKeyInfo describes a foreign key of an existing table
+type ForeignKeyInfo struct {
+	Column           string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+// queryStrings return the first column of rows as strings
+func queryStrings(db SQLCommon, query string, args ...interface{}) (values []string, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// queryColumnInfos scan rows of column name, data type, nullable and primary key to ColumnInfos
+func queryColumnInfos(db SQLCommon, query string, args ...interface{}) (columns []ColumnInfo, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			column     ColumnInfo
+			nullable   sql.NullString
+			primaryKey sql.NullString
+		)
+		if err = rows.Scan(&column.Name, &column.DataType, &nullable, &primaryKey); err != nil {
+			return nil, err
+		}
+		column.Nullable = nullable.String == "YES"
+		column.PrimaryKey = primaryKey.String == "PRI"
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// queryForeignKeyInfos scan rows of column, referenced table and referenced column to ForeignKeyInfos
+func queryForeignKeyInfos(db SQLCommon, query string, args ...interface{}) (foreignKeys []ForeignKeyInfo, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var foreignKey ForeignKeyInfo
+		if err = rows.Scan(&foreignKey.Column, &foreignKey.ReferencedTable, &foreignKey.ReferencedColumn); err != nil {
+			return nil, err
+		}
+		foreignKeys = append(foreignKeys, foreignKey)
+	}
+	return foreignKeys, rows.Err()
+}
@@ -0,0 +1,78 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+)
+
+type SchemaTenant struct {
+	ID   uint
+	Name string
+}
+
+func testSchemaName() string {
+	switch DB.Dialect().GetName() {
+	case "sqlite3":
+		return "main"
+	case "postgres", "cockroach":
+		return "public"
+	default:
+		return DB.Dialect().CurrentDatabase()
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := testSchemaName()
+	db := DB.Schema(schema).Set("gorm:table_options", "")
+
+	if tableName := db.NewScope(&SchemaTenant{}).TableName(); tableName != schema+".schema_tenants" {
+		t.Errorf("Table name should be qualified with schema, but got %v", tableName)
+	}
+
+	if quoted := db.NewScope(&SchemaTenant{}).QuotedTableName(); !strings.Contains(quoted, schema) {
+		t.Errorf("Quoted table name should be qualified with schema, but got %v", quoted)
+	}
+
+	db.DropTableIfExists(&SchemaTenant{})
+	if db.HasTable(&SchemaTenant{}) {
+		t.Errorf("Table should be dropped in schema %v", schema)
+	}
+
+	if err := db.AutoMigrate(&SchemaTenant{}).Error; err != nil {
+		t.Fatalf("Failed to migrate table in schema, got %v", err)
+	}
+
+	if !db.HasTable(&SchemaTenant{}) || !db.Dialect().HasColumn(schema+".schema_tenants", "name") {
+		t.Errorf("Table should be created in schema %v", schema)
+	}
+
+	if err := db.AutoMigrate(&SchemaTenant{}).Error; err != nil {
+		t.Errorf("Should migrate existing table in schema, got %v", err)
+	}
+
+	if err := db.Create(&SchemaTenant{Name: "tenant"}).Error; err != nil {
+		t.Errorf("Failed to create record in schema, got %v", err)
+	}
+
+	var tenant SchemaTenant
+	if err := db.Where("name = ?", "tenant").First(&tenant).Error; err != nil || tenant.ID == 0 {
+		t.Errorf("Failed to find record in schema, got %v", err)
+	}
+
+	db.Model(&tenant).Update("name", "tenant 2")
+	var count int
+	DB.Model(&SchemaTenant{}).Where("name = ?", "tenant 2").Count(&count)
+	if count != 1 {
+		t.Errorf("Should update record in schema, but got %v", count)
+	}
+
+	db.Delete(&tenant)
+	db.Model(&SchemaTenant{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Should delete record in schema, but got %v", count)
+	}
+
+	if tableName := db.Table("schema_tenants AS t").NewScope(nil).TableName(); tableName != "schema_tenants AS t" {
+		t.Errorf("Aliased table name should be kept, but got %v", tableName)
+	}
+}
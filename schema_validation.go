@@ -0,0 +1,153 @@
+package gorm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaDiff describes the differences between a model definition and its table in the database
+type SchemaDiff struct {
+	Model          string
+	Table          string
+	MissingTable   bool
+	MissingColumns []string // columns defined by the model but missing in the database
+	ExtraColumns   []string // columns existing in the database but not defined by the model
+	MissingIndexes []string // indexes defined by the model but missing in the database
+	ExtraIndexes   []string // indexes existing in the database but not defined by the model
+}
+
+// Empty return true if the model matches the database
+func (diff SchemaDiff) Empty() bool {
+	return !diff.MissingTable && len(diff.MissingColumns) == 0 && len(diff.ExtraColumns) == 0 &&
+		len(diff.MissingIndexes) == 0 && len(diff.ExtraIndexes) == 0
+}
+
+func (diff SchemaDiff) String() string {
+	if diff.MissingTable {
+		return fmt.Sprintf("%v: table %v doesn't exist", diff.Model, diff.Table)
+	}
+
+	var details []string
+	for _, item := range []struct {
+		name  string
+		names []string
+	}{
+		{"missing columns", diff.MissingColumns},
+		{"extra columns", diff.ExtraColumns},
+		{"missing indexes", diff.MissingIndexes},
+		{"extra indexes", diff.ExtraIndexes},
+	} {
+		if len(item.names) > 0 {
+			details = append(details, fmt.Sprintf("%v %v", item.name, strings.Join(item.names, ", ")))
+		}
+	}
+	return fmt.Sprintf("%v (%v): %v", diff.Model, diff.Table, strings.Join(details, "; "))
+}
+
+// SchemaDriftError is returned by `ValidateSchema` when the database schema doesn't match the models
+type SchemaDriftError struct {
+	Diffs []SchemaDiff
+}
+
+func (err *SchemaDriftError) Error() string {
+	var diffs []string
+	for _, diff := range err.Diffs {
+		diffs = append(diffs, diff.String())
+	}
+	return "schema drift detected: " + strings.Join(diffs, "; ")
+}
+
+// indexLister is implemented by dialects which are able to list the indexes of a table,
+// indexes backing primary keys, unique constraints and foreign keys are not included
+type indexLister interface {
+	IndexNames(tableName string) ([]string, error)
+}
+
+// ValidateSchema compare models with the database schema, return a `*SchemaDriftError` describing
+// the missing and extra columns/indexes if they don't match
+//     if err := db.ValidateSchema(&User{}, &Product{}); err != nil {
+//       log.Fatal(err)
+//     }
+func (s *DB) ValidateSchema(models ...interface{}) error {
+	var drift SchemaDriftError
+	for _, model := range models {
+		diff, err := s.NewScope(model).schemaDiff()
+		if err != nil {
+			return err
+		}
+		if !diff.Empty() {
+			drift.Diffs = append(drift.Diffs, diff)
+		}
+	}
+
+	if len(drift.Diffs) > 0 {
+		return &drift
+	}
+	return nil
+}
+
+func (scope *Scope) schemaDiff() (diff SchemaDiff, err error) {
+	diff.Model = scope.GetModelStruct().ModelType.String()
+	diff.Table = scope.TableName()
+
+	if !scope.Dialect().HasTable(diff.Table) {
+		diff.MissingTable = true
+		return
+	}
+
+	rows, err := scope.SQLDB().Query(fmt.Sprintf("SELECT * FROM %v WHERE 1 = 0", scope.QuotedTableName()))
+	if err != nil {
+		return
+	}
+	columns, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return
+	}
+
+	modelColumns := map[string]bool{}
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal {
+			modelColumns[field.DBName] = true
+			if !strInSlice(field.DBName, columns) {
+				diff.MissingColumns = append(diff.MissingColumns, field.DBName)
+			}
+		}
+	}
+
+	for _, column := range columns {
+		if !modelColumns[column] {
+			diff.ExtraColumns = append(diff.ExtraColumns, column)
+		}
+	}
+
+	indexes, uniqueIndexes := scope.modelIndexes()
+	for name, columns := range uniqueIndexes {
+		indexes[name] = columns
+	}
+
+	var modelIndexNames []string
+	for name := range indexes {
+		modelIndexNames = append(modelIndexNames, name)
+		if !scope.Dialect().HasIndex(diff.Table, name) {
+			diff.MissingIndexes = append(diff.MissingIndexes, name)
+		}
+	}
+	sort.Strings(diff.MissingIndexes)
+
+	if lister, ok := scope.Dialect().(indexLister); ok {
+		var indexNames []string
+		if indexNames, err = lister.IndexNames(diff.Table); err != nil {
+			return
+		}
+
+		for _, name := range indexNames {
+			if !strInSlice(name, modelIndexNames) {
+				diff.ExtraIndexes = append(diff.ExtraIndexes, name)
+			}
+		}
+		sort.Strings(diff.ExtraIndexes)
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package gorm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type SchemaDriftModel struct {
+	ID    uint
+	Name  string `gorm:"index:idx_schema_drift_name"`
+	Email string
+}
+
+func (SchemaDriftModel) TableName() string {
+	return "schema_drift_models"
+}
+
+type SchemaDriftModelV2 struct {
+	ID      uint
+	Name    string
+	Email   string `gorm:"unique_index:uix_schema_drift_email"`
+	Country string
+}
+
+func (SchemaDriftModelV2) TableName() string {
+	return "schema_drift_models"
+}
+
+func TestValidateSchema(t *testing.T) {
+	DB.DropTableIfExists(&SchemaDriftModel{})
+	if err := DB.AutoMigrate(&SchemaDriftModel{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	if err := DB.ValidateSchema(&SchemaDriftModel{}); err != nil {
+		t.Errorf("Schema should match the model, but got %v", err)
+	}
+
+	err := DB.ValidateSchema(&SchemaDriftModelV2{})
+	drift, ok := err.(*gorm.SchemaDriftError)
+	if !ok || len(drift.Diffs) != 1 {
+		t.Fatalf("Should report schema drift, but got %v", err)
+	}
+
+	diff := drift.Diffs[0]
+	if !reflect.DeepEqual(diff.MissingColumns, []string{"country"}) {
+		t.Errorf("Should report missing column country, but got %v", diff.MissingColumns)
+	}
+
+	if !reflect.DeepEqual(diff.MissingIndexes, []string{"uix_schema_drift_email"}) {
+		t.Errorf("Should report missing index uix_schema_drift_email, but got %v", diff.MissingIndexes)
+	}
+
+	if dialect := DB.Dialect().GetName(); dialect != "mssql" && dialect != "common" {
+		if !reflect.DeepEqual(diff.ExtraIndexes, []string{"idx_schema_drift_name"}) {
+			t.Errorf("Should report extra index idx_schema_drift_name, but got %v", diff.ExtraIndexes)
+		}
+	}
+
+	type SchemaDriftMissingTable struct {
+		ID uint
+	}
+	err = DB.ValidateSchema(&SchemaDriftMissingTable{})
+	if drift, ok := err.(*gorm.SchemaDriftError); !ok || !drift.Diffs[0].MissingTable {
+		t.Errorf("Should report missing table, but got %v", err)
+	}
+}
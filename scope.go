@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,6 +27,8 @@ type Scope struct {
 	skipLeft        bool
 	fields          *[]*Field
 	selectAttrs     *[]string
+	callbacks       []*func(s *Scope)
+	sqlComment      *string
 }
 
 // IndirectValue return scope's reflect value's indirect value
@@ -106,12 +111,14 @@ func (scope *Scope) SkipLeft() {
 func (scope *Scope) Fields() []*Field {
 	if scope.fields == nil {
 		var (
-			fields             []*Field
+			structFields       = scope.GetModelStruct().StructFields
+			fields             = make([]*Field, len(structFields))
+			fieldValues        = make([]Field, len(structFields)) // allocate fields at once
 			indirectScopeValue = scope.IndirectValue()
 			isStruct           = indirectScopeValue.Kind() == reflect.Struct
 		)
 
-		for _, structField := range scope.GetModelStruct().StructFields {
+		for idx, structField := range structFields {
 			if isStruct {
 				fieldValue := indirectScopeValue
 				for _, name := range structField.Names {
@@ -120,10 +127,11 @@ func (scope *Scope) Fields() []*Field {
 					}
 					fieldValue = reflect.Indirect(fieldValue).FieldByName(name)
 				}
-				fields = append(fields, &Field{StructField: structField, Field: fieldValue, IsBlank: isBlank(fieldValue)})
+				fieldValues[idx] = Field{StructField: structField, Field: fieldValue, IsBlank: isBlank(fieldValue)}
 			} else {
-				fields = append(fields, &Field{StructField: structField, IsBlank: true})
+				fieldValues[idx] = Field{StructField: structField, IsBlank: true}
 			}
+			fields[idx] = &fieldValues[idx]
 		}
 		scope.fields = &fields
 	}
@@ -221,7 +229,7 @@ func (scope *Scope) SetColumn(column interface{}, value interface{}) error {
 			mostMatchedField *Field
 		)
 		for _, field := range scope.Fields() {
-			if field.DBName == value {
+			if field.DBName == name {
 				updateAttrs[field.DBName] = value
 				return field.Set(value)
 			}
@@ -238,12 +246,17 @@ func (scope *Scope) SetColumn(column interface{}, value interface{}) error {
 	return errors.New("could not convert column to field")
 }
 
-// CallMethod call scope value's method, if it is a slice, will call its element's method one by one
+// CallMethod call scope value's method, if it is a slice, will call its element's method one by one,
+// methods are not called if hooks are skipped with `SkipHooks`
 func (scope *Scope) CallMethod(methodName string) {
 	if scope.Value == nil {
 		return
 	}
 
+	if skip, ok := scope.Get("gorm:skip_hooks"); ok && skip == true {
+		return
+	}
+
 	if indirectScopeValue := scope.IndirectValue(); indirectScopeValue.Kind() == reflect.Slice {
 		for i := 0; i < indirectScopeValue.Len(); i++ {
 			scope.callMethod(methodName, indirectScopeValue.Index(i))
@@ -257,6 +270,20 @@ func (scope *Scope) CallMethod(methodName string) {
 func (scope *Scope) AddToVars(value interface{}) string {
 	_, skipBindVar := scope.InstanceGet("skip_bindvar")
 
+	if valuer, ok := value.(GormValuer); ok {
+		if reflectValue := reflect.ValueOf(valuer); reflectValue.Kind() != reflect.Ptr || !reflectValue.IsNil() {
+			value = valuer.GormValue(scope.Dialect())
+		}
+	}
+
+	if db, ok := value.(*DB); ok && db.search != nil && db.search.alias != "" {
+		return db.NewScope(db.Value).fromTableSQL()
+	}
+
+	if clause, ok := value.(Clause); ok {
+		return scope.buildClause(clause)
+	}
+
 	if expr, ok := value.(*SqlExpr); ok {
 		exp := expr.expr
 		for _, arg := range expr.args {
@@ -302,6 +329,25 @@ func (scope *Scope) OmitAttrs() []string {
 	return scope.Search.omits
 }
 
+// TableOptions are options of tables keyed by dialect names, used with setting `gorm:table_options` to create tables
+// with options of the dialect, e.g. engines and charsets of mysql, or tablespaces and partitions of postgres
+//     db.Set("gorm:table_options", gorm.TableOptions{
+//       "mysql":    "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+//       "postgres": "TABLESPACE fast_storage",
+//     }).AutoMigrate(&User{})
+// Models could declare their own options, which override the setting
+//     func (Event) TableOptions(dialect gorm.Dialect) string {
+//       if dialect.GetName() == "postgres" {
+//         return "PARTITION BY RANGE (created_at)"
+//       }
+//       return ""
+//     }
+type TableOptions map[string]string
+
+type tableOptioner interface {
+	TableOptions(dialect Dialect) string
+}
+
 type tabler interface {
 	TableName() string
 }
@@ -310,8 +356,21 @@ type dbTabler interface {
 	TableName(*DB) string
 }
 
-// TableName return table name
+// TableName return table name, qualified with the schema set with `DB.Schema`
 func (scope *Scope) TableName() string {
+	return scope.qualifyTableName(scope.unqualifiedTableName())
+}
+
+// qualifyTableName qualify table name with the schema set with `DB.Schema`, names already qualified,
+// sub queries or aliased tables are kept as they are
+func (scope *Scope) qualifyTableName(name string) string {
+	if schema, ok := scope.Get("gorm:schema"); ok && schema != "" && !strings.ContainsAny(name, ". (") {
+		return fmt.Sprintf("%v.%v", schema, name)
+	}
+	return name
+}
+
+func (scope *Scope) unqualifiedTableName() string {
 	if scope.Search != nil && len(scope.Search.tableName) > 0 {
 		return scope.Search.tableName
 	}
@@ -327,18 +386,33 @@ func (scope *Scope) TableName() string {
 	return scope.GetModelStruct().TableName(scope.db.Model(scope.Value))
 }
 
-// QuotedTableName return quoted table name
+// QuotedTableName return quoted table name, or the quoted alias of queries with `Alias`
 func (scope *Scope) QuotedTableName() (name string) {
+	if scope.Search != nil && scope.Search.alias != "" {
+		return scope.Quote(scope.Search.alias)
+	}
+	return scope.quotedTableNameWithoutAlias()
+}
+
+func (scope *Scope) quotedTableNameWithoutAlias() string {
 	if scope.Search != nil && len(scope.Search.tableName) > 0 {
 		if strings.Contains(scope.Search.tableName, " ") {
 			return scope.Search.tableName
 		}
-		return scope.Quote(scope.Search.tableName)
+		return scope.Quote(scope.qualifyTableName(scope.Search.tableName))
 	}
 
 	return scope.Quote(scope.TableName())
 }
 
+// fromTableSQL return the table of queries with its alias
+func (scope *Scope) fromTableSQL() string {
+	if scope.Search.alias != "" {
+		return fmt.Sprintf("%v AS %v", scope.quotedTableNameWithoutAlias(), scope.Quote(scope.Search.alias))
+	}
+	return scope.QuotedTableName()
+}
+
 // CombinedConditionSql return combined condition sql
 func (scope *Scope) CombinedConditionSql() string {
 	joinSQL := scope.joinsSQL()
@@ -360,10 +434,14 @@ func (scope *Scope) Raw(sql string) *Scope {
 func (scope *Scope) Exec() *Scope {
 	defer scope.trace(NowFunc())
 
-	if !scope.HasError() {
-		if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+	if !scope.HasError() && !scope.dryRun() {
+		if result, err := scope.sqlExec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 			if count, err := result.RowsAffected(); scope.Err(err) == nil {
 				scope.db.RowsAffected = count
+				// rows affected by statements of operations like `Association.Replace` are counted
+				if counter, ok := scope.Get("gorm:rows_affected_counter"); ok {
+					*counter.(*int64) += count
+				}
 			}
 		}
 	}
@@ -401,10 +479,18 @@ func (scope *Scope) InstanceGet(name string) (interface{}, bool) {
 
 // Begin start a transaction
 func (scope *Scope) Begin() *Scope {
+	if scope.isDryRun() {
+		return scope
+	}
+
 	if db, ok := scope.SQLDB().(sqlDb); ok {
 		if tx, err := db.Begin(); scope.Err(err) == nil {
 			scope.db.db = interface{}(tx).(SQLCommon)
-			scope.InstanceSet("gorm:started_transaction", true)
+			scope.InstanceSet("gorm:started_transaction", db)
+
+			if _, pinned := db.(*pinnedConn); !pinned {
+				scope.Err(scope.db.runConnectionHooks())
+			}
 		}
 	}
 	return scope
@@ -412,14 +498,14 @@ func (scope *Scope) Begin() *Scope {
 
 // CommitOrRollback commit current transaction if no error happened, otherwise will rollback it
 func (scope *Scope) CommitOrRollback() *Scope {
-	if _, ok := scope.InstanceGet("gorm:started_transaction"); ok {
+	if started, ok := scope.InstanceGet("gorm:started_transaction"); ok {
 		if db, ok := scope.db.db.(sqlTx); ok {
 			if scope.HasError() {
 				db.Rollback()
 			} else {
 				scope.Err(db.Commit())
 			}
-			scope.db.db = scope.db.parent.db
+			scope.db.db = started.(SQLCommon)
 		}
 	}
 	return scope
@@ -443,6 +529,7 @@ func (scope *Scope) callMethod(methodName string, reflectValue reflect.Value) {
 			method(scope)
 		case func(*DB):
 			newDB := scope.NewDB()
+			newDB.values.Store("gorm:statement", &Statement{scope: scope, record: reflect.Indirect(reflectValue)})
 			method(newDB)
 			scope.Err(newDB.Error)
 		case func() error:
@@ -451,6 +538,7 @@ func (scope *Scope) callMethod(methodName string, reflectValue reflect.Value) {
 			scope.Err(method(scope))
 		case func(*DB) error:
 			newDB := scope.NewDB()
+			newDB.values.Store("gorm:statement", &Statement{scope: scope, record: reflect.Indirect(reflectValue)})
 			scope.Err(method(newDB))
 			scope.Err(newDB.Error)
 		default:
@@ -464,6 +552,7 @@ var (
 	isNumberRegexp      = regexp.MustCompile("^\\s*\\d+\\s*$")                   // match if string is number
 	comparisonRegexp    = regexp.MustCompile("(?i) (=|<>|(>|<)(=?)|LIKE|IS|IN) ")
 	countingQueryRegexp = regexp.MustCompile("(?i)^count(.+)$")
+	distinctQueryRegexp = regexp.MustCompile(`(?i)^\s*distinct\s`)
 )
 
 func (scope *Scope) quoteIfPossible(str string) string {
@@ -480,6 +569,7 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 		selectFields       []*Field
 		selectedColumnsMap = map[string]int{}
 		resetFields        = map[int]*Field{}
+		matchedFields      = map[*Field]bool{}
 	)
 
 	for index, column := range columns {
@@ -494,7 +584,13 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 
 		for fieldIndex, field := range selectFields {
 			if field.DBName == column {
-				if field.Field.Kind() == reflect.Ptr {
+				if serializer, ok, err := serializerOf(field.StructField); ok {
+					if scope.Err(err) == nil {
+						values[index] = &serializerScanner{serializer: serializer, field: field}
+					}
+				} else if isBinaryUUIDField(field.StructField) {
+					values[index] = &binaryUUIDScanner{field: field}
+				} else if field.Field.Kind() == reflect.Ptr {
 					values[index] = field.Field.Addr().Interface()
 				} else {
 					reflectValue := reflect.New(reflect.PtrTo(field.Struct.Type))
@@ -504,6 +600,7 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 				}
 
 				selectedColumnsMap[column] = offset + fieldIndex
+				matchedFields[field] = true
 
 				if field.IsNormal {
 					break
@@ -512,6 +609,7 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 		}
 	}
 
+	scope.checkStrictScan(columns, fields, matchedFields, values, &ignored)
 	scope.Err(rows.Scan(values...))
 
 	for index, field := range resetFields {
@@ -521,10 +619,51 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 	}
 }
 
+// primaryKeySQLValues convert inline primary keys to be used in SQL, refer `Field.sqlValue`
+func (scope *Scope) primaryKeySQLValues(values interface{}) interface{} {
+	field := scope.PrimaryField()
+	if field == nil || !hasSQLValue(field.StructField) {
+		return values
+	}
+
+	reflectValue := reflect.ValueOf(values)
+	results := make([]interface{}, reflectValue.Len())
+	for i := range results {
+		results[i] = sqlValueOf(field.StructField, reflectValue.Index(i).Interface())
+	}
+	return results
+}
+
 func (scope *Scope) primaryCondition(value interface{}) string {
 	return fmt.Sprintf("(%v.%v = %v)", scope.QuotedTableName(), scope.Quote(scope.PrimaryKey()), value)
 }
 
+// GormValuer is implemented by values bound as SQL expressions of the dialect, e.g. spatial values like `Point`
+// are bound as `ST_GeomFromText(?, ?)`
+type GormValuer interface {
+	GormValue(dialect Dialect) *SqlExpr
+}
+
+// conditionExpression is implemented by expressions which build their own condition SQL, e.g. `JSONQuery`
+type conditionExpression interface {
+	conditionSQL(scope *Scope) string
+}
+
+// orderExpression is implemented by expressions which build their own order SQL, e.g. `Distance`
+type orderExpression interface {
+	orderSQL(scope *Scope) string
+}
+
+// sqlBuffersPool reuse buffers of SQL built by conditions and queries, `String` copies their contents, so they could be
+// put back once the SQL is built
+var sqlBuffersPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+func getSQLBuffer() *bytes.Buffer {
+	buff := sqlBuffersPool.Get().(*bytes.Buffer)
+	buff.Reset()
+	return buff
+}
+
 func (scope *Scope) buildCondition(clause map[string]interface{}, include bool) (str string) {
 	var (
 		quotedTableName  = scope.QuotedTableName()
@@ -549,8 +688,15 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 			return
 		}
 		str = fmt.Sprintf("(%v.%v %s (?))", quotedTableName, quotedPrimaryKey, inSQL)
-		clause["args"] = []interface{}{value}
+		clause["args"] = []interface{}{scope.primaryKeySQLValues(value)}
 	case string:
+		if strings.Contains(value, "@") {
+			if query, args, ok := scope.namedArgs(value, clause["args"].([]interface{})); ok {
+				value = query
+				clause = map[string]interface{}{"query": query, "args": args}
+			}
+		}
+
 		if isNumberRegexp.MatchString(value) {
 			return fmt.Sprintf("(%v.%v %s %v)", quotedTableName, quotedPrimaryKey, equalSQL, scope.AddToVars(value))
 		}
@@ -566,6 +712,22 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 				str = fmt.Sprintf("(%v)", value)
 			}
 		}
+	case conditionExpression:
+		if str = value.conditionSQL(scope); str == "" {
+			return
+		}
+		if !include {
+			return fmt.Sprintf("NOT (%v)", str)
+		}
+		return fmt.Sprintf("(%v)", str)
+	case Clause:
+		if str = scope.buildClause(value); str == "" {
+			return
+		}
+		if !include {
+			return fmt.Sprintf("NOT (%v)", str)
+		}
+		return fmt.Sprintf("(%v)", str)
 	case map[string]interface{}:
 		var sqls []string
 		for key, value := range value {
@@ -588,11 +750,36 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 			scope.Err(fmt.Errorf("invalid query condition: %v", value))
 			return
 		}
+		// fields of struct conditions could be specified with their names, they are used even if they are blank
+		var searchFields = map[string]bool{}
+		for _, arg := range clause["args"].([]interface{}) {
+			name, ok := arg.(string)
+			if !ok {
+				scope.Err(fmt.Errorf("invalid search field of struct condition: %v", arg))
+				return
+			}
+
+			field, ok := newScope.FieldByName(name)
+			if !ok || field.IsIgnored || field.Relationship != nil {
+				scope.Err(fmt.Errorf("invalid search field of struct condition: %v", name))
+				return
+			}
+			searchFields[field.DBName] = true
+		}
+
 		scopeQuotedTableName := newScope.QuotedTableName()
+		if scope.Search.alias != "" && newScope.TableName() == scope.TableName() {
+			scopeQuotedTableName = scope.QuotedTableName()
+		}
 		for _, field := range newScope.Fields() {
-			if !field.IsIgnored && !field.IsBlank && field.Relationship == nil {
-				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", scopeQuotedTableName, scope.Quote(field.DBName), equalSQL, scope.AddToVars(field.Field.Interface())))
+			if len(searchFields) > 0 {
+				if !searchFields[field.DBName] {
+					continue
+				}
+			} else if field.IsIgnored || field.IsBlank || field.Relationship != nil {
+				continue
 			}
+			sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", scopeQuotedTableName, scope.Quote(field.DBName), equalSQL, scope.AddToVars(field.sqlValue())))
 		}
 		return strings.Join(sqls, " AND ")
 	default:
@@ -637,8 +824,11 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 				replacements = append(replacements, scope.AddToVars(Expr("NULL")))
 			}
 		default:
-			if valuer, ok := interface{}(arg).(driver.Valuer); ok {
-				arg, err = valuer.Value()
+			// values bound as expressions of the dialect are converted when they are added to vars
+			if _, ok := arg.(GormValuer); !ok {
+				if valuer, ok := interface{}(arg).(driver.Valuer); ok {
+					arg, err = valuer.Value()
+				}
 			}
 
 			replacements = append(replacements, scope.AddToVars(arg))
@@ -649,15 +839,29 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 		}
 	}
 
-	buff := bytes.NewBuffer([]byte{})
+	buff := getSQLBuffer()
+	defer sqlBuffersPool.Put(buff)
 	i := 0
-	for _, s := range str {
-		if s == '?' && len(replacements) > i {
+	var quote rune
+	runes := []rune(str)
+	for idx := 0; idx < len(runes); idx++ {
+		s := runes[idx]
+		switch {
+		case quote != 0:
+			if s == quote {
+				quote = 0
+			}
+		case s == '\'' || s == '"' || s == '`':
+			quote = s
+		case s == '?' && idx+1 < len(runes) && runes[idx+1] == '?':
+			// `??` is an escaped `?`, like operators of postgres jsonb
+			idx++
+		case s == '?' && len(replacements) > i:
 			buff.WriteString(replacements[i])
 			i++
-		} else {
-			buff.WriteRune(s)
+			continue
 		}
+		buff.WriteRune(s)
 	}
 
 	str = buff.String()
@@ -671,6 +875,8 @@ func (scope *Scope) buildSelectQuery(clause map[string]interface{}) (str string)
 		str = value
 	case []string:
 		str = strings.Join(value, ", ")
+	case Clause:
+		str = scope.buildClause(value)
 	}
 
 	args := clause["args"].([]interface{})
@@ -685,14 +891,17 @@ func (scope *Scope) buildSelectQuery(clause map[string]interface{}) (str string)
 			}
 			replacements = append(replacements, strings.Join(tempMarks, ","))
 		default:
-			if valuer, ok := interface{}(arg).(driver.Valuer); ok {
-				arg, _ = valuer.Value()
+			if _, ok := arg.(GormValuer); !ok {
+				if valuer, ok := interface{}(arg).(driver.Valuer); ok {
+					arg, _ = valuer.Value()
+				}
 			}
 			replacements = append(replacements, scope.AddToVars(arg))
 		}
 	}
 
-	buff := bytes.NewBuffer([]byte{})
+	buff := getSQLBuffer()
+	defer sqlBuffersPool.Put(buff)
 	i := 0
 	for pos, char := range str {
 		if str[pos] == '?' {
@@ -711,18 +920,18 @@ func (scope *Scope) buildSelectQuery(clause map[string]interface{}) (str string)
 func (scope *Scope) whereSQL() (sql string) {
 	var (
 		quotedTableName                                = scope.QuotedTableName()
-		deletedAtField, hasDeletedAtField              = scope.FieldByName("DeletedAt")
 		primaryConditions, andConditions, orConditions []string
 	)
 
-	if !scope.Search.Unscoped && hasDeletedAtField {
-		sql := fmt.Sprintf("%v.%v IS NULL", quotedTableName, scope.Quote(deletedAtField.DBName))
-		primaryConditions = append(primaryConditions, sql)
+	if !scope.Search.Unscoped {
+		if sql, ok := scope.softDeleteCondition(); ok {
+			primaryConditions = append(primaryConditions, sql)
+		}
 	}
 
 	if !scope.PrimaryKeyZero() {
 		for _, field := range scope.PrimaryFields() {
-			sql := fmt.Sprintf("%v.%v = %v", quotedTableName, scope.Quote(field.DBName), scope.AddToVars(field.Field.Interface()))
+			sql := fmt.Sprintf("%v.%v = %v", quotedTableName, scope.Quote(field.DBName), scope.AddToVars(field.sqlValue()))
 			primaryConditions = append(primaryConditions, sql)
 		}
 	}
@@ -768,14 +977,53 @@ func (scope *Scope) whereSQL() (sql string) {
 
 func (scope *Scope) selectSQL() string {
 	if len(scope.Search.selects) == 0 {
+		if queryFields, ok := scope.Get("gorm:query_fields"); ok && queryFields == true {
+			if columns := scope.queryFieldsSQL(); columns != "" {
+				return columns
+			}
+		}
+
 		if len(scope.Search.joinConditions) > 0 {
-			return fmt.Sprintf("%v.*", scope.QuotedTableName())
+			columns := []string{fmt.Sprintf("%v.*", scope.QuotedTableName())}
+			for _, association := range scope.joinedAssociations() {
+				columns = append(columns, association.selectSQL(scope))
+			}
+			return strings.Join(columns, ", ")
 		}
 		return "*"
 	}
+
+	if len(scope.Search.joinConditions) > 0 {
+		if columns, ok := scope.joinedSelectSQL(); ok {
+			return columns
+		}
+	}
 	return scope.buildSelectQuery(scope.Search.selects)
 }
 
+// queryFieldsSQL return columns of the model's fields to select instead of `*`, refer `Session.QueryFields`
+func (scope *Scope) queryFieldsSQL() string {
+	var (
+		quotedTableName = scope.QuotedTableName()
+		columns         []string
+	)
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal && !field.IsIgnored {
+			columns = append(columns, fmt.Sprintf("%v.%v", quotedTableName, scope.Quote(field.DBName)))
+		}
+	}
+
+	if len(columns) == 0 {
+		return ""
+	}
+
+	for _, association := range scope.joinedAssociations() {
+		columns = append(columns, association.selectSQL(scope))
+	}
+	return strings.Join(columns, ", ")
+}
+
 func (scope *Scope) orderSQL() string {
 	if len(scope.Search.orders) == 0 || scope.Search.ignoreOrderQuery {
 		return ""
@@ -791,8 +1039,20 @@ func (scope *Scope) orderSQL() string {
 				exp = strings.Replace(exp, "?", scope.AddToVars(arg), 1)
 			}
 			orders = append(orders, exp)
+		} else if expr, ok := order.(orderExpression); ok {
+			if exp := expr.orderSQL(scope); exp != "" {
+				orders = append(orders, exp)
+			}
+		} else if clause, ok := order.(Clause); ok {
+			if exp := scope.buildClause(clause); exp != "" {
+				orders = append(orders, exp)
+			}
 		}
 	}
+
+	if len(orders) == 0 {
+		return ""
+	}
 	return " ORDER BY " + strings.Join(orders, ",")
 }
 
@@ -832,7 +1092,9 @@ func (scope *Scope) havingSQL() string {
 func (scope *Scope) joinsSQL() string {
 	var joinConditions []string
 	for _, clause := range scope.Search.joinConditions {
-		if sql := scope.buildCondition(clause, true); sql != "" {
+		if association, ok := scope.joinedAssociation(clause); ok {
+			joinConditions = append(joinConditions, association.joinSQL(scope))
+		} else if sql := scope.buildCondition(clause, true); sql != "" {
 			joinConditions = append(joinConditions, strings.TrimSuffix(strings.TrimPrefix(sql, "("), ")"))
 		}
 	}
@@ -845,7 +1107,12 @@ func (scope *Scope) prepareQuerySQL() {
 	if scope.Search.raw {
 		sql = scope.CombinedConditionSql()
 	} else {
-		sql = fmt.Sprintf("SELECT %v FROM %v %v", scope.selectSQL(), scope.QuotedTableName(), scope.CombinedConditionSql())
+		buff := getSQLBuffer()
+		defer sqlBuffersPool.Put(buff)
+		for _, part := range [...]string{"SELECT ", scope.optimizerHintsSQL(), scope.selectSQL(), " FROM ", scope.fromTableSQL(), scope.tableHintsSQL(), scope.asOfSystemTimeSQL(), " ", scope.CombinedConditionSql()} {
+			buff.WriteString(part)
+		}
+		sql = buff.String()
 	}
 	if str, ok := scope.Get("gorm:query_option"); ok {
 		sql += addExtraSpaceIfExist(fmt.Sprint(str))
@@ -853,6 +1120,13 @@ func (scope *Scope) prepareQuerySQL() {
 	scope.Raw(sql)
 }
 
+func (scope *Scope) asOfSystemTimeSQL() string {
+	if clause, ok := scope.Get("gorm:as_of_system_time"); ok {
+		return fmt.Sprintf(" AS OF SYSTEM TIME %v", clause)
+	}
+	return ""
+}
+
 func (scope *Scope) inlineCondition(values ...interface{}) *Scope {
 	if len(values) > 0 {
 		scope.Search.Where(values[0], values[1:]...)
@@ -860,22 +1134,63 @@ func (scope *Scope) inlineCondition(values ...interface{}) *Scope {
 	return scope
 }
 
+func (scope *Scope) isQueryCallbacks(funcs []*func(s *Scope)) bool {
+	for _, queries := range [][]*func(s *Scope){scope.db.parent.callbacks.queries, scope.db.parent.callbacks.rowQueries} {
+		if len(funcs) == len(queries) && (len(funcs) == 0 || &funcs[0] == &queries[0]) {
+			return true
+		}
+	}
+	return false
+}
+
 func (scope *Scope) callCallbacks(funcs []*func(s *Scope)) *Scope {
 	defer func() {
 		if err := recover(); err != nil {
 			if db, ok := scope.db.db.(sqlTx); ok {
 				db.Rollback()
 			}
+			scope.finishSingleflight(fmt.Errorf("query panicked: %v", err))
 			panic(err)
 		}
+		// identical queries waiting for this one are released even if callbacks sharing results are skipped
+		scope.finishSingleflight(scope.db.Error)
 	}()
-	for _, f := range funcs {
-		(*f)(scope)
-		if scope.skipLeft {
-			break
+
+	// retry statements failed with deadlocks or serialization errors if the retry policy asks to,
+	// statements in transactions are retried with the transaction
+	policy, retryable := scope.db.retryPolicy()
+	_, inTransaction := scope.db.db.(sqlTx)
+	retryable = retryable && policy.Statements && !inTransaction && !scope.HasError()
+	scope.callbacks = funcs
+
+	// aliases are only used by queries
+	if scope.Search.alias != "" && !scope.isQueryCallbacks(funcs) {
+		scope.Search.alias = ""
+	}
+
+	var search *search
+	if retryable {
+		search = scope.Search.clone()
+	}
+
+	for attempt := 1; ; attempt++ {
+		// statements are built again by each attempt
+		if attempt > 1 {
+			scope.Search, scope.SQL, scope.SQLVars = search.clone(), "", nil
 		}
+
+		for _, f := range funcs {
+			(*f)(scope)
+			if scope.skipLeft {
+				break
+			}
+		}
+
+		if !retryable || !policy.shouldRetry(scope.db.Context(), scope.db.Error, attempt) {
+			return scope
+		}
+		scope.db.Error, scope.db.RowsAffected, scope.skipLeft = nil, 0, false
 	}
-	return scope
 }
 
 func convertInterfaceToMap(values interface{}, withIgnoredField bool, db *DB) map[string]interface{} {
@@ -911,12 +1226,17 @@ func convertInterfaceToMap(values interface{}, withIgnoredField bool, db *DB) ma
 
 func (scope *Scope) updatedAttrsWithValues(value interface{}) (results map[string]interface{}, hasUpdate bool) {
 	if scope.IndirectValue().Kind() != reflect.Struct {
-		return convertInterfaceToMap(value, false, scope.db), true
+		results = map[string]interface{}{}
+		for key, value := range convertInterfaceToMap(value, false, scope.db) {
+			results[ToColumnName(key)] = subQueryValue(value)
+		}
+		return results, true
 	}
 
 	results = map[string]interface{}{}
 
 	for key, value := range convertInterfaceToMap(value, true, scope.db) {
+		value = subQueryValue(value)
 		if field, ok := scope.FieldByName(key); ok {
 			if scope.changeableField(field) {
 				if _, ok := value.(*SqlExpr); ok {
@@ -929,18 +1249,33 @@ func (scope *Scope) updatedAttrsWithValues(value interface{}) (results map[strin
 						if err == ErrUnaddressable {
 							results[field.DBName] = value
 						} else {
-							results[field.DBName] = field.Field.Interface()
+							results[field.DBName] = field.sqlValue()
 						}
 					}
 				}
 			}
 		} else {
-			results[key] = value
+			results[ToColumnName(key)] = value
 		}
 	}
 	return
 }
 
+// subQueryValue convert queries used as updating values to sub queries, e.g.
+//     db.Model(&user).Update("rank", db.Table("scores").Select("AVG(score)").Where("scores.user_id = users.id"))
+//     db.Model(&user).Update("rank", db.Table("scores").Select("AVG(score)").Where("scores.user_id = users.id").QueryExpr())
+func subQueryValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *DB:
+		return v.SubQuery()
+	case *SqlExpr:
+		if v != nil && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(v.expr)), "SELECT ") {
+			return &SqlExpr{expr: "(" + v.expr + ")", args: v.args}
+		}
+	}
+	return value
+}
+
 func (scope *Scope) row() *sql.Row {
 	defer scope.trace(NowFunc())
 
@@ -1018,23 +1353,56 @@ func (scope *Scope) pluck(column string, value interface{}) *Scope {
 	return scope
 }
 
-func (scope *Scope) count(value interface{}) *Scope {
-	if query, ok := scope.Search.selects["query"]; !ok || !countingQueryRegexp.MatchString(fmt.Sprint(query)) {
-		if len(scope.Search.group) != 0 {
-			if len(scope.Search.havingConditions) != 0 {
-				scope.prepareQuerySQL()
-				scope.Search = &search{}
-				scope.Search.Select("count(*)")
-				scope.Search.Table(fmt.Sprintf("( %s ) AS count_table", scope.SQL))
-			} else {
-				scope.Search.Select("count(*) FROM ( SELECT count(*) as name ")
-				scope.Search.group += " ) AS count_table"
+func (scope *Scope) pluckMap(keyColumn, valueColumn string, value interface{}) *Scope {
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() != reflect.Ptr || reflectValue.Elem().Kind() != reflect.Map {
+		scope.Err(fmt.Errorf("results should be a pointer to a map, not %T", value))
+		return scope
+	}
+
+	dest := reflectValue.Elem()
+	dest.Set(reflect.MakeMap(dest.Type()))
+	scope.Search.Select(fmt.Sprintf("%v, %v", keyColumn, valueColumn))
+
+	rows, err := scope.rows()
+	if scope.Err(err) == nil {
+		defer rows.Close()
+		for rows.Next() {
+			key := reflect.New(dest.Type().Key())
+			elem := reflect.New(dest.Type().Elem())
+			if scope.Err(rows.Scan(key.Interface(), elem.Interface())) != nil {
+				return scope
 			}
-		} else {
-			scope.Search.Select("count(*)")
+			dest.SetMapIndex(key.Elem(), elem.Elem())
+		}
+
+		if err := rows.Err(); err != nil {
+			scope.Err(err)
 		}
 	}
+	return scope
+}
+
+func (scope *Scope) count(value interface{}) *Scope {
+	// orders, limits and offsets don't change the number of records
 	scope.Search.ignoreOrderQuery = true
+	scope.Search.Limit(-1).Offset(-1)
+
+	query, hasSelect := scope.Search.selects["query"]
+	switch {
+	case hasSelect && countingQueryRegexp.MatchString(fmt.Sprint(query)):
+		// count with the selected expression, e.g. `count(distinct name)`
+	case scope.Search.group != "" || hasSelect && distinctQueryRegexp.MatchString(fmt.Sprint(query)):
+		// count groups or distinct rows by wrapping the query as a subquery
+		if !hasSelect {
+			scope.Search.Select("1")
+		}
+		scope.InstanceSet("gorm:count_subquery", true)
+	default:
+		// selected columns are replaced as they may break COUNT, e.g. multiple columns
+		scope.Search.Select("count(*)")
+	}
+
 	scope.Err(scope.row().Scan(value))
 	return scope
 }
@@ -1052,7 +1420,7 @@ func (scope *Scope) typeName() string {
 // trace print sql log
 func (scope *Scope) trace(t time.Time) {
 	if len(scope.SQL) > 0 {
-		scope.db.slog(scope.SQL, t, scope.SQLVars...)
+		scope.db.slog(scope.commentSQL(scope.SQL), t, scope.SQLVars...)
 	}
 }
 
@@ -1062,6 +1430,11 @@ func (scope *Scope) changeableField(field *Field) bool {
 			if field.Name == attr || field.DBName == attr {
 				return true
 			}
+
+			// selecting nested fields of the association like `Company.Name` selects the association
+			if field.Relationship != nil && strings.HasPrefix(attr, field.Name+".") {
+				return true
+			}
 		}
 		return false
 	}
@@ -1075,9 +1448,36 @@ func (scope *Scope) changeableField(field *Field) bool {
 	return true
 }
 
+// associationDB return a new DB to save the association field with, nested fields of the association selected or
+// omitted like `Select("Company.Name")` and `Omit("Orders.PaymentToken")` are selected or omitted by the DB
+func (scope *Scope) associationDB(field *Field) *DB {
+	db := scope.NewDB()
+	if selects := nestedAttrs(scope.SelectAttrs(), field.Name); len(selects) > 0 {
+		db = db.Select(selects)
+	}
+
+	if omits := nestedAttrs(scope.OmitAttrs(), field.Name); len(omits) > 0 {
+		db = db.Omit(omits...)
+	}
+	return db
+}
+
+// nestedAttrs return attributes nested in the field, e.g. `Name` and `Address.City` of `Company.Name` and
+// `Company.Address.City` for field `Company`
+func nestedAttrs(attrs []string, fieldName string) (nested []string) {
+	for _, attr := range attrs {
+		if strings.HasPrefix(attr, fieldName+".") {
+			nested = append(nested, strings.TrimPrefix(attr, fieldName+"."))
+		}
+	}
+	return
+}
+
 func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 	toScope := scope.db.NewScope(value)
 	tx := scope.db.Set("gorm:association:source", scope.Value)
+	// the source model shouldn't be used to query related records
+	tx.search.model = nil
 
 	for _, foreignKey := range append(foreignKeys, toScope.typeName()+"Id", scope.typeName()+"Id") {
 		fromField, _ := scope.FieldByName(foreignKey)
@@ -1088,17 +1488,19 @@ func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 				if relationship.Kind == "many_to_many" {
 					joinTableHandler := relationship.JoinTableHandler
 					scope.Err(joinTableHandler.JoinWith(joinTableHandler, tx, scope.Value).Find(value).Error)
+				} else if relationship.Kind == "has_many_through" {
+					scope.Err(scope.joinThrough(tx, fromField.StructField).Find(value).Error)
 				} else if relationship.Kind == "belongs_to" {
 					for idx, foreignKey := range relationship.ForeignDBNames {
 						if field, ok := scope.FieldByName(foreignKey); ok {
-							tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.AssociationForeignDBNames[idx])), field.Field.Interface())
+							tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.AssociationForeignDBNames[idx])), field.sqlValue())
 						}
 					}
 					scope.Err(tx.Find(value).Error)
 				} else if relationship.Kind == "has_many" || relationship.Kind == "has_one" {
 					for idx, foreignKey := range relationship.ForeignDBNames {
 						if field, ok := scope.FieldByName(relationship.AssociationForeignDBNames[idx]); ok {
-							tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.Field.Interface())
+							tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.sqlValue())
 						}
 					}
 
@@ -1109,12 +1511,16 @@ func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 				}
 			} else {
 				sql := fmt.Sprintf("%v = ?", scope.Quote(toScope.PrimaryKey()))
-				scope.Err(tx.Where(sql, fromField.Field.Interface()).Find(value).Error)
+				scope.Err(tx.Where(sql, fromField.sqlValue()).Find(value).Error)
 			}
 			return scope
 		} else if toField != nil {
 			sql := fmt.Sprintf("%v = ?", scope.Quote(toField.DBName))
-			scope.Err(tx.Where(sql, scope.PrimaryKeyValue()).Find(value).Error)
+			var primaryKey interface{} = scope.PrimaryKeyValue()
+			if field := scope.PrimaryField(); field != nil {
+				primaryKey = field.sqlValue()
+			}
+			scope.Err(tx.Where(sql, primaryKey).Find(value).Error)
 			return scope
 		}
 	}
@@ -1123,13 +1529,34 @@ func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 	return scope
 }
 
-// getTableOptions return the table options string or an empty string if the table options does not exist
+// getTableOptions return the table options string or an empty string if the table options does not exist,
+// options of TableOptions are chosen by the dialect
 func (scope *Scope) getTableOptions() string {
 	tableOptions, ok := scope.Get("gorm:table_options")
 	if !ok {
 		return ""
 	}
-	return " " + tableOptions.(string)
+
+	if options, ok := tableOptions.(TableOptions); ok {
+		if option := options[scope.Dialect().GetName()]; option != "" {
+			return " " + option
+		}
+		return ""
+	}
+	return " " + fmt.Sprint(tableOptions)
+}
+
+// getModelTableOptions return options of the model's table, models implementing `TableOptions(Dialect) string`
+// override the setting `gorm:table_options`
+func (scope *Scope) getModelTableOptions() string {
+	if modelType := scope.GetModelStruct().ModelType; modelType != nil {
+		if model, ok := reflect.New(modelType).Interface().(tableOptioner); ok {
+			if option := model.TableOptions(scope.Dialect()); option != "" {
+				return " " + option
+			}
+		}
+	}
+	return scope.getTableOptions()
 }
 
 func (scope *Scope) createJoinTable(field *StructField) {
@@ -1174,7 +1601,7 @@ func (scope *Scope) createTable() *Scope {
 	var primaryKeyInColumnType = false
 	for _, field := range scope.GetModelStruct().StructFields {
 		if field.IsNormal {
-			sqlTag := scope.Dialect().DataTypeOf(field)
+			sqlTag := scope.dataTypeOf(field)
 
 			// Check if the primary key constraint was specified as
 			// part of the column type. If so, we can only support
@@ -1192,13 +1619,19 @@ func (scope *Scope) createTable() *Scope {
 		scope.createJoinTable(field)
 	}
 
+	// primary keys of partitioned tables should include the partition key
+	if field, _, ok := scope.partitionField(); ok && !field.IsPrimaryKey && len(primaryKeys) > 0 {
+		primaryKeys = append(primaryKeys, scope.Quote(field.DBName))
+	}
+
 	var primaryKeyStr string
 	if len(primaryKeys) > 0 && !primaryKeyInColumnType {
 		primaryKeyStr = fmt.Sprintf(", PRIMARY KEY (%v)", strings.Join(primaryKeys, ","))
 	}
 
-	scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.getTableOptions())).Exec()
+	scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v)%s%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.partitionSQL(), scope.getModelTableOptions())).Exec()
 
+	scope.migrateComments()
 	scope.autoIndex()
 	return scope
 }
@@ -1242,7 +1675,16 @@ func (scope *Scope) addForeignKey(field string, dest string, onDelete string, on
 		return
 	}
 	var query = `ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s;`
-	scope.Raw(fmt.Sprintf(query, scope.QuotedTableName(), scope.quoteIfPossible(keyName), scope.quoteIfPossible(field), dest, onDelete, onUpdate)).Exec()
+	scope.Raw(fmt.Sprintf(query, scope.QuotedTableName(), scope.quoteIfPossible(keyName), scope.quoteColumns(field), dest, onDelete, onUpdate)).Exec()
+}
+
+// quoteColumns quote comma separated columns of composite keys
+func (scope *Scope) quoteColumns(columns string) string {
+	var quotedColumns []string
+	for _, column := range splitKeys(columns) {
+		quotedColumns = append(quotedColumns, scope.quoteIfPossible(column))
+	}
+	return strings.Join(quotedColumns, ",")
 }
 
 func (scope *Scope) removeForeignKey(field string, dest string) {
@@ -1275,105 +1717,162 @@ func (scope *Scope) autoMigrate() *Scope {
 		for _, field := range scope.GetModelStruct().StructFields {
 			if !scope.Dialect().HasColumn(tableName, field.DBName) {
 				if field.IsNormal {
-					sqlTag := scope.Dialect().DataTypeOf(field)
+					sqlTag := scope.dataTypeOf(field)
 					scope.Raw(fmt.Sprintf("ALTER TABLE %v ADD %v %v;", quotedTableName, scope.Quote(field.DBName), sqlTag)).Exec()
 				}
+			} else if field.IsNormal {
+				scope.migrateEnumColumn(field)
 			}
 			scope.createJoinTable(field)
 		}
+		scope.migrateComments()
 		scope.autoIndex()
 	}
 	return scope
 }
 
 func (scope *Scope) autoIndex() *Scope {
-	var indexes = map[string][]string{}
-	var uniqueIndexes = map[string][]string{}
+	indexes, uniqueIndexes := scope.modelIndexes()
+
+	for _, name := range sortedIndexNames(indexes) {
+		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddIndex(name, indexes[name]...); db.Error != nil {
+			scope.db.AddError(db.Error)
+		}
+	}
+
+	for _, name := range sortedIndexNames(uniqueIndexes) {
+		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddUniqueIndex(name, uniqueIndexes[name]...); db.Error != nil {
+			scope.db.AddError(db.Error)
+		}
+	}
+
+	return scope
+}
+
+func sortedIndexNames(indexes map[string][]string) []string {
+	var names []string
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultIndexPriority is the priority of columns of composite indexes if it isn't given
+const defaultIndexPriority = 10
+
+type modelIndexColumn struct {
+	column   string
+	priority int
+}
+
+// modelIndexes return indexes and unique indexes declared with tags, keyed by index name.
+// Fields declaring the same index name share a composite index, ordered by priorities suffixed to names, then by the
+// order of fields, the index is unique if any of them declares it with `unique_index`
+//     Name string `gorm:"index:idx_name_age"`      // priority 10 by default
+//     Age  int    `gorm:"index:idx_name_age:5"`    // (age, name)
+//     Code string `gorm:"unique_index:uix_code_org:1"`
+//     Org  string `gorm:"unique_index:uix_code_org:2"`
+func (scope *Scope) modelIndexes() (indexes map[string][]string, uniqueIndexes map[string][]string) {
+	var (
+		indexColumns = map[string][]modelIndexColumn{}
+		unique       = map[string]bool{}
+	)
 
 	for _, field := range scope.GetStructFields() {
-		if name, ok := field.TagSettingsGet("INDEX"); ok {
-			names := strings.Split(name, ",")
+		for _, key := range []string{"INDEX", "UNIQUE_INDEX"} {
+			value, ok := field.TagSettingsGet(key)
+			if !ok {
+				continue
+			}
 
-			for _, name := range names {
-				if name == "INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("idx", scope.TableName(), field.DBName)
+			for _, name := range strings.Split(value, ",") {
+				priority := defaultIndexPriority
+				if idx := strings.LastIndex(name, ":"); idx >= 0 {
+					if p, err := strconv.Atoi(name[idx+1:]); err == nil {
+						name, priority = name[:idx], p
+					}
+				}
+
+				if name == key || name == "" {
+					prefix := "idx"
+					if key == "UNIQUE_INDEX" {
+						prefix = "uix"
+					}
+					name = scope.Dialect().BuildKeyName(prefix, scope.TableName(), field.DBName)
 				}
+
 				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
-				indexes[name] = append(indexes[name], column)
+				indexColumns[name] = append(indexColumns[name], modelIndexColumn{column: column, priority: priority})
+				unique[name] = unique[name] || key == "UNIQUE_INDEX"
 			}
 		}
+	}
 
-		if name, ok := field.TagSettingsGet("UNIQUE_INDEX"); ok {
-			names := strings.Split(name, ",")
+	indexes = map[string][]string{}
+	uniqueIndexes = map[string][]string{}
+	for name, columns := range indexColumns {
+		sort.SliceStable(columns, func(i, j int) bool {
+			return columns[i].priority < columns[j].priority
+		})
 
-			for _, name := range names {
-				if name == "UNIQUE_INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("uix", scope.TableName(), field.DBName)
-				}
-				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
-				uniqueIndexes[name] = append(uniqueIndexes[name], column)
+		for _, column := range columns {
+			if unique[name] {
+				uniqueIndexes[name] = append(uniqueIndexes[name], column.column)
+			} else {
+				indexes[name] = append(indexes[name], column.column)
 			}
 		}
 	}
+	return
+}
 
-	for name, columns := range indexes {
-		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddIndex(name, columns...); db.Error != nil {
-			scope.db.AddError(db.Error)
+// getColumnAsArray return distinct values of fields of records, values are converted to be used in SQL with
+// `Field.sqlValue`, like binary UUIDs, and records without values are skipped
+func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (results [][]interface{}) {
+	var (
+		resultMap = make(map[string][]interface{})
+		modelType reflect.Type
+		sqlFields []*StructField
+	)
+	addResult := func(object reflect.Value) {
+		if object.Type() != modelType {
+			modelType, sqlFields = object.Type(), scope.sqlValueFields(object.Type(), columns)
 		}
-	}
 
-	for name, columns := range uniqueIndexes {
-		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddUniqueIndex(name, columns...); db.Error != nil {
-			scope.db.AddError(db.Error)
+		var result []interface{}
+		var hasValue = false
+		for _, column := range columns {
+			field := object.FieldByName(column)
+			if hasValue || !isBlank(field) {
+				hasValue = true
+			}
+			result = append(result, field.Interface())
 		}
-	}
 
-	return scope
-}
+		if hasValue {
+			h := fmt.Sprint(result...)
+			if _, exist := resultMap[h]; !exist {
+				for idx, field := range sqlFields {
+					if field != nil {
+						result[idx] = sqlValueOf(field, result[idx])
+					}
+				}
+				resultMap[h] = result
+			}
+		}
+	}
 
-func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (results [][]interface{}) {
-	resultMap := make(map[string][]interface{})
 	for _, value := range values {
 		indirectValue := indirect(reflect.ValueOf(value))
 
 		switch indirectValue.Kind() {
 		case reflect.Slice:
 			for i := 0; i < indirectValue.Len(); i++ {
-				var result []interface{}
-				var object = indirect(indirectValue.Index(i))
-				var hasValue = false
-				for _, column := range columns {
-					field := object.FieldByName(column)
-					if hasValue || !isBlank(field) {
-						hasValue = true
-					}
-					result = append(result, field.Interface())
-				}
-
-				if hasValue {
-					h := fmt.Sprint(result...)
-					if _, exist := resultMap[h]; !exist {
-						resultMap[h] = result
-					}
-				}
+				addResult(indirect(indirectValue.Index(i)))
 			}
 		case reflect.Struct:
-			var result []interface{}
-			var hasValue = false
-			for _, column := range columns {
-				field := indirectValue.FieldByName(column)
-				if hasValue || !isBlank(field) {
-					hasValue = true
-				}
-				result = append(result, field.Interface())
-			}
-
-			if hasValue {
-				h := fmt.Sprint(result...)
-				if _, exist := resultMap[h]; !exist {
-					resultMap[h] = result
-				}
-			}
+			addResult(indirectValue)
 		}
 	}
 	for _, v := range resultMap {
@@ -1382,6 +1881,20 @@ func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (r
 	return
 }
 
+// sqlValueFields return fields of the model type whose values are converted to be used in SQL, refer
+// `Field.sqlValue`, fields of other names are nil
+func (scope *Scope) sqlValueFields(modelType reflect.Type, fieldNames []string) []*StructField {
+	fields := make([]*StructField, len(fieldNames))
+	for _, field := range scope.New(reflect.New(modelType).Interface()).GetModelStruct().StructFields {
+		for idx, name := range fieldNames {
+			if field.Name == name && hasSQLValue(field) {
+				fields[idx] = field
+			}
+		}
+	}
+	return fields
+}
+
 func (scope *Scope) getColumnAsScope(column string) *Scope {
 	indirectScopeValue := scope.IndirectValue()
 
@@ -1411,16 +1924,23 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 					results = reflect.Append(results, result.Addr())
 				}
 			}
-			return scope.New(results.Interface())
+			return scope.newWithUnscoped(results.Interface())
 		}
 	case reflect.Struct:
 		if field := indirectScopeValue.FieldByName(column); field.CanAddr() {
-			return scope.New(field.Addr().Interface())
+			return scope.newWithUnscoped(field.Addr().Interface())
 		}
 	}
 	return nil
 }
 
+// newWithUnscoped create a new Scope without search information except Unscoped
+func (scope *Scope) newWithUnscoped(value interface{}) *Scope {
+	newScope := scope.New(value)
+	newScope.Search.Unscoped = scope.Search.Unscoped
+	return newScope
+}
+
 func (scope *Scope) hasConditions() bool {
 	return !scope.PrimaryKeyZero() ||
 		len(scope.Search.whereConditions) > 0 ||
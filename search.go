@@ -2,6 +2,7 @@ package gorm
 
 import (
 	"fmt"
+	"strings"
 )
 
 type search struct {
@@ -21,6 +22,8 @@ type search struct {
 	limit            interface{}
 	group            string
 	tableName        string
+	alias            string
+	model            interface{}
 	raw              bool
 	Unscoped         bool
 	ignoreOrderQuery bool
@@ -31,76 +34,85 @@ type searchPreload struct {
 	conditions []interface{}
 }
 
+// clone copy the search, slices of conditions are shared until they are appended to, their capacities are limited
+// to their lengths, so appending to either search allocates a new array instead of writing to the shared one
 func (s *search) clone() *search {
-	clone := search{
-		db:               s.db,
-		whereConditions:  make([]map[string]interface{}, len(s.whereConditions)),
-		orConditions:     make([]map[string]interface{}, len(s.orConditions)),
-		notConditions:    make([]map[string]interface{}, len(s.notConditions)),
-		havingConditions: make([]map[string]interface{}, len(s.havingConditions)),
-		joinConditions:   make([]map[string]interface{}, len(s.joinConditions)),
-		initAttrs:        make([]interface{}, len(s.initAttrs)),
-		assignAttrs:      make([]interface{}, len(s.assignAttrs)),
-		selects:          s.selects,
-		omits:            make([]string, len(s.omits)),
-		orders:           make([]interface{}, len(s.orders)),
-		preload:          make([]searchPreload, len(s.preload)),
-		offset:           s.offset,
-		limit:            s.limit,
-		group:            s.group,
-		tableName:        s.tableName,
-		raw:              s.raw,
-		Unscoped:         s.Unscoped,
-		ignoreOrderQuery: s.ignoreOrderQuery,
-	}
-	for i, value := range s.whereConditions {
-		clone.whereConditions[i] = value
-	}
-	for i, value := range s.orConditions {
-		clone.orConditions[i] = value
-	}
-	for i, value := range s.notConditions {
-		clone.notConditions[i] = value
-	}
-	for i, value := range s.havingConditions {
-		clone.havingConditions[i] = value
-	}
-	for i, value := range s.joinConditions {
-		clone.joinConditions[i] = value
-	}
-	for i, value := range s.initAttrs {
-		clone.initAttrs[i] = value
-	}
-	for i, value := range s.assignAttrs {
-		clone.assignAttrs[i] = value
-	}
-	for i, value := range s.omits {
-		clone.omits[i] = value
-	}
-	for i, value := range s.orders {
-		clone.orders[i] = value
-	}
-	for i, value := range s.preload {
-		clone.preload[i] = value
-	}
+	clone := *s
+	clone.whereConditions = s.whereConditions[:len(s.whereConditions):len(s.whereConditions)]
+	clone.orConditions = s.orConditions[:len(s.orConditions):len(s.orConditions)]
+	clone.notConditions = s.notConditions[:len(s.notConditions):len(s.notConditions)]
+	clone.havingConditions = s.havingConditions[:len(s.havingConditions):len(s.havingConditions)]
+	clone.joinConditions = s.joinConditions[:len(s.joinConditions):len(s.joinConditions)]
+	clone.initAttrs = s.initAttrs[:len(s.initAttrs):len(s.initAttrs)]
+	clone.assignAttrs = s.assignAttrs[:len(s.assignAttrs):len(s.assignAttrs)]
+	clone.omits = s.omits[:len(s.omits):len(s.omits)]
+	clone.orders = s.orders[:len(s.orders):len(s.orders)]
+	clone.preload = s.preload[:len(s.preload):len(s.preload)]
 	return &clone
 }
 
 func (s *search) Where(query interface{}, values ...interface{}) *search {
+	s.checkPlaceholders(query, values)
 	s.whereConditions = append(s.whereConditions, map[string]interface{}{"query": query, "args": values})
 	return s
 }
 
 func (s *search) Not(query interface{}, values ...interface{}) *search {
+	// column names like `Not("name", names)` are built with an implicit placeholder
+	if str, ok := query.(string); !ok || comparisonRegexp.MatchString(str) {
+		s.checkPlaceholders(query, values)
+	}
 	s.notConditions = append(s.notConditions, map[string]interface{}{"query": query, "args": values})
 	return s
 }
 
 func (s *search) Or(query interface{}, values ...interface{}) *search {
+	s.checkPlaceholders(query, values)
 	s.orConditions = append(s.orConditions, map[string]interface{}{"query": query, "args": values})
 	return s
 }
 
+// checkPlaceholders report an error if the number of placeholders `?` of the string condition doesn't match its values,
+// placeholders in quoted strings and identifiers aren't counted. Conditions without values aren't checked, so `?` could
+// be used as operators like `Where("attributes ? 'color'")` of postgres jsonb, `?` of conditions with values is escaped
+// as `??`, e.g. `Where("attributes ?? 'color' AND age > ?", 18)`
+func (s *search) checkPlaceholders(query interface{}, values []interface{}) {
+	str, ok := query.(string)
+	if !ok || s.db == nil || len(values) == 0 || isNumberRegexp.MatchString(str) {
+		return
+	}
+
+	// named arguments are checked when they are bound, refer `namedArgs`
+	if strings.Contains(str, "@") && namedArgsLookup(s.db.NewScope(nil), values) != nil {
+		return
+	}
+
+	if count := countPlaceholders(str); count != len(values) {
+		s.db.AddError(fmt.Errorf("%v: %v placeholders in condition %q, but got %v arguments", ErrInvalidSQL, count, str, len(values)))
+	}
+}
+
+// countPlaceholders count placeholders `?` of the SQL which aren't in quoted strings or identifiers, or escaped as `??`
+func countPlaceholders(sql string) (count int) {
+	var quote rune
+	runes := []rune(sql)
+	for idx := 0; idx < len(runes); idx++ {
+		switch r := runes[idx]; {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?' && idx+1 < len(runes) && runes[idx+1] == '?':
+			idx++
+		case r == '?':
+			count++
+		}
+	}
+	return
+}
+
 func (s *search) Attrs(attrs ...interface{}) *search {
 	s.initAttrs = append(s.initAttrs, toSearchableMap(attrs...))
 	return s
@@ -151,6 +163,7 @@ func (s *search) Having(query interface{}, values ...interface{}) *search {
 	if val, ok := query.(*SqlExpr); ok {
 		s.havingConditions = append(s.havingConditions, map[string]interface{}{"query": val.expr, "args": val.args})
 	} else {
+		s.checkPlaceholders(query, values)
 		s.havingConditions = append(s.havingConditions, map[string]interface{}{"query": query, "args": values})
 	}
 	return s
@@ -188,6 +201,11 @@ func (s *search) Table(name string) *search {
 	return s
 }
 
+func (s *search) Alias(name string) *search {
+	s.alias = name
+	return s
+}
+
 func (s *search) getInterfaceAsSQL(value interface{}) (str string) {
 	switch value.(type) {
 	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
@@ -50,3 +50,24 @@ func TestWhereCloneCorruption(t *testing.T) {
 		})
 	}
 }
+
+func TestCloneSearchWithoutCopying(t *testing.T) {
+	s := new(search)
+	s.Where("name = ?", "jinzhu").Order("name").Attrs("name", "jinzhu").Preload("Emails")
+
+	s1, s2 := s.clone(), s.clone()
+	if &s1.whereConditions[0] != &s.whereConditions[0] {
+		t.Errorf("Conditions should be shared until they are appended to")
+	}
+
+	s1.Where("age = ?", 20).Order("age").Attrs("email", "a@e.org").Preload("Languages")
+	s2.Where("age = ?", 30).Order("email").Attrs("email", "b@e.org").Preload("Company")
+
+	if len(s.whereConditions) != 1 || len(s.orders) != 1 || len(s.initAttrs) != 1 || len(s.preload) != 1 {
+		t.Errorf("Appending to clones shouldn't change the search")
+	}
+
+	if s1.whereConditions[1]["args"].([]interface{})[0] != 20 || s1.orders[1] != "age" || fmt.Sprint(s1.initAttrs[1]) != "map[email:a@e.org]" || s1.preload[1].schema != "Languages" {
+		t.Errorf("Appending to a clone shouldn't change its sibling, got %v, %v, %v, %v", s1.whereConditions, s1.orders, s1.initAttrs, s1.preload)
+	}
+}
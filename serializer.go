@@ -0,0 +1,236 @@
+package gorm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Serializer marshal field values to the value stored in database and unmarshal them back, fields use it with tag
+// `serializer`, e.g.
+//     type User struct {
+//       Roles    []string          `gorm:"serializer:json"`
+//       Settings map[string]string `gorm:"serializer:gob"`
+//     }
+type Serializer interface {
+	// Serialize convert the field's value to the value stored in database
+	Serialize(field *StructField, value interface{}) (interface{}, error)
+	// Deserialize assign the value read from database to dest, which is a pointer to the field's value
+	Deserialize(field *StructField, dbValue interface{}, dest interface{}) error
+}
+
+var serializers sync.Map
+
+func init() {
+	RegisterSerializer("json", JSONSerializer{})
+	RegisterSerializer("gob", GobSerializer{})
+}
+
+// RegisterSerializer register a serializer with name, which could be used with tag `serializer:name`
+func RegisterSerializer(name string, serializer Serializer) {
+	serializers.Store(name, serializer)
+}
+
+// GetSerializer get the serializer registered with name
+func GetSerializer(name string) (Serializer, bool) {
+	if serializer, ok := serializers.Load(name); ok {
+		return serializer.(Serializer), true
+	}
+	return nil, false
+}
+
+// serializerOf return the serializer of the field, the error is set if the serializer isn't registered
+func serializerOf(field *StructField) (Serializer, bool, error) {
+	name, ok := field.TagSettingsGet("SERIALIZER")
+	if !ok {
+		return nil, false, nil
+	}
+
+	if serializer, ok := GetSerializer(name); ok {
+		return serializer, true, nil
+	}
+	return nil, true, fmt.Errorf("serializer %v of field %v is not registered", name, field.Name)
+}
+
+// sqlValue return the value used in SQL for the field, which is serialized if the field has a serializer, or
+// converted for the dialect if the field is a binary UUID
+func (field *Field) sqlValue() interface{} {
+	return sqlValueOf(field.StructField, field.Field.Interface())
+}
+
+// sqlValueOf return the value used in SQL for the value of the field, refer `Field.sqlValue`, it is used to bind
+// values of keys which are read from other records, like foreign keys of preloading and associations
+func sqlValueOf(field *StructField, value interface{}) interface{} {
+	if _, ok := field.TagSettingsGet("SERIALIZER"); ok {
+		return serializerValuer{field: field, value: value}
+	}
+
+	if isBinaryUUIDField(field) {
+		return binaryUUIDValuer{binaryUUIDValue{field: field, value: value}}
+	}
+	return value
+}
+
+// hasSQLValue report whether values of the field are converted to be used in SQL, refer `Field.sqlValue`
+func hasSQLValue(field *StructField) bool {
+	_, ok := field.TagSettingsGet("SERIALIZER")
+	return ok || isBinaryUUIDField(field)
+}
+
+// serializerValuer serialize the field's value when it is used as a query argument
+type serializerValuer struct {
+	field *StructField
+	value interface{}
+}
+
+// String return the value of the field, so keys of records are compared with their values
+func (valuer serializerValuer) String() string {
+	return fmt.Sprint(valuer.value)
+}
+
+func (valuer serializerValuer) Value() (driver.Value, error) {
+	serializer, _, err := serializerOf(valuer.field)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := serializer.Serialize(valuer.field, valuer.value)
+	if err != nil {
+		return nil, err
+	}
+
+	// the serializer may return a Valuer like JSON
+	if v, ok := value.(driver.Valuer); ok {
+		return v.Value()
+	}
+	return value, nil
+}
+
+// serializerScanner deserialize values read from database to the field
+type serializerScanner struct {
+	serializer Serializer
+	field      *Field
+}
+
+func (scanner *serializerScanner) Scan(src interface{}) error {
+	return scanner.serializer.Deserialize(scanner.field.StructField, src, scanner.field.Field.Addr().Interface())
+}
+
+// serializedDataType return the sql data type of fields with serializer, serializers could decide it by
+// implementing `GormDataType(Dialect) string`, text types are used by default
+func serializedDataType(field *StructField, dialect Dialect) (string, bool) {
+	serializer, ok, _ := serializerOf(field)
+	if !ok {
+		return "", false
+	}
+
+	if _, ok := field.TagSettingsGet("TYPE"); ok {
+		return "", false
+	}
+
+	if dataTyper, ok := serializer.(interface{ GormDataType(Dialect) string }); ok {
+		return dataTyper.GormDataType(dialect), true
+	}
+	return JSON{}.GormDataType(dialect), true
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	switch reflectValue := reflect.ValueOf(value); reflectValue.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return reflectValue.IsNil()
+	}
+	return false
+}
+
+func dbValueBytes(dbValue interface{}) ([]byte, error) {
+	switch v := dbValue.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("failed to deserialize value %#v", dbValue)
+	}
+}
+
+// JSONSerializer serialize values as JSON text, registered as `json`
+type JSONSerializer struct{}
+
+// Serialize marshal value to JSON, nil values are stored as NULL
+func (JSONSerializer) Serialize(field *StructField, value interface{}) (interface{}, error) {
+	if isNilValue(value) {
+		return nil, nil
+	}
+
+	bytes, err := json.Marshal(value)
+	return string(bytes), err
+}
+
+// Deserialize unmarshal JSON to dest
+func (JSONSerializer) Deserialize(field *StructField, dbValue interface{}, dest interface{}) error {
+	if dbValue == nil {
+		reflect.ValueOf(dest).Elem().Set(reflect.Zero(reflect.TypeOf(dest).Elem()))
+		return nil
+	}
+
+	bytes, err := dbValueBytes(dbValue)
+	if err != nil {
+		return err
+	}
+
+	reflect.ValueOf(dest).Elem().Set(reflect.Zero(reflect.TypeOf(dest).Elem()))
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, dest)
+}
+
+// GobSerializer serialize values with encoding/gob, registered as `gob`
+type GobSerializer struct{}
+
+// Serialize encode value with gob, nil values are stored as NULL
+func (GobSerializer) Serialize(field *StructField, value interface{}) (interface{}, error) {
+	if isNilValue(value) {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(value)
+	return buf.Bytes(), err
+}
+
+// Deserialize decode gob data to dest
+func (GobSerializer) Deserialize(field *StructField, dbValue interface{}, dest interface{}) error {
+	reflect.ValueOf(dest).Elem().Set(reflect.Zero(reflect.TypeOf(dest).Elem()))
+	if dbValue == nil {
+		return nil
+	}
+
+	data, err := dbValueBytes(dbValue)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(dest)
+}
+
+// GormDataType return binary data types for gob data
+func (GobSerializer) GormDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres", "cockroach":
+		return "BYTEA"
+	case "mysql":
+		return "LONGBLOB"
+	case "mssql":
+		return "VARBINARY(MAX)"
+	default:
+		return "BLOB"
+	}
+}
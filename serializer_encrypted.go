@@ -0,0 +1,234 @@
+package gorm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrEncryptionKeyNotFound returned when the key used to encrypt a value isn't provided by the key provider
+var ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+
+// KeyProvider provide keys for EncryptedSerializer, keys must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256
+type KeyProvider interface {
+	// CurrentKey return the key used to encrypt new values and its id
+	CurrentKey() (id string, key []byte, err error)
+	// Key return the key with id, used to decrypt values encrypted with it
+	Key(id string) ([]byte, error)
+}
+
+// KeyRing is a KeyProvider holding keys in memory, keys could be rotated by adding a new key and making it current,
+// values encrypted with old keys are still readable as long as old keys are kept in the ring
+type KeyRing struct {
+	mutex     sync.RWMutex
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyRing create a KeyRing with keys, the key with currentID is used to encrypt
+func NewKeyRing(currentID string, keys map[string][]byte) *KeyRing {
+	keyRing := &KeyRing{keys: map[string][]byte{}}
+	for id, key := range keys {
+		keyRing.keys[id] = key
+	}
+	keyRing.currentID = currentID
+	return keyRing
+}
+
+// Rotate add the key to the ring and use it to encrypt new values
+func (keyRing *KeyRing) Rotate(id string, key []byte) {
+	keyRing.mutex.Lock()
+	defer keyRing.mutex.Unlock()
+	keyRing.keys[id] = key
+	keyRing.currentID = id
+}
+
+// CurrentKey return the current key
+func (keyRing *KeyRing) CurrentKey() (string, []byte, error) {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+	key, ok := keyRing.keys[keyRing.currentID]
+	if !ok {
+		return "", nil, fmt.Errorf("%v: %v", ErrEncryptionKeyNotFound, keyRing.currentID)
+	}
+	return keyRing.currentID, key, nil
+}
+
+// Key return the key with id
+func (keyRing *KeyRing) Key(id string) ([]byte, error) {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+	if key, ok := keyRing.keys[id]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%v: %v", ErrEncryptionKeyNotFound, id)
+}
+
+// EncryptedSerializer encrypt field values with AES-GCM, values are stored as `<key id>$<base64 of nonce and ciphertext>`
+//     gorm.RegisterSerializer("encrypted", gorm.EncryptedSerializer{Keys: gorm.NewKeyRing("v1", keys)})
+//
+//     type User struct {
+//       Token string `gorm:"serializer:encrypted"`
+//       SSN   string `gorm:"serializer:encrypted;deterministic"`
+//     }
+//
+//     db.Where(&User{SSN: "123-45-6789"}).First(&user)
+// Nonces are random unless the serializer or field (with tag `deterministic`) is deterministic, in which case the
+// nonce is derived from the value, so equal values are encrypted to equal ciphertexts and could be queried by exact
+// match. Deterministic values are only matched with the current key, values written before rotating keys need to be
+// saved again to be queryable.
+type EncryptedSerializer struct {
+	Keys          KeyProvider
+	Deterministic bool
+}
+
+const encryptedKeySeparator = "$"
+
+// Serialize encrypt the value, strings and bytes are encrypted as they are, others are encrypted as JSON
+func (serializer EncryptedSerializer) Serialize(field *StructField, value interface{}) (interface{}, error) {
+	if isNilValue(value) {
+		return nil, nil
+	}
+
+	if serializer.Keys == nil {
+		return nil, errors.New("no key provider for encrypted serializer")
+	}
+
+	var (
+		plaintext    []byte
+		reflectValue = reflect.Indirect(reflect.ValueOf(value))
+	)
+	switch {
+	case reflectValue.Kind() == reflect.String:
+		plaintext = []byte(reflectValue.String())
+	case reflectValue.Kind() == reflect.Slice && reflectValue.Type().Elem().Kind() == reflect.Uint8:
+		plaintext = reflectValue.Bytes()
+	default:
+		var err error
+		if plaintext, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	keyID, key, err := serializer.Keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if serializer.isDeterministic(field) {
+		mac := hmac.New(sha256.New, deriveKey(key, "gorm encrypted serializer nonce"))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, []byte(keyID))
+	return keyID + encryptedKeySeparator + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Deserialize decrypt the value with the key it was encrypted with
+func (serializer EncryptedSerializer) Deserialize(field *StructField, dbValue interface{}, dest interface{}) error {
+	reflectValue := reflect.ValueOf(dest).Elem()
+	reflectValue.Set(reflect.Zero(reflectValue.Type()))
+	if dbValue == nil {
+		return nil
+	}
+
+	data, err := dbValueBytes(dbValue)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.SplitN(string(data), encryptedKeySeparator, 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid encrypted value of field %v", field.Name)
+	}
+
+	if serializer.Keys == nil {
+		return errors.New("no key provider for encrypted serializer")
+	}
+
+	key, err := serializer.Keys.Key(parts[0])
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return fmt.Errorf("invalid encrypted value of field %v", field.Name)
+	}
+
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return err
+	}
+
+	if reflectValue.Kind() == reflect.Ptr {
+		reflectValue.Set(reflect.New(reflectValue.Type().Elem()))
+		reflectValue = reflectValue.Elem()
+	}
+
+	switch {
+	case reflectValue.Kind() == reflect.String:
+		reflectValue.SetString(string(plaintext))
+	case reflectValue.Kind() == reflect.Slice && reflectValue.Type().Elem().Kind() == reflect.Uint8:
+		reflectValue.SetBytes(plaintext)
+	default:
+		return json.Unmarshal(plaintext, reflectValue.Addr().Interface())
+	}
+	return nil
+}
+
+func (serializer EncryptedSerializer) isDeterministic(field *StructField) bool {
+	if serializer.Deterministic {
+		return true
+	}
+	_, ok := field.TagSettingsGet("DETERMINISTIC")
+	return ok
+}
+
+// deriveKey derive a key for the purpose from the key with HKDF-SHA256 (RFC 5869), so the key isn't reused by other
+// algorithms, like the HMAC of deterministic nonces
+func deriveKey(key []byte, info string) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,70 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type EncryptedAccount struct {
+	ID     uint
+	Token  string `gorm:"serializer:encrypted"`
+	SSN    string `gorm:"serializer:encrypted;deterministic"`
+	Secret *struct {
+		Pin int
+	} `gorm:"serializer:encrypted"`
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	keys := gorm.NewKeyRing("v1", map[string][]byte{"v1": []byte("0123456789abcdef0123456789abcdef")})
+	gorm.RegisterSerializer("encrypted", gorm.EncryptedSerializer{Keys: keys})
+
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&EncryptedAccount{})
+	if err := db.AutoMigrate(&EncryptedAccount{}).Error; err != nil {
+		t.Fatalf("Failed to migrate encrypted fields, got %v", err)
+	}
+
+	account := EncryptedAccount{Token: "token", SSN: "123-45-6789", Secret: &struct{ Pin int }{Pin: 1234}}
+	if err := db.Create(&account).Error; err != nil {
+		t.Fatalf("Failed to create encrypted fields, got %v", err)
+	}
+
+	var token, ssn string
+	db.Table("encrypted_accounts").Where("id = ?", account.ID).Select("token, ssn").Row().Scan(&token, &ssn)
+	if !strings.HasPrefix(token, "v1$") || strings.Contains(token, "token") || strings.Contains(ssn, "6789") {
+		t.Errorf("Fields should be stored encrypted, but got %v, %v", token, ssn)
+	}
+
+	var result EncryptedAccount
+	if err := db.First(&result, account.ID).Error; err != nil {
+		t.Fatalf("Failed to find encrypted fields, got %v", err)
+	}
+	if result.Token != "token" || result.SSN != "123-45-6789" || result.Secret == nil || result.Secret.Pin != 1234 {
+		t.Errorf("Fields should be decrypted, but got %#v", result)
+	}
+
+	var found EncryptedAccount
+	if err := db.Where(&EncryptedAccount{SSN: "123-45-6789"}).First(&found).Error; err != nil || found.ID != account.ID {
+		t.Errorf("Deterministic fields should be queryable by exact match, got %v", err)
+	}
+
+	keys.Rotate("v2", []byte("fedcba9876543210fedcba9876543210"))
+
+	var rotated EncryptedAccount
+	if err := db.First(&rotated, account.ID).Error; err != nil || rotated.Token != "token" {
+		t.Errorf("Values encrypted with old keys should be decrypted after rotation, got %v, %#v", err, rotated)
+	}
+
+	db.Save(&rotated)
+	db.Table("encrypted_accounts").Where("id = ?", account.ID).Select("token").Row().Scan(&token)
+	if !strings.HasPrefix(token, "v2$") {
+		t.Errorf("Values should be encrypted with the current key, but got %v", token)
+	}
+
+	gorm.RegisterSerializer("encrypted", gorm.EncryptedSerializer{Keys: gorm.NewKeyRing("v3", map[string][]byte{"v3": []byte("0123456789abcdef")})})
+	if err := db.First(&EncryptedAccount{}, account.ID).Error; err == nil {
+		t.Errorf("Should return error when decrypting with unknown key")
+	}
+}
@@ -0,0 +1,111 @@
+package gorm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type SerializerProfile struct {
+	Theme string
+	Tags  []string
+}
+
+type SerializerUser struct {
+	ID       uint
+	Name     string
+	Roles    []string           `gorm:"serializer:json"`
+	Profile  *SerializerProfile `gorm:"serializer:json"`
+	Settings map[string]int     `gorm:"serializer:gob"`
+	Nickname string             `gorm:"serializer:reverse"`
+}
+
+// reverseSerializer store strings reversed
+type reverseSerializer struct{}
+
+func (reverseSerializer) Serialize(field *gorm.StructField, value interface{}) (interface{}, error) {
+	return reverseString(value.(string)), nil
+}
+
+func (reverseSerializer) Deserialize(field *gorm.StructField, dbValue interface{}, dest interface{}) error {
+	switch v := dbValue.(type) {
+	case []byte:
+		*dest.(*string) = reverseString(string(v))
+	case string:
+		*dest.(*string) = reverseString(v)
+	default:
+		return errors.New("invalid value")
+	}
+	return nil
+}
+
+func reverseString(str string) string {
+	runes := []rune(str)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestSerializer(t *testing.T) {
+	gorm.RegisterSerializer("reverse", reverseSerializer{})
+
+	db := DB.Set("gorm:table_options", "")
+	db.DropTableIfExists(&SerializerUser{})
+	if err := db.AutoMigrate(&SerializerUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate serialized fields, got %v", err)
+	}
+
+	user := SerializerUser{
+		Name:     "serializer",
+		Roles:    []string{"admin", "dev"},
+		Profile:  &SerializerProfile{Theme: "dark", Tags: []string{"a"}},
+		Settings: map[string]int{"volume": 3},
+		Nickname: "jinzhu",
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("Failed to create with serialized fields, got %v", err)
+	}
+
+	var roles, nickname string
+	db.Table("serializer_users").Where("id = ?", user.ID).Select("roles, nickname").Row().Scan(&roles, &nickname)
+	if roles != `["admin","dev"]` || nickname != "uhznij" {
+		t.Errorf("Fields should be stored serialized, but got %v, %v", roles, nickname)
+	}
+
+	var result SerializerUser
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("Failed to find serialized fields, got %v", err)
+	}
+
+	if strings.Join(result.Roles, ",") != "admin,dev" || result.Profile == nil || result.Profile.Theme != "dark" ||
+		result.Settings["volume"] != 3 || result.Nickname != "jinzhu" {
+		t.Errorf("Fields should be deserialized, but got %#v", result)
+	}
+
+	if err := db.Model(&result).Updates(map[string]interface{}{"roles": []string{"guest"}, "profile": nil}).Error; err != nil {
+		t.Errorf("Failed to update serialized fields, got %v", err)
+	}
+
+	var updated SerializerUser
+	db.First(&updated, user.ID)
+	if len(updated.Roles) != 1 || updated.Roles[0] != "guest" || updated.Profile != nil {
+		t.Errorf("Serialized fields should be updated, but got %#v", updated)
+	}
+
+	var count int
+	db.Model(&SerializerUser{}).Where(&SerializerUser{Nickname: "jinzhu"}).Count(&count)
+	if count != 1 {
+		t.Errorf("Struct conditions should be serialized, but got %v", count)
+	}
+
+	type unregisteredSerializerUser struct {
+		ID   uint
+		Data []string `gorm:"serializer:unknown"`
+	}
+	if err := db.Table("serializer_users").Create(&unregisteredSerializerUser{Data: []string{"a"}}).Error; err == nil {
+		t.Errorf("Should return error for unregistered serializer")
+	}
+}
@@ -0,0 +1,173 @@
+package gorm
+
+import (
+	"time"
+)
+
+// Session is the configuration of a session created with `DB.Session`
+type Session struct {
+	// NewDB start the session without search conditions, like `DB.New`
+	NewDB bool
+	// DryRun build SQL without executing it, the SQL could be got with `DB.DryRunSQL`,
+	// row queries like `Row`, `Rows`, `Count` and `Pluck` are still executed
+	DryRun bool
+	// Logger replace the logger of the session
+	Logger logger
+	// LogMode set log mode of the session if not nil, refer `DB.LogMode`
+	LogMode *bool
+	// InterpolateLogSQL log SQL of the session with values instead of placeholders, refer `DB.InterpolateLogSQL`
+	InterpolateLogSQL bool
+	// NowFunc replace the function to get current time of the session, refer `DB.SetNowFuncOverride`
+	NowFunc func() time.Time
+	// TimeZone store times of the session in the location, refer `DB.TimeZone`
+	TimeZone *time.Location
+	// Timeout set the timeout of statements of the session, refer `DB.Timeout`
+	Timeout time.Duration
+	// SlowThreshold log statements of the session taking longer than it at WARN level, refer `DB.SlowThreshold`
+	SlowThreshold time.Duration
+	// SkipHooks skip model hooks of the session, refer `DB.SkipHooks`
+	SkipHooks bool
+	// SkipValidation skip validating records of the session, refer `DB.SkipValidation`
+	SkipValidation bool
+	// SkipTenancy skip tenancy of the session, refer `DB.SkipTenancy`
+	SkipTenancy bool
+	// TrackChanges track original values of records, refer `DB.TrackChanges`
+	TrackChanges bool
+	// FullSaveAssociations create missing associations and update existing ones with all their fields when saving,
+	// associations are saved even if auto update or auto create is disabled with tags or settings
+	FullSaveAssociations bool
+	// StrictScan return StrictScanError when columns of results have no fields to scan into, or fields of the
+	// destination receive no columns, which catches typos in `Select`
+	StrictScan bool
+	// QueryFields select columns of the model's fields instead of `*`, so new columns added to tables won't be
+	// scanned and covering indexes could be used, refer `DB.QueryFields`
+	QueryFields bool
+	// ConnectionHooks run hooks of the session when it acquires connections or starts transactions, after hooks of
+	// the DB, refer `DB.OnConnection`
+	ConnectionHooks []func(tx *DB) error
+}
+
+// Session create a session with the configuration, the receiver won't be changed, so it is safe to create sessions
+// from a shared DB concurrently
+//     tx := db.Session(&gorm.Session{DryRun: true})
+//     sql, vars := tx.Create(&user).DryRunSQL()
+func (s *DB) Session(config *Session) *DB {
+	tx := s.clone()
+	if config == nil {
+		return tx
+	}
+
+	if config.NewDB {
+		tx.search = nil
+		tx.Value = nil
+	}
+
+	if config.DryRun {
+		tx.values.Store("gorm:dry_run", true)
+	}
+
+	if config.Logger != nil {
+		tx.logger = config.Logger
+	}
+
+	if config.LogMode != nil {
+		tx.LogMode(*config.LogMode)
+	}
+
+	if config.InterpolateLogSQL {
+		tx.values.Store("gorm:interpolate_log_sql", true)
+	}
+
+	if config.NowFunc != nil {
+		tx.nowFuncOverride = config.NowFunc
+	}
+
+	if config.TimeZone != nil {
+		tx.values.Store("gorm:time_zone", config.TimeZone)
+	}
+
+	if config.Timeout > 0 {
+		tx.values.Store("gorm:timeout", config.Timeout)
+	}
+
+	if config.SlowThreshold > 0 {
+		tx.values.Store("gorm:slow_threshold", config.SlowThreshold)
+	}
+
+	if config.SkipHooks {
+		tx.values.Store("gorm:skip_hooks", true)
+	}
+
+	if config.SkipValidation {
+		tx.values.Store("gorm:skip_validation", true)
+	}
+
+	if config.SkipTenancy {
+		tx.values.Store("gorm:skip_tenancy", true)
+	}
+
+	if config.FullSaveAssociations {
+		tx.values.Store("gorm:full_save_associations", true)
+	}
+
+	if config.QueryFields {
+		tx.values.Store("gorm:query_fields", true)
+	}
+
+	if config.StrictScan {
+		tx.values.Store("gorm:strict_scan", true)
+	}
+
+	if len(config.ConnectionHooks) > 0 {
+		tx.values.Store("gorm:connection_hooks", tx.appendConnectionHooks(config.ConnectionHooks))
+	}
+
+	if config.TrackChanges {
+		tx.values.Store("gorm:change_tracker", &changeTracker{records: map[interface{}]map[string]interface{}{}})
+	}
+	return tx
+}
+
+// SkipHooks skip model hooks like `BeforeSave`, `AfterCreate` and `AfterFind`, including hooks of associations,
+// useful for backfills and imports
+//     db.SkipHooks().Create(&user)
+func (s *DB) SkipHooks() *DB {
+	return s.Set("gorm:skip_hooks", true)
+}
+
+// QueryFields select columns of the model's fields instead of `*` for queries without `Select`
+//     db.QueryFields().Find(&users)
+//     // SELECT "users"."id", "users"."name", "users"."age" FROM "users"
+func (s *DB) QueryFields() *DB {
+	return s.Set("gorm:query_fields", true)
+}
+
+// DryRunSQL return the SQL and its vars built by the last operation in dry run mode
+func (s *DB) DryRunSQL() (string, []interface{}) {
+	if value, ok := s.values.Load("gorm:dry_run_sql"); ok {
+		if statement, ok := value.(*dryRunStatement); ok {
+			return statement.sql, statement.vars
+		}
+	}
+	return "", nil
+}
+
+type dryRunStatement struct {
+	sql  string
+	vars []interface{}
+}
+
+func (scope *Scope) isDryRun() bool {
+	value, ok := scope.Get("gorm:dry_run")
+	return ok && value == true
+}
+
+// dryRun report whether the scope is in dry run mode, the SQL of the scope is saved for `DryRunSQL` if so
+func (scope *Scope) dryRun() bool {
+	if !scope.isDryRun() {
+		return false
+	}
+
+	scope.db.values.Store("gorm:dry_run_sql", &dryRunStatement{sql: scope.SQL, vars: scope.SQLVars})
+	return true
+}
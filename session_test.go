@@ -0,0 +1,141 @@
+package gorm_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zanmato/gorm"
+)
+
+func TestSessionDryRun(t *testing.T) {
+	tx := DB.Session(&gorm.Session{DryRun: true})
+
+	user := User{Name: "dry-run"}
+	sql, vars := tx.Create(&user).DryRunSQL()
+	if !strings.Contains(sql, "INSERT INTO") || len(vars) == 0 {
+		t.Errorf("Should build insert SQL, but got %v, %v", sql, vars)
+	}
+
+	if !DB.Where("name = ?", "dry-run").First(&User{}).RecordNotFound() {
+		t.Errorf("Shouldn't create record in dry run mode")
+	}
+
+	sql, vars = tx.Where("name = ?", "jinzhu").Find(&[]User{}).DryRunSQL()
+	if !strings.Contains(sql, "SELECT") || len(vars) != 1 || vars[0] != "jinzhu" {
+		t.Errorf("Should build query SQL, but got %v, %v", sql, vars)
+	}
+
+	sql, _ = tx.Model(&User{}).Where("name = ?", "jinzhu").Update("age", 1).DryRunSQL()
+	if !strings.Contains(sql, "UPDATE") {
+		t.Errorf("Should build update SQL, but got %v", sql)
+	}
+}
+
+func TestSession(t *testing.T) {
+	db := DB.Where("name = ?", "session")
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tx := db.Session(&gorm.Session{NewDB: true, NowFunc: func() time.Time { return now }})
+
+	user := User{Name: "session"}
+	if err := tx.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to save with session, got %v", err)
+	}
+
+	if !user.CreatedAt.Equal(now) {
+		t.Errorf("Session should use NowFunc, but got %v", user.CreatedAt)
+	}
+
+	DB.Save(&User{Name: "not-session"})
+
+	var count, total int
+	tx.Model(&User{}).Count(&count)
+	DB.Model(&User{}).Count(&total)
+	if count != total || count < 2 {
+		t.Errorf("NewDB session shouldn't have conditions of the original DB, but got %v of %v", count, total)
+	}
+
+	// sessions are created concurrently with callbacks of their own, as other tests register callbacks to DB
+	concurrentDB, err := gorm.Open(DB.Dialect().GetName(), DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open db, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(dryRun bool) {
+			defer wg.Done()
+			concurrentDB.Session(&gorm.Session{DryRun: dryRun}).First(&User{})
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	var users []User
+	if sql, _ := concurrentDB.Find(&users).DryRunSQL(); sql != "" || len(users) == 0 {
+		t.Errorf("Creating sessions shouldn't change the original DB")
+	}
+}
+
+func TestSessionStrictScan(t *testing.T) {
+	DB.Save(&User{Name: "strict_scan", Age: 20})
+
+	type UserName struct {
+		Name string
+		Age  int64
+	}
+
+	strict := DB.Session(&gorm.Session{StrictScan: true})
+
+	var name UserName
+	if err := strict.Model(&User{}).Select("name, age").Where("name = ?", "strict_scan").Scan(&name).Error; err != nil || name.Name != "strict_scan" {
+		t.Errorf("Should scan matched columns in strict mode, got %v, %v", name, err)
+	}
+
+	var names []UserName
+	err := strict.Model(&User{}).Select("name, age AS agee").Where("name = ?", "strict_scan").Scan(&names).Error
+	scanErr, ok := err.(*gorm.StrictScanError)
+	if !ok {
+		t.Fatalf("Should return strict scan error, got %v", err)
+	}
+
+	if len(scanErr.UnmatchedColumns) != 1 || scanErr.UnmatchedColumns[0] != "agee" {
+		t.Errorf("Should report unmatched columns, got %v", scanErr.UnmatchedColumns)
+	}
+
+	if len(scanErr.UnmatchedFields) != 1 || scanErr.UnmatchedFields[0] != "Age" {
+		t.Errorf("Should report unmatched fields, got %v", scanErr.UnmatchedFields)
+	}
+
+	if err := DB.Model(&User{}).Select("name, age AS agee").Where("name = ?", "strict_scan").Scan(&names).Error; err != nil {
+		t.Errorf("Shouldn't check columns without strict mode, got %v", err)
+	}
+}
+
+func TestSessionQueryFields(t *testing.T) {
+	tx := DB.Session(&gorm.Session{DryRun: true, QueryFields: true})
+
+	sql, _ := tx.Find(&[]User{}).DryRunSQL()
+	if strings.Contains(sql, "*") || !strings.Contains(sql, DB.Dialect().Quote("users")+"."+DB.Dialect().Quote("name")) {
+		t.Errorf("Should select columns of fields, but got %v", sql)
+	}
+
+	if strings.Contains(sql, DB.Dialect().Quote("ignore_me")) {
+		t.Errorf("Shouldn't select ignored fields, but got %v", sql)
+	}
+
+	sql, _ = tx.Select("name").Find(&[]User{}).DryRunSQL()
+	if strings.Contains(sql, DB.Dialect().Quote("age")) {
+		t.Errorf("Should select selected columns only, but got %v", sql)
+	}
+
+	user := User{Name: "query_fields", Age: 18}
+	DB.Save(&user)
+
+	var result User
+	if err := DB.QueryFields().Joins("Company").First(&result, user.Id).Error; err != nil || result.Name != "query_fields" || result.Age != 18 {
+		t.Errorf("Should find user with query fields, got %#v, %v", result, err)
+	}
+}
@@ -0,0 +1,45 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Union combine results of the query and given queries with UNION, the result could be further ordered, limited or used as sub query
+//     db.Table("users").Select("name").Union(db.Table("admins").Select("name")).Order("name").Limit(10).Find(&results)
+//     db.Where("name IN ?", db.Table("users").Select("name").Union(db.Table("admins").Select("name")).SubQuery())
+func (s *DB) Union(queries ...*DB) *DB {
+	return s.setOperation("UNION", queries...)
+}
+
+// UnionAll combine results of the query and given queries with UNION ALL, refer `Union`
+func (s *DB) UnionAll(queries ...*DB) *DB {
+	return s.setOperation("UNION ALL", queries...)
+}
+
+// Intersect return results exist in both the query and given queries, refer `Union`
+func (s *DB) Intersect(queries ...*DB) *DB {
+	return s.setOperation("INTERSECT", queries...)
+}
+
+// Except return results of the query that don't exist in given queries, refer `Union`
+func (s *DB) Except(queries ...*DB) *DB {
+	return s.setOperation("EXCEPT", queries...)
+}
+
+// setOperation build a raw query combining queries with the set operator, every query is wrapped as a sub query,
+// so they could have their own order and limit
+func (s *DB) setOperation(operator string, queries ...*DB) *DB {
+	var (
+		sqls []string
+		args []interface{}
+	)
+
+	for idx, query := range append([]*DB{s}, queries...) {
+		expr := query.QueryExpr()
+		sqls = append(sqls, fmt.Sprintf("SELECT * FROM (%v) AS %v", expr.expr, s.Dialect().Quote(fmt.Sprintf("q%d", idx))))
+		args = append(args, expr.args...)
+	}
+
+	return s.New().Raw(strings.Join(sqls, " "+operator+" "), args...)
+}
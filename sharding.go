@@ -0,0 +1,208 @@
+package gorm
+
+import (
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// shardingKeyRegexps caches regexps matching conditions of sharding keys, by the key
+var shardingKeyRegexps sync.Map
+
+// ShardingAlgorithm return the table suffix for the sharding key's value
+type ShardingAlgorithm func(value interface{}) (suffix string, err error)
+
+// Sharding defines how a table is sharded
+type Sharding struct {
+	// Key is the column used to decide the shard, e.g. `user_id`
+	Key string
+	// Algorithm return the table suffix of the value of sharding key, e.g. `HashSharding(8)`
+	Algorithm ShardingAlgorithm
+}
+
+// RegisterSharding shard tables with the sharding, table names of create, query, update and delete operations will be
+// rewritten with the suffix returned by the algorithm, the sharding key's value is extracted from the model value or
+// `Where` conditions like `user_id = ?` or `map[string]interface{}{"user_id": 1}`
+//     db.RegisterSharding(gorm.Sharding{Key: "user_id", Algorithm: gorm.HashSharding(8)}, "orders")
+//     db.Where("user_id = ?", 7).Find(&orders)
+//     // SELECT * FROM orders_07 WHERE user_id = 7
+func (s *DB) RegisterSharding(sharding Sharding, tables ...string) *DB {
+	shardings := map[string]Sharding{}
+	if value, ok := s.Get("gorm:sharding"); ok {
+		for table, sharding := range value.(map[string]Sharding) {
+			shardings[table] = sharding
+		}
+	}
+
+	for _, table := range tables {
+		shardings[table] = sharding
+	}
+	s.InstantSet("gorm:sharding", shardings)
+	return s
+}
+
+// HashSharding distribute records to shards by the hash of the sharding key, integers are distributed by modulo,
+// suffixes are like `_00`, `_01`
+func HashSharding(shards int) ShardingAlgorithm {
+	width := len(strconv.Itoa(shards - 1))
+	if width < 2 {
+		width = 2
+	}
+
+	return func(value interface{}) (string, error) {
+		if shards <= 0 {
+			return "", fmt.Errorf("invalid number of shards %v", shards)
+		}
+
+		var index uint64
+		if number, ok := shardingNumber(value); ok {
+			index = uint64(number) % uint64(shards)
+		} else {
+			index = uint64(crc32.ChecksumIEEE([]byte(fmt.Sprint(value)))) % uint64(shards)
+		}
+		return fmt.Sprintf("_%0*d", width, index), nil
+	}
+}
+
+// RangeSharding distribute records to shards by ranges of the sharding key, e.g. with size 1000,
+// key 0-999 are in shard `_0`, key 1000-1999 are in shard `_1`
+func RangeSharding(size int64) ShardingAlgorithm {
+	return func(value interface{}) (string, error) {
+		number, ok := shardingNumber(value)
+		if !ok || size <= 0 {
+			return "", fmt.Errorf("can't shard %v with range %v", value, size)
+		}
+		return fmt.Sprintf("_%d", number/size), nil
+	}
+}
+
+func shardingNumber(value interface{}) (int64, bool) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number := reflectValue.Int(); number >= 0 {
+			return number, true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(reflectValue.Uint()), true
+	}
+	return 0, false
+}
+
+// shardingCallback rewrite table name of sharded tables
+func shardingCallback(scope *Scope) {
+	value, ok := scope.Get("gorm:sharding")
+	if !ok || scope.HasError() || scope.Search.tableName != "" || scope.Search.raw {
+		return
+	}
+
+	tableName := scope.unqualifiedTableName()
+	sharding, ok := value.(map[string]Sharding)[tableName]
+	if !ok {
+		return
+	}
+
+	keys, ok := scope.shardingKeyValues(sharding.Key)
+	if !ok {
+		scope.Err(fmt.Errorf("%v: %v of table %v", ErrMissingShardingKey, sharding.Key, tableName))
+		return
+	}
+
+	// values of `IN` conditions, like conditions of preloading, should be in the same shard
+	var suffix string
+	for idx, key := range keys {
+		keySuffix, err := sharding.Algorithm(key)
+		if scope.Err(err) != nil {
+			return
+		}
+
+		if idx > 0 && keySuffix != suffix {
+			scope.Err(fmt.Errorf("%v of table %v are in different shards", sharding.Key, tableName))
+			return
+		}
+		suffix = keySuffix
+	}
+	scope.Search.Table(tableName + suffix)
+}
+
+// shardingKeyRegexp return the regexp matching conditions of the sharding key, like `user_id = ?` or
+// `user_id IN (?,?)`, marks of `IN` are captured
+func shardingKeyRegexp(key string) *regexp.Regexp {
+	if keyRegexp, ok := shardingKeyRegexps.Load(key); ok {
+		return keyRegexp.(*regexp.Regexp)
+	}
+
+	keyRegexp := regexp.MustCompile(fmt.Sprintf("(?i)(?:^|[^\\w])[\"`]?%v[\"`]?\\s*(?:=\\s*\\?|IN\\s*\\(((?:\\s*\\?\\s*,)*\\s*\\?\\s*)\\))", regexp.QuoteMeta(key)))
+	shardingKeyRegexps.Store(key, keyRegexp)
+	return keyRegexp
+}
+
+// shardingKeyValues find sharding key's values from the model value or where conditions
+func (scope *Scope) shardingKeyValues(key string) ([]interface{}, bool) {
+	if scope.IndirectValue().Kind() == reflect.Struct {
+		if field, ok := scope.FieldByName(key); ok && !field.IsBlank {
+			return []interface{}{field.Field.Interface()}, true
+		}
+	}
+
+	keyRegexp := shardingKeyRegexp(key)
+	for _, condition := range scope.Search.whereConditions {
+		args, _ := condition["args"].([]interface{})
+		switch query := condition["query"].(type) {
+		case string:
+			if loc := keyRegexp.FindStringSubmatchIndex(query); loc != nil {
+				var index int
+				for _, char := range query[:loc[0]] {
+					if char == '?' {
+						index++
+					}
+				}
+
+				marks := 1
+				if loc[2] >= 0 {
+					marks = strings.Count(query[loc[2]:loc[3]], "?")
+				}
+				if index+marks > len(args) {
+					continue
+				}
+
+				var values []interface{}
+				for _, arg := range args[index : index+marks] {
+					values = append(values, shardingKeyElements(arg)...)
+				}
+				if len(values) > 0 {
+					return values, true
+				}
+			}
+		case map[string]interface{}:
+			if value, ok := query[key]; ok {
+				return shardingKeyElements(value), true
+			}
+		default:
+			if reflect.Indirect(reflect.ValueOf(query)).Kind() == reflect.Struct {
+				if field, ok := scope.New(query).FieldByName(key); ok && !field.IsBlank {
+					return []interface{}{field.Field.Interface()}, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// shardingKeyElements return elements of slices, e.g. arguments of `user_id IN (?)`
+func shardingKeyElements(value interface{}) []interface{} {
+	if _, ok := value.([]byte); !ok {
+		if reflectValue := reflect.ValueOf(value); reflectValue.Kind() == reflect.Slice {
+			elements := make([]interface{}, reflectValue.Len())
+			for i := range elements {
+				elements[i] = reflectValue.Index(i).Interface()
+			}
+			return elements
+		}
+	}
+	return []interface{}{value}
+}
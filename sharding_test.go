@@ -0,0 +1,113 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type ShardedOrder struct {
+	ID     uint
+	UserID uint
+	Amount int
+}
+
+type ShardedUser struct {
+	ID            uint
+	ShardedOrders []ShardedOrder `gorm:"foreignkey:UserID"`
+}
+
+func TestSharding(t *testing.T) {
+	db := DB.New().RegisterSharding(gorm.Sharding{Key: "user_id", Algorithm: gorm.HashSharding(2)}, "sharded_orders")
+
+	for _, table := range []string{"sharded_orders_00", "sharded_orders_01"} {
+		DB.Table(table).DropTableIfExists(&ShardedOrder{})
+		if err := DB.Set("gorm:table_options", "").Table(table).AutoMigrate(&ShardedOrder{}).Error; err != nil {
+			t.Fatalf("Failed to migrate %v, got %v", table, err)
+		}
+	}
+
+	for _, order := range []ShardedOrder{{UserID: 1, Amount: 10}, {UserID: 2, Amount: 20}, {UserID: 3, Amount: 30}} {
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("Failed to create sharded order, got %v", err)
+		}
+	}
+
+	var count int
+	DB.Table("sharded_orders_01").Count(&count)
+	if count != 2 {
+		t.Errorf("Orders of user 1 and 3 should be created in shard 01, but got %v", count)
+	}
+
+	var orders []ShardedOrder
+	db.Where("user_id = ?", 2).Find(&orders)
+	if len(orders) != 1 || orders[0].Amount != 20 {
+		t.Errorf("Should find orders from shard 00, but got %#v", orders)
+	}
+
+	orders = nil
+	db.Where(map[string]interface{}{"user_id": 3}).Find(&orders)
+	if len(orders) != 1 || orders[0].Amount != 30 {
+		t.Errorf("Should find orders with map conditions, but got %#v", orders)
+	}
+
+	db.Model(&ShardedOrder{}).Where("amount > ? AND user_id = ?", 5, 1).Count(&count)
+	if count != 1 {
+		t.Errorf("Should count orders of user 1 in shard 01, but got %v", count)
+	}
+
+	db.Model(&ShardedOrder{}).Where("user_id = ?", 1).Update("amount", 11)
+	var order ShardedOrder
+	db.Where("user_id = ?", 1).First(&order)
+	if order.Amount != 11 {
+		t.Errorf("Should update order in shard, but got %v", order.Amount)
+	}
+
+	db.Delete(&order)
+	if !db.Where("user_id = ?", 1).First(&ShardedOrder{}).RecordNotFound() {
+		t.Errorf("Should delete order in shard")
+	}
+
+	if err := db.Find(&orders).Error; err == nil {
+		t.Errorf("Should return error without sharding key")
+	}
+
+	db.Create(&ShardedOrder{UserID: 5, Amount: 50})
+	orders = nil
+	db.Where("user_id IN (?)", []uint{3, 5}).Order("amount").Find(&orders)
+	if len(orders) != 2 || orders[0].Amount != 30 || orders[1].Amount != 50 {
+		t.Errorf("Should find orders of users in the same shard, but got %#v", orders)
+	}
+
+	if err := db.Where("user_id IN (?)", []uint{2, 3}).Find(&orders).Error; err == nil {
+		t.Errorf("Should return error when users are in different shards")
+	}
+
+	DB.DropTableIfExists(&ShardedUser{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&ShardedUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate sharded users, got %v", err)
+	}
+	DB.Create(&ShardedUser{ID: 3})
+	DB.Create(&ShardedUser{ID: 5})
+
+	var users []ShardedUser
+	if err := db.Preload("ShardedOrders").Order("id").Find(&users).Error; err != nil {
+		t.Fatalf("Should preload orders of users in the same shard, got %v", err)
+	}
+	if len(users) != 2 || len(users[0].ShardedOrders) != 1 || users[1].ShardedOrders[0].Amount != 50 {
+		t.Errorf("Should preload orders from shard 01, but got %#v", users)
+	}
+}
+
+func TestRangeSharding(t *testing.T) {
+	algorithm := gorm.RangeSharding(1000)
+	for value, expected := range map[int]string{0: "_0", 999: "_0", 1000: "_1", 25000: "_25"} {
+		if suffix, err := algorithm(value); err != nil || suffix != expected {
+			t.Errorf("Suffix of %v should be %v, but got %v, %v", value, expected, suffix, err)
+		}
+	}
+
+	if _, err := algorithm("string"); err == nil {
+		t.Errorf("Should return error for non integer keys")
+	}
+}
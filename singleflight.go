@@ -0,0 +1,194 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Singleflight collapse identical queries running concurrently into one, queries with the same SQL, vars and
+// destination type wait for the running one and get their own deep copies of its results, including preloaded
+// associations, which protects hot keys from stampedes when their caches expire. Waiting queries stop waiting when
+// their contexts are done or their timeouts exceed
+//     db.Singleflight().Where("slug = ?", slug).First(&article)
+// queries in transactions aren't collapsed as they may see different data
+func (s *DB) Singleflight() *DB {
+	return s.Set("gorm:singleflight", true)
+}
+
+// queryCall is a running query which identical queries wait for
+type queryCall struct {
+	done         chan struct{}
+	result       reflect.Value
+	rowsAffected int64
+	err          error
+}
+
+var (
+	queryCallsMutex sync.Mutex
+	queryCalls      = map[string]*queryCall{}
+)
+
+// singleflightCallback wait for the running identical query and copy its results, or mark the query as the one
+// others wait for
+func singleflightCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:singleflight"); !ok || scope.HasError() || scope.isDryRun() {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	if _, inTransaction := scope.db.db.(sqlTx); inTransaction {
+		return
+	}
+
+	queryScope := &Scope{db: scope.db, Search: scope.Search.clone(), Value: scope.Value}
+	queryScope.prepareQuerySQL()
+	if queryScope.HasError() {
+		return
+	}
+	key := fmt.Sprintf("%p|%v", scope.db.db, queryScope.querySignature(scope.queryDestination()))
+
+	queryCallsMutex.Lock()
+	if call, ok := queryCalls[key]; ok {
+		queryCallsMutex.Unlock()
+		if scope.Err(scope.waitQueryCall(call)) != nil {
+			return
+		}
+
+		copyQueryResult(indirect(reflect.ValueOf(scope.queryDestination())), call.result)
+		scope.db.RowsAffected = call.rowsAffected
+		scope.Err(call.err)
+		scope.InstanceSet("gorm:skip_query_callback", true)
+		return
+	}
+
+	queryCalls[key] = &queryCall{done: make(chan struct{})}
+	queryCallsMutex.Unlock()
+	scope.InstanceSet("gorm:singleflight_key", key)
+}
+
+// waitQueryCall wait for the running query until the context set with `WithContext` is done or the timeout set with
+// `Timeout` exceeds
+func (scope *Scope) waitQueryCall(call *queryCall) error {
+	ctx := scope.db.Context()
+	if value, ok := scope.Get("gorm:timeout"); ok {
+		if timeout, _ := value.(time.Duration); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	select {
+	case <-call.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// finishSingleflightCallback share results of the query with identical queries waiting for it
+func finishSingleflightCallback(scope *Scope) {
+	scope.finishSingleflight(scope.db.Error)
+}
+
+func (scope *Scope) finishSingleflight(err error) {
+	key, ok := scope.InstanceGet("gorm:singleflight_key")
+	if !ok || key == nil {
+		return
+	}
+	scope.InstanceSet("gorm:singleflight_key", nil)
+
+	queryCallsMutex.Lock()
+	call, ok := queryCalls[fmt.Sprint(key)]
+	delete(queryCalls, fmt.Sprint(key))
+	queryCallsMutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// waiters are released even if copying results panics
+	defer close(call.done)
+	call.rowsAffected, call.err = scope.db.RowsAffected, err
+	if err == nil {
+		call.err = errors.New("failed to copy results of the query")
+		results := indirect(reflect.ValueOf(scope.queryDestination()))
+		call.result = reflect.New(results.Type()).Elem()
+		copyQueryResult(call.result, results)
+		call.err = nil
+	}
+}
+
+// copyQueryResult deep copy results of a query, so callers could change them and records they point to separately,
+// values of unexported fields are shared
+func copyQueryResult(dst, src reflect.Value) {
+	if src.IsValid() {
+		dst.Set(deepCopy(src, map[copiedPointer]reflect.Value{}))
+	}
+}
+
+// copiedPointer identify pointers copied by deepCopy, records pointed by multiple pointers are copied once
+type copiedPointer struct {
+	typ     reflect.Type
+	pointer uintptr
+}
+
+func deepCopy(src reflect.Value, copied map[copiedPointer]reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return src
+		}
+
+		key := copiedPointer{typ: src.Type(), pointer: src.Pointer()}
+		if result, ok := copied[key]; ok {
+			return result
+		}
+		result := reflect.New(src.Type().Elem())
+		copied[key] = result
+		result.Elem().Set(deepCopy(src.Elem(), copied))
+		return result
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+		result := reflect.New(src.Type()).Elem()
+		result.Set(deepCopy(src.Elem(), copied))
+		return result
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		result := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			result.Index(i).Set(deepCopy(src.Index(i), copied))
+		}
+		return result
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		result := reflect.MakeMapWithSize(src.Type(), src.Len())
+		for _, key := range src.MapKeys() {
+			result.SetMapIndex(key, deepCopy(src.MapIndex(key), copied))
+		}
+		return result
+	case reflect.Struct:
+		result := reflect.New(src.Type()).Elem()
+		result.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if field := result.Field(i); field.CanSet() {
+				field.Set(deepCopy(src.Field(i), copied))
+			}
+		}
+		return result
+	}
+	return src
+}
@@ -0,0 +1,145 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Soft delete strategies, a model's soft delete field is the field tagged with `soft_delete`, or named `DeletedAt`
+//     DeletedAt *time.Time                        // deleted_at IS NULL, set to current time when deleting
+//     IsDeleted bool  `gorm:"soft_delete:flag"`   // is_deleted = false, set to true when deleting
+//     IsDeleted int8  `gorm:"soft_delete:flag"`   // is_deleted = 0, set to 1 when deleting
+//     DeletedAt int64 `gorm:"soft_delete:milli"`  // deleted_at = 0, set to unix milliseconds when deleting
+//     DeletedAt int64 `gorm:"soft_delete:unix"`   // deleted_at = 0, set to unix seconds when deleting
+//     DeletedBy *uint `gorm:"deleted_by"`         // set to the value of setting `gorm:deleted_by` or `gorm:current_user` when deleting
+const (
+	softDeleteTime  = "TIME"
+	softDeleteFlag  = "FLAG"
+	softDeleteMilli = "MILLI"
+	softDeleteUnix  = "UNIX"
+)
+
+// softDeleteField return the field used to mark records as deleted and its strategy
+func (scope *Scope) softDeleteField() (*Field, string, bool) {
+	var deletedAtField *Field
+	for _, field := range scope.Fields() {
+		if field.IsIgnored || !field.IsNormal {
+			continue
+		}
+
+		if value, ok := field.TagSettingsGet("SOFT_DELETE"); ok {
+			switch strategy := strings.ToUpper(value); strategy {
+			case softDeleteTime, softDeleteFlag, softDeleteMilli, softDeleteUnix:
+				return field, strategy, true
+			default:
+				return field, defaultSoftDeleteStrategy(field), true
+			}
+		}
+
+		if field.Name == "DeletedAt" && deletedAtField == nil {
+			deletedAtField = field
+		}
+	}
+
+	if deletedAtField != nil {
+		return deletedAtField, defaultSoftDeleteStrategy(deletedAtField), true
+	}
+	return nil, "", false
+}
+
+func defaultSoftDeleteStrategy(field *Field) string {
+	fieldType := field.Struct.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if isTimeType(fieldType) {
+		return softDeleteTime
+	}
+	return softDeleteFlag
+}
+
+// isTimeType report whether the type stores times, like `time.Time`, or nullable times like `sql.NullTime`, which
+// are structs of the field `Time` and the field `Valid`
+func isTimeType(fieldType reflect.Type) bool {
+	timeType := reflect.TypeOf(time.Time{})
+	if fieldType.ConvertibleTo(timeType) {
+		return true
+	}
+
+	if fieldType.Kind() == reflect.Struct {
+		timeField, hasTime := fieldType.FieldByName("Time")
+		validField, hasValid := fieldType.FieldByName("Valid")
+		return hasTime && hasValid && timeField.Type == timeType && validField.Type.Kind() == reflect.Bool
+	}
+	return false
+}
+
+// softDeleteCondition return the condition used to exclude soft deleted records
+func (scope *Scope) softDeleteCondition() (string, bool) {
+	query, args, ok := scope.softDeleteQuery()
+	for _, arg := range args {
+		query = strings.Replace(query, "?", scope.AddToVars(arg), 1)
+	}
+	return query, ok
+}
+
+// softDeleteQuery return the query and its arguments used to exclude soft deleted records
+func (scope *Scope) softDeleteQuery() (string, []interface{}, bool) {
+	field, strategy, ok := scope.softDeleteField()
+	if !ok {
+		return "", nil, false
+	}
+
+	column := fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName))
+	if strategy == softDeleteTime || field.Struct.Type.Kind() == reflect.Ptr {
+		return fmt.Sprintf("%v IS NULL", column), nil, true
+	}
+	return fmt.Sprintf("%v = ?", column), []interface{}{reflect.Zero(field.Struct.Type).Interface()}, true
+}
+
+// softDeleteAssignments return the assignments used to mark records as deleted
+func (scope *Scope) softDeleteAssignments(field *Field, strategy string) string {
+	var (
+		now   = scope.db.nowFunc()
+		value interface{}
+	)
+
+	switch strategy {
+	case softDeleteTime:
+		value = now
+	case softDeleteMilli:
+		value = now.UnixNano() / int64(time.Millisecond)
+	case softDeleteUnix:
+		value = now.Unix()
+	default:
+		fieldType := field.Struct.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Bool:
+			value = true
+		default:
+			value = 1
+		}
+	}
+
+	assignments := []string{fmt.Sprintf("%v=%v", scope.Quote(field.DBName), scope.AddToVars(value))}
+
+	deletedBy, ok := scope.Get("gorm:deleted_by")
+	if !ok {
+		deletedBy, ok = scope.currentUser()
+	}
+
+	if ok {
+		for _, f := range scope.auditFields(auditDeletedBy) {
+			assignments = append(assignments, fmt.Sprintf("%v=%v", scope.Quote(f.DBName), scope.AddToVars(deletedBy)))
+		}
+	}
+
+	return strings.Join(assignments, ", ")
+}
@@ -0,0 +1,427 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Geometry is a spatial value which could be saved to geometry columns and used with spatial queries like `DWithin`
+type Geometry interface {
+	// WKT return the well-known text of the geometry
+	WKT() string
+	// SpatialReferenceID return the SRID of the geometry, 0 if unspecified
+	SpatialReferenceID() int
+}
+
+// Point is a spatial point, which will be migrated to geometry(Point) for postgres, POINT for mysql, geometry for
+// mssql and TEXT for others, the type could be changed with tags, e.g. `gorm:"type:geography(Point,4326)"`
+//     type Store struct {
+//       Location gorm.Point
+//     }
+//
+//     db.Create(&Store{Location: gorm.Point{X: 139.69, Y: 35.68, SRID: 4326}})
+type Point struct {
+	X, Y float64
+	SRID int
+}
+
+// WKT return the well-known text of the point
+func (p Point) WKT() string {
+	return "POINT(" + p.coordinates() + ")"
+}
+
+// SpatialReferenceID return the SRID of the point
+func (p Point) SpatialReferenceID() int {
+	return p.SRID
+}
+
+// Value return the extended well-known text of the point, implements driver.Valuer interface
+func (p Point) Value() (driver.Value, error) {
+	return extendedWKT(p), nil
+}
+
+// GormValue return the SQL expression building the point for the dialect
+func (p Point) GormValue(dialect Dialect) *SqlExpr {
+	return geometryExpr(dialect, p)
+}
+
+// Scan scan WKB, EWKB or WKT values into the point, implements sql.Scanner interface
+func (p *Point) Scan(value interface{}) error {
+	geometry, err := decodeGeometry(value)
+	if err != nil {
+		return err
+	}
+
+	point, ok := geometry.(Point)
+	if !ok {
+		return fmt.Errorf("failed to scan %v into Point", geometry.WKT())
+	}
+	*p = point
+	return nil
+}
+
+// GormDataType return the data type for the dialect
+func (Point) GormDataType(dialect Dialect) string {
+	return spatialDataType(dialect, "Point")
+}
+
+func (p Point) coordinates() string {
+	return strconv.FormatFloat(p.X, 'f', -1, 64) + " " + strconv.FormatFloat(p.Y, 'f', -1, 64)
+}
+
+// Polygon is a spatial polygon, the first ring is the exterior ring and others are holes, refer `Point` for its types
+//     gorm.Polygon{SRID: 4326, Rings: [][]gorm.Point{{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 0}}}}
+type Polygon struct {
+	Rings [][]Point
+	SRID  int
+}
+
+// WKT return the well-known text of the polygon
+func (p Polygon) WKT() string {
+	var rings []string
+	for _, ring := range p.Rings {
+		var points []string
+		for _, point := range ring {
+			points = append(points, point.coordinates())
+		}
+		rings = append(rings, "("+strings.Join(points, ",")+")")
+	}
+	return "POLYGON(" + strings.Join(rings, ",") + ")"
+}
+
+// SpatialReferenceID return the SRID of the polygon
+func (p Polygon) SpatialReferenceID() int {
+	return p.SRID
+}
+
+// Value return the extended well-known text of the polygon, implements driver.Valuer interface
+func (p Polygon) Value() (driver.Value, error) {
+	return extendedWKT(p), nil
+}
+
+// GormValue return the SQL expression building the polygon for the dialect
+func (p Polygon) GormValue(dialect Dialect) *SqlExpr {
+	return geometryExpr(dialect, p)
+}
+
+// Scan scan WKB, EWKB or WKT values into the polygon, implements sql.Scanner interface
+func (p *Polygon) Scan(value interface{}) error {
+	geometry, err := decodeGeometry(value)
+	if err != nil {
+		return err
+	}
+
+	polygon, ok := geometry.(Polygon)
+	if !ok {
+		return fmt.Errorf("failed to scan %v into Polygon", geometry.WKT())
+	}
+	*p = polygon
+	return nil
+}
+
+// GormDataType return the data type for the dialect
+func (Polygon) GormDataType(dialect Dialect) string {
+	return spatialDataType(dialect, "Polygon")
+}
+
+// DWithinExpression build conditions of geometries within the distance, could be used with `Where`, `Or` and `Not`
+type DWithinExpression struct {
+	column   string
+	geometry Geometry
+	distance float64
+}
+
+// DWithin return records whose geometry column is within the distance of the geometry, the distance is in units of
+// the spatial reference, e.g. meters for geography columns of postgres
+//     db.Where(gorm.DWithin("location", gorm.Point{X: 139.69, Y: 35.68, SRID: 4326}, 1000)).Find(&stores)
+//     // postgres: SELECT * FROM "stores" WHERE (ST_DWithin("location", ST_GeomFromText('POINT(139.69 35.68)', 4326), 1000))
+func DWithin(column string, geometry Geometry, distance float64) *DWithinExpression {
+	return &DWithinExpression{column: column, geometry: geometry, distance: distance}
+}
+
+func (expr *DWithinExpression) conditionSQL(scope *Scope) string {
+	column, geometry := scope.Quote(expr.column), scope.AddToVars(geometryExpr(scope.Dialect(), expr.geometry))
+
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroach":
+		return fmt.Sprintf("ST_DWithin(%v, %v, %v)", column, geometry, scope.AddToVars(expr.distance))
+	case "mysql":
+		return fmt.Sprintf("ST_Distance(%v, %v) <= %v", column, geometry, scope.AddToVars(expr.distance))
+	case "mssql":
+		return fmt.Sprintf("%v.STDistance(%v) <= %v", column, geometry, scope.AddToVars(expr.distance))
+	}
+
+	scope.Err(fmt.Errorf("spatial queries aren't supported by dialect %v", scope.Dialect().GetName()))
+	return ""
+}
+
+// DistanceExpression order records by the distance of a geometry column, could be used with `Order`
+type DistanceExpression struct {
+	column   string
+	geometry Geometry
+}
+
+// Distance order records by the distance from the geometry, nearest first, postgres uses the `<->` operator which
+// could be served by GiST indexes
+//     db.Order(gorm.Distance("location", gorm.Point{X: 139.69, Y: 35.68, SRID: 4326})).Limit(10).Find(&stores)
+func Distance(column string, geometry Geometry) *DistanceExpression {
+	return &DistanceExpression{column: column, geometry: geometry}
+}
+
+func (expr *DistanceExpression) orderSQL(scope *Scope) string {
+	column, geometry := scope.Quote(expr.column), scope.AddToVars(geometryExpr(scope.Dialect(), expr.geometry))
+
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroach":
+		return fmt.Sprintf("%v <-> %v", column, geometry)
+	case "mysql":
+		return fmt.Sprintf("ST_Distance(%v, %v)", column, geometry)
+	case "mssql":
+		return fmt.Sprintf("%v.STDistance(%v)", column, geometry)
+	}
+
+	scope.Err(fmt.Errorf("spatial queries aren't supported by dialect %v", scope.Dialect().GetName()))
+	return ""
+}
+
+func spatialDataType(dialect Dialect, kind string) string {
+	switch dialect.GetName() {
+	case "postgres", "cockroach":
+		return "geometry(" + kind + ")"
+	case "mysql":
+		return strings.ToUpper(kind)
+	case "mssql":
+		return "geometry"
+	default:
+		return "TEXT"
+	}
+}
+
+// geometryExpr build the geometry from its well-known text for the dialect, geometries are saved as extended
+// well-known text for dialects without spatial types. Coordinates of well-known text are in the x-y order, which is
+// longitude-latitude for geographic spatial references, mysql 8 reads them in the latitude-longitude order of
+// spatial references like 4326 by default, so the axis order is specified
+func geometryExpr(dialect Dialect, geometry Geometry) *SqlExpr {
+	switch dialect.GetName() {
+	case "mysql":
+		if mysqlDialect, ok := dialect.(*mysql); ok && mysqlDialect.supportsAxisOrder() {
+			return Expr("ST_GeomFromText(?, ?, 'axis-order=long-lat')", geometry.WKT(), geometry.SpatialReferenceID())
+		}
+		return Expr("ST_GeomFromText(?, ?)", geometry.WKT(), geometry.SpatialReferenceID())
+	case "postgres", "cockroach":
+		return Expr("ST_GeomFromText(?, ?)", geometry.WKT(), geometry.SpatialReferenceID())
+	case "mssql":
+		return Expr("geometry::STGeomFromText(?, ?)", geometry.WKT(), geometry.SpatialReferenceID())
+	default:
+		return Expr("?", extendedWKT(geometry))
+	}
+}
+
+func extendedWKT(geometry Geometry) string {
+	if srid := geometry.SpatialReferenceID(); srid != 0 {
+		return fmt.Sprintf("SRID=%d;%v", srid, geometry.WKT())
+	}
+	return geometry.WKT()
+}
+
+// decodeGeometry decode well-known text, hex encoded EWKB of postgres, WKB prefixed with the SRID of mysql and WKB
+func decodeGeometry(value interface{}) (Geometry, error) {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, fmt.Errorf("failed to scan geometry value %v", value)
+	}
+
+	text := strings.ToUpper(strings.TrimSpace(string(data)))
+	if strings.HasPrefix(text, "SRID=") || strings.HasPrefix(text, "POINT") || strings.HasPrefix(text, "POLYGON") {
+		return decodeWKT(string(data))
+	}
+
+	if decoded, err := hex.DecodeString(string(data)); err == nil {
+		data = decoded
+	}
+
+	if geometry, err := decodeWKB(data, 0); err == nil {
+		return geometry, nil
+	}
+
+	if len(data) > 4 {
+		return decodeWKB(data[4:], int(binary.LittleEndian.Uint32(data)))
+	}
+	return nil, errors.New("failed to decode geometry value")
+}
+
+const (
+	wkbPoint   = 1
+	wkbPolygon = 3
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+type wkbReader struct {
+	data   []byte
+	order  binary.ByteOrder
+	offset int
+	err    error
+}
+
+func (reader *wkbReader) uint32() uint32 {
+	if reader.err != nil || reader.offset+4 > len(reader.data) {
+		reader.err = errors.New("invalid WKB value, unexpected end of data")
+		return 0
+	}
+	value := reader.order.Uint32(reader.data[reader.offset:])
+	reader.offset += 4
+	return value
+}
+
+func (reader *wkbReader) point(extraDimensions int) (point Point) {
+	for i, value := range []*float64{&point.X, &point.Y} {
+		if reader.err != nil || reader.offset+8*(2-i+extraDimensions) > len(reader.data) {
+			reader.err = errors.New("invalid WKB value, unexpected end of data")
+			return
+		}
+		*value = math.Float64frombits(reader.order.Uint64(reader.data[reader.offset:]))
+		reader.offset += 8
+	}
+	reader.offset += 8 * extraDimensions
+	return
+}
+
+func decodeWKB(data []byte, srid int) (Geometry, error) {
+	if len(data) < 5 || data[0] > 1 {
+		return nil, errors.New("invalid WKB value")
+	}
+
+	reader := &wkbReader{data: data, order: binary.BigEndian, offset: 1}
+	if data[0] == 1 {
+		reader.order = binary.LittleEndian
+	}
+
+	var (
+		kind            = reader.uint32()
+		extraDimensions int
+	)
+	if kind&ewkbSRID != 0 {
+		srid = int(reader.uint32())
+	}
+	if kind&ewkbZ != 0 {
+		extraDimensions++
+	}
+	if kind&ewkbM != 0 {
+		extraDimensions++
+	}
+
+	// ISO WKB types of 1000, 2000 and 3000 are types with Z, M and ZM dimensions
+	kind &^= ewkbZ | ewkbM | ewkbSRID
+	extraDimensions += []int{0, 1, 1, 2}[kind/1000%4]
+	kind %= 1000
+
+	var geometry Geometry
+	switch kind {
+	case wkbPoint:
+		point := reader.point(extraDimensions)
+		point.SRID = srid
+		geometry = point
+	case wkbPolygon:
+		polygon := Polygon{SRID: srid}
+		for rings := reader.uint32(); rings > 0 && reader.err == nil; rings-- {
+			var ring []Point
+			for points := reader.uint32(); points > 0 && reader.err == nil; points-- {
+				ring = append(ring, reader.point(extraDimensions))
+			}
+			polygon.Rings = append(polygon.Rings, ring)
+		}
+		geometry = polygon
+	default:
+		return nil, fmt.Errorf("unsupported WKB geometry type %v", kind)
+	}
+
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	if reader.offset != len(data) {
+		return nil, errors.New("invalid WKB value, unexpected trailing data")
+	}
+	return geometry, nil
+}
+
+func decodeWKT(text string) (Geometry, error) {
+	var srid int
+	if strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		idx := strings.Index(text, ";")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid WKT value %v", text)
+		}
+
+		var err error
+		if srid, err = strconv.Atoi(text[len("SRID="):idx]); err != nil {
+			return nil, fmt.Errorf("invalid SRID of WKT value %v", text)
+		}
+		text = text[idx+1:]
+	}
+
+	start, end := strings.Index(text, "("), strings.LastIndex(text, ")")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid WKT value %v", text)
+	}
+
+	body := text[start+1 : end]
+	switch kind := strings.ToUpper(strings.TrimSpace(text[:start])); kind {
+	case "POINT":
+		points, err := decodeWKTPoints(body)
+		if err != nil || len(points) != 1 {
+			return nil, fmt.Errorf("invalid WKT value %v", text)
+		}
+		points[0].SRID = srid
+		return points[0], nil
+	case "POLYGON":
+		polygon := Polygon{SRID: srid}
+		for _, ring := range strings.Split(body, ")") {
+			if ring = strings.Trim(ring, " ,("); ring == "" {
+				continue
+			}
+
+			points, err := decodeWKTPoints(ring)
+			if err != nil {
+				return nil, fmt.Errorf("invalid WKT value %v", text)
+			}
+			polygon.Rings = append(polygon.Rings, points)
+		}
+		return polygon, nil
+	default:
+		return nil, fmt.Errorf("unsupported WKT geometry type %v", kind)
+	}
+}
+
+func decodeWKTPoints(str string) (points []Point, err error) {
+	for _, pair := range strings.Split(str, ",") {
+		coordinates := strings.Fields(pair)
+		if len(coordinates) < 2 {
+			return nil, fmt.Errorf("invalid WKT coordinates %v", pair)
+		}
+
+		var point Point
+		if point.X, err = strconv.ParseFloat(coordinates[0], 64); err != nil {
+			return nil, err
+		}
+		if point.Y, err = strconv.ParseFloat(coordinates[1], 64); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package gorm_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type SpatialStore struct {
+	ID       uint
+	Name     string
+	Location gorm.Point
+	Area     *gorm.Polygon
+}
+
+func TestSpatialTypes(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&SpatialStore{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&SpatialStore{}).Error; err != nil {
+		t.Fatalf("Failed to migrate spatial columns, got %v", err)
+	}
+
+	area := gorm.Polygon{SRID: 4326, Rings: [][]gorm.Point{
+		{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 0}},
+		{{X: 0.1, Y: 0.1}, {X: 0.1, Y: 0.2}, {X: 0.2, Y: 0.2}, {X: 0.1, Y: 0.1}},
+	}}
+	store := SpatialStore{Name: "tokyo", Location: gorm.Point{X: 139.6917, Y: 35.6895, SRID: 4326}, Area: &area}
+	if err := DB.Save(&store).Error; err != nil {
+		t.Fatalf("Failed to save spatial values, got %v", err)
+	}
+
+	if err := DB.Save(&SpatialStore{Name: "no_area", Location: gorm.Point{X: 1, Y: 2}}).Error; err != nil {
+		t.Fatalf("Failed to save null spatial values, got %v", err)
+	}
+
+	var result SpatialStore
+	if err := DB.First(&result, store.ID).Error; err != nil {
+		t.Fatalf("Failed to find spatial values, got %v", err)
+	}
+
+	if result.Location != store.Location {
+		t.Errorf("Should scan point, but got %+v", result.Location)
+	}
+
+	if result.Area == nil || result.Area.WKT() != area.WKT() || result.Area.SRID != 4326 {
+		t.Errorf("Should scan polygon, but got %+v", result.Area)
+	}
+
+	var noArea SpatialStore
+	if DB.Where("name = ?", "no_area").First(&noArea); noArea.Area != nil || noArea.Location.X != 1 {
+		t.Errorf("Should scan null polygon, but got %+v", noArea)
+	}
+
+	if area.WKT() != "POLYGON((0 0,0 1,1 1,0 0),(0.1 0.1,0.1 0.2,0.2 0.2,0.1 0.1))" {
+		t.Errorf("Wrong WKT of polygon, got %v", area.WKT())
+	}
+}
+
+func TestScanSpatialValues(t *testing.T) {
+	var point gorm.Point
+	// EWKB of postgres, SRID=4326;POINT(1 2)
+	if err := point.Scan("0101000020E6100000000000000000F03F0000000000000040"); err != nil || point != (gorm.Point{X: 1, Y: 2, SRID: 4326}) {
+		t.Errorf("Should scan EWKB, but got %+v, %v", point, err)
+	}
+
+	// WKB prefixed with SRID of mysql
+	data, _ := hex.DecodeString("E61000000101000000000000000000084000000000000010C0")
+	if err := point.Scan(data); err != nil || point != (gorm.Point{X: 3, Y: -4, SRID: 4326}) {
+		t.Errorf("Should scan mysql geometry, but got %+v, %v", point, err)
+	}
+
+	// WKB with Z dimension
+	data, _ = hex.DecodeString("0101000080000000000000F03F00000000000000400000000000000840")
+	if err := point.Scan(data); err != nil || point != (gorm.Point{X: 1, Y: 2}) {
+		t.Errorf("Should scan WKB with Z dimension, but got %+v, %v", point, err)
+	}
+
+	var polygon gorm.Polygon
+	if err := polygon.Scan("POLYGON ((0 0, 0 1, 1 1, 0 0))"); err != nil || polygon.WKT() != "POLYGON((0 0,0 1,1 1,0 0))" {
+		t.Errorf("Should scan WKT polygon, but got %+v, %v", polygon, err)
+	}
+
+	if err := polygon.Scan("SRID=4326;POINT(1 2)"); err == nil {
+		t.Errorf("Should fail to scan point into polygon")
+	}
+}
+
+func TestSpatialQueries(t *testing.T) {
+	point := gorm.Point{X: 139.6917, Y: 35.6895, SRID: 4326}
+	tx := DB.Session(&gorm.Session{DryRun: true})
+	sql, vars := tx.Where(gorm.DWithin("location", point, 1000)).Order(gorm.Distance("location", point)).Find(&[]SpatialStore{}).DryRunSQL()
+
+	switch DB.Dialect().GetName() {
+	case "postgres":
+		if !strings.Contains(sql, `ST_DWithin("location", ST_GeomFromText($1, $2), $3)`) || !strings.Contains(sql, `ORDER BY "location" <-> ST_GeomFromText($4, $5)`) {
+			t.Errorf("Wrong spatial query, got %v", sql)
+		}
+
+		if len(vars) != 5 || vars[0] != "POINT(139.6917 35.6895)" || vars[1] != 4326 {
+			t.Errorf("Wrong vars of spatial query, got %v", vars)
+		}
+	case "mysql":
+		// mysql 8 reads well-known text of geographic spatial references in the latitude-longitude order by default
+		if !strings.Contains(sql, "ST_Distance(`location`, ST_GeomFromText(?, ?)) <= ?") && !strings.Contains(sql, "ST_Distance(`location`, ST_GeomFromText(?, ?, 'axis-order=long-lat')) <= ?") {
+			t.Errorf("Wrong spatial query, got %v", sql)
+		}
+	case "sqlite3":
+		if err := DB.Where(gorm.DWithin("location", point, 1000)).Find(&[]SpatialStore{}).Error; err == nil {
+			t.Errorf("Should fail to query spatial values with sqlite")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SQLCommenter tag statements with comments of the sqlcommenter format, so statements in slow query logs could be
+// attributed to the application and code paths, tags are pulled from the context set with `WithContext`
+//     db = db.WithSQLCommenter(&gorm.SQLCommenter{
+//       Application: "billing",
+//       Tags: func(ctx context.Context) map[string]string {
+//         return map[string]string{"route": routeOf(ctx), "traceparent": traceParentOf(ctx)}
+//       },
+//     })
+//
+//     db.WithContext(ctx).Find(&invoices)
+//     // SELECT * FROM "invoices" /*application='billing',route='%2Finvoices',traceparent='00-4bf9...-01'*/
+type SQLCommenter struct {
+	// Application is the name of the application, tagged as `application`
+	Application string
+	// Tags return tags of statements executed with the context, blank tags are skipped
+	Tags func(ctx context.Context) map[string]string
+}
+
+// WithSQLCommenter tag statements executed by the returned DB with comments of the commenter
+func (s *DB) WithSQLCommenter(commenter *SQLCommenter) *DB {
+	return s.Set("gorm:sql_commenter", commenter)
+}
+
+// Comment return the comment of tags in the sqlcommenter format, keys are sorted and values are URL encoded
+func (commenter *SQLCommenter) Comment(ctx context.Context) string {
+	tags := map[string]string{}
+	if commenter.Tags != nil {
+		for key, value := range commenter.Tags(ctx) {
+			tags[key] = value
+		}
+	}
+
+	if commenter.Application != "" {
+		tags["application"] = commenter.Application
+	}
+
+	var pairs []string
+	for key, value := range tags {
+		if value != "" {
+			pairs = append(pairs, sqlCommentEscape(key)+"='"+sqlCommentEscape(value)+"'")
+		}
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	sort.Strings(pairs)
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+func sqlCommentEscape(str string) string {
+	return strings.Replace(url.QueryEscape(str), "+", "%20", -1)
+}
+
+// commentSQL append the comment of the SQLCommenter set with `WithSQLCommenter` to the statement, statements are
+// commented here only, when they are executed, prepared or logged, so logged statements are the same as executed
+// ones. The comment is built once for each scope
+func (scope *Scope) commentSQL(sql string) string {
+	if scope.sqlComment == nil {
+		var comment string
+		if value, ok := scope.Get("gorm:sql_commenter"); ok {
+			if commenter, ok := value.(*SQLCommenter); ok && commenter != nil {
+				comment = commenter.Comment(scope.db.Context())
+			}
+		}
+		scope.sqlComment = &comment
+	}
+
+	if *scope.sqlComment == "" {
+		return sql
+	}
+	return sql + " " + *scope.sqlComment
+}
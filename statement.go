@@ -0,0 +1,176 @@
+package gorm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Statement describe the operation running hooks, it could be got with `Scope.Statement` or `DB.Statement` in hooks
+//     func (user *User) BeforeUpdate(tx *gorm.DB) error {
+//       stmt := tx.Statement()
+//       if stmt.Changed("Password") {
+//         return stmt.SetColumn("PasswordChangedAt", time.Now())
+//       }
+//       return nil
+//     }
+type Statement struct {
+	scope  *Scope
+	record reflect.Value
+}
+
+// Statement return the statement of the scope
+func (scope *Scope) Statement() *Statement {
+	return &Statement{scope: scope, record: scope.IndirectValue()}
+}
+
+// Statement return the statement of the operation running hooks, it is nil if the db is not created for hooks
+func (s *DB) Statement() *Statement {
+	if value, ok := s.values.Load("gorm:statement"); ok {
+		if statement, ok := value.(*Statement); ok {
+			return statement
+		}
+	}
+	return nil
+}
+
+// Model return the record running hooks, or the destination of the operation out of hooks
+func (statement *Statement) Model() interface{} {
+	if statement.record.IsValid() && statement.record.CanAddr() {
+		return statement.record.Addr().Interface()
+	}
+	return statement.scope.Value
+}
+
+// Table return the quoted table name of the operation
+func (statement *Statement) Table() string {
+	return statement.scope.QuotedTableName()
+}
+
+// Operation return the kind of the operation, which is one of create, update, delete, query and row_query
+func (statement *Statement) Operation() string {
+	if len(statement.scope.callbacks) == 0 {
+		return ""
+	}
+
+	for _, processor := range statement.scope.db.parent.callbacks.processors {
+		if processor.processor == statement.scope.callbacks[0] {
+			return processor.kind
+		}
+	}
+	return ""
+}
+
+// Changes return columns and values written by the operation, the record's values are returned if they will be
+// written, only changed values are returned for records tracked with `DB.TrackChanges`
+func (statement *Statement) Changes() map[string]interface{} {
+	var changes = map[string]interface{}{}
+	if attrs, ok := statement.scope.InstanceGet("gorm:update_attrs"); ok {
+		for column, value := range attrs.(map[string]interface{}) {
+			changes[column] = value
+		}
+		return changes
+	}
+
+	operation := statement.Operation()
+	if operation != "create" && operation != "update" {
+		return changes
+	}
+
+	scope := statement.recordScope()
+	original, tracked := scope.originalValues()
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored || !statement.scope.changeableField(field) {
+			continue
+		}
+
+		if field.IsPrimaryKey && (operation == "update" || field.IsBlank) {
+			continue
+		}
+
+		if operation == "update" && tracked && !original.changed(field) {
+			continue
+		}
+		changes[field.DBName] = field.Field.Interface()
+	}
+	return changes
+}
+
+// Changed report whether fields with names are changed by the operation, refer `Scope.Changed`
+func (statement *Statement) Changed(names ...string) bool {
+	return statement.recordScope().Changed(names...)
+}
+
+// Conditions return conditions of the operation without the WHERE keyword
+func (statement *Statement) Conditions() (string, []interface{}) {
+	scope := statement.scope.clone()
+	sql := strings.TrimPrefix(scope.whereSQL(), "WHERE ")
+	return sql, scope.SQLVars
+}
+
+// SQL return the SQL of the operation and its vars, the SQL is built without executing it if the operation isn't
+// executed yet, e.g. in before hooks
+func (statement *Statement) SQL() (string, []interface{}) {
+	if statement.scope.SQL != "" {
+		return statement.scope.SQL, statement.scope.SQLVars
+	}
+
+	scope := statement.scope.clone()
+	scope.db.logMode = noLogMode
+	scope.db.values.Store("gorm:dry_run", true)
+
+	switch operation := statement.Operation(); operation {
+	case "query":
+		scope.prepareQuerySQL()
+	case "create", "update", "delete":
+		for _, processor := range scope.db.parent.callbacks.processors {
+			if processor.kind == operation && processor.name == "gorm:"+operation && processor.processor != nil {
+				(*processor.processor)(scope)
+			}
+		}
+	}
+	return scope.SQL, scope.SQLVars
+}
+
+// SetColumn set the column's value of the record, the column is written by the operation if it is called in before
+// hooks of creating or updating
+func (statement *Statement) SetColumn(name string, value interface{}) error {
+	field, ok := statement.recordScope().FieldByName(name)
+	if !ok {
+		return errors.New("could not convert column to field")
+	}
+
+	if err := field.Set(value); err != nil {
+		return err
+	}
+
+	if attrs, ok := statement.scope.InstanceGet("gorm:update_attrs"); ok && field.IsNormal && !field.IsIgnored {
+		attrs.(map[string]interface{})[field.DBName] = field.sqlValue()
+	}
+	return nil
+}
+
+// recordScope return the scope of the record running hooks, which shares settings with the statement's scope
+func (statement *Statement) recordScope() *Scope {
+	if !statement.record.IsValid() || statement.record.Kind() != reflect.Struct || !statement.record.CanAddr() ||
+		statement.record.Addr().Interface() == statement.scope.Value {
+		return statement.scope
+	}
+
+	scope := statement.scope.clone()
+	scope.Value = statement.record.Addr().Interface()
+	scope.fields = nil
+	scope.primaryKeyField = nil
+	return scope
+}
+
+// clone return a copy of the scope sharing its instance settings, used to build SQL without changing the scope
+func (scope *Scope) clone() *Scope {
+	return &Scope{
+		Search:     scope.Search.clone(),
+		Value:      scope.Value,
+		db:         scope.db.clone(),
+		instanceID: scope.InstanceID(),
+		callbacks:  scope.callbacks,
+	}
+}
@@ -0,0 +1,57 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StrictScanError occurs in strict scan mode when columns of results have no fields to scan into, or fields of the
+// destination receive no columns, refer `Session.StrictScan`
+type StrictScanError struct {
+	// UnmatchedColumns are columns of results without fields
+	UnmatchedColumns []string
+	// UnmatchedFields are names of fields without columns
+	UnmatchedFields []string
+}
+
+func (err *StrictScanError) Error() string {
+	var messages []string
+	if len(err.UnmatchedColumns) > 0 {
+		messages = append(messages, fmt.Sprintf("columns %v have no fields", strings.Join(err.UnmatchedColumns, ", ")))
+	}
+
+	if len(err.UnmatchedFields) > 0 {
+		messages = append(messages, fmt.Sprintf("fields %v receive no columns", strings.Join(err.UnmatchedFields, ", ")))
+	}
+	return "strict scan: " + strings.Join(messages, ", ")
+}
+
+// checkStrictScan report columns without fields and fields without columns in strict scan mode, results are only
+// checked once for each scope
+func (scope *Scope) checkStrictScan(columns []string, fields []*Field, matched map[*Field]bool, values []interface{}, ignored interface{}) {
+	if value, ok := scope.Get("gorm:strict_scan"); !ok || value != true {
+		return
+	}
+
+	if _, checked := scope.InstanceGet("gorm:strict_scan_checked"); checked {
+		return
+	}
+	scope.InstanceSet("gorm:strict_scan_checked", true)
+
+	err := &StrictScanError{}
+	for index, column := range columns {
+		if values[index] == ignored {
+			err.UnmatchedColumns = append(err.UnmatchedColumns, column)
+		}
+	}
+
+	for _, field := range fields {
+		if field.IsNormal && !field.IsIgnored && !matched[field] {
+			err.UnmatchedFields = append(err.UnmatchedFields, field.Name)
+		}
+	}
+
+	if len(err.UnmatchedColumns) > 0 || len(err.UnmatchedFields) > 0 {
+		scope.Err(err)
+	}
+}
@@ -0,0 +1,84 @@
+package gorm_test
+
+import (
+	"testing"
+)
+
+type PrefixedAuthor struct {
+	ID    uint
+	Name  string
+	Books []PrefixedBook
+	Tags  []PrefixedTag `gorm:"many2many:prefixed_author_tags"`
+}
+
+type PrefixedBook struct {
+	ID               uint
+	PrefixedAuthorID uint
+	Title            string
+}
+
+type PrefixedTag struct {
+	ID   uint
+	Name string
+}
+
+// TablePrefix overrides the global table prefix
+func (PrefixedTag) TablePrefix() string {
+	return "shared_"
+}
+
+func TestTablePrefix(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+	db.SetTablePrefix("app1_")
+
+	for value, tableName := range map[interface{}]string{
+		&PrefixedAuthor{}: "app1_prefixed_authors",
+		&PrefixedBook{}:   "app1_prefixed_books",
+		&PrefixedTag{}:    "shared_prefixed_tags",
+	} {
+		if name := db.NewScope(value).TableName(); name != tableName {
+			t.Errorf("Table name should be %v, but got %v", tableName, name)
+		}
+	}
+
+	if name := db.Table("prefixed_authors").NewScope(&PrefixedAuthor{}).TableName(); name != "prefixed_authors" {
+		t.Errorf("Table specified with Table shouldn't be prefixed, but got %v", name)
+	}
+
+	db.DropTableIfExists(&PrefixedAuthor{}, &PrefixedBook{}, &PrefixedTag{}, "app1_prefixed_author_tags")
+	if err := db.AutoMigrate(&PrefixedAuthor{}, &PrefixedBook{}, &PrefixedTag{}).Error; err != nil {
+		t.Fatalf("Failed to migrate prefixed tables, got %v", err)
+	}
+
+	for _, tableName := range []string{"app1_prefixed_authors", "app1_prefixed_books", "shared_prefixed_tags", "app1_prefixed_author_tags"} {
+		if !db.HasTable(tableName) {
+			t.Errorf("Table %v should be created", tableName)
+		}
+	}
+
+	author := PrefixedAuthor{
+		Name:  "author",
+		Books: []PrefixedBook{{Title: "book 1"}, {Title: "book 2"}},
+		Tags:  []PrefixedTag{{Name: "tag 1"}},
+	}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatalf("Failed to create with associations, got %v", err)
+	}
+
+	var result PrefixedAuthor
+	if err := db.Preload("Books").Preload("Tags").First(&result, author.ID).Error; err != nil {
+		t.Fatalf("Failed to preload prefixed tables, got %v", err)
+	}
+
+	if len(result.Books) != 2 || len(result.Tags) != 1 {
+		t.Errorf("Should preload associations from prefixed tables, but got %#v", result)
+	}
+
+	if count := db.Model(&result).Association("Tags").Count(); count != 1 {
+		t.Errorf("Should count associations with prefixed join table, but got %v", count)
+	}
+}
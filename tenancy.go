@@ -0,0 +1,124 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Tenancy scope records of models with the tenant field to the tenant of the context, refer `DB.RegisterTenancy`
+type Tenancy struct {
+	// Field is the name of the tenant field of models, the default is `TenantID`, fields of embedded structs are
+	// supported
+	Field string
+}
+
+type tenantContextKey struct{}
+
+// WithTenant return a context of the tenant, pass it to `DB.WithContext` to scope statements to the tenant
+func WithTenant(ctx context.Context, tenant interface{}) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext return the tenant of the context set with `WithTenant`
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	tenant := ctx.Value(tenantContextKey{})
+	return tenant, tenant != nil
+}
+
+// RegisterTenancy enforce row-level multi-tenancy for models with the tenant field, queries, updates and deletes of
+// them are scoped to the tenant of the context set with `WithContext`, and the tenant field is set to it when
+// creating, statements without tenants fail with ErrMissingTenant so tenant filters can't be forgotten
+//     db.RegisterTenancy(gorm.Tenancy{})
+//     db.WithContext(gorm.WithTenant(ctx, 42)).Find(&invoices)
+//     // SELECT * FROM "invoices" WHERE ("invoices"."tenant_id" = 42)
+//
+//     // queries of all tenants, e.g. for admins
+//     db.SkipTenancy().Find(&invoices)
+// Raw SQL isn't scoped
+func (s *DB) RegisterTenancy(tenancy Tenancy) *DB {
+	if tenancy.Field == "" {
+		tenancy.Field = "TenantID"
+	}
+	s.InstantSet("gorm:tenancy", tenancy)
+	return s
+}
+
+// SkipTenancy skip tenancy enforced with `RegisterTenancy`, for statements across tenants like admin queries
+//     db.SkipTenancy().Model(&Invoice{}).Count(&count)
+func (s *DB) SkipTenancy() *DB {
+	return s.Set("gorm:skip_tenancy", true)
+}
+
+// tenancy return the tenant field of the model and the tenant of the context if tenancy is enforced for the model
+func (scope *Scope) tenancy() (*Field, interface{}, bool) {
+	value, ok := scope.Get("gorm:tenancy")
+	if !ok || scope.HasError() || scope.Value == nil || scope.Search.raw {
+		return nil, nil, false
+	}
+
+	if skip, ok := scope.Get("gorm:skip_tenancy"); ok && skip == true {
+		return nil, nil, false
+	}
+
+	field, ok := scope.FieldByName(value.(Tenancy).Field)
+	if !ok || !field.IsNormal || field.IsIgnored {
+		return nil, nil, false
+	}
+
+	tenant, ok := TenantFromContext(scope.db.Context())
+	if !ok {
+		scope.Err(ErrMissingTenant)
+		return nil, nil, false
+	}
+	return field, tenant, true
+}
+
+// tenancyForCreateCallback set blank tenant fields of records to the tenant, records of other tenants can't be created
+func tenancyForCreateCallback(scope *Scope) {
+	if field, tenant, ok := scope.tenancy(); ok {
+		scope.assignTenant(field, tenant)
+	}
+}
+
+// tenancyForUpdateCallback scope updates to the tenant, records can't be moved to other tenants
+func tenancyForUpdateCallback(scope *Scope) {
+	field, tenant, ok := scope.tenancy()
+	if !ok {
+		return
+	}
+
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		if value, ok := attrs.(map[string]interface{})[field.DBName]; ok && !sameTenant(value, tenant) {
+			scope.Err(ErrTenantMismatch)
+		}
+	} else if scope.IndirectValue().Kind() == reflect.Struct {
+		scope.assignTenant(field, tenant)
+	}
+	scope.whereTenant(field, tenant)
+}
+
+// tenancyCallback scope queries and deletes to the tenant
+func tenancyCallback(scope *Scope) {
+	if field, tenant, ok := scope.tenancy(); ok {
+		scope.whereTenant(field, tenant)
+	}
+}
+
+func (scope *Scope) whereTenant(field *Field, tenant interface{}) {
+	if !scope.HasError() {
+		scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)), tenant)
+	}
+}
+
+func (scope *Scope) assignTenant(field *Field, tenant interface{}) {
+	if field.IsBlank {
+		scope.Err(field.Set(tenant))
+	} else if value := field.Field.Interface(); !sameTenant(value, tenant) {
+		scope.Err(ErrTenantMismatch)
+	}
+}
+
+func sameTenant(value, tenant interface{}) bool {
+	return fmt.Sprint(reflect.Indirect(reflect.ValueOf(value))) == fmt.Sprint(reflect.Indirect(reflect.ValueOf(tenant)))
+}
@@ -0,0 +1,87 @@
+package gorm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zanmato/gorm"
+)
+
+type TenantScoped struct {
+	TenantID uint `gorm:"index"`
+}
+
+type TenantInvoice struct {
+	ID uint
+	TenantScoped
+	Amount int
+}
+
+func TestTenancy(t *testing.T) {
+	db := DB.New().RegisterTenancy(gorm.Tenancy{})
+	DB.DropTableIfExists(&TenantInvoice{})
+	if err := DB.Set("gorm:table_options", "").AutoMigrate(&TenantInvoice{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	tenant1 := db.WithContext(gorm.WithTenant(context.Background(), uint(1)))
+	tenant2 := db.WithContext(gorm.WithTenant(context.Background(), uint(2)))
+
+	invoice := TenantInvoice{Amount: 10}
+	if err := tenant1.Create(&invoice).Error; err != nil || invoice.TenantID != 1 {
+		t.Fatalf("Tenant should be set when creating, got %v, %v", invoice.TenantID, err)
+	}
+	tenant2.Create(&TenantInvoice{Amount: 20})
+	tenant2.CreateInBatches([]TenantInvoice{{Amount: 30}, {Amount: 40}}, 10)
+
+	if err := tenant1.Create(&TenantInvoice{TenantScoped: TenantScoped{TenantID: 2}}).Error; !gorm.IsError(err, gorm.ErrTenantMismatch) {
+		t.Errorf("Records of other tenants shouldn't be created, got %v", err)
+	}
+
+	var invoices []TenantInvoice
+	tenant2.Order("amount").Find(&invoices)
+	if len(invoices) != 3 || invoices[0].Amount != 20 {
+		t.Errorf("Queries should be scoped to the tenant, got %v", invoices)
+	}
+
+	var count int
+	tenant1.Model(&TenantInvoice{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Counts should be scoped to the tenant, got %v", count)
+	}
+
+	var found TenantInvoice
+	if err := tenant2.First(&found, invoice.ID).Error; !gorm.IsRecordNotFoundError(err) {
+		t.Errorf("Records of other tenants shouldn't be found, got %v", err)
+	}
+
+	tenant2.Model(&TenantInvoice{}).Where("amount = ?", 10).Update("amount", 11)
+	tenant2.Where("amount = ?", 10).Delete(&TenantInvoice{})
+	DB.First(&found, invoice.ID)
+	if found.Amount != 10 {
+		t.Errorf("Records of other tenants shouldn't be updated or deleted, got %v", found.Amount)
+	}
+
+	if err := tenant1.Model(&invoice).Update("tenant_id", 2).Error; !gorm.IsError(err, gorm.ErrTenantMismatch) {
+		t.Errorf("Records shouldn't be moved to other tenants, got %v", err)
+	}
+
+	invoice.TenantID = 2
+	if err := tenant1.Save(&invoice).Error; !gorm.IsError(err, gorm.ErrTenantMismatch) {
+		t.Errorf("Records shouldn't be saved to other tenants, got %v", err)
+	}
+
+	if err := db.Find(&invoices).Error; !gorm.IsError(err, gorm.ErrMissingTenant) {
+		t.Errorf("Statements without tenants should fail, got %v", err)
+	}
+
+	db.SkipTenancy().Model(&TenantInvoice{}).Count(&count)
+	db.Session(&gorm.Session{SkipTenancy: true}).Find(&invoices)
+	if count != 4 || len(invoices) != 4 {
+		t.Errorf("Tenancy should be skipped, got %v, %v", count, len(invoices))
+	}
+
+	if err := db.Create(&User{Name: "tenancy_user"}).Error; err != nil {
+		t.Errorf("Models without tenant fields shouldn't be scoped, got %v", err)
+	}
+}
@@ -1,4 +1,7 @@
-dialects=("postgres" "mysql" "mssql" "sqlite")
+# the cgo-free sqlite driver is built with tag purego
+go build -tags purego ./...
+
+dialects=("postgres" "cockroach" "mysql" "mssql" "sqlite")
 
 for dialect in "${dialects[@]}" ; do
     DEBUG=false GORM_DIALECT=${dialect} go test
@@ -0,0 +1,224 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// throughAssociation is a has many through association, like `User.LineItems` with tag `through:Orders`, which goes
+// through the association `User.Orders` to the association `Order.LineItems` of the intermediate model
+//     type User struct {
+//       ID        uint
+//       Orders    []Order
+//       LineItems []LineItem `gorm:"through:Orders"`
+//     }
+type throughAssociation struct {
+	through      *Relationship // relationship from the source to the intermediate model, like `User.Orders`
+	source       *Relationship // relationship from the intermediate model to associations, like `Order.LineItems`
+	throughScope *Scope
+	fieldScope   *Scope
+}
+
+// throughAssociation resolve the has many through association of the field, both associations should be has one or
+// has many associations, the association of the intermediate model has the same name as the field, or the same type
+func (scope *Scope) throughAssociation(field *StructField) (*throughAssociation, error) {
+	var (
+		relationship = field.Relationship
+		fieldType    = indirectType(field.Struct.Type)
+		association  = &throughAssociation{fieldScope: scope.New(reflect.New(fieldType).Interface())}
+	)
+
+	for _, throughField := range scope.GetModelStruct().StructFields {
+		if throughField.Name == relationship.Through && isHasAssociation(throughField.Relationship) {
+			association.through = throughField.Relationship
+			association.throughScope = scope.New(reflect.New(indirectType(throughField.Struct.Type)).Interface())
+			break
+		}
+	}
+
+	if association.through == nil {
+		return nil, fmt.Errorf("invalid through association %v of %v for %v", relationship.Through, field.Name, scope.GetModelStruct().ModelType)
+	}
+
+	for _, sourceField := range association.throughScope.GetModelStruct().StructFields {
+		if isHasAssociation(sourceField.Relationship) && indirectType(sourceField.Struct.Type) == fieldType {
+			if association.source = sourceField.Relationship; sourceField.Name == field.Name {
+				break
+			}
+		}
+	}
+
+	if association.source == nil {
+		return nil, fmt.Errorf("invalid through association %v of %v, %v has no associations of %v", relationship.Through, field.Name, association.throughScope.GetModelStruct().ModelType, fieldType)
+	}
+	return association, nil
+}
+
+// joinThrough join the intermediate table to db, and filter associations of the scope's value
+//     // SELECT "line_items".* FROM "line_items" INNER JOIN "orders" ON "orders"."id" = "line_items"."order_id"
+//     // WHERE ("orders"."user_id" IN (1))
+func (scope *Scope) joinThrough(db *DB, field *StructField) *DB {
+	association, err := scope.throughAssociation(field)
+	if err != nil {
+		db = db.clone()
+		db.AddError(err)
+		return db
+	}
+
+	var (
+		through          = association.through
+		source           = association.source
+		throughTableName = association.throughScope.QuotedTableName()
+		fieldTableName   = association.fieldScope.QuotedTableName()
+		joinConditions   []string
+		joinValues       []interface{}
+		foreignDBNames   []string
+	)
+
+	for idx, foreignKey := range source.ForeignDBNames {
+		joinConditions = append(joinConditions, fmt.Sprintf("%v.%v = %v.%v", throughTableName, scope.Quote(source.AssociationForeignDBNames[idx]), fieldTableName, scope.Quote(foreignKey)))
+	}
+	if source.PolymorphicType != "" {
+		joinConditions = append(joinConditions, fmt.Sprintf("%v.%v = ?", fieldTableName, scope.Quote(source.PolymorphicDBName)))
+		joinValues = append(joinValues, source.PolymorphicValue)
+	}
+	if !db.search.Unscoped {
+		if query, args, ok := association.throughScope.softDeleteQuery(); ok {
+			joinConditions = append(joinConditions, query)
+			joinValues = append(joinValues, args...)
+		}
+	}
+
+	for _, foreignKey := range through.ForeignDBNames {
+		foreignDBNames = append(foreignDBNames, association.throughScope.TableName()+"."+foreignKey)
+	}
+	condition, values := toInCondition(scope, foreignDBNames, scope.getColumnAsArray(through.AssociationForeignFieldNames, scope.Value))
+	if len(values) == 0 {
+		condition = "1 <> 1"
+	}
+	if through.PolymorphicType != "" {
+		condition += fmt.Sprintf(" AND %v.%v = ?", throughTableName, scope.Quote(through.PolymorphicDBName))
+		values = append(values, through.PolymorphicValue)
+	}
+
+	// columns of the intermediate table would be scanned into associations, like their ids
+	if len(db.search.selects) == 0 {
+		db = db.Select(fieldTableName + ".*")
+	}
+
+	return db.Joins(fmt.Sprintf("INNER JOIN %v ON %v", throughTableName, strings.Join(joinConditions, " AND ")), joinValues...).
+		Where(condition, values...)
+}
+
+// handleHasManyThroughPreload used to preload has many through associations, intermediate records are found with
+// their keys only, then associations of them are found and assigned to their source records
+func (scope *Scope) handleHasManyThroughPreload(field *Field, conditions []interface{}) {
+	association, err := scope.throughAssociation(field.StructField)
+	if scope.Err(err) != nil {
+		return
+	}
+
+	var (
+		through = association.through
+		source  = association.source
+	)
+
+	// find keys of intermediate records
+	primaryKeys := scope.getColumnAsArray(through.AssociationForeignFieldNames, scope.Value)
+	if len(primaryKeys) == 0 {
+		return
+	}
+
+	throughDB := scope.NewDB()
+	if scope.Search.Unscoped {
+		throughDB = throughDB.Unscoped()
+	}
+	query, values := toInCondition(scope, through.ForeignDBNames, primaryKeys)
+	if through.PolymorphicType != "" {
+		query += fmt.Sprintf(" AND %v = ?", scope.Quote(through.PolymorphicDBName))
+		values = append(values, through.PolymorphicValue)
+	}
+
+	var selects []string
+	for _, column := range append(append([]string{}, through.ForeignDBNames...), source.AssociationForeignDBNames...) {
+		selects = append(selects, fmt.Sprintf("%v.%v", association.throughScope.QuotedTableName(), scope.Quote(column)))
+	}
+
+	throughResults := makeSlice(reflect.SliceOf(association.throughScope.GetModelStruct().ModelType))
+	if scope.Err(throughDB.Select(selects).Where(query, values...).Find(throughResults).Error) != nil {
+		return
+	}
+
+	// find associations of intermediate records
+	var (
+		throughValue     = indirect(reflect.ValueOf(throughResults))
+		throughToSources = map[string]string{}
+		results          = makeSlice(field.Struct.Type)
+	)
+
+	if throughKeys := scope.getColumnAsArray(source.AssociationForeignFieldNames, throughResults); len(throughKeys) > 0 {
+		preloadDB, preloadConditions := scope.generatePreloadDBWithConditions(conditions)
+		tableName, keys := scope.preloadKeys(field, source.ForeignDBNames...)
+		if source.PolymorphicDBName != "" {
+			keys = append(keys, source.PolymorphicDBName)
+		}
+		preloadDB = scope.selectPreloadKeys(preloadDB, tableName, keys)
+
+		query, values := toInCondition(scope, source.ForeignDBNames, throughKeys)
+		if source.PolymorphicType != "" {
+			query += fmt.Sprintf(" AND %v = ?", scope.Quote(source.PolymorphicDBName))
+			values = append(values, source.PolymorphicValue)
+		}
+		if scope.Err(preloadDB.Where(query, values...).Find(results, preloadConditions...).Error) != nil {
+			return
+		}
+
+		for i := 0; i < throughValue.Len(); i++ {
+			result := indirect(throughValue.Index(i))
+			throughToSources[toString(getValueFromFields(result, source.AssociationForeignFieldNames))] = toString(getValueFromFields(result, through.ForeignFieldNames))
+		}
+	}
+
+	// assign find results
+	var (
+		resultsValue       = indirect(reflect.ValueOf(results))
+		indirectScopeValue = scope.IndirectValue()
+	)
+
+	if indirectScopeValue.Kind() == reflect.Slice {
+		preloadMap := make(map[string][]reflect.Value)
+		for i := 0; i < resultsValue.Len(); i++ {
+			result := resultsValue.Index(i)
+			if key, ok := throughToSources[toString(getValueFromFields(result, source.ForeignFieldNames))]; ok {
+				preloadMap[key] = append(preloadMap[key], result)
+			}
+		}
+
+		for j := 0; j < indirectScopeValue.Len(); j++ {
+			object := indirect(indirectScopeValue.Index(j))
+			objectRealValue := getValueFromFields(object, through.AssociationForeignFieldNames)
+			f := object.FieldByName(field.Name)
+			if results, ok := preloadMap[toString(objectRealValue)]; ok {
+				f.Set(reflect.Append(reflect.MakeSlice(f.Type(), 0, len(results)), results...))
+			} else {
+				f.Set(reflect.MakeSlice(f.Type(), 0, 0))
+			}
+		}
+	} else {
+		scope.Err(field.Set(resultsValue))
+	}
+}
+
+// isHasAssociation report whether the relationship is a has one or has many association
+func isHasAssociation(relationship *Relationship) bool {
+	return relationship != nil && (relationship.Kind == "has_one" || relationship.Kind == "has_many")
+}
+
+// indirectType return the element type of slices and pointers
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,129 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Timeout set the timeout of statements executed by the returned DB, statements exceeding it are cancelled
+// with `context.DeadlineExceeded`, it is honored by create, query, update, delete, row query and `Exec`
+//     db.Timeout(3 * time.Second).Where("name = ?", "jinzhu").Find(&users)
+func (s *DB) Timeout(timeout time.Duration) *DB {
+	return s.Set("gorm:timeout", timeout)
+}
+
+// SlowThreshold log statements taking longer than threshold at WARN level with their durations and callers, even if
+// detailed logs are disabled with `LogMode(false)`, which still disables them.
+// Slow statements are printed with level `slow sql`, so loggers could filter them with the values passed to `Print`:
+// level, caller's file:line, duration, SQL, vars, rows affected and threshold
+//     db.SlowThreshold(200 * time.Millisecond).Find(&users)
+func (s *DB) SlowThreshold(threshold time.Duration) *DB {
+	return s.Set("gorm:slow_threshold", threshold)
+}
+
+// slowThreshold return the threshold set with `SlowThreshold`
+func (s *DB) slowThreshold() (time.Duration, bool) {
+	value, ok := s.Get("gorm:slow_threshold")
+	threshold, _ := value.(time.Duration)
+	return threshold, ok && threshold > 0
+}
+
+// sqlContextCommon is implemented by *sql.DB and *sql.Tx
+type sqlContextCommon interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// WithContext set the context of statements executed by the returned DB, statements are cancelled when it is done,
+// it is also passed to plugins like SQLCommenter
+//     db.WithContext(ctx).Find(&users)
+func (s *DB) WithContext(ctx context.Context) *DB {
+	return s.Set("gorm:context", ctx)
+}
+
+// Context return the context set with `WithContext`, or `context.Background()` if it isn't set
+func (s *DB) Context() context.Context {
+	if value, ok := s.Get("gorm:context"); ok {
+		if ctx, ok := value.(context.Context); ok && ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// statementContext return the context set with `WithContext` and `Timeout` and the connection to use it
+func (scope *Scope) statementContext() (context.Context, context.CancelFunc, sqlContextCommon, bool) {
+	db, isContextCommon := scope.SQLDB().(sqlContextCommon)
+	if !isContextCommon {
+		return nil, nil, nil, false
+	}
+
+	_, hasContext := scope.Get("gorm:context")
+	value, hasTimeout := scope.Get("gorm:timeout")
+	timeout, _ := value.(time.Duration)
+	if hasTimeout = hasTimeout && timeout > 0; !hasContext && !hasTimeout {
+		return nil, nil, nil, false
+	}
+
+	ctx := scope.db.Context()
+	if hasTimeout {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, db, true
+	}
+	return ctx, func() {}, db, true
+}
+
+// sqlPrepare prepare the statement, which is executed after returning, so it is prepared with the context set with
+// `WithContext` only, without the timeout
+func (scope *Scope) sqlPrepare(query string) (*sql.Stmt, error) {
+	query = scope.commentSQL(query)
+	if db, ok := scope.SQLDB().(interface {
+		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	}); ok {
+		return db.PrepareContext(scope.db.Context(), query)
+	}
+	return scope.SQLDB().Prepare(query)
+}
+
+// releaseAtDeadline release the context at its deadline, used if results might be read after returning
+func releaseAtDeadline(ctx context.Context, cancel context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		time.AfterFunc(time.Until(deadline), cancel)
+	}
+}
+
+func (scope *Scope) sqlExec(query string, args ...interface{}) (sql.Result, error) {
+	query = scope.commentSQL(query)
+	if ctx, cancel, db, ok := scope.statementContext(); ok {
+		defer cancel()
+		return db.ExecContext(ctx, query, args...)
+	}
+	return scope.SQLDB().Exec(query, args...)
+}
+
+// sqlQuery execute the query, the rows might be read after returning, so the context is released at its deadline
+func (scope *Scope) sqlQuery(query string, args ...interface{}) (*sql.Rows, error) {
+	query = scope.commentSQL(query)
+	if ctx, cancel, db, ok := scope.statementContext(); ok {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			cancel()
+		} else {
+			releaseAtDeadline(ctx, cancel)
+		}
+		return rows, err
+	}
+	return scope.SQLDB().Query(query, args...)
+}
+
+// sqlQueryRow execute the query, the row might be scanned after returning, so the context is released at its deadline
+func (scope *Scope) sqlQueryRow(query string, args ...interface{}) *sql.Row {
+	query = scope.commentSQL(query)
+	if ctx, cancel, db, ok := scope.statementContext(); ok {
+		releaseAtDeadline(ctx, cancel)
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	return scope.SQLDB().QueryRow(query, args...)
+}
@@ -0,0 +1,152 @@
+package gorm
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// TimePrecision set the default number of fractional second digits of time fields, fields could set their own with
+// tag `precision`, time columns are created with the precision, e.g. `DATETIME(6)` on mysql and `timestamp(3)` on
+// postgres, and times are truncated to it when records are written, so values read back equal the written values
+//     type Event struct {
+//       ID         uint
+//       OccurredAt time.Time `gorm:"precision:3"`
+//     }
+//     db = db.TimePrecision(6)
+//     db.AutoMigrate(&Event{})
+//     // CREATE TABLE `events` (`id` int unsigned AUTO_INCREMENT,`occurred_at` DATETIME(3) NULL, ...)
+// times aren't truncated if neither of them is set, dialects store them with their default precisions
+func (s *DB) TimePrecision(precision int) *DB {
+	return s.Set("gorm:time_precision", precision)
+}
+
+// timePrecision return the precision of the time field from its tag or the setting of `TimePrecision`
+func (scope *Scope) timePrecision(field *StructField) (int, bool) {
+	if !isTimeField(field) {
+		return 0, false
+	}
+
+	if value, ok := field.TagSettingsGet("PRECISION"); ok {
+		precision, err := strconv.Atoi(value)
+		return precision, err == nil
+	}
+
+	if value, ok := scope.Get("gorm:time_precision"); ok {
+		precision, ok := value.(int)
+		return precision, ok
+	}
+	return 0, false
+}
+
+func isTimeField(field *StructField) bool {
+	fieldType := field.Struct.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType == reflect.TypeOf(time.Time{})
+}
+
+// withTimePrecision return the time field with tag `precision` set to the precision of `TimePrecision`
+func (scope *Scope) withTimePrecision(field *StructField) *StructField {
+	if _, ok := field.TagSettingsGet("PRECISION"); ok {
+		return field
+	}
+
+	if precision, ok := scope.timePrecision(field); ok {
+		field = field.clone()
+		field.TagSettingsSet("PRECISION", strconv.Itoa(precision))
+	}
+	return field
+}
+
+// truncateTime truncate the time or pointer of time to the precision, other values are returned as they are
+func truncateTime(value interface{}, precision int) interface{} {
+	if precision < 0 || precision >= 9 {
+		return value
+	}
+
+	unit := time.Second
+	for i := 0; i < precision; i++ {
+		unit /= 10
+	}
+
+	switch t := value.(type) {
+	case time.Time:
+		return t.Truncate(unit)
+	case *time.Time:
+		if t != nil {
+			truncated := t.Truncate(unit)
+			return &truncated
+		}
+	}
+	return value
+}
+
+// TimeZone store times in the location, e.g. `time.UTC`, timestamps like `CreatedAt` and `DeletedAt` are set to the
+// current time in it, and times of records are converted to it when records are written
+//     db = db.TimeZone(time.UTC)
+// refer `Session.NowFunc` to freeze the current time, e.g. in tests
+func (s *DB) TimeZone(location *time.Location) *DB {
+	return s.Set("gorm:time_zone", location)
+}
+
+func (s *DB) timeZone() (*time.Location, bool) {
+	value, ok := s.Get("gorm:time_zone")
+	location, _ := value.(*time.Location)
+	return location, ok && location != nil
+}
+
+// normalizeTime convert the time or pointer of time to the location if it isn't nil and truncate it to the precision
+// if it is set, other values are returned as they are
+func normalizeTime(value interface{}, location *time.Location, precision int, truncate bool) interface{} {
+	if location != nil {
+		switch t := value.(type) {
+		case time.Time:
+			value = t.In(location)
+		case *time.Time:
+			if t != nil {
+				converted := t.In(location)
+				value = &converted
+			}
+		}
+	}
+
+	if truncate {
+		value = truncateTime(value, precision)
+	}
+	return value
+}
+
+// normalizeTimesCallback convert time fields and updated time attributes to the time zone of `TimeZone` and truncate
+// them to their precisions before writing records
+func normalizeTimesCallback(scope *Scope) {
+	if scope.HasError() || scope.IndirectValue().Kind() != reflect.Struct {
+		return
+	}
+
+	var updateAttrs map[string]interface{}
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		updateAttrs = attrs.(map[string]interface{})
+	}
+
+	location, _ := scope.db.timeZone()
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored || !isTimeField(field.StructField) {
+			continue
+		}
+
+		precision, truncate := scope.timePrecision(field.StructField)
+		if location == nil && !truncate {
+			continue
+		}
+
+		if !field.IsBlank {
+			field.Field.Set(reflect.ValueOf(normalizeTime(field.Field.Interface(), location, precision, truncate)))
+		}
+
+		if value, ok := updateAttrs[field.DBName]; ok {
+			updateAttrs[field.DBName] = normalizeTime(value, location, precision, truncate)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gorm
+
+import (
+	"strings"
+	"time"
+)
+
+// Timestamp fields are set to the current time when creating and updating, fields are found by names or tags, integer
+// fields are set to unix timestamps in seconds, or milliseconds and nanoseconds with tag values `milli` and `nano`
+//     type User struct {
+//       ID        uint
+//       CreatedAt int64                               // unix seconds, set when creating if blank
+//       UpdatedAt int64 `gorm:"autoUpdateTime:milli"` // unix milliseconds, set when creating and updating
+//       Modified  int64 `gorm:"autoUpdateTime:nano"`  // tagged fields are filled too
+//       Joined    time.Time `gorm:"autoCreateTime"`
+//       Synced    time.Time `gorm:"autoUpdateTime:false"` // disable tracking fields with a tag value `false`
+//     }
+const (
+	autoCreateTime = "AUTOCREATETIME"
+	autoUpdateTime = "AUTOUPDATETIME"
+)
+
+var autoTimeFieldNames = map[string]string{
+	autoCreateTime: "CreatedAt",
+	autoUpdateTime: "UpdatedAt",
+}
+
+// autoTimeUnit return the unit of the timestamp field tracked with the tag, reports false if the field isn't tracked
+func autoTimeUnit(field *StructField, tag string) (string, bool) {
+	value, ok := field.TagSettingsGet(tag)
+	if !ok {
+		return "", field.Name == autoTimeFieldNames[tag]
+	}
+
+	value = strings.ToLower(strings.TrimSpace(value))
+	return value, value != "false"
+}
+
+func isAutoTimeField(field *Field, tag string) bool {
+	_, ok := autoTimeUnit(field.StructField, tag)
+	return ok
+}
+
+// timestampFields return fields tracked with the tag
+func (scope *Scope) timestampFields(tag string) (fields []*Field) {
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+
+		if isAutoTimeField(field, tag) {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// timestampValue return the value of now for the field tracked with the tag, unix timestamps for integer fields
+func timestampValue(field *Field, tag string, now time.Time) interface{} {
+	if !isIntegerType(field.Struct.Type) {
+		return now
+	}
+
+	switch unit, _ := autoTimeUnit(field.StructField, tag); unit {
+	case "milli":
+		return now.UnixNano() / int64(time.Millisecond)
+	case "nano":
+		return now.UnixNano()
+	default:
+		return now.Unix()
+	}
+}
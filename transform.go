@@ -0,0 +1,116 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Transformer transform values of fields before they are written, fields use transformers with tag `transform`,
+// transformers are applied in order
+//     type User struct {
+//       Email    string `gorm:"transform:trim,lower"`
+//       Password string `gorm:"transform:hash"`
+//     }
+//
+//     gorm.RegisterTransformer("hash", func(value interface{}) (interface{}, error) {
+//       if password := value.(string); !isHashed(password) {
+//         return hash(password)
+//       }
+//       return value, nil
+//     })
+// Transformers are applied whenever records are saved, so they should be idempotent
+type Transformer func(value interface{}) (interface{}, error)
+
+var transformers sync.Map
+
+func init() {
+	RegisterTransformer("trim", stringTransformer("trim", strings.TrimSpace))
+	RegisterTransformer("lower", stringTransformer("lower", strings.ToLower))
+	RegisterTransformer("upper", stringTransformer("upper", strings.ToUpper))
+}
+
+// RegisterTransformer register a transformer with name, which could be used with tag `transform:name`
+func RegisterTransformer(name string, transformer Transformer) {
+	transformers.Store(name, transformer)
+}
+
+// GetTransformer get the transformer registered with name
+func GetTransformer(name string) (Transformer, bool) {
+	if transformer, ok := transformers.Load(name); ok {
+		return transformer.(Transformer), true
+	}
+	return nil, false
+}
+
+// stringTransformer transform strings and pointers of strings with fn
+func stringTransformer(name string, fn func(string) string) Transformer {
+	return func(value interface{}) (interface{}, error) {
+		reflectValue := reflect.Indirect(reflect.ValueOf(value))
+		if reflectValue.Kind() != reflect.String {
+			return nil, fmt.Errorf("transformer %v can't transform %T", name, value)
+		}
+		return fn(reflectValue.String()), nil
+	}
+}
+
+// transform apply transformers of the field's tag `transform` to the value, nil values and expressions aren't
+// transformed
+func transform(field *StructField, value interface{}) (interface{}, error) {
+	names, ok := field.TagSettingsGet("TRANSFORM")
+	if _, isExpr := value.(*SqlExpr); !ok || isExpr || value == nil {
+		return value, nil
+	}
+
+	if reflectValue := reflect.ValueOf(value); reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil() {
+		return value, nil
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		transformer, ok := GetTransformer(name)
+		if !ok {
+			return nil, fmt.Errorf("transformer %v of field %v is not registered", name, field.Name)
+		}
+
+		var err error
+		if value, err = transformer(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// transformFieldsCallback apply transformers to fields and updated attributes before writing records
+func transformFieldsCallback(scope *Scope) {
+	if scope.HasError() || scope.IndirectValue().Kind() != reflect.Struct {
+		return
+	}
+
+	var updateAttrs map[string]interface{}
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		updateAttrs = attrs.(map[string]interface{})
+	}
+
+	for _, field := range scope.Fields() {
+		if _, ok := field.TagSettingsGet("TRANSFORM"); !ok || !field.IsNormal || field.IsIgnored {
+			continue
+		}
+
+		if !field.IsBlank {
+			value, err := transform(field.StructField, field.Field.Interface())
+			if scope.Err(err) != nil || scope.Err(field.Set(value)) != nil {
+				return
+			}
+		}
+
+		if value, ok := updateAttrs[field.DBName]; ok {
+			transformed, err := transform(field.StructField, value)
+			if scope.Err(err) != nil {
+				return
+			}
+			updateAttrs[field.DBName] = transformed
+		}
+	}
+}
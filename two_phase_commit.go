@@ -0,0 +1,224 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TwoPhaseCommitter is implemented by dialects supporting two phase commits, transactions are controlled with the
+// statements on a dedicated connection, the xid is generated by `Coordinator`
+type TwoPhaseCommitter interface {
+	// BeginTwoPhaseSQL return statements starting the transaction
+	BeginTwoPhaseSQL(xid string) []string
+	// PrepareTwoPhaseSQL return statements preparing the transaction, which could be committed after that
+	PrepareTwoPhaseSQL(xid string) []string
+	// CommitPreparedSQL return statements committing the prepared transaction
+	CommitPreparedSQL(xid string) []string
+	// RollbackTwoPhaseSQL return statements rolling back the transaction, which may be prepared
+	RollbackTwoPhaseSQL(xid string, prepared bool) []string
+}
+
+// Coordinator run a function against several DBs and commit their transactions together, it is best-effort:
+// transactions of dialects implementing TwoPhaseCommitter (PREPARE TRANSACTION of postgres, XA of mysql) are
+// prepared before any transaction is committed, transactions of other dialects are committed before prepared ones,
+// so one DB without two phase commits is still atomic, changes of committed DBs are undone with their compensations
+// if later DBs fail to commit
+//     err := gorm.NewCoordinator().Join(ordersDB, nil).Join(billingDB, nil).Run(func(txs []*gorm.DB) error {
+//       if err := txs[0].Create(&order).Error; err != nil {
+//         return err
+//       }
+//       return txs[1].Create(&invoice).Error
+//     })
+// prepared transactions which failed to commit are left in the database to be recovered with their xids, which
+// are reported with the error
+type Coordinator struct {
+	participants []*participant
+}
+
+type participant struct {
+	db         *DB
+	compensate func(db *DB) error
+	tx         *DB
+	conn       *twoPhaseConn
+	xid        string
+	prepared   bool
+}
+
+// NewCoordinator create a coordinator of transactions across DBs
+func NewCoordinator() *Coordinator {
+	return &Coordinator{}
+}
+
+// Join add db to the coordinator, compensate is called with db to undo changes if db is committed but the DBs after
+// it fail to commit, it could be nil
+func (coordinator *Coordinator) Join(db *DB, compensate func(db *DB) error) *Coordinator {
+	coordinator.participants = append(coordinator.participants, &participant{db: db, compensate: compensate})
+	return coordinator
+}
+
+// Run call fc with transactions of joined DBs in the joined order, transactions are committed if fc return nil,
+// otherwise rolled back
+func (coordinator *Coordinator) Run(fc func(txs []*DB) error) (err error) {
+	uuid, err := newUUIDv4()
+	if err != nil {
+		return err
+	}
+
+	var txs []*DB
+	for idx, p := range coordinator.participants {
+		p.xid, p.prepared = fmt.Sprintf("gorm-%v-%d", formatUUID(uuid), idx), false
+		if err = p.begin(); err != nil {
+			coordinator.rollback()
+			return err
+		}
+		txs = append(txs, p.tx)
+	}
+
+	panicked := true
+	defer func() {
+		if panicked {
+			coordinator.rollback()
+		}
+	}()
+
+	err = fc(txs)
+	panicked = false
+	if err != nil {
+		coordinator.rollback()
+		return err
+	}
+
+	for _, p := range coordinator.participants {
+		if p.conn != nil {
+			if err = p.exec(p.dialect().PrepareTwoPhaseSQL(p.xid)); err != nil {
+				coordinator.rollback()
+				return err
+			}
+			p.prepared = true
+		}
+	}
+
+	var committed []*participant
+	for _, p := range coordinator.participants {
+		if p.conn == nil {
+			if err = p.tx.Commit().Error; err != nil {
+				p.tx.Rollback()
+				p.tx = nil
+				coordinator.rollback()
+				return coordinator.compensate(committed, err)
+			}
+			p.tx = nil
+			committed = append(committed, p)
+		}
+	}
+
+	var failedXids []string
+	for _, p := range coordinator.participants {
+		if p.conn != nil {
+			if commitErr := p.exec(p.dialect().CommitPreparedSQL(p.xid)); commitErr != nil {
+				err = commitErr
+				failedXids = append(failedXids, p.xid)
+			}
+			p.release()
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to commit prepared transactions %v: %v", strings.Join(failedXids, ", "), err)
+	}
+	return nil
+}
+
+// rollback roll back transactions which aren't committed
+func (coordinator *Coordinator) rollback() {
+	for _, p := range coordinator.participants {
+		if p.conn != nil {
+			p.exec(p.dialect().RollbackTwoPhaseSQL(p.xid, p.prepared))
+			p.release()
+		} else if p.tx != nil {
+			p.tx.Rollback()
+			p.tx = nil
+		}
+	}
+}
+
+// compensate undo changes of committed DBs in reverse order
+func (coordinator *Coordinator) compensate(committed []*participant, err error) error {
+	for i := len(committed) - 1; i >= 0; i-- {
+		if p := committed[i]; p.compensate != nil {
+			if compensateErr := p.compensate(p.db); compensateErr != nil {
+				return fmt.Errorf("%v, failed to compensate: %v", err, compensateErr)
+			}
+		}
+	}
+	return err
+}
+
+func (p *participant) dialect() TwoPhaseCommitter {
+	committer, _ := twoPhaseCommitter(p.db.Dialect())
+	return committer
+}
+
+// twoPhaseCommitter return the dialect if it supports two phase commits, CockroachDB inherits statements of postgres
+// but doesn't support prepared transactions
+func twoPhaseCommitter(dialect Dialect) (TwoPhaseCommitter, bool) {
+	committer, ok := dialect.(TwoPhaseCommitter)
+	return committer, ok && dialect.GetName() != "cockroach"
+}
+
+// begin start the transaction, with a dedicated connection if the dialect supports two phase commits
+func (p *participant) begin() error {
+	committer, ok := twoPhaseCommitter(p.db.Dialect())
+	if !ok {
+		p.tx = p.db.Begin()
+		return p.tx.Error
+	}
+
+	sqlDB, ok := p.db.CommonDB().(*sql.DB)
+	if !ok {
+		return errors.New("two phase commits should be started with a DB which isn't in a transaction")
+	}
+
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+
+	p.conn = &twoPhaseConn{sqlConn{conn}}
+	p.tx = p.db.clone()
+	p.tx.db = p.conn
+	p.tx.dialect.SetDB(p.conn)
+
+	if err = p.exec(committer.BeginTwoPhaseSQL(p.xid)); err == nil {
+		err = p.tx.runConnectionHooks()
+	}
+
+	if err != nil {
+		p.release()
+	}
+	return err
+}
+
+func (p *participant) exec(statements []string) error {
+	for _, statement := range statements {
+		if _, err := p.conn.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *participant) release() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.tx = nil, nil
+	}
+}
+
+// twoPhaseConn is a dedicated connection whose transaction is controlled by two phase commit statements
+type twoPhaseConn struct {
+	sqlConn
+}
@@ -0,0 +1,132 @@
+package gorm_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zanmato/gorm"
+	"github.com/zanmato/gorm/dialects/sqlite"
+)
+
+type TwoPhaseOrder struct {
+	ID   uint
+	Code string
+}
+
+type TwoPhaseInvoice struct {
+	ID        uint
+	OrderCode string `gorm:"unique_index"`
+}
+
+func TestCoordinator(t *testing.T) {
+	billingDB, err := gorm.Open("sqlite3", sqlite.DriverName, filepath.Join(os.TempDir(), "gorm_billing.db"))
+	if err != nil {
+		t.Fatalf("Failed to open billing database, got %v", err)
+	}
+	defer billingDB.Close()
+
+	ordersDB := DB.Set("gorm:table_options", "")
+	ordersDB.DropTableIfExists(&TwoPhaseOrder{})
+	ordersDB.AutoMigrate(&TwoPhaseOrder{})
+	billingDB.DropTableIfExists(&TwoPhaseInvoice{})
+	billingDB.AutoMigrate(&TwoPhaseInvoice{})
+
+	create := func(code string) func(txs []*gorm.DB) error {
+		return func(txs []*gorm.DB) error {
+			if err := txs[0].Create(&TwoPhaseOrder{Code: code}).Error; err != nil {
+				return err
+			}
+			return txs[1].Create(&TwoPhaseInvoice{OrderCode: code}).Error
+		}
+	}
+
+	if err := gorm.NewCoordinator().Join(ordersDB, nil).Join(billingDB, nil).Run(create("order1")); err != nil {
+		t.Fatalf("Failed to run coordinated transactions, got %v", err)
+	}
+
+	var count int
+	if DB.Model(&TwoPhaseOrder{}).Where("code = ?", "order1").Count(&count); count != 1 {
+		t.Errorf("Order should be committed, got %v", count)
+	}
+
+	if billingDB.Model(&TwoPhaseInvoice{}).Where("order_code = ?", "order1").Count(&count); count != 1 {
+		t.Errorf("Invoice should be committed, got %v", count)
+	}
+
+	if err := gorm.NewCoordinator().Join(ordersDB, nil).Join(billingDB, nil).Run(create("order1")); err == nil {
+		t.Errorf("Should return error of failed transactions")
+	}
+
+	if DB.Model(&TwoPhaseOrder{}).Where("code = ?", "order1").Count(&count); count != 1 {
+		t.Errorf("Order should be rolled back if the invoice failed to create, got %v", count)
+	}
+
+	err = gorm.NewCoordinator().Join(ordersDB, nil).Join(billingDB, nil).Run(func(txs []*gorm.DB) error {
+		if err := create("order2")(txs); err != nil {
+			return err
+		}
+		return errors.New("cancelled")
+	})
+
+	if err == nil || err.Error() != "cancelled" {
+		t.Errorf("Should return error of the function, got %v", err)
+	}
+
+	if DB.Model(&TwoPhaseOrder{}).Where("code = ?", "order2").Count(&count); count != 0 {
+		t.Errorf("Order should be rolled back, got %v", count)
+	}
+
+	if billingDB.Model(&TwoPhaseInvoice{}).Where("order_code = ?", "order2").Count(&count); count != 0 {
+		t.Errorf("Invoice should be rolled back, got %v", count)
+	}
+}
+
+func TestCoordinatorCompensate(t *testing.T) {
+	billingDB, err := gorm.Open("sqlite3", sqlite.DriverName, filepath.Join(os.TempDir(), "gorm_billing.db"))
+	if err != nil {
+		t.Fatalf("Failed to open billing database, got %v", err)
+	}
+	defer billingDB.Close()
+
+	billingDB.DropTableIfExists(&TwoPhaseInvoice{})
+	billingDB.AutoMigrate(&TwoPhaseInvoice{})
+
+	// foreign keys are checked when committing, so the transaction fails to commit
+	shippingDB, err := gorm.Open("sqlite3", sqlite.DriverName, "file:"+filepath.Join(os.TempDir(), "gorm_shipping.db")+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatalf("Failed to open shipping database, got %v", err)
+	}
+	defer shippingDB.Close()
+
+	shippingDB.Exec("DROP TABLE IF EXISTS shipments")
+	shippingDB.Exec("CREATE TABLE IF NOT EXISTS addresses (id INTEGER PRIMARY KEY)")
+	shippingDB.Exec("CREATE TABLE shipments (id INTEGER PRIMARY KEY, address_id INTEGER REFERENCES addresses(id) DEFERRABLE INITIALLY DEFERRED)")
+
+	var compensated bool
+	compensate := func(db *gorm.DB) error {
+		compensated = true
+		return db.Where("order_code = ?", "order3").Delete(&TwoPhaseInvoice{}).Error
+	}
+
+	err = gorm.NewCoordinator().Join(billingDB, compensate).Join(shippingDB, nil).Run(func(txs []*gorm.DB) error {
+		if err := txs[0].Create(&TwoPhaseInvoice{OrderCode: "order3"}).Error; err != nil {
+			return err
+		}
+		return txs[1].Exec("INSERT INTO shipments (address_id) VALUES (?)", 404).Error
+	})
+
+	if err == nil {
+		t.Errorf("Should return error if failed to commit")
+	}
+
+	if !compensated {
+		t.Errorf("Committed databases should be compensated")
+	}
+
+	var count int
+	if billingDB.Model(&TwoPhaseInvoice{}).Where("order_code = ?", "order3").Count(&count); count != 0 {
+		t.Errorf("Invoice should be compensated, got %v", count)
+	}
+}
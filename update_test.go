@@ -1,6 +1,7 @@
 package gorm_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -463,3 +464,198 @@ func TestUpdateDecodeVirtualAttributes(t *testing.T) {
 		t.Errorf("should decode virtual attributes to struct, so it could be used in callbacks")
 	}
 }
+
+type TrackedProduct struct {
+	ID           uint
+	Code         string
+	Price        int
+	UpdatedAt    time.Time
+	PriceChanged bool `sql:"-"`
+}
+
+func (product *TrackedProduct) BeforeUpdate(scope *gorm.Scope) {
+	product.PriceChanged = scope.Changed("Price")
+}
+
+func TestTrackChanges(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&TrackedProduct{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&TrackedProduct{})
+
+	product := TrackedProduct{Code: "track_changes", Price: 100}
+	DB.Save(&product)
+
+	tx := DB.TrackChanges()
+	var loaded TrackedProduct
+	tx.First(&loaded, product.ID)
+
+	DB.Model(&TrackedProduct{}).Where("id = ?", product.ID).UpdateColumn("price", 200)
+
+	loaded.Code = "track_changes_2"
+	if sql, _ := tx.Session(&gorm.Session{DryRun: true}).Save(&loaded).DryRunSQL(); strings.Contains(sql, "price") || !strings.Contains(sql, "code") || !strings.Contains(sql, "updated_at") {
+		t.Errorf("Should only update changed fields, got %v", sql)
+	}
+
+	if loaded.PriceChanged {
+		t.Errorf("Price should not be changed in hooks")
+	}
+
+	tx = DB.Session(&gorm.Session{TrackChanges: true})
+	tx.First(&loaded, product.ID)
+	loaded.Code = "track_changes_2"
+	tx.Save(&loaded)
+
+	var result TrackedProduct
+	DB.First(&result, product.ID)
+	if result.Code != "track_changes_2" || result.Price != 200 {
+		t.Errorf("Concurrent updates of unchanged fields should be kept, got %v, %v", result.Code, result.Price)
+	}
+
+	updatedAt := result.UpdatedAt
+	tx.Save(&loaded)
+	if DB.First(&result, product.ID); !result.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("Should not update records without changes")
+	}
+
+	var products []TrackedProduct
+	tx.Where("id = ?", product.ID).Find(&products)
+	products[0].Price = 300
+	tx.Save(&products[0])
+	if DB.First(&result, product.ID); result.Price != 300 || !products[0].PriceChanged {
+		t.Errorf("Records found as slices should be tracked, got %v", result.Price)
+	}
+
+	if DB.Save(&result); !result.PriceChanged {
+		t.Errorf("Fields of records not tracked should be changed")
+	}
+
+	tx.First(&loaded, product.ID)
+	DB.Delete(&TrackedProduct{}, product.ID)
+	if err := tx.Save(&loaded).Error; err != nil {
+		t.Errorf("Saving records without changes should be no-op, got %v", err)
+	}
+	if !DB.First(&TrackedProduct{}, product.ID).RecordNotFound() {
+		t.Errorf("Saving records without changes shouldn't create them")
+	}
+}
+
+func TestUpdateWithSubQuery(t *testing.T) {
+	user := User{Name: "update_with_sub_query", Emails: []Email{{Email: "sub_query1@example.com"}, {Email: "sub_query2@example.com"}}}
+	DB.Save(&user)
+
+	emailsCount := DB.Model(&Email{}).Select("count(*)").Where("emails.user_id = users.id")
+	if err := DB.Model(&user).Update("age", emailsCount).Error; err != nil {
+		t.Errorf("Should update with sub query, got %v", err)
+	}
+
+	var result User
+	if DB.First(&result, user.Id); result.Age != 2 {
+		t.Errorf("Should update with correlated sub query, got %v", result.Age)
+	}
+
+	if err := DB.Table("users").Where("id = ?", user.Id).Updates(map[string]interface{}{
+		"age": DB.Model(&Email{}).Select("count(*)").Where("emails.user_id = users.id AND emails.email = ?", "sub_query1@example.com").QueryExpr(),
+	}).Error; err != nil {
+		t.Errorf("Should update with query expr, got %v", err)
+	}
+
+	if DB.First(&result, user.Id); result.Age != 1 {
+		t.Errorf("Should update with correlated query expr, got %v", result.Age)
+	}
+}
+
+type UnixTimestampRecord struct {
+	ID        uint
+	Name      string
+	CreatedAt int64
+	UpdatedAt int64     `gorm:"autoUpdateTime:milli"`
+	Modified  int64     `gorm:"autoUpdateTime:nano"`
+	Joined    time.Time `gorm:"autoCreateTime"`
+	Synced    int64     `gorm:"autoUpdateTime:false"`
+}
+
+func TestUnixTimestampFields(t *testing.T) {
+	DB.Set("gorm:table_options", "").DropTableIfExists(&UnixTimestampRecord{})
+	DB.Set("gorm:table_options", "").AutoMigrate(&UnixTimestampRecord{})
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	db := DB.New().SetNowFuncOverride(func() time.Time { return now })
+
+	record := UnixTimestampRecord{Name: "unix"}
+	if err := db.Create(&record).Error; err != nil {
+		t.Fatalf("Failed to create record with unix timestamps, got %v", err)
+	}
+
+	if record.CreatedAt != now.Unix() || record.UpdatedAt != now.UnixNano()/int64(time.Millisecond) ||
+		record.Modified != now.UnixNano() || !record.Joined.Equal(now) || record.Synced != 0 {
+		t.Errorf("Timestamp fields should be set when creating, got %#v", record)
+	}
+
+	updated := now.Add(time.Hour)
+	db = DB.New().SetNowFuncOverride(func() time.Time { return updated })
+	if err := db.Model(&record).Update("name", "unix_updated").Error; err != nil {
+		t.Fatalf("Failed to update record with unix timestamps, got %v", err)
+	}
+
+	var result UnixTimestampRecord
+	DB.First(&result, record.ID)
+	if result.CreatedAt != now.Unix() || result.UpdatedAt != updated.UnixNano()/int64(time.Millisecond) ||
+		result.Modified != updated.UnixNano() || result.Synced != 0 {
+		t.Errorf("Update timestamp fields should be set when updating, got %#v", result)
+	}
+
+	result.Name = "unix_saved"
+	later := updated.Add(time.Hour)
+	DB.New().SetNowFuncOverride(func() time.Time { return later }).Save(&result)
+	if DB.First(&result, record.ID); result.CreatedAt != now.Unix() || result.UpdatedAt != later.UnixNano()/int64(time.Millisecond) {
+		t.Errorf("Update timestamp fields should be set when saving, got %#v", result)
+	}
+}
+
+func TestSelectAndOmitNestedAssociationFields(t *testing.T) {
+	user := User{
+		Name:           "nested_select",
+		Age:            20,
+		BillingAddress: Address{Address1: "nested_select_address1", Address2: "nested_select_address2"},
+		Emails:         []Email{{Email: "nested_select@example.com"}},
+	}
+	DB.Save(&user)
+
+	user.Name = "nested_select_new"
+	user.Age = 30
+	user.BillingAddress.Address1 = "nested_select_address1_new"
+	user.BillingAddress.Address2 = "nested_select_address2_new"
+	user.Emails[0].Email = "nested_select_new@example.com"
+
+	if err := DB.Select("Name", "BillingAddress.Address1").Save(&user).Error; err != nil {
+		t.Fatalf("Failed to save with nested selected fields, got %v", err)
+	}
+
+	var result User
+	DB.Preload("BillingAddress").Preload("Emails").First(&result, user.Id)
+	if result.Name != "nested_select_new" || result.Age != 20 {
+		t.Errorf("Only selected fields should be saved, got %v, %v", result.Name, result.Age)
+	}
+
+	if result.BillingAddress.Address1 != "nested_select_address1_new" || result.BillingAddress.Address2 != "nested_select_address2" {
+		t.Errorf("Only selected nested fields should be saved, got %+v", result.BillingAddress)
+	}
+
+	if result.Emails[0].Email != "nested_select@example.com" {
+		t.Errorf("Associations not selected shouldn't be saved, got %v", result.Emails[0].Email)
+	}
+
+	user.Emails[0].Email = "nested_omit@example.com"
+	user.BillingAddress.Address1 = "nested_omit_address1"
+	if err := DB.Omit("Emails.Email").Save(&user).Error; err != nil {
+		t.Fatalf("Failed to save with nested omitted fields, got %v", err)
+	}
+
+	DB.Preload("BillingAddress").Preload("Emails").First(&result, user.Id)
+	if result.Age != 30 || result.BillingAddress.Address2 != "nested_select_address2_new" {
+		t.Errorf("Fields not omitted should be saved, got %v, %+v", result.Age, result.BillingAddress)
+	}
+
+	if result.Emails[0].Email != "nested_select@example.com" {
+		t.Errorf("Omitted nested fields shouldn't be saved, got %v", result.Emails[0].Email)
+	}
+}
@@ -3,6 +3,7 @@ package gorm
 import (
 	"database/sql/driver"
 	"fmt"
+	"path"
 	"reflect"
 	"regexp"
 	"runtime"
@@ -26,7 +27,12 @@ var commonInitialisms = []string{"API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GU
 var commonInitialismsReplacer *strings.Replacer
 
 var goSrcRegexp = regexp.MustCompile(`zanmato/gorm(@.*)?/.*.go`)
-var goTestRegexp = regexp.MustCompile(`zanmato/gorm(@.*)?/.*test.go`)
+
+// gormSourceDir is the directory of gorm source files, callers in it are skipped even if gorm is vendored or replaced
+var gormSourceDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return path.Dir(file) + "/"
+}()
 
 func init() {
 	var commonInitialismsForReplacer []string
@@ -76,6 +82,15 @@ func indirect(reflectValue reflect.Value) reflect.Value {
 	return reflectValue
 }
 
+// isMapDestination return true if value is a pointer of map[string]interface{} or []map[string]interface{}
+func isMapDestination(value interface{}) bool {
+	switch value.(type) {
+	case *map[string]interface{}, *[]map[string]interface{}:
+		return true
+	}
+	return false
+}
+
 func toQueryMarks(primaryValues [][]interface{}) string {
 	var results []string
 
@@ -106,6 +121,44 @@ func toQueryCondition(scope *Scope, columns []string) string {
 	return strings.Join(newColumns, ",")
 }
 
+// toInCondition build the condition matching columns with any of values, composite columns are matched with `OR` of
+// `AND` conditions, as row values like `("a","b") IN (("1","2"))` aren't supported by all dialects, no values match
+// nothing, as `IN ()` isn't valid SQL
+func toInCondition(scope *Scope, columns []string, values [][]interface{}) (string, []interface{}) {
+	if len(values) == 0 {
+		return "1 = 0", nil
+	}
+
+	if len(columns) <= 1 {
+		return fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, columns), toQueryMarks(values)), toQueryValues(values)
+	}
+
+	var conditions []string
+	for range values {
+		var equalities []string
+		for _, column := range columns {
+			equalities = append(equalities, fmt.Sprintf("%v = ?", scope.Quote(column)))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%v)", strings.Join(equalities, " AND ")))
+	}
+	return fmt.Sprintf("(%v)", strings.Join(conditions, " OR ")), toQueryValues(values)
+}
+
+// toNotInCondition build the condition matching columns with none of values, refer `toInCondition`, no values match
+// everything
+func toNotInCondition(scope *Scope, columns []string, values [][]interface{}) (string, []interface{}) {
+	if len(values) == 0 {
+		return "1 = 1", nil
+	}
+
+	if len(columns) <= 1 {
+		return fmt.Sprintf("%v NOT IN (%v)", toQueryCondition(scope, columns), toQueryMarks(values)), toQueryValues(values)
+	}
+
+	condition, vars := toInCondition(scope, columns, values)
+	return "NOT " + condition, vars
+}
+
 func toQueryValues(values [][]interface{}) (results []interface{}) {
 	for _, value := range values {
 		for _, v := range value {
@@ -115,16 +168,28 @@ func toQueryValues(values [][]interface{}) (results []interface{}) {
 	return
 }
 
+// fileWithLineNum return file:line of the first caller outside gorm, test files of gorm are treated as callers
 func fileWithLineNum() string {
-	for i := 2; i < 15; i++ {
+	for i := 2; i < 30; i++ {
 		_, file, line, ok := runtime.Caller(i)
-		if ok && (!goSrcRegexp.MatchString(file) || goTestRegexp.MatchString(file)) {
+		if !ok {
+			break
+		}
+
+		if strings.HasSuffix(file, "_test.go") || !isGormSource(file) {
 			return fmt.Sprintf("%v:%v", file, line)
 		}
 	}
 	return ""
 }
 
+func isGormSource(file string) bool {
+	if goSrcRegexp.MatchString(file) {
+		return true
+	}
+	return strings.HasPrefix(file, gormSourceDir) && !strings.Contains(strings.TrimPrefix(file, gormSourceDir), "/")
+}
+
 func isBlank(value reflect.Value) bool {
 	switch value.Kind() {
 	case reflect.String:
@@ -199,6 +264,44 @@ func strInSlice(a string, list []string) bool {
 	return false
 }
 
+// splitKeys split comma separated keys like `CompanyCode, OrderNo` of tags, blank keys are skipped
+func splitKeys(str string) (keys []string) {
+	for _, key := range strings.Split(str, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// splitSelects split selected columns like `name, COALESCE(nickname, '') AS alias` at commas, which aren't in
+// parentheses or quotes
+func splitSelects(str string) (selects []string) {
+	var (
+		depth int
+		quote rune
+		start int
+	)
+	for idx, s := range str {
+		switch {
+		case quote != 0:
+			if s == quote {
+				quote = 0
+			}
+		case s == '\'' || s == '"' || s == '`':
+			quote = s
+		case s == '(':
+			depth++
+		case s == ')':
+			depth--
+		case s == ',' && depth == 0:
+			selects = append(selects, str[start:idx])
+			start = idx + 1
+		}
+	}
+	return append(selects, str[start:])
+}
+
 // getValueFromFields return given fields's value
 func getValueFromFields(value reflect.Value, fieldNames []string) (results []interface{}) {
 	// If value is a nil pointer, Indirect returns a zero Value!
@@ -224,3 +327,16 @@ func addExtraSpaceIfExist(str string) string {
 	}
 	return ""
 }
+
+func isIntegerType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
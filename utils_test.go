@@ -0,0 +1,21 @@
+package gorm
+
+import "testing"
+
+func TestInConditionsOfCompositeKeys(t *testing.T) {
+	scope := &Scope{db: &DB{dialect: &commonDialect{}}}
+	columns := []string{"order_no", "line_no"}
+
+	if sql, vars := toInCondition(scope, columns, nil); sql != "1 = 0" || len(vars) != 0 {
+		t.Errorf("Empty key sets shouldn't match any records, got %v %v", sql, vars)
+	}
+
+	if sql, vars := toNotInCondition(scope, columns, nil); sql != "1 = 1" || len(vars) != 0 {
+		t.Errorf("Empty key sets shouldn't exclude any records, got %v %v", sql, vars)
+	}
+
+	sql, vars := toInCondition(scope, columns, [][]interface{}{{"A1", 1}, {"A1", 2}})
+	if sql != `(("order_no" = ? AND "line_no" = ?) OR ("order_no" = ? AND "line_no" = ?))` || len(vars) != 4 {
+		t.Errorf("Composite keys should be matched with OR of AND conditions, got %v %v", sql, vars)
+	}
+}
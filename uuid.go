@@ -0,0 +1,216 @@
+package gorm
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// uuidGenerators contains UUID generators could be used with tag `default`, e.g:
+//     ID string `gorm:"primary_key;default:uuid"`
+var uuidGenerators = map[string]func() ([16]byte, error){
+	"uuid":    newUUIDv4,
+	"uuid_v4": newUUIDv4,
+	"uuid_v7": newUUIDv7,
+}
+
+// uuidGeneratorOf return the UUID generator defined with field's `default` tag
+func uuidGeneratorOf(field *StructField) (func() ([16]byte, error), bool) {
+	if value, ok := field.TagSettingsGet("DEFAULT"); ok {
+		generator, ok := uuidGenerators[strings.ToLower(strings.Trim(value, "'\""))]
+		return generator, ok
+	}
+	return nil, false
+}
+
+// newUUIDv4 generate a random UUID (RFC 4122 version 4)
+func newUUIDv4() (uuid [16]byte, err error) {
+	if _, err = rand.Read(uuid[:]); err != nil {
+		return
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return
+}
+
+// newUUIDv7 generate a time ordered UUID (RFC 9562 version 7)
+func newUUIDv7() (uuid [16]byte, err error) {
+	if _, err = rand.Read(uuid[6:]); err != nil {
+		return
+	}
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(NowFunc().UnixNano()/1e6))
+	copy(uuid[:6], timestamp[2:])
+	uuid[6] = (uuid[6] & 0x0f) | 0x70
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return
+}
+
+func formatUUID(uuid [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// setUUID assign generated UUID to field, string fields get the canonical format,
+// byte arrays/slices get the raw 16 bytes
+func setUUID(field *Field, uuid [16]byte) error {
+	fieldType := field.Struct.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch {
+	case fieldType.Kind() == reflect.Array && fieldType.Len() == 16 && fieldType.Elem().Kind() == reflect.Uint8:
+		value := reflect.New(fieldType).Elem()
+		reflect.Copy(value, reflect.ValueOf(uuid[:]))
+		return field.Set(value)
+	case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8:
+		return field.Set(uuid[:])
+	default:
+		return field.Set(formatUUID(uuid))
+	}
+}
+
+// isBinaryUUIDField report whether the field is stored as binary UUID with tag `type:uuid_binary`, which is BINARY(16)
+// for mysql and mssql, uuid for postgres and BLOB for others, values of the field are UUIDs in the canonical format
+// or 16 bytes, the time bytes of version 1 UUIDs could be swapped to be index friendly with tag `swap_time`, the same
+// as `UUID_TO_BIN(uuid, 1)` of mysql
+//     ID        string `gorm:"primary_key;type:uuid_binary;default:uuid_v7"`
+//     SessionID string `gorm:"type:uuid_binary;swap_time"`
+func isBinaryUUIDField(field *StructField) bool {
+	value, ok := field.TagSettingsGet("TYPE")
+	return ok && strings.ToLower(strings.TrimSpace(value)) == "uuid_binary"
+}
+
+func binaryUUIDDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres", "cockroach":
+		return "UUID"
+	case "mysql", "mssql":
+		return "BINARY(16)"
+	default:
+		return "BLOB"
+	}
+}
+
+func isNativeUUIDDialect(dialect Dialect) bool {
+	switch dialect.GetName() {
+	case "postgres", "cockroach":
+		return true
+	}
+	return false
+}
+
+// binaryUUIDValue convert the UUID of binary UUID fields to 16 bytes, or the canonical format for dialects with
+// native uuid types
+type binaryUUIDValue struct {
+	field  *StructField
+	value  interface{}
+	binary bool
+}
+
+// String return the UUID, so keys of records are compared with their values
+func (v binaryUUIDValue) String() string {
+	return fmt.Sprint(v.value)
+}
+
+func (v binaryUUIDValue) Value() (driver.Value, error) {
+	if isNilValue(v.value) || isBlank(reflect.ValueOf(v.value)) {
+		return nil, nil
+	}
+
+	uuid, err := parseUUID(v.value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID of field %v: %v", v.field.Name, err)
+	}
+
+	if !v.binary {
+		return formatUUID(uuid), nil
+	}
+
+	if _, ok := v.field.TagSettingsGet("SWAP_TIME"); ok {
+		uuid = swapUUIDTime(uuid)
+	}
+	return uuid[:], nil
+}
+
+// binaryUUIDValuer bind UUIDs of binary UUID fields for the dialect
+type binaryUUIDValuer struct {
+	binaryUUIDValue
+}
+
+func (valuer binaryUUIDValuer) GormValue(dialect Dialect) *SqlExpr {
+	value := valuer.binaryUUIDValue
+	value.binary = !isNativeUUIDDialect(dialect)
+	return Expr("?", value)
+}
+
+// binaryUUIDScanner scan binary UUIDs or UUIDs in the canonical format into binary UUID fields
+type binaryUUIDScanner struct {
+	field *Field
+}
+
+func (scanner *binaryUUIDScanner) Scan(src interface{}) error {
+	if src == nil {
+		return scanner.field.Set(nil)
+	}
+
+	data, err := dbValueBytes(src)
+	if err != nil {
+		return err
+	}
+
+	var uuid [16]byte
+	if len(data) == 16 {
+		copy(uuid[:], data)
+		if _, ok := scanner.field.TagSettingsGet("SWAP_TIME"); ok {
+			uuid = unswapUUIDTime(uuid)
+		}
+	} else if uuid, err = parseUUID(string(data)); err != nil {
+		return fmt.Errorf("invalid UUID of field %v: %v", scanner.field.Name, err)
+	}
+	return setUUID(scanner.field, uuid)
+}
+
+// parseUUID parse UUIDs in the canonical format, or 16 bytes
+func parseUUID(value interface{}) (uuid [16]byte, err error) {
+	reflectValue := indirect(reflect.ValueOf(value))
+	switch {
+	case reflectValue.Kind() == reflect.String:
+		var decoded []byte
+		if decoded, err = hex.DecodeString(strings.Replace(reflectValue.String(), "-", "", -1)); err != nil {
+			return
+		}
+
+		if len(decoded) != 16 {
+			return uuid, fmt.Errorf("%v is not a UUID", value)
+		}
+		copy(uuid[:], decoded)
+	case (reflectValue.Kind() == reflect.Array || reflectValue.Kind() == reflect.Slice) && reflectValue.Type().Elem().Kind() == reflect.Uint8 && reflectValue.Len() == 16:
+		reflect.Copy(reflect.ValueOf(uuid[:]), reflectValue)
+	default:
+		err = fmt.Errorf("%v is not a UUID", value)
+	}
+	return
+}
+
+// swapUUIDTime move the high and middle time bytes to the front, so UUIDs of version 1 are time ordered
+func swapUUIDTime(uuid [16]byte) (swapped [16]byte) {
+	copy(swapped[0:2], uuid[6:8])
+	copy(swapped[2:4], uuid[4:6])
+	copy(swapped[4:8], uuid[0:4])
+	copy(swapped[8:], uuid[8:])
+	return
+}
+
+func unswapUUIDTime(swapped [16]byte) (uuid [16]byte) {
+	copy(uuid[0:4], swapped[4:8])
+	copy(uuid[4:6], swapped[2:4])
+	copy(uuid[6:8], swapped[0:2])
+	copy(uuid[8:], swapped[8:])
+	return
+}
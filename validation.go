@@ -0,0 +1,91 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator is implemented by models to validate records before they are created or updated, records aren't written
+// if it returns an error, return ValidationErrors to report errors of fields
+//     func (user *User) Validate() error {
+//       var errs gorm.ValidationErrors
+//       if user.Name == "" {
+//         errs = append(errs, gorm.FieldError{Field: "Name", Message: "can't be blank"})
+//       }
+//       return errs
+//     }
+// It is called after `BeforeSave`, `BeforeCreate` and `BeforeUpdate` hooks, validations could be skipped with
+// `DB.SkipValidation`
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a validation error of a field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (err FieldError) Error() string {
+	return fmt.Sprintf("%v %v", err.Field, err.Message)
+}
+
+// ValidationErrors is returned by `Validator`s to report errors of fields, empty ValidationErrors are not errors
+//     if errs, ok := db.Create(&user).Error.(gorm.ValidationErrors); ok {
+//       for _, err := range errs {
+//         form.AddError(err.Field, err.Message)
+//       }
+//     }
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// SkipValidation skip validating records with `Validator`, e.g. for imports of trusted data
+//     db.SkipValidation().Create(&user)
+func (s *DB) SkipValidation() *DB {
+	return s.Set("gorm:skip_validation", true)
+}
+
+// validateCallback validate records implementing `Validator` before they are created or updated
+func validateCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if _, ok := scope.Get("gorm:update_column"); ok {
+		return
+	}
+
+	if skip, ok := scope.Get("gorm:skip_validation"); ok && skip == true {
+		return
+	}
+
+	record := scope.IndirectValue()
+	if record.Kind() != reflect.Struct || !record.CanAddr() {
+		return
+	}
+
+	if validator, ok := record.Addr().Interface().(Validator); ok {
+		err := validator.Validate()
+		if errs, ok := err.(ValidationErrors); ok && len(errs) == 0 {
+			err = nil
+		}
+		scope.Err(err)
+	}
+}
+
+// validateForUpdateCallback validate records being updated, bulk updates like
+// `db.Model(&User{}).Where("age = ?", 1).Update("age", 2)` are skipped as they don't save the blank model
+func validateForUpdateCallback(scope *Scope) {
+	if scope.PrimaryKeyZero() {
+		return
+	}
+	validateCallback(scope)
+}
@@ -0,0 +1,195 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrViewQueryRequired occurs when creating views without queries
+var ErrViewQueryRequired = errors.New("query is required to create views")
+
+// ViewOption is the option to create views
+type ViewOption struct {
+	// Query is the query of the view
+	Query *DB
+	// Replace replace the view if it exists
+	Replace bool
+	// CheckOption is the check option of updatable views, e.g. `WITH CHECK OPTION`
+	CheckOption string
+}
+
+// CreateView create view with the query, values of the query are inlined as views can't have bind vars
+//     db.CreateView("active_users", gorm.ViewOption{Query: db.Model(&User{}).Where("active = ?", true), Replace: true})
+//
+//     type ActiveUser struct {
+//       User
+//     }
+//
+//     func (ActiveUser) TableName() string {
+//       return "active_users"
+//     }
+//
+//     db.Find(&activeUsers)
+func (s *DB) CreateView(name string, option ViewOption) *DB {
+	db := s.clone()
+	if option.Query == nil {
+		db.AddError(ErrViewQueryRequired)
+		return db
+	}
+
+	query := option.Query.QueryExpr()
+	querySQL, err := interpolateSQL(s.Dialect(), query.expr, query.args)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	createSQL := "CREATE VIEW"
+	if option.Replace {
+		switch s.Dialect().GetName() {
+		case "sqlite3":
+			// sqlite doesn't support replacing views
+			if db = db.DropView(name); db.Error != nil {
+				return db
+			}
+		case "mssql":
+			createSQL = "CREATE OR ALTER VIEW"
+		default:
+			createSQL = "CREATE OR REPLACE VIEW"
+		}
+	}
+
+	return db.Exec(fmt.Sprintf("%v %v AS %v%v", createSQL, quoteTableName(s.Dialect(), name), querySQL, addExtraSpaceIfExist(option.CheckOption)))
+}
+
+// DropView drop the view if it exists
+func (s *DB) DropView(name string) *DB {
+	return s.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %v", quoteTableName(s.Dialect(), name)))
+}
+
+// interpolateSQL replace placeholders `?` or `$n` of the SQL with literals of vars, placeholders in quoted strings and
+// identifiers are kept, used for statements which can't have bind vars
+func interpolateSQL(dialect Dialect, sql string, vars []interface{}) (string, error) {
+	var (
+		result strings.Builder
+		quote  byte
+		count  int
+	)
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' || c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			position := count
+			if c == '$' {
+				end := i + 1
+				for end < len(sql) && isDigit(sql[end]) {
+					end++
+				}
+				position, _ = strconv.Atoi(sql[i+1 : end])
+				position, i = position-1, end-1
+			}
+
+			if position >= len(vars) {
+				return "", fmt.Errorf("%v: not enough values for placeholders of %v", ErrInvalidSQL, sql)
+			}
+
+			literal, err := sqlLiteral(dialect, vars[position])
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(literal)
+
+			if position >= count {
+				count = position + 1
+			}
+			continue
+		}
+		result.WriteByte(c)
+	}
+
+	if count != len(vars) {
+		return "", fmt.Errorf("%v: too many values for placeholders of %v", ErrInvalidSQL, sql)
+	}
+	return result.String(), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// sqlLiteral return the value as a SQL literal of the dialect
+func sqlLiteral(dialect Dialect, value interface{}) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if isNilValue(value) {
+			return "NULL", nil
+		}
+
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return "", err
+		}
+	}
+
+	if value == nil {
+		return "NULL", nil
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return "NULL", nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+
+	if t, ok := reflectValue.Interface().(time.Time); ok {
+		switch dialect.GetName() {
+		case "mysql", "mssql":
+			return quoteString(dialect, t.Format("2006-01-02 15:04:05.999999999")), nil
+		}
+		return quoteString(dialect, t.Format("2006-01-02 15:04:05.999999999-07:00")), nil
+	}
+
+	switch reflectValue.Kind() {
+	case reflect.Bool:
+		switch dialect.GetName() {
+		case "postgres", "cloudsqlpostgres", "cockroach":
+			return strings.ToUpper(fmt.Sprint(reflectValue.Bool())), nil
+		}
+		if reflectValue.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.String:
+		return quoteString(dialect, reflectValue.String()), nil
+	case reflect.Slice:
+		if reflectValue.Type().Elem().Kind() == reflect.Uint8 {
+			return quoteString(dialect, string(reflectValue.Bytes())), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(reflectValue.Interface()), nil
+	}
+	return quoteString(dialect, fmt.Sprint(reflectValue.Interface())), nil
+}
+
+// quoteString quote the string as a SQL string literal, backslashes are escaped for mysql
+func quoteString(dialect Dialect, str string) string {
+	if dialect.GetName() == "mysql" {
+		str = strings.Replace(str, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
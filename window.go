@@ -0,0 +1,265 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	windowOrderRegexp = regexp.MustCompile(`(?i)^\s*(\S+)(\s+(ASC|DESC))?\s*$`)
+	versionRegexp     = regexp.MustCompile(`^(\d+)\.(\d+)`)
+	// windowFunctionsSupports caches whether mysql servers support window functions, by the parent DB
+	windowFunctionsSupports sync.Map
+)
+
+// WindowFunction is a window function like `ROW_NUMBER() OVER (PARTITION BY "user_id" ORDER BY "created_at" DESC)`,
+// which is a `Clause`, so it could be selected with `Select`
+//     db.Select("*, ?", gorm.RowNumber().Over(gorm.PartitionBy("user_id").OrderBy("created_at desc")).As("position")).
+//       Find(&orders)
+//     // SELECT *, ROW_NUMBER() OVER (PARTITION BY "user_id" ORDER BY "created_at" DESC) AS "position" FROM "orders"
+type WindowFunction struct {
+	function string
+	args     []interface{}
+	window   *Window
+	alias    string
+}
+
+// RowNumber return the window function `ROW_NUMBER()`, numbering rows of windows from 1
+func RowNumber() *WindowFunction {
+	return &WindowFunction{function: "ROW_NUMBER"}
+}
+
+// Rank return the window function `RANK()`, ranking rows of windows with gaps for ties
+func Rank() *WindowFunction {
+	return &WindowFunction{function: "RANK"}
+}
+
+// DenseRank return the window function `DENSE_RANK()`, ranking rows of windows without gaps for ties
+func DenseRank() *WindowFunction {
+	return &WindowFunction{function: "DENSE_RANK"}
+}
+
+// WindowFunc return the window function of the name, like aggregate functions, arguments are bound as vars, columns
+// could be passed with `Column`
+//     gorm.WindowFunc("SUM", gorm.Column("amount")).Over(gorm.PartitionBy("user_id"))
+//     // SUM("amount") OVER (PARTITION BY "user_id")
+func WindowFunc(name string, args ...interface{}) *WindowFunction {
+	return &WindowFunction{function: name, args: args}
+}
+
+// Over set the window of the function, all rows are in the same window if it is nil
+func (function *WindowFunction) Over(window *Window) *WindowFunction {
+	clone := *function
+	clone.window = window
+	return &clone
+}
+
+// As name the result of the function when it is selected
+func (function *WindowFunction) As(alias string) *WindowFunction {
+	clone := *function
+	clone.alias = alias
+	return &clone
+}
+
+// Build build the window function
+func (function *WindowFunction) Build(builder ClauseBuilder) {
+	builder.WriteString(function.function + "(")
+	if len(function.args) > 0 {
+		builder.AddVar(function.args...)
+	}
+	builder.WriteString(") OVER (")
+	if function.window != nil {
+		function.window.build(builder)
+	}
+	builder.WriteString(")")
+
+	if function.alias != "" {
+		builder.WriteString(" AS ")
+		builder.WriteQuoted(function.alias)
+	}
+}
+
+// Window is the window of window functions, rows are partitioned by columns and ordered in partitions
+type Window struct {
+	partitionBy []string
+	orderBy     []string
+}
+
+// PartitionBy return the window partitioning rows by the columns
+func PartitionBy(columns ...string) *Window {
+	return &Window{partitionBy: columns}
+}
+
+// OrderBy order rows of partitions, orders are columns followed by optional directions like `created_at desc`
+func (window *Window) OrderBy(orders ...string) *Window {
+	clone := *window
+	clone.orderBy = append(window.orderBy[:len(window.orderBy):len(window.orderBy)], orders...)
+	return &clone
+}
+
+func (window *Window) build(builder ClauseBuilder) {
+	for idx, column := range window.partitionBy {
+		if idx == 0 {
+			builder.WriteString("PARTITION BY ")
+		} else {
+			builder.WriteString(", ")
+		}
+		builder.WriteQuoted(strings.TrimSpace(column))
+	}
+
+	for idx, order := range window.orderBy {
+		if idx == 0 {
+			if len(window.partitionBy) > 0 {
+				builder.WriteString(" ")
+			}
+			builder.WriteString("ORDER BY ")
+		} else {
+			builder.WriteString(", ")
+		}
+
+		column, direction := parseWindowOrder(order)
+		builder.WriteQuoted(column)
+		if direction != "" {
+			builder.WriteString(" " + direction)
+		}
+	}
+}
+
+// parseWindowOrder split the order into the column and direction, the whole order is used as the column if it
+// isn't a column followed by `ASC` or `DESC`, so it is quoted instead of being written as raw SQL
+func parseWindowOrder(order string) (column, direction string) {
+	if matches := windowOrderRegexp.FindStringSubmatch(order); matches != nil {
+		return matches[1], strings.ToUpper(matches[3])
+	}
+	return strings.TrimSpace(order), ""
+}
+
+// Column return the clause of the quoted column, like `users.name`
+func Column(name string) Clause {
+	return ClauseFunc(func(builder ClauseBuilder) {
+		builder.WriteQuoted(name)
+	})
+}
+
+// LatestPerGroup filter the first n records of each group partitioned by the column when they are ordered by orders,
+// like the latest orders of every user, records are ranked after they are filtered with other conditions.
+// The primary key is used to break ties, MySQL servers without window functions (before 8.0 or MariaDB 10.2) are
+// filtered with correlated subqueries counting records before them, which could be forced by setting
+// `gorm:window_functions` to false
+//     db.Where("state = ?", "paid").LatestPerGroup(3, "user_id", "created_at desc").Find(&orders)
+//     // SELECT * FROM "orders" WHERE (state = 'paid') AND ("orders"."id" IN (SELECT "id" FROM (SELECT "orders"."id",
+//     // ROW_NUMBER() OVER (PARTITION BY "user_id" ORDER BY "created_at" DESC, "id") AS "gorm_row_number"
+//     // FROM "orders" WHERE (state = 'paid')) AS "gorm_ranked" WHERE "gorm_row_number" <= 3))
+func (s *DB) LatestPerGroup(n int, partitionBy string, orderBy ...string) *DB {
+	return s.clone().search.Where(&latestPerGroup{n: n, partitionBy: partitionBy, orderBy: orderBy}).db
+}
+
+// latestPerGroup is the condition of `LatestPerGroup`, it is built when querying, as it depends on the model and
+// other conditions
+type latestPerGroup struct {
+	n           int
+	partitionBy string
+	orderBy     []string
+}
+
+func (latest *latestPerGroup) conditionSQL(scope *Scope) string {
+	primaryKey := scope.PrimaryKey()
+	if primaryKey == "" {
+		scope.Err(fmt.Errorf("%v: records without primary keys can't be filtered per group", ErrInvalidSQL))
+		return ""
+	}
+
+	// records are ranked with other conditions, orders, limits and offsets are only used by the outer query
+	ranked := scope.NewDB()
+	ranked.Value = scope.Value
+	ranked.search = scope.Search.clone()
+	ranked.search.db = ranked
+	ranked.search.whereConditions = nil
+	ranked.search.Order(nil, true).Limit(-1).Offset(-1)
+	ranked.search.selects, ranked.search.preload, ranked.search.group, ranked.search.havingConditions = nil, nil, "", nil
+	for _, clause := range scope.Search.whereConditions {
+		if clause["query"] != latest {
+			ranked.search.whereConditions = append(ranked.search.whereConditions, clause)
+		}
+	}
+
+	orderBy := append(latest.orderBy[:len(latest.orderBy):len(latest.orderBy)], primaryKey)
+	if !scope.supportsWindowFunctions() {
+		preceding := ranked.Alias("gorm_latest").Select("COUNT(*)").
+			Where(fmt.Sprintf("%v.%v = %v.%v", scope.Quote("gorm_latest"), scope.Quote(latest.partitionBy), scope.QuotedTableName(), scope.Quote(latest.partitionBy))).
+			Where(scope.precedingCondition("gorm_latest", orderBy))
+		return fmt.Sprintf("%v < %v", scope.AddToVars(preceding.SubQuery()), scope.AddToVars(latest.n))
+	}
+
+	ranked = ranked.Select("?, ?",
+		Column(fmt.Sprintf("%v.%v", ranked.NewScope(ranked.Value).TableName(), primaryKey)),
+		RowNumber().Over(PartitionBy(latest.partitionBy).OrderBy(orderBy...)).As("gorm_row_number"),
+	)
+	return fmt.Sprintf(
+		"%v.%v IN (SELECT %v FROM %v AS %v WHERE %v <= %v)",
+		scope.QuotedTableName(), scope.Quote(primaryKey), scope.Quote(primaryKey), scope.AddToVars(ranked.SubQuery()),
+		scope.Quote("gorm_ranked"), scope.Quote("gorm_row_number"), scope.AddToVars(latest.n),
+	)
+}
+
+// precedingCondition return the condition of records of the alias ordered before records of the scope's table
+func (scope *Scope) precedingCondition(alias string, orderBy []string) string {
+	var (
+		quotedTableName = scope.QuotedTableName()
+		conditions      []string
+		equalities      []string
+	)
+
+	for _, order := range orderBy {
+		column, direction := parseWindowOrder(order)
+		aliasColumn := fmt.Sprintf("%v.%v", scope.Quote(alias), scope.Quote(column))
+		tableColumn := fmt.Sprintf("%v.%v", quotedTableName, scope.Quote(column))
+
+		operator := "<"
+		if direction == "DESC" {
+			operator = ">"
+		}
+		conditions = append(conditions, strings.Join(append(equalities[:len(equalities):len(equalities)], fmt.Sprintf("%v %v %v", aliasColumn, operator, tableColumn)), " AND "))
+		equalities = append(equalities, fmt.Sprintf("%v = %v", aliasColumn, tableColumn))
+	}
+	return "(" + strings.Join(conditions, ") OR (") + ")"
+}
+
+// supportsWindowFunctions report whether the database supports window functions, versions of mysql servers are
+// queried once
+func (scope *Scope) supportsWindowFunctions() bool {
+	if value, ok := scope.Get("gorm:window_functions"); ok {
+		if supports, ok := value.(bool); ok {
+			return supports
+		}
+	}
+
+	if scope.Dialect().GetName() != "mysql" {
+		return true
+	}
+
+	if supports, ok := windowFunctionsSupports.Load(scope.db.parent); ok {
+		return supports.(bool)
+	}
+
+	var version string
+	if err := scope.SQLDB().QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return true
+	}
+
+	supports := true
+	if matches := versionRegexp.FindStringSubmatch(version); matches != nil {
+		major, _ := strconv.Atoi(matches[1])
+		minor, _ := strconv.Atoi(matches[2])
+		if strings.Contains(strings.ToLower(version), "mariadb") {
+			supports = major > 10 || major == 10 && minor >= 2
+		} else {
+			supports = major >= 8
+		}
+	}
+	windowFunctionsSupports.Store(scope.db.parent, supports)
+	return supports
+}
@@ -0,0 +1,55 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WriteLimiter is implemented by dialects supporting ORDER BY and LIMIT in UPDATE and DELETE statements like mysql,
+// so `Order` and `Limit` are applied to the statements directly, otherwise records are limited with a sub-select of
+// their primary keys
+//     db.Where("created_at < ?", cutoff).Order("created_at").Limit(1000).Delete(&Event{})
+//     // mysql: DELETE FROM `events` WHERE (created_at < ?) ORDER BY created_at LIMIT 1000
+//     // others: DELETE FROM "events" WHERE "id" IN (SELECT * FROM (SELECT "events"."id" FROM "events"
+//     //   WHERE (created_at < $1) ORDER BY created_at LIMIT 1000) AS limited_records)
+type WriteLimiter interface {
+	// SupportWriteLimit report whether UPDATE and DELETE statements support ORDER BY and LIMIT without OFFSET and joins
+	SupportWriteLimit() bool
+}
+
+// writeConditionSQL return conditions of UPDATE and DELETE statements, statements with `Limit` or `Offset` are
+// limited natively if the dialect supports it, or with a sub-select of primary keys
+func (scope *Scope) writeConditionSQL() string {
+	limitSQL := scope.limitAndOffsetSQL()
+	if limitSQL == "" {
+		return scope.CombinedConditionSql()
+	}
+
+	if limiter, ok := scope.Dialect().(WriteLimiter); ok && limiter.SupportWriteLimit() && len(scope.Search.joinConditions) == 0 {
+		if sql, err := scope.Dialect().LimitAndOffsetSQL(scope.Search.limit, nil); err == nil && sql == limitSQL {
+			return scope.CombinedConditionSql()
+		}
+	}
+
+	primaryFields := scope.PrimaryFields()
+	if len(primaryFields) == 0 {
+		scope.Err(errors.New("records without primary keys can't be updated or deleted with limits"))
+		return ""
+	}
+
+	var columns, qualifiedColumns []string
+	for _, field := range primaryFields {
+		columns = append(columns, field.DBName)
+		qualifiedColumns = append(qualifiedColumns, fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName)))
+	}
+
+	// the sub-select is wrapped with a derived table, as mysql can't select from the table being written to
+	return fmt.Sprintf(
+		"WHERE %v IN (SELECT * FROM (SELECT %v FROM %v %v) AS limited_records)",
+		toQueryCondition(scope, columns),
+		strings.Join(qualifiedColumns, ","),
+		scope.QuotedTableName(),
+		scope.CombinedConditionSql(),
+	)
+}